@@ -1,10 +1,25 @@
 package main
 
 import (
+	"log"
+	"os"
+
 	"github.com/airbnb/rudolph/internal/handlers/authorizer"
+	"github.com/airbnb/rudolph/pkg/logging"
 	"github.com/aws/aws-lambda-go/lambda"
 )
 
 func main() {
+	loggingConfig, err := logging.ConfigFromEnv(os.Getenv)
+	if err != nil {
+		log.Fatalf("unable to configure logging, %v", err)
+	}
+	logging.Configure(loggingConfig)
+
+	// The same binary authorizes the admin API, with tokens from the identity provider instead of machine IDs
+	if os.Getenv("AUTHORIZER") == "admin" {
+		lambda.Start(authorizer.HandleAdminAuthorizerRequest)
+		return
+	}
 	lambda.Start(authorizer.HandleAuthorizerRequest)
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/internal/console"
+	"github.com/airbnb/rudolph/pkg/logging"
+	"github.com/airbnb/rudolph/pkg/oidc"
+)
+
+func main() {
+	loggingConfig, err := logging.ConfigFromEnv(os.Getenv)
+	if err != nil {
+		log.Fatalf("unable to configure logging, %v", err)
+	}
+	logging.Configure(loggingConfig)
+
+	consoleURL := strings.TrimSuffix(os.Getenv("CONSOLE_URL"), "/")
+	if consoleURL == "" {
+		log.Fatal("CONSOLE_URL is required")
+	}
+	login, err := oidc.DiscoverLogin(
+		os.Getenv("OIDC_ISSUER"),
+		os.Getenv("OIDC_CLIENT_ID"),
+		os.Getenv("OIDC_CLIENT_SECRET"),
+		consoleURL+"/callback",
+	)
+	if err != nil {
+		log.Fatalf("unable to configure login, %v", err)
+	}
+
+	server, err := console.New(console.Config{
+		AdminAPIURL: os.Getenv("RUDOLPH_ADMIN_API_URL"),
+		URL:         consoleURL,
+		Login:       login,
+		SessionKey:  []byte(os.Getenv("CONSOLE_SESSION_KEY")),
+		Contact:     os.Getenv("CONSOLE_SUPPORT_CONTACT"),
+	})
+	if err != nil {
+		log.Fatalf("unable to start the console, %v", err)
+	}
+
+	addr := os.Getenv("CONSOLE_LISTEN_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	slog.Info("Console listening", "addr", addr)
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Fatal(httpServer.ListenAndServe())
+}
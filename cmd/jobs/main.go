@@ -0,0 +1,22 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/airbnb/rudolph/internal/handlers"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/logging"
+	"github.com/airbnb/rudolph/pkg/metrics"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func main() {
+	loggingConfig, err := logging.ConfigFromEnv(os.Getenv)
+	if err != nil {
+		log.Fatalf("unable to configure logging, %v", err)
+	}
+	logging.Configure(loggingConfig)
+	metrics.SetRecorder(metrics.NewEMFRecorder(os.Stdout, os.Getenv("METRICS_NAMESPACE"), clock.ConcreteTimeProvider{}))
+	lambda.Start(handlers.JobRouter)
+}
@@ -13,6 +13,38 @@ module "santa_api" {
   eventupload_kinesis_name        = var.eventupload_kinesis_name
   eventupload_autocreate_policies = var.eventupload_autocreate_policies
   eventupload_output_lambda_name  = var.eventupload_output_lambda_name
+  eventupload_store_events        = var.eventupload_store_events
+  eventupload_update_catalog      = var.eventupload_update_catalog
+
+  # Automatic MONITOR -> LOCKDOWN promotion
+  lockdown_promotion_enabled         = var.lockdown_promotion_enabled
+  lockdown_promotion_schedule        = var.lockdown_promotion_schedule
+  lockdown_promotion_monitor_days    = var.lockdown_promotion_monitor_days
+  lockdown_promotion_daily_cap       = var.lockdown_promotion_daily_cap
+  lockdown_promotion_groups          = var.lockdown_promotion_groups
+  lockdown_promotion_excluded_groups = var.lockdown_promotion_excluded_groups
+  lockdown_demotion_window_hours     = var.lockdown_demotion_window_hours
+  lockdown_demotion_block_threshold  = var.lockdown_demotion_block_threshold
+  lockdown_promotion_dry_run         = var.lockdown_promotion_dry_run
+
+  # Sync health report
+  sync_health_enabled              = var.sync_health_enabled
+  sync_health_schedule             = var.sync_health_schedule
+  sync_health_stale_days           = var.sync_health_stale_days
+  sync_health_repeated_clean_syncs = var.sync_health_repeated_clean_syncs
+
+  # Lambda function logs
+  log_level             = var.log_level
+  log_debug_machine_ids = var.log_debug_machine_ids
+
+  # Admin API
+  admin_api_enabled              = var.admin_api_enabled
+  admin_api_oidc_issuer          = var.admin_api_oidc_issuer
+  admin_api_oidc_audience        = var.admin_api_oidc_audience
+  admin_api_oidc_jwks_url        = var.admin_api_oidc_jwks_url
+  admin_api_oidc_principal_claim = var.admin_api_oidc_principal_claim
+  admin_api_oidc_groups_claim    = var.admin_api_oidc_groups_claim
+  admin_api_role_groups          = var.admin_api_role_groups
 
   # The route53 zone id
   route53_zone_name   = var.route53_zone_name
@@ -20,6 +52,7 @@ module "santa_api" {
 
   lambda_api_zip  = var.lambda_api_zip
   lambda_authorizer_zip = var.lambda_authorizer_zip
+  lambda_jobs_zip = var.lambda_jobs_zip
   
   enable_s3_logging = var.enable_s3_logging
 
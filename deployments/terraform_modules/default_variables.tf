@@ -8,6 +8,11 @@ variable "lambda_authorizer_zip" {
   description = "Full path to zip with go binary for Lambda to be uploaded to S3"
 }
 
+variable "lambda_jobs_zip" {
+  type        = string
+  description = "Full path to zip with go binary for Lambda to be uploaded to S3"
+}
+
 // These variables are provided by config.auto.tfvars.json
 variable "region" {
   type    = string
@@ -94,6 +99,126 @@ variable "eventupload_output_lambda_name" {
   default = ""
 }
 
+variable "eventupload_store_events" {
+  type = bool
+  default = false
+}
+
+variable "eventupload_update_catalog" {
+  type = bool
+  default = false
+}
+
+variable "lockdown_promotion_enabled" {
+  type = bool
+  default = false
+}
+
+variable "lockdown_promotion_schedule" {
+  type = string
+  default = "rate(1 hour)"
+}
+
+variable "lockdown_promotion_monitor_days" {
+  type = number
+  default = 14
+}
+
+variable "lockdown_promotion_daily_cap" {
+  type = number
+  default = 25
+}
+
+variable "lockdown_promotion_groups" {
+  type = list(string)
+  default = []
+}
+
+variable "lockdown_promotion_excluded_groups" {
+  type = list(string)
+  default = []
+}
+
+variable "lockdown_demotion_window_hours" {
+  type = number
+  default = 24
+}
+
+variable "lockdown_demotion_block_threshold" {
+  type = number
+  default = 5
+}
+
+variable "lockdown_promotion_dry_run" {
+  type = bool
+  default = false
+}
+
+variable "sync_health_enabled" {
+  type = bool
+  default = false
+}
+
+variable "sync_health_schedule" {
+  type = string
+  default = "rate(1 day)"
+}
+
+variable "sync_health_stale_days" {
+  type = number
+  default = 7
+}
+
+variable "sync_health_repeated_clean_syncs" {
+  type = number
+  default = 3
+}
+
+variable "log_level" {
+  type = string
+  default = "info"
+}
+
+variable "log_debug_machine_ids" {
+  type = list(string)
+  default = []
+}
+
+variable "admin_api_enabled" {
+  type = bool
+  default = false
+}
+
+variable "admin_api_oidc_issuer" {
+  type = string
+  default = ""
+}
+
+variable "admin_api_oidc_audience" {
+  type = string
+  default = ""
+}
+
+variable "admin_api_oidc_jwks_url" {
+  type = string
+  default = ""
+}
+
+variable "admin_api_oidc_principal_claim" {
+  type = string
+  default = "email"
+}
+
+variable "admin_api_oidc_groups_claim" {
+  type = string
+  default = "groups"
+}
+
+variable "admin_api_role_groups" {
+  type = map(list(string))
+  default = {}
+}
+
 variable "enable_s3_logging" {
   type = bool
   default = true
@@ -49,6 +49,11 @@ variable "lambda_authorizer_zip" {
   description = "Full path to zip with go binary for Lambda to be uploaded to S3"
 }
 
+variable "lambda_jobs_zip" {
+  type        = string
+  description = "Full path to zip with go binary for the scheduled jobs Lambda to be uploaded to S3"
+}
+
 variable "use_existing_route53_zone" {
   type        = bool
   description = "Whether or not to import an existing Route 53 Hosted Zone"
@@ -120,6 +125,150 @@ variable "eventupload_output_lambda_name" {
   default     = ""
 }
 
+variable "eventupload_store_events" {
+  type        = bool
+  description = "When true, the eventupload endpoint also keeps a copy of uploaded events in DynamoDB for 30 days, so they can be browsed with `rudolph events list`."
+  default     = false
+}
+
+variable "eventupload_update_catalog" {
+  type        = bool
+  description = "When true, the eventupload endpoint aggregates uploaded events into a fleet-wide binary catalog, browsable with `rudolph catalog`."
+  default     = false
+}
+
+variable "lockdown_promotion_enabled" {
+  type        = bool
+  description = "When true, a scheduled job promotes machines that have been clean in MONITOR to LOCKDOWN. Requires eventupload_store_events."
+  default     = false
+}
+
+variable "lockdown_promotion_schedule" {
+  type        = string
+  description = "EventBridge schedule expression for the lockdown promotion job. Demotions are only as timely as this schedule."
+  default     = "rate(1 hour)"
+}
+
+variable "lockdown_promotion_monitor_days" {
+  type        = number
+  description = "Days of uploaded events, without a would-be-blocked execution, required before a machine is promoted"
+  default     = 14
+}
+
+variable "lockdown_promotion_daily_cap" {
+  type        = number
+  description = "Maximum number of machines promoted per UTC day"
+  default     = 25
+}
+
+variable "lockdown_promotion_groups" {
+  type        = list(string)
+  description = "When not empty, only members of these machine groups are promoted"
+  default     = []
+}
+
+variable "lockdown_promotion_excluded_groups" {
+  type        = list(string)
+  description = "Members of these machine groups are never promoted"
+  default     = []
+}
+
+variable "lockdown_demotion_window_hours" {
+  type        = number
+  description = "Hours after an automatic promotion during which a machine is demoted back to MONITOR if it gets blocked"
+  default     = 24
+}
+
+variable "lockdown_demotion_block_threshold" {
+  type        = number
+  description = "Number of LOCKDOWN blocks within the demotion window that demotes a machine back to MONITOR"
+  default     = 5
+}
+
+variable "lockdown_promotion_dry_run" {
+  type        = bool
+  description = "When true, the lockdown promotion job only logs what it would do"
+  default     = false
+}
+
+variable "sync_health_enabled" {
+  type        = bool
+  description = "When true, a scheduled job logs the machines that stopped syncing, whose last sync stalled, or that keep clean syncing"
+  default     = false
+}
+
+variable "sync_health_schedule" {
+  type        = string
+  description = "EventBridge schedule expression for the sync health job"
+  default     = "rate(1 day)"
+}
+
+variable "sync_health_stale_days" {
+  type        = number
+  description = "Days without a sync after which a machine is reported as stale"
+  default     = 7
+}
+
+variable "sync_health_repeated_clean_syncs" {
+  type        = number
+  description = "Number of clean syncs in a row after which a machine is reported"
+  default     = 3
+}
+
+variable "log_level" {
+  type        = string
+  description = "Level of the Lambda function logs: debug, info, warn or error"
+  default     = "info"
+}
+
+variable "log_debug_machine_ids" {
+  type        = list(string)
+  description = "Machine IDs whose requests are logged at debug level, with request and response bodies and without redaction"
+  default     = []
+}
+
+variable "admin_api_enabled" {
+  type        = bool
+  description = "When true, deploys the admin API under /admin, for operators that authenticate with the identity provider below"
+  default     = false
+}
+
+variable "admin_api_oidc_issuer" {
+  type        = string
+  description = "Issuer of the identity provider tokens the admin API accepts, e.g. https://example.okta.com"
+  default     = ""
+}
+
+variable "admin_api_oidc_audience" {
+  type        = string
+  description = "Audience that admin API tokens must be issued for"
+  default     = ""
+}
+
+variable "admin_api_oidc_jwks_url" {
+  type        = string
+  description = "URL of the identity provider's signing keys (JWKS)"
+  default     = ""
+}
+
+variable "admin_api_oidc_principal_claim" {
+  type        = string
+  description = "Token claim that names the caller in the admin API audit log"
+  default     = "email"
+}
+
+variable "admin_api_oidc_groups_claim" {
+  type        = string
+  description = "Token claim that lists the caller's groups, which admin_api_role_groups turns into roles"
+  default     = "groups"
+}
+
+variable "admin_api_role_groups" {
+  type        = map(list(string))
+  description = "Groups that grant each admin API role: viewer, responder, rule-admin and fleet-admin. When empty, each role is granted by the group of the same name."
+  default     = {}
+}
+
 variable "kms_key_administrators_arns" {
   type = list(string)
   description = "List of KMS Key Administrator ARNs to allow access to Rudolph KMS key operations"
@@ -0,0 +1,286 @@
+#
+# Admin API
+#
+# The admin API manages rules, configurations and machines for operators that authenticate with the identity provider,
+# instead of giving them AWS credentials that can write to DynamoDB. It runs the same code as the sync API, in its own
+# function whose role may write global rules and configurations, behind its own authorizer.
+#
+locals {
+  admin_api_count = var.admin_api_enabled ? 1 : 0
+}
+
+module "admin_authorizer" {
+  count  = local.admin_api_count
+  source = "./modules/lambda/authorizer"
+
+  name                      = "admin_authorizer"
+  prefix                    = var.prefix
+  region                    = var.region
+  alias_name                = var.stage_name
+  api_gateway_id            = aws_api_gateway_rest_api.api_gateway.id
+  api_gateway_execution_arn = aws_api_gateway_rest_api.api_gateway.execution_arn
+  lambda_source_bucket      = aws_s3_bucket_object.santa_api_authorizer_source.bucket
+  lambda_source_key         = aws_s3_bucket_object.santa_api_authorizer_source.key
+  lambda_source_hash        = local.lambda_authorizer_hash
+
+  env_vars = {
+    AUTHORIZER            = "admin"
+    REGION                = var.region
+    GATEWAY_ID            = aws_api_gateway_rest_api.api_gateway.id
+    ACCOUNT_ID            = var.aws_account_id
+    OIDC_ISSUER           = var.admin_api_oidc_issuer
+    OIDC_AUDIENCE         = var.admin_api_oidc_audience
+    OIDC_JWKS_URL         = var.admin_api_oidc_jwks_url
+    OIDC_PRINCIPAL_CLAIM  = var.admin_api_oidc_principal_claim
+    OIDC_GROUPS_CLAIM     = var.admin_api_oidc_groups_claim
+    ADMIN_ROLE_GROUPS     = length(var.admin_api_role_groups) > 0 ? jsonencode(var.admin_api_role_groups) : ""
+    LOG_LEVEL             = var.log_level
+    LOG_DEBUG_MACHINE_IDS = local.log_debug_machine_ids
+  }
+}
+
+module "admin_function" {
+  count  = local.admin_api_count
+  source = "./modules/lambda/api-handler"
+
+  prefix                    = var.prefix
+  region                    = var.region
+  alias_name                = var.stage_name
+  lambda_source_bucket      = aws_s3_bucket_object.santa_api_source.bucket
+  lambda_source_key         = aws_s3_bucket_object.santa_api_source.key
+  lambda_source_hash        = local.lambda_source_hash
+  endpoint                  = "admin"
+  lambda_memory_size        = 512
+  api_gateway_execution_arn = aws_api_gateway_rest_api.api_gateway.execution_arn
+
+  env_vars = {
+    REGION                = var.region
+    DYNAMODB_NAME         = local.dynamodb_table_name
+    METRICS_NAMESPACE     = local.metrics_namespace
+    LOG_LEVEL             = var.log_level
+    LOG_DEBUG_MACHINE_IDS = local.log_debug_machine_ids
+  }
+}
+
+# /admin resources
+module "admin_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = aws_api_gateway_rest_api.api_gateway.root_resource_id
+  resource_path            = "admin"
+  integration_http_methods = []
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+
+# /admin/whoami resources
+module "admin_whoami_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_api[0].resource_id
+  resource_path            = "whoami"
+  integration_http_methods = ["GET"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+
+# /admin/rules resources
+module "admin_rules_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_api[0].resource_id
+  resource_path            = "rules"
+  integration_http_methods = ["GET", "POST"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+module "admin_rules_type_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_rules_api[0].resource_id
+  resource_path            = "{rule_type}"
+  integration_http_methods = []
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+module "admin_rules_resource_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_rules_type_api[0].resource_id
+  resource_path            = "{identifier}"
+  integration_http_methods = ["GET", "PUT", "DELETE"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+
+# /admin/config resources
+module "admin_config_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_api[0].resource_id
+  resource_path            = "config"
+  integration_http_methods = ["GET", "PUT", "PATCH", "DELETE"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+
+# /admin/machines resources
+module "admin_machines_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_api[0].resource_id
+  resource_path            = "machines"
+  integration_http_methods = ["GET"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+module "admin_machines_lookup_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_machines_api[0].resource_id
+  resource_path            = "lookup"
+  integration_http_methods = ["GET"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+module "admin_machines_resource_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_machines_api[0].resource_id
+  resource_path            = "{machine_id}"
+  integration_http_methods = ["GET"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+module "admin_machines_config_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_machines_resource_api[0].resource_id
+  resource_path            = "config"
+  integration_http_methods = ["GET", "PUT", "PATCH", "DELETE"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+module "admin_machines_rules_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_machines_resource_api[0].resource_id
+  resource_path            = "rules"
+  integration_http_methods = ["GET", "POST"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+module "admin_machines_rules_type_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_machines_rules_api[0].resource_id
+  resource_path            = "{rule_type}"
+  integration_http_methods = []
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+module "admin_machines_rules_resource_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_machines_rules_type_api[0].resource_id
+  resource_path            = "{identifier}"
+  integration_http_methods = ["PUT", "DELETE"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+
+# /admin/blocks resources
+module "admin_blocks_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_api[0].resource_id
+  resource_path            = "blocks"
+  integration_http_methods = []
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+module "admin_blocks_machine_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_blocks_api[0].resource_id
+  resource_path            = "{machine_id}"
+  integration_http_methods = []
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+module "admin_blocks_resource_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_blocks_machine_api[0].resource_id
+  resource_path            = "{file_sha256}"
+  integration_http_methods = ["GET"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+
+# /admin/unblock-requests resources
+module "admin_unblock_requests_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_api[0].resource_id
+  resource_path            = "unblock-requests"
+  integration_http_methods = ["GET", "POST"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+module "admin_unblock_requests_machine_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_unblock_requests_api[0].resource_id
+  resource_path            = "{machine_id}"
+  integration_http_methods = []
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
+module "admin_unblock_requests_resource_api" {
+  count  = local.admin_api_count
+  source = "./modules/rest_api"
+
+  gateway_rest_api_id      = aws_api_gateway_rest_api.api_gateway.id
+  parent_resource_id       = module.admin_unblock_requests_machine_api[0].resource_id
+  resource_path            = "{file_sha256}"
+  integration_http_methods = ["PUT"]
+  lambda_invocation_arn    = module.admin_function[0].lambda_alias_invoke_arn
+  authorizer_id            = module.admin_authorizer[0].api_gateway_authorizer_id
+}
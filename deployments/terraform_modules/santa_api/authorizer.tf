@@ -14,8 +14,10 @@ module "rudolph_api_authorizer" {
   lambda_source_hash        = local.lambda_authorizer_hash
 
   env_vars = {
-    REGION      = var.region
-    GATEWAY_ID  = aws_api_gateway_rest_api.api_gateway.id
-    ACCOUNT_ID  = var.aws_account_id
+    REGION                = var.region
+    GATEWAY_ID            = aws_api_gateway_rest_api.api_gateway.id
+    ACCOUNT_ID            = var.aws_account_id
+    LOG_LEVEL             = var.log_level
+    LOG_DEBUG_MACHINE_IDS = local.log_debug_machine_ids
   }
 }
@@ -0,0 +1,63 @@
+#
+# Scheduled jobs
+#
+# All jobs share one binary; the schedule of each job tells the binary which job to run.
+#
+locals {
+  lambda_jobs_hash       = filebase64sha256(var.lambda_jobs_zip)
+  lambda_jobs_source_key = "rudolph-source-jobs-${filemd5(var.lambda_jobs_zip)}.zip"
+}
+
+resource "aws_s3_bucket_object" "santa_jobs_source" {
+  bucket = local.lambda_source_bucket
+  key    = local.lambda_jobs_source_key
+  source = var.lambda_jobs_zip
+  etag   = filemd5(var.lambda_jobs_zip)
+}
+
+module "lockdown_promotion_job" {
+  count  = var.lockdown_promotion_enabled ? 1 : 0
+  source = "./modules/lambda/scheduled-job"
+
+  prefix               = var.prefix
+  lambda_source_bucket = aws_s3_bucket_object.santa_jobs_source.bucket
+  lambda_source_key    = aws_s3_bucket_object.santa_jobs_source.key
+  lambda_source_hash   = local.lambda_jobs_hash
+  job                  = "lockdown_promotion" # This needs to be consistent with the JobLockdownPromotion constant
+  schedule_expression  = var.lockdown_promotion_schedule
+
+  env_vars = {
+    REGION                    = var.region
+    DYNAMODB_NAME             = local.dynamodb_table_name
+    PROMOTION_MONITOR_DAYS    = var.lockdown_promotion_monitor_days
+    PROMOTION_DAILY_CAP       = var.lockdown_promotion_daily_cap
+    PROMOTION_GROUPS          = join(",", var.lockdown_promotion_groups)
+    PROMOTION_EXCLUDED_GROUPS = join(",", var.lockdown_promotion_excluded_groups)
+    DEMOTION_WINDOW_HOURS     = var.lockdown_demotion_window_hours
+    DEMOTION_BLOCK_THRESHOLD  = var.lockdown_demotion_block_threshold
+    PROMOTION_DRY_RUN         = var.lockdown_promotion_dry_run
+    METRICS_NAMESPACE         = local.metrics_namespace
+    LOG_LEVEL                 = var.log_level
+  }
+}
+
+module "sync_health_job" {
+  count  = var.sync_health_enabled ? 1 : 0
+  source = "./modules/lambda/scheduled-job"
+
+  prefix               = var.prefix
+  lambda_source_bucket = aws_s3_bucket_object.santa_jobs_source.bucket
+  lambda_source_key    = aws_s3_bucket_object.santa_jobs_source.key
+  lambda_source_hash   = local.lambda_jobs_hash
+  job                  = "sync_health" # This needs to be consistent with the JobSyncHealth constant
+  schedule_expression  = var.sync_health_schedule
+
+  env_vars = {
+    REGION                    = var.region
+    DYNAMODB_NAME             = local.dynamodb_table_name
+    SYNC_STALE_DAYS           = var.sync_health_stale_days
+    SYNC_REPEATED_CLEAN_SYNCS = var.sync_health_repeated_clean_syncs
+    METRICS_NAMESPACE         = local.metrics_namespace
+    LOG_LEVEL                 = var.log_level
+  }
+}
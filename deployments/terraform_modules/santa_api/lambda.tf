@@ -6,6 +6,9 @@ locals {
   lambda_source_bucket = length(module.lambda_source) > 0 ? module.lambda_source[0].bucket_name : var.lambda_source_s3_bucket_name
   dynamodb_table_name = format("%s_rudolph_store", var.prefix)
   firehose_name     = var.eventupload_firehose_name == "" ? format("%s_rudolph_eventsupload_firehose", var.prefix) : var.eventupload_firehose_name
+  # Metrics are written to the logs in the CloudWatch Embedded Metric Format, one namespace per deployment
+  metrics_namespace = format("Rudolph/%s", var.prefix)
+  log_debug_machine_ids = join(",", var.log_debug_machine_ids)
 }
 
 #
@@ -49,8 +52,11 @@ module "health_function" {
   api_gateway_execution_arn = aws_api_gateway_rest_api.api_gateway.execution_arn
 
   env_vars = {
-    REGION = var.region
-    DYNAMODB_NAME = local.dynamodb_table_name
+    REGION                = var.region
+    DYNAMODB_NAME         = local.dynamodb_table_name
+    METRICS_NAMESPACE     = local.metrics_namespace
+    LOG_LEVEL             = var.log_level
+    LOG_DEBUG_MACHINE_IDS = local.log_debug_machine_ids
   }
 }
 
@@ -67,7 +73,11 @@ module "xsrf_function" {
   endpoint                  = "xsrf"
   api_gateway_execution_arn = aws_api_gateway_rest_api.api_gateway.execution_arn
 
-  env_vars = {}
+  env_vars = {
+    METRICS_NAMESPACE     = local.metrics_namespace
+    LOG_LEVEL             = var.log_level
+    LOG_DEBUG_MACHINE_IDS = local.log_debug_machine_ids
+  }
 }
 
 
@@ -85,8 +95,11 @@ module "preflight_function" {
   api_gateway_execution_arn = aws_api_gateway_rest_api.api_gateway.execution_arn
 
   env_vars = {
-    REGION = var.region
-    DYNAMODB_NAME = local.dynamodb_table_name
+    REGION                = var.region
+    DYNAMODB_NAME         = local.dynamodb_table_name
+    METRICS_NAMESPACE     = local.metrics_namespace
+    LOG_LEVEL             = var.log_level
+    LOG_DEBUG_MACHINE_IDS = local.log_debug_machine_ids
   }
 }
 
@@ -105,11 +118,17 @@ module "eventupload_function" {
   api_gateway_execution_arn = aws_api_gateway_rest_api.api_gateway.execution_arn
 
   env_vars = {
-    REGION        = var.region
-    HANDLER       = var.eventupload_handler
-    FIREHOSE_NAME = local.firehose_name
-    KINESIS_NAME  = var.eventupload_kinesis_name
-    LAMBDA_NAME   = var.eventupload_output_lambda_name
+    REGION                = var.region
+    HANDLER               = var.eventupload_handler
+    FIREHOSE_NAME         = local.firehose_name
+    KINESIS_NAME          = var.eventupload_kinesis_name
+    LAMBDA_NAME           = var.eventupload_output_lambda_name
+    STORE_EVENTS          = var.eventupload_store_events
+    UPDATE_CATALOG        = var.eventupload_update_catalog
+    DYNAMODB_NAME         = local.dynamodb_table_name
+    METRICS_NAMESPACE     = local.metrics_namespace
+    LOG_LEVEL             = var.log_level
+    LOG_DEBUG_MACHINE_IDS = local.log_debug_machine_ids
   }
 }
 
@@ -128,8 +147,11 @@ module "ruledownload_function" {
   api_gateway_execution_arn = aws_api_gateway_rest_api.api_gateway.execution_arn
 
   env_vars = {
-    REGION = var.region
-    DYNAMODB_NAME = local.dynamodb_table_name
+    REGION                = var.region
+    DYNAMODB_NAME         = local.dynamodb_table_name
+    METRICS_NAMESPACE     = local.metrics_namespace
+    LOG_LEVEL             = var.log_level
+    LOG_DEBUG_MACHINE_IDS = local.log_debug_machine_ids
   }
 }
 
@@ -148,7 +170,10 @@ module "postflight_function" {
   api_gateway_execution_arn = aws_api_gateway_rest_api.api_gateway.execution_arn
 
   env_vars = {
-    REGION = var.region
-    DYNAMODB_NAME = local.dynamodb_table_name
+    REGION                = var.region
+    DYNAMODB_NAME         = local.dynamodb_table_name
+    METRICS_NAMESPACE     = local.metrics_namespace
+    LOG_LEVEL             = var.log_level
+    LOG_DEBUG_MACHINE_IDS = local.log_debug_machine_ids
   }
 }
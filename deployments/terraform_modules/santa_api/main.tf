@@ -45,6 +45,19 @@ resource "aws_api_gateway_deployment" "api_deployment" {
       module.xsrf_resource_api.integration_shas,
       module.postflight_api.integration_shas,
       module.postflight_resource_api.integration_shas,
+      join(",", module.admin_whoami_api[*].integration_shas),
+      join(",", module.admin_rules_api[*].integration_shas),
+      join(",", module.admin_rules_resource_api[*].integration_shas),
+      join(",", module.admin_config_api[*].integration_shas),
+      join(",", module.admin_machines_api[*].integration_shas),
+      join(",", module.admin_machines_lookup_api[*].integration_shas),
+      join(",", module.admin_machines_resource_api[*].integration_shas),
+      join(",", module.admin_machines_config_api[*].integration_shas),
+      join(",", module.admin_machines_rules_api[*].integration_shas),
+      join(",", module.admin_machines_rules_resource_api[*].integration_shas),
+      join(",", module.admin_blocks_resource_api[*].integration_shas),
+      join(",", module.admin_unblock_requests_api[*].integration_shas),
+      join(",", module.admin_unblock_requests_resource_api[*].integration_shas),
     ])
   }
 
@@ -60,6 +73,19 @@ resource "aws_api_gateway_deployment" "api_deployment" {
     module.xsrf_resource_api.integration_ids,
     module.postflight_api.integration_ids,
     module.postflight_resource_api.integration_ids,
+    module.admin_whoami_api,
+    module.admin_rules_api,
+    module.admin_rules_resource_api,
+    module.admin_config_api,
+    module.admin_machines_api,
+    module.admin_machines_lookup_api,
+    module.admin_machines_resource_api,
+    module.admin_machines_config_api,
+    module.admin_machines_rules_api,
+    module.admin_machines_rules_resource_api,
+    module.admin_blocks_resource_api,
+    module.admin_unblock_requests_api,
+    module.admin_unblock_requests_resource_api,
   ]
 
   lifecycle {
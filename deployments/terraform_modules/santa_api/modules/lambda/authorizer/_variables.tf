@@ -3,6 +3,12 @@ variable "prefix" {
   description = "Prefix to all resource names"
 }
 
+variable "name" {
+  type        = string
+  description = "Name of this authorizer, used as the endpoint name of its Lambda function"
+  default     = "authorizer"
+}
+
 variable "region" {
   type        = string
   description = "AWS Region"
@@ -5,7 +5,7 @@
 # The role that API Gateway assumes and uses to then invoke the lambda function
 # This is NOT the same role as the role that the lambda function invokes AS
 resource "aws_iam_role" "invocation_role" {
-  name               = "${var.prefix}_rudolph_api_gateway_authorizer"
+  name               = "${var.prefix}_rudolph_api_gateway_authorizer${local.name_suffix}"
   path               = "/rudolph/"
   assume_role_policy = data.aws_iam_policy_document.assume_role_policy.json
 }
@@ -23,7 +23,7 @@ data "aws_iam_policy_document" "assume_role_policy" {
 }
 
 resource "aws_iam_role_policy" "invocation_policy" {
-  name   = "${var.prefix}_AllowApiGatewaytoInvokeAuthorizerLambda"
+  name   = "${var.prefix}_AllowApiGatewaytoInvokeAuthorizerLambda${local.name_suffix}"
   role   = aws_iam_role.invocation_role.id
   policy = data.aws_iam_policy_document.invocation_policy.json
 }
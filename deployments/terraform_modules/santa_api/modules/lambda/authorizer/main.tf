@@ -3,8 +3,13 @@
 #
 # This module covers the REST API Gateway Authorizer
 #
+locals {
+  # The default authorizer keeps the names it always had; any other instance gets its name appended
+  name_suffix = var.name == "authorizer" ? "" : "_${var.name}"
+}
+
 resource "aws_api_gateway_authorizer" "api_authorizer" {
-  name                   = "${var.prefix}_rudolph_lambda_authorizer" # Authorizers are scoped per gateway, so names don't conflict
+  name                   = "${var.prefix}_rudolph_lambda_authorizer${local.name_suffix}" # Authorizers are scoped per gateway, so names don't conflict
   rest_api_id            = var.api_gateway_id
   authorizer_uri         = module.authorizer_function.lambda_invoke_arn
   authorizer_credentials = aws_iam_role.invocation_role.arn
@@ -29,7 +34,7 @@ module "authorizer_function" {
   lambda_source_bucket      = var.lambda_source_bucket
   lambda_source_key         = var.lambda_source_key
   lambda_source_hash        = var.lambda_source_hash
-  endpoint                  = "authorizer"
+  endpoint                  = var.name
   api_gateway_execution_arn = var.api_gateway_execution_arn
 
   env_vars = var.env_vars
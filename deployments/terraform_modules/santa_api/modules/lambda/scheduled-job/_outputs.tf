@@ -0,0 +1,7 @@
+output "lambda_function_arn" {
+  value = aws_lambda_function.scheduled_job.arn
+}
+
+output "lambda_role_name" {
+  value = aws_iam_role.scheduled_job_role.id
+}
@@ -0,0 +1,47 @@
+variable "prefix" {
+  type        = string
+  description = "Prefix to all resource names"
+}
+
+variable "lambda_source_bucket" {
+  type        = string
+  description = "Name of S3 bucket used for uploading Lambda code"
+}
+
+variable "lambda_source_key" {
+  type        = string
+  description = "Key of S3 object that is the zip containing the go binary for Lambda"
+}
+
+variable "lambda_source_hash" {
+  type        = string
+  description = "Base64 encoded hash of S3 object contents"
+}
+
+variable "job" {
+  type        = string
+  description = "Name of the job to run; this is passed to the function as its input and used to name resources"
+}
+
+variable "schedule_expression" {
+  type        = string
+  description = "EventBridge schedule expression, e.g. rate(1 hour) or cron(0 12 * * ? *)"
+}
+
+variable "env_vars" {
+  type        = map(string)
+  description = "Map of environment variables to pass to the function"
+  default     = {}
+}
+
+variable "lambda_memory_size" {
+  type        = number
+  description = "Lambda function runtime memory size in MB. Valid value between 128 MB to 10,240 MB (10 GB), in 64 MB increments."
+  default     = 256
+}
+
+variable "lambda_timeout_seconds" {
+  type        = number
+  description = "Lambda function timeout in seconds. Jobs are not user facing, so they are allowed to run far longer than API handlers."
+  default     = 300
+}
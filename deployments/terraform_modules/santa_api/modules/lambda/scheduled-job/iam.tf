@@ -0,0 +1,32 @@
+#
+# IAM
+#
+
+# IAM Role for the scheduled job Lambda
+resource "aws_iam_role" "scheduled_job_role" {
+  name               = "${var.prefix}_rudolph_${var.job}_role"
+  assume_role_policy = data.aws_iam_policy_document.lambda_execution_policy.json
+  path               = "/rudolph/"
+}
+
+data "aws_iam_policy_document" "lambda_execution_policy" {
+  statement {
+    effect  = "Allow"
+    actions = ["sts:AssumeRole"]
+
+    principals {
+      type        = "Service"
+      identifiers = ["lambda.amazonaws.com"]
+    }
+  }
+}
+
+# Attach write permissions for CloudWatch logs
+data "aws_iam_policy" "basic_execution_role" {
+  arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+}
+
+resource "aws_iam_role_policy_attachment" "basic_execution_role" {
+  role       = aws_iam_role.scheduled_job_role.id
+  policy_arn = data.aws_iam_policy.basic_execution_role.arn
+}
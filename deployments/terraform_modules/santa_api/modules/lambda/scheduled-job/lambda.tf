@@ -0,0 +1,57 @@
+#
+# Lambdas
+#
+
+locals {
+  handler = "bootstrap"
+  runtime = "provided.al2"
+}
+
+resource "aws_lambda_function" "scheduled_job" {
+  function_name = "${var.prefix}_rudolph_${var.job}"
+  role          = aws_iam_role.scheduled_job_role.arn
+  handler       = local.handler
+  runtime       = local.runtime
+  publish       = false
+  architectures = ["arm64"]
+
+  s3_bucket        = var.lambda_source_bucket
+  s3_key           = var.lambda_source_key
+  source_code_hash = var.lambda_source_hash
+  memory_size      = var.lambda_memory_size
+  timeout          = var.lambda_timeout_seconds
+
+  # Only one run of a job should be in flight at a time; runs that overlap would race each other
+  reserved_concurrent_executions = 1
+
+  dynamic "environment" {
+    for_each = length(var.env_vars) == 0 ? [] : [1]
+    content {
+      variables = var.env_vars
+    }
+  }
+}
+
+#
+# Schedule
+#
+
+resource "aws_cloudwatch_event_rule" "schedule" {
+  name                = "${var.prefix}_rudolph_${var.job}_schedule"
+  description         = "Runs the Rudolph ${var.job} job"
+  schedule_expression = var.schedule_expression
+}
+
+resource "aws_cloudwatch_event_target" "scheduled_job" {
+  rule  = aws_cloudwatch_event_rule.schedule.name
+  arn   = aws_lambda_function.scheduled_job.arn
+  input = jsonencode({ job = var.job })
+}
+
+resource "aws_lambda_permission" "scheduled_job_permissions" {
+  statement_id  = "AllowExecutionFromEventBridge"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.scheduled_job.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.schedule.arn
+}
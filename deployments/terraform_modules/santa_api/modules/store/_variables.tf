@@ -14,6 +14,12 @@ variable "read_lambda_role_names" {
   description = "List of IAM Role names that should be allowed to read from rule store tables"
 }
 
+variable "admin_lambda_role_names" {
+  type        = list(string)
+  description = "List of IAM Role names that should also be allowed to write global rules and configurations. They need to be in read_lambda_role_names too."
+  default     = []
+}
+
 variable "aws_account_id" {
   type        = string
   description = "AWS Account Id"
@@ -27,11 +27,31 @@ resource "aws_dynamodb_table" "store" {
     type = "S"
   }
 
+  attribute {
+    name = "MachineID"
+    type = "S"
+  }
+
   attribute {
     name = "SerialNum"
     type = "S"
   }
 
+  attribute {
+    name = "PrimaryUser"
+    type = "S"
+  }
+
+  attribute {
+    name = "FileSHA256"
+    type = "S"
+  }
+
+  attribute {
+    name = "ExecutedAt"
+    type = "S"
+  }
+
   ttl {
     attribute_name = "ExpiresAfter"
     enabled        = true
@@ -45,6 +65,32 @@ resource "aws_dynamodb_table" "store" {
     non_key_attributes = ["MachineID"]
   }
 
+  # Finds the machines of a primary user, for bulk operations keyed by user
+  global_secondary_index {
+    name               = "PrimaryUser_DataType"
+    hash_key           = "PrimaryUser"
+    range_key          = "DataType"
+    projection_type    = "INCLUDE"
+    non_key_attributes = ["MachineID"]
+  }
+
+  # Lists every machine that has checked in, by querying for the SensorData DataType. The whole sensor data item is
+  # projected so that the fleet inventory can be filtered without reading each machine from the table.
+  global_secondary_index {
+    name            = "DataType_MachineID"
+    hash_key        = "DataType"
+    range_key       = "MachineID"
+    projection_type = "ALL"
+  }
+
+  # Only uploaded events carry a FileSHA256, so this index stays sparse
+  global_secondary_index {
+    name            = "FileSHA256_ExecutedAt"
+    hash_key        = "FileSHA256"
+    range_key       = "ExecutedAt"
+    projection_type = "ALL"
+  }
+
   server_side_encryption {
     enabled     = true
     kms_key_arn = aws_kms_key.store_sse_key.arn
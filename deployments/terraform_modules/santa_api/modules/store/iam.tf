@@ -38,9 +38,13 @@ data "aws_iam_policy_document" "lambda_permissions" {
       variable = "dynamodb:LeadingKeys"
 
       values = [
-        "Machine#*",      # This needs to be consistent with the machineInfoPKPrefix constant
-        "MachineInfo#*",  # This needs to be consistent with the machineInfoPKPrefix constant
-        "MachineRules#*", # This needs to be consistent with the MachineRulesPKPrefix constant
+        "Machine#*",         # This needs to be consistent with the machineInfoPKPrefix constant
+        "MachineInfo#*",     # This needs to be consistent with the machineInfoPKPrefix constant
+        "MachineRules#*",    # This needs to be consistent with the MachineRulesPKPrefix constant
+        "MachineEvents#*",   # This needs to be consistent with the eventsPKPrefix constant
+        "Catalog#*",         # This needs to be consistent with the catalogPKPrefix constant
+        "CatalogMember#*",   # This needs to be consistent with the catalogMemberPKPrefix constant
+        "ModeTransitions#*", # This needs to be consistent with the modeTransitionsPKPrefix constant
       ]
     }
   }
@@ -58,3 +62,35 @@ resource "aws_iam_role_policy_attachment" "lambda_policy" {
   role       = element(var.read_lambda_role_names, count.index)
   policy_arn = aws_iam_policy.lambda_policy.arn
 }
+
+#
+# Policy for the admin API, which manages global rules and configurations on behalf of operators
+#
+data "aws_iam_policy_document" "admin_lambda_permissions" {
+  # Unlike the policy above, writes are not limited to machine-specific keys
+  statement {
+    actions = [
+      "dynamodb:PutItem",
+      "dynamodb:UpdateItem",
+      "dynamodb:DeleteItem",
+      "dynamodb:ConditionCheckItem",
+      "dynamodb:TransactWriteItems",
+    ]
+
+    resources = [
+      aws_dynamodb_table.store.arn,
+      "arn:aws:dynamodb:${var.region}:${var.aws_account_id}:table/*_rudolph_store",
+    ]
+  }
+}
+
+resource "aws_iam_policy" "admin_lambda_policy" {
+  name   = "${var.prefix}_rudolph_store_admin_policy"
+  policy = data.aws_iam_policy_document.admin_lambda_permissions.json
+}
+
+resource "aws_iam_role_policy_attachment" "admin_lambda_policy" {
+  count      = length(var.admin_lambda_role_names)
+  role       = element(var.admin_lambda_role_names, count.index)
+  policy_arn = aws_iam_policy.admin_lambda_policy.arn
+}
@@ -7,10 +7,19 @@ module "rule_store" {
 
   # Add function role names to this that need access to the
   # rule table(s)
-  read_lambda_role_names = [
-    module.health_function.lambda_role_name,
-    module.ruledownload_function.lambda_role_name,
-    module.preflight_function.lambda_role_name,
-    module.postflight_function.lambda_role_name,
-  ]
+  read_lambda_role_names = concat(
+    [
+      module.health_function.lambda_role_name,
+      module.ruledownload_function.lambda_role_name,
+      module.preflight_function.lambda_role_name,
+      module.postflight_function.lambda_role_name,
+      module.eventupload_function.lambda_role_name,
+    ],
+    module.lockdown_promotion_job[*].lambda_role_name,
+    module.sync_health_job[*].lambda_role_name,
+    module.admin_function[*].lambda_role_name,
+  )
+
+  # The admin API manages global rules and configurations too
+  admin_lambda_role_names = module.admin_function[*].lambda_role_name
 }
@@ -0,0 +1,58 @@
+package bulk
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/pkg/bulk"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		bf            bulkFlags
+		clientModeArg flags.ClientMode
+	)
+
+	var bulkConfigCmd = &cobra.Command{
+		Use:   "config -f <targets.csv> -c <ClientMode - 'monitor' or 'lockdown'> [--results results.csv] [--dry-run]",
+		Short: "Set the same client mode on every machine of a targets file",
+		Long: `Set the same client mode on every machine of a targets file, e.g. to put a list of serial numbers in
+LOCKDOWN. Like "config update --machine", only the client mode of each machine changes, and every change of mode is
+recorded as a transition.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+
+			clientMode := clientModeArg.AsClientMode()
+			clientModeText, err := clientMode.MarshalText()
+			if err != nil {
+				return err
+			}
+
+			return runBulk(bf, bulk.Runner{
+				Finder: sensordata.GetSensorDataFinder(dynamodbClient),
+				Operation: bulk.ClientModeOperation{
+					ClientMode: clientMode,
+					Store:      bulk.GetClientModeStore(dynamodbClient, clock.ConcreteTimeProvider{}),
+				},
+			}, []string{
+				fmt.Sprintf("ClientMode: %s", clientModeText),
+			})
+		},
+	}
+
+	bf.addBulkFlags(bulkConfigCmd)
+
+	// client-mode should be one of "monitor" or "lockdown"
+	bulkConfigCmd.Flags().VarP(&clientModeArg, "client-mode", "c", `type of client mode being applied. valid options are: "monitor" or "lockdown"`)
+	_ = bulkConfigCmd.MarkFlagRequired("client-mode")
+
+	BulkCmd.AddCommand(bulkConfigCmd)
+}
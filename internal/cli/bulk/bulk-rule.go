@@ -0,0 +1,79 @@
+package bulk
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/pkg/bulk"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		bf          bulkFlags
+		ruleType    flags.RuleType
+		policy      flags.RulePolicy
+		identifier  string
+		description string
+		days        int
+	)
+
+	var bulkRuleCmd = &cobra.Command{
+		Use:   "rule -f <targets.csv> -i <identifier> -t <rule-type> -p <policy> [--days 7] [--results results.csv] [--dry-run]",
+		Short: "Create the same machine rule on every machine of a targets file",
+		Long: `Create the same machine rule on every machine of a targets file, e.g. to allow a binary on a few hundred
+machines for a week. Machine rules replace any machine rule the machine has for the same identifier, and expire after
+--days.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+			timeProvider := clock.ConcreteTimeProvider{}
+
+			if days <= 0 {
+				return fmt.Errorf("--days must be positive")
+			}
+			operation := bulk.MachineRuleOperation{
+				RuleType:    ruleType.AsRuleType(),
+				Policy:      policy.AsRulePolicy(),
+				Identifier:  identifier,
+				Description: description,
+				Expires:     timeProvider.Now().Add(time.Duration(days) * 24 * time.Hour).UTC(),
+			}
+
+			policyDescription, _ := operation.Policy.MarshalText()
+			ruleTypeDescription, _ := operation.RuleType.MarshalText()
+
+			return runBulk(bf, bulk.Runner{
+				Finder:    sensordata.GetSensorDataFinder(dynamodbClient),
+				Client:    dynamodbClient,
+				Operation: operation,
+			}, []string{
+				fmt.Sprintf("Identifier:  %s", identifier),
+				fmt.Sprintf("RuleType:    %s", ruleTypeDescription),
+				fmt.Sprintf("Policy:      %s", policyDescription),
+				fmt.Sprintf("Description: %s", description),
+				fmt.Sprintf("Expires:     %s", operation.Expires.Format(time.RFC3339)),
+			})
+		},
+	}
+
+	bf.addBulkFlags(bulkRuleCmd)
+	bulkRuleCmd.Flags().StringVarP(&identifier, "identifier", "i", "", `The Identifier/SHA256 for a file, application, teamID, or signingID`)
+	_ = bulkRuleCmd.MarkFlagRequired("identifier")
+	bulkRuleCmd.Flags().VarP(&ruleType, "rule-type", "t", `type of rule being applied. valid options are: "binary", "bin", "certificate", "cert", "teamid", "signingid", "cdhash"`)
+	_ = bulkRuleCmd.MarkFlagRequired("rule-type")
+	bulkRuleCmd.Flags().VarP(&policy, "rule-policy", "p", `policy of the rule being applied. valid options are: "allowlist", "blocklist" or "silent_blocklist"`)
+	_ = bulkRuleCmd.MarkFlagRequired("rule-policy")
+	bulkRuleCmd.Flags().StringVarP(&description, "description", "d", "", "A description of the rule, e.g. the reason for it")
+	bulkRuleCmd.Flags().IntVar(&days, "days", machinerules.MachineRuleDefaultExpirationHours/24, "Number of days after which the machine rules expire")
+
+	BulkCmd.AddCommand(bulkRuleCmd)
+}
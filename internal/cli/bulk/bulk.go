@@ -0,0 +1,147 @@
+package bulk
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/bulk"
+	"github.com/spf13/cobra"
+)
+
+var (
+	BulkCmd = &cobra.Command{
+		Use:   "bulk",
+		Short: "Apply a machine rule or a machine configuration to many machines at once",
+		Long: `Apply a machine rule or a machine configuration to many machines at once.
+
+The machines are listed in a csv or json targets file, by machine ID, serial number or primary user. A csv file has a
+header row with any of the machine_id, serial and primary_user columns; a json file is a list of objects with any of
+these keys. Each entry sets exactly one of them, and other columns are ignored:
+
+	serial,primary_user
+	C02ABC123DEF,
+	,alice
+
+Serial numbers and primary users are resolved with the sensor data that machines upload when they sync, so only
+machines that have checked in can be found.`,
+	}
+)
+
+// bulkFlags are the flags shared by every bulk command
+type bulkFlags struct {
+	targetsFile string
+	resultsFile string
+	dryRun      bool
+	autoApprove bool
+}
+
+func (f *bulkFlags) addBulkFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.targetsFile, "filename", "f", "", "The csv or json file listing the target machines")
+	_ = cmd.MarkFlagRequired("filename")
+	cmd.Flags().StringVar(&f.resultsFile, "results", "", "Write the result of every entry to this csv or json file")
+	cmd.Flags().BoolVar(&f.dryRun, "dry-run", false, "Resolve the target machines and report what would be written, without writing anything")
+	cmd.Flags().BoolVar(&f.autoApprove, "auto-approve", false, "Apply without asking for confirmation, e.g. in CI")
+}
+
+// runBulk reads the targets file, asks for confirmation of the operation, runs it and reports the results
+func runBulk(f bulkFlags, runner bulk.Runner, description []string) (err error) {
+	targetsFormat, err := bulk.DetectFormat(f.targetsFile)
+	if err != nil {
+		return
+	}
+	var resultsFormat bulk.Format
+	if f.resultsFile != "" {
+		resultsFormat, err = bulk.DetectFormat(f.resultsFile)
+		if err != nil {
+			return
+		}
+	}
+
+	file, err := os.Open(f.targetsFile)
+	if err != nil {
+		return fmt.Errorf("failed to open targets file: %w", err)
+	}
+	targets, err := bulk.ReadTargets(targetsFormat, file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read targets file %q: %w", f.targetsFile, err)
+	}
+
+	fmt.Println("Applying the following to every machine of", f.targetsFile, fmt.Sprintf("(%d entries)", len(targets)))
+	fmt.Println()
+	for _, line := range description {
+		fmt.Println(" ", line)
+	}
+
+	runner.DryRun = f.dryRun
+	if !f.dryRun && !f.autoApprove {
+		fmt.Println()
+		fmt.Println(`Apply changes? (Enter: "yes" or "ok")`)
+		fmt.Print("> ")
+
+		reader := bufio.NewReader(os.Stdin)
+		text, _ := reader.ReadString('\n')
+		text = strings.TrimSpace(text)
+		if text != "ok" && text != "yes" {
+			fmt.Println("Confirmation not successful...")
+			return nil
+		}
+	}
+	fmt.Println()
+
+	runner.Progress = func(done int, total int) {
+		fmt.Fprintf(os.Stderr, "\rprocessed %d of %d entries", done, total)
+		if done == total {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+	results, err := runner.Run(targets)
+	if err != nil {
+		return
+	}
+
+	if f.resultsFile != "" {
+		err = writeResults(f.resultsFile, resultsFormat, results)
+		if err != nil {
+			return
+		}
+		fmt.Println("results written to", f.resultsFile)
+	} else {
+		// Without a results file, only show what needs attention
+		for _, result := range results {
+			if result.Error != "" {
+				fmt.Printf("  %s: %s %s\n", result.Position, result.Status, result.Error)
+			}
+		}
+	}
+
+	summary := bulk.Summarize(results)
+	statuses := make([]string, 0, len(summary))
+	for status, count := range summary {
+		statuses = append(statuses, fmt.Sprintf("%s: %d", status, count))
+	}
+	sort.Strings(statuses)
+	fmt.Println(strings.Join(statuses, ", "))
+
+	if summary[bulk.StatusFailed] > 0 {
+		return fmt.Errorf("%d machines failed", summary[bulk.StatusFailed])
+	}
+	return nil
+}
+
+func writeResults(filename string, format bulk.Format, results []bulk.Result) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create results file: %w", err)
+	}
+	defer file.Close()
+
+	err = bulk.WriteResults(format, file, results)
+	if err != nil {
+		return fmt.Errorf("failed to write results file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/airbnb/rudolph/internal/csv"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/catalog"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		entryType string
+		filename  string
+		format    string
+	)
+
+	var catalogExportCmd = &cobra.Command{
+		Use:   "export -f <file-name>",
+		Short: "Export a catalog into a csv or json file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+
+			parsedType, err := catalog.ParseEntryType(entryType)
+			if err != nil {
+				return err
+			}
+
+			return runExport(dynamodbClient, parsedType, filename, format)
+		},
+	}
+
+	catalogExportCmd.Flags().StringVarP(&filename, "filename", "f", "", "The filename")
+	_ = catalogExportCmd.MarkFlagRequired("filename")
+
+	catalogExportCmd.Flags().StringVarP(&format, "fileformat", "t", "csv", "File format (one of: [json|csv])")
+	catalogExportCmd.Flags().StringVar(&entryType, "type", "binary", "Catalog to export (one of: [binary|signingid|teamid|certificate])")
+
+	CatalogCmd.AddCommand(catalogExportCmd)
+}
+
+func runExport(client dynamodb.QueryAPI, entryType catalog.EntryType, filename string, format string) error {
+	switch format {
+	case "json":
+		return runJsonExport(client, entryType, filename)
+	case "csv":
+		return runCsvExport(client, entryType, filename)
+	}
+	return fmt.Errorf("unknown file format %q", format)
+}
+
+func runJsonExport(client dynamodb.QueryAPI, entryType catalog.EntryType, filename string) (err error) {
+	var entries []catalogOutput
+	fmt.Println("Querying catalog from DynamoDB...")
+	err = catalog.GetCatalogEntries(client, entryType, func(row catalog.CatalogEntryRow) error {
+		entries = append(entries, toOutput(row))
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	jsondata, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return
+	}
+	err = os.WriteFile(filename, jsondata, 0644)
+	if err != nil {
+		return
+	}
+
+	fmt.Printf("catalog entries written: %d\n", len(entries))
+	return
+}
+
+func runCsvExport(client dynamodb.QueryAPI, entryType catalog.EntryType, filename string) (err error) {
+	records := make(chan []string)
+
+	header := []string{
+		"type",
+		"identifier",
+		"first_seen",
+		"last_seen",
+		"executions",
+		"machines",
+		"users",
+		"blocks",
+		"file_name",
+		"signing_id",
+		"team_id",
+		"certificate_sha256",
+		"certificate_cn",
+		"bundle_id",
+		"bundle_name",
+		"bundle_version",
+	}
+
+	wg, err := csv.WriteCsvFile(filename, header, records)
+	if err != nil {
+		return
+	}
+
+	fmt.Println("Querying catalog from DynamoDB...")
+	var totalWritten int64
+	err = catalog.GetCatalogEntries(client, entryType, func(row catalog.CatalogEntryRow) error {
+		records <- []string{
+			string(row.EntryType),
+			row.Identifier,
+			row.FirstSeen,
+			row.LastSeen,
+			strconv.FormatInt(row.Executions, 10),
+			strconv.Itoa(row.MachineCount()),
+			strconv.Itoa(row.UserCount()),
+			strconv.FormatInt(row.BlockCount(), 10),
+			row.FileName,
+			row.SigningID,
+			row.TeamID,
+			row.CertificateSHA256,
+			row.CertificateCN,
+			row.BundleID,
+			row.BundleName,
+			row.BundleVersion,
+		}
+		totalWritten += 1
+		return nil
+	})
+	close(records)
+	wg.Wait()
+	if err != nil {
+		return
+	}
+
+	fmt.Printf("catalog entries written: %d\n", totalWritten)
+	return
+}
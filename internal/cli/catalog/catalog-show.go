@@ -0,0 +1,97 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/catalog"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		entryType  string
+		jsonOutput bool
+	)
+
+	var catalogShowCmd = &cobra.Command{
+		Use:   "show <identifier>",
+		Short: "Show everything the catalog knows about a single binary, signing ID, team ID or certificate",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+
+			parsedType, err := catalog.ParseEntryType(entryType)
+			if err != nil {
+				return err
+			}
+
+			entry, err := catalog.GetCatalogEntry(dynamodbClient, parsedType, args[0])
+			if err != nil {
+				return err
+			}
+			if entry == nil {
+				return fmt.Errorf("no %s catalog entry for %q", parsedType, args[0])
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(toOutput(*entry))
+			}
+			printEntry(*entry)
+			return nil
+		},
+	}
+
+	catalogShowCmd.Flags().StringVarP(&entryType, "type", "t", "binary", "Catalog to look in (one of: [binary|signingid|teamid|certificate])")
+	catalogShowCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the entry as JSON")
+
+	CatalogCmd.AddCommand(catalogShowCmd)
+}
+
+func printEntry(entry catalog.CatalogEntryRow) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+
+	fmt.Fprintf(writer, "Type:\t%s\n", entry.EntryType)
+	fmt.Fprintf(writer, "Identifier:\t%s\n", entry.Identifier)
+	fmt.Fprintf(writer, "First Seen:\t%s\n", entry.FirstSeen)
+	fmt.Fprintf(writer, "Last Seen:\t%s\n", entry.LastSeen)
+	fmt.Fprintf(writer, "Executions:\t%d\n", entry.Executions)
+	fmt.Fprintf(writer, "Machines:\t%d\n", entry.MachineCount())
+	fmt.Fprintf(writer, "Users:\t%d\n", entry.UserCount())
+
+	decisions := make([]string, 0, len(entry.DecisionCounts))
+	for decision := range entry.DecisionCounts {
+		decisions = append(decisions, decision)
+	}
+	sort.Strings(decisions)
+	for _, decision := range decisions {
+		fmt.Fprintf(writer, "Decision %s:\t%d\n", decision, entry.DecisionCounts[decision])
+	}
+
+	details := [][2]string{
+		{"File Name", entry.FileName},
+		{"File Path", entry.FilePath},
+		{"Signing ID", entry.SigningID},
+		{"Team ID", entry.TeamID},
+		{"Certificate SHA256", entry.CertificateSHA256},
+		{"Certificate CN", entry.CertificateCN},
+		{"Bundle ID", entry.BundleID},
+		{"Bundle Name", entry.BundleName},
+		{"Bundle Version", entry.BundleVersion},
+	}
+	for _, detail := range details {
+		if detail[1] != "" {
+			fmt.Fprintf(writer, "%s:\t%s\n", detail[0], detail[1])
+		}
+	}
+	writer.Flush()
+}
@@ -0,0 +1,65 @@
+package catalog
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/catalog"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		entryType  string
+		orderBy    string
+		limit      int
+		jsonOutput bool
+	)
+
+	var catalogTopCmd = &cobra.Command{
+		Use:   "top [--type binary|signingid|teamid|certificate] [--by machines|executions|blocks|last_seen]",
+		Short: "List the most widespread binaries, signing IDs, team IDs or certificates across the fleet",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+
+			parsedType, err := catalog.ParseEntryType(entryType)
+			if err != nil {
+				return err
+			}
+			parsedOrder, err := catalog.ParseOrderBy(orderBy)
+			if err != nil {
+				return err
+			}
+
+			entries, err := catalog.ListCatalogEntries(dynamodbClient, parsedType)
+			if err != nil {
+				return err
+			}
+			top := catalog.TopEntries(entries, parsedOrder, limit)
+
+			if jsonOutput {
+				output := make([]catalogOutput, len(top))
+				for i, entry := range top {
+					output[i] = toOutput(entry)
+				}
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(output)
+			}
+			printEntries(top)
+			return nil
+		},
+	}
+
+	catalogTopCmd.Flags().StringVarP(&entryType, "type", "t", "binary", "Catalog to list (one of: [binary|signingid|teamid|certificate])")
+	catalogTopCmd.Flags().StringVar(&orderBy, "by", "machines", "Ordering (one of: [machines|executions|blocks|last_seen])")
+	catalogTopCmd.Flags().IntVarP(&limit, "limit", "n", 20, "Maximum number of entries to list")
+	catalogTopCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output entries as JSON")
+
+	CatalogCmd.AddCommand(catalogTopCmd)
+}
@@ -0,0 +1,65 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/airbnb/rudolph/pkg/model/catalog"
+	"github.com/spf13/cobra"
+)
+
+var (
+	CatalogCmd = &cobra.Command{
+		Use:   "catalog",
+		Short: "Browse the fleet-wide catalog of binaries built from uploaded events",
+	}
+)
+
+// catalogOutput is the exported representation of a catalog entry, with the number of blocks added to its counts
+type catalogOutput struct {
+	catalog.CatalogEntry
+	BlockCount int64 `json:"block_count"`
+}
+
+func toOutput(row catalog.CatalogEntryRow) catalogOutput {
+	return catalogOutput{
+		CatalogEntry: row.CatalogEntry,
+		BlockCount:   row.BlockCount(),
+	}
+}
+
+func printEntries(entries []catalog.CatalogEntryRow) {
+	if len(entries) == 0 {
+		fmt.Println("No catalog entries found.")
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(writer, "IDENTIFIER\tMACHINES\tUSERS\tEXECUTIONS\tBLOCKS\tLAST SEEN\tNAME")
+	for _, entry := range entries {
+		fmt.Fprintf(
+			writer,
+			"%s\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			entry.Identifier,
+			entry.MachineCount(),
+			entry.UserCount(),
+			entry.Executions,
+			entry.BlockCount(),
+			entry.LastSeen,
+			displayName(entry.CatalogEntry),
+		)
+	}
+	writer.Flush()
+}
+
+func displayName(entry catalog.CatalogEntry) string {
+	switch entry.EntryType {
+	case catalog.EntryTypeCertificate, catalog.EntryTypeTeamID:
+		return entry.CertificateCN
+	}
+	if entry.BundleName != "" {
+		return fmt.Sprintf("%s (%s)", entry.BundleName, entry.FileName)
+	}
+	return entry.FileName
+}
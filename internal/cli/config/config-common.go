@@ -1,6 +1,15 @@
 package config
 
 import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/internal/cli/remote"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/modetransitions"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	"github.com/airbnb/rudolph/pkg/types"
 	"github.com/spf13/cobra"
 )
 
@@ -17,3 +26,37 @@ func init() {
 		Short: "Perform various config operations",
 	}
 }
+
+// getService returns the config service of the admin API when the CLI was pointed at it, and the one over DynamoDB
+// otherwise. The permission is what the admin API caller needs. The DynamoDB client is nil for the admin API, which
+// records mode transitions itself.
+func getService(cmd *cobra.Command, permission rbac.Permission) (machineconfiguration.MachineConfigurationService, dynamodb.PutItemAPI, error) {
+	if remote.Enabled(cmd) {
+		client, err := remote.Client(cmd, permission)
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, nil, nil
+	}
+
+	region, _ := cmd.Flags().GetString("region")
+	table, _ := cmd.Flags().GetString("dynamodb_table")
+
+	dynamodbClient := dynamodb.GetClient(table, region)
+	timeProvider := clock.ConcreteTimeProvider{}
+
+	return machineconfiguration.GetMachineConfigurationService(dynamodbClient, timeProvider), dynamodbClient, nil
+}
+
+// recordTransition records a change of a machine's client mode made by the given command, so that automatic promotion
+// leaves recently changed machines alone. Without a client, the admin API made the change and recorded it already.
+func recordTransition(client dynamodb.PutItemAPI, timeProvider clock.TimeProvider, machineID string, fromMode types.ClientMode, toMode types.ClientMode, command string) error {
+	if client == nil || fromMode == toMode {
+		return nil
+	}
+	_, err := modetransitions.RecordTransition(client, timeProvider, machineID, fromMode, toMode, modetransitions.ActorCLI, "rudolph "+command)
+	if err != nil {
+		return fmt.Errorf("configuration was changed, but the transition could not be recorded: %w", err)
+	}
+	return nil
+}
@@ -6,9 +6,9 @@ import (
 	"text/tabwriter"
 
 	"github.com/airbnb/rudolph/internal/cli/flags"
-	"github.com/airbnb/rudolph/pkg/clock"
-	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/internal/cli/remote"
 	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/rbac"
 
 	"github.com/spf13/cobra"
 )
@@ -16,23 +16,19 @@ import (
 func init() {
 	tf := flags.TargetFlags{}
 
-	var configGetCmd = &cobra.Command{
+	var configGetCmd = remote.Supported(&cobra.Command{
 		Use:   "get [-m <machine-id>|--global]",
 		Short: "Get the current global or specific machine UUID specific configuration from the sync server",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-
-			region, _ := cmd.Flags().GetString("region")
-			table, _ := cmd.Flags().GetString("dynamodb_table")
-
-			dynamodbClient := dynamodb.GetClient(table, region)
-			timeProvider := clock.ConcreteTimeProvider{}
-
-			service := machineconfiguration.GetMachineConfigurationService(dynamodbClient, timeProvider)
+			service, _, err := getService(cmd, rbac.View)
+			if err != nil {
+				return err
+			}
 
 			return getConfig(service, tf)
 		},
-	}
+	})
 
 	tf.AddTargetFlags(configGetCmd)
 
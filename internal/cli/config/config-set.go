@@ -8,9 +8,11 @@ import (
 	"text/tabwriter"
 
 	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/internal/cli/remote"
 	"github.com/airbnb/rudolph/pkg/clock"
 	"github.com/airbnb/rudolph/pkg/dynamodb"
 	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/rbac"
 	"github.com/spf13/cobra"
 )
 
@@ -29,21 +31,20 @@ func init() {
 
 	tf := flags.TargetFlags{}
 
-	var configSetCmd = &cobra.Command{
+	var configSetCmd = remote.Supported(&cobra.Command{
 		Use:   "set [-m <machine-id>|--global] [-c <ClientMode - 'monitor' or 'lockdown'>|--client-mode]",
 		Short: "Create a configuration and set globally or a specific machine UUID",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			region, _ := cmd.Flags().GetString("region")
-			table, _ := cmd.Flags().GetString("dynamodb_table")
-
-			dynamodbClient := dynamodb.GetClient(table, region)
-			timeProvider := clock.ConcreteTimeProvider{}
-
-			service := machineconfiguration.GetMachineConfigurationService(dynamodbClient, timeProvider)
+			service, dynamodbClient, err := getService(cmd, rbac.ManageConfigs)
+			if err != nil {
+				return err
+			}
 
 			return applyConfig(
 				service,
+				dynamodbClient,
+				clock.ConcreteTimeProvider{},
 				tf,
 				clientModeArg,
 				blockedPathRegexArg,
@@ -56,7 +57,7 @@ func init() {
 				fullSyncIntervalArg,
 			)
 		},
-	}
+	})
 
 	tf.AddTargetFlags(configSetCmd)
 
@@ -85,6 +86,8 @@ func init() {
 
 func applyConfig(
 	service machineconfiguration.MachineConfigurationService,
+	client dynamodb.PutItemAPI,
+	timeProvider clock.TimeProvider,
 	tf flags.TargetFlags,
 	clientModeArg flags.ClientMode,
 	blockedPathRegexArg string,
@@ -162,9 +165,14 @@ func applyConfig(
 		UploadLogsURL:          uploadLogsUrlArgs,
 	}
 
+	var previousConfig machineconfiguration.MachineConfiguration
 	if tf.IsGlobal {
 		err = service.SetGlobalConfig(newConfig)
 	} else {
+		previousConfig, err = service.GetIntendedConfig(machineID)
+		if err != nil {
+			return fmt.Errorf("error reading the current configuration from the sync server: %w", err)
+		}
 		err = service.SetMachineConfig(machineID, newConfig)
 	}
 
@@ -173,6 +181,10 @@ func applyConfig(
 	} else {
 		fmt.Println("Success! Configuration was sent properly to DynamoDB...")
 	}
+
+	if !tf.IsGlobal {
+		err = recordTransition(client, timeProvider, machineID, previousConfig.ClientMode, clientMode, "config set")
+	}
 	return
 
 }
@@ -8,9 +8,11 @@ import (
 	"text/tabwriter"
 
 	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/internal/cli/remote"
 	"github.com/airbnb/rudolph/pkg/clock"
 	"github.com/airbnb/rudolph/pkg/dynamodb"
 	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/rbac"
 	"github.com/spf13/cobra"
 )
 
@@ -21,26 +23,25 @@ func init() {
 
 	tf := flags.TargetFlags{}
 
-	var configUpdateClientModeCmd = &cobra.Command{
+	var configUpdateClientModeCmd = remote.Supported(&cobra.Command{
 		Use:   "update [-m <machine-id>|--global] [-c <ClientMode - 'monitor' or 'lockdown'>|--client-mode]",
 		Short: "Update the client-mode globally or for a specific machine UUID",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			region, _ := cmd.Flags().GetString("region")
-			table, _ := cmd.Flags().GetString("dynamodb_table")
-
-			dynamodbClient := dynamodb.GetClient(table, region)
-			timeProvider := clock.ConcreteTimeProvider{}
-
-			service := machineconfiguration.GetMachineConfigurationService(dynamodbClient, timeProvider)
+			service, dynamodbClient, err := getService(cmd, rbac.ManageConfigs)
+			if err != nil {
+				return err
+			}
 
 			return updateConfig(
 				service,
+				dynamodbClient,
+				clock.ConcreteTimeProvider{},
 				tf,
 				clientModeArg,
 			)
 		},
-	}
+	})
 
 	tf.AddTargetFlags(configUpdateClientModeCmd)
 
@@ -54,6 +55,8 @@ func init() {
 
 func updateConfig(
 	service machineconfiguration.MachineConfigurationService,
+	client dynamodb.PutItemAPI,
+	timeProvider clock.TimeProvider,
 	tf flags.TargetFlags,
 	clientModeArg flags.ClientMode) (err error) {
 	clientMode := clientModeArg.AsClientMode()
@@ -106,9 +109,14 @@ func updateConfig(
 		ClientMode: &clientMode,
 	}
 
+	var previousConfig machineconfiguration.MachineConfiguration
 	if tf.IsGlobal {
 		_, err = service.UpdateGlobalConfig(updateRequest)
 	} else {
+		previousConfig, err = service.GetIntendedConfig(machineID)
+		if err != nil {
+			return fmt.Errorf("error reading the current configuration from the sync server: %w", err)
+		}
 		_, err = service.UpdateMachineConfig(machineID, updateRequest)
 	}
 
@@ -117,6 +125,10 @@ func updateConfig(
 	} else {
 		fmt.Println("Success! Configuration was sent properly to DynamoDB...")
 	}
+
+	if !tf.IsGlobal {
+		err = recordTransition(client, timeProvider, machineID, previousConfig.ClientMode, clientMode, "config update")
+	}
 	return
 
 }
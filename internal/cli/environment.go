@@ -20,6 +20,10 @@ type environmentConfiguration struct {
 	AWSAccountID string `json:"aws_account_id"`
 	DDBPrefix    string `json:"ddb_prefix"`
 	StageName    string `json:"stage_name"`
+	Org          string `json:"org"`
+	// Route53ZoneName is the zone in which the API's domain name is created; see deployments/terraform_modules/santa_api/route53.tf.
+	// viper decodes with mapstructure, which ignores the json tags.
+	Route53ZoneName string `json:"route53_zone_name" mapstructure:"route53_zone_name"`
 }
 
 func fileExists(name string) bool {
@@ -101,6 +105,14 @@ func retrieveConfig(cmd *cobra.Command) (*environmentConfiguration, error) {
 	cmd.Flags().Set("prefix", config.Prefix)
 	cmd.Flags().Set("region", config.Region)
 	cmd.Flags().Set("dynamodb_table", fmt.Sprintf("%s_rudolph_store", config.Prefix))
+	if config.Route53ZoneName != "" && !cmd.Flags().Changed("sync_base_url") {
+		// Setting the value directly leaves the flag unchanged, so that commands can still tell whether it was given
+		// and prefer other sources of the URL over the environment's
+		cmd.Flags().Lookup("sync_base_url").Value.Set(fmt.Sprintf("https://%s-rudolph.%s/", config.Prefix, config.Route53ZoneName))
+	}
+	if !cmd.Flags().Changed("org") {
+		cmd.Flags().Set("org", config.Org)
+	}
 
 	return config, err
 
@@ -0,0 +1,116 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		machineID  string
+		fileSHA256 string
+		since      string
+		limit      int
+		jsonOutput bool
+	)
+
+	var eventsListCmd = &cobra.Command{
+		Use:   "list (--machine <machine-id>|--sha <sha256>) [--since 24h]",
+		Short: "List recently uploaded events for a machine or for a binary",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+			timeProvider := clock.ConcreteTimeProvider{}
+
+			if (machineID == "") == (fileSHA256 == "") {
+				return errors.New("exactly one of --machine or --sha must be provided")
+			}
+
+			sinceTime, err := flags.ParseSince(timeProvider, since)
+			if err != nil {
+				return fmt.Errorf("invalid --since value: %w", err)
+			}
+
+			var items []eventlog.EventRow
+			if machineID != "" {
+				if err = types.ValidateMachineID(machineID); err != nil {
+					return err
+				}
+				items, err = eventlog.GetEventsByMachineID(dynamodbClient, machineID, sinceTime, limit)
+			} else {
+				fileSHA256 = strings.ToLower(fileSHA256)
+				if err = types.ValidateSha256(fileSHA256); err != nil {
+					return err
+				}
+				items, err = eventlog.GetEventsByFileSHA256(dynamodbClient, fileSHA256, sinceTime, limit)
+			}
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printEventsJSON(items)
+			}
+			printEvents(items)
+			return nil
+		},
+	}
+
+	eventsListCmd.Flags().StringVarP(&machineID, "machine", "m", "", "Machine ID to list events for")
+	eventsListCmd.Flags().StringVar(&fileSHA256, "sha", "", "SHA256 of the binary to list events for, across all machines")
+	eventsListCmd.Flags().StringVar(&since, "since", "24h", "Only list events newer than this; either a duration (e.g. 36h, 7d) or an RFC3339 timestamp")
+	eventsListCmd.Flags().IntVarP(&limit, "limit", "n", 50, "Maximum number of events to list")
+	eventsListCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output events as JSON")
+
+	EventsCmd.AddCommand(eventsListCmd)
+}
+
+func printEvents(items []eventlog.EventRow) {
+	if len(items) == 0 {
+		fmt.Println("No events found.")
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(writer, "EXECUTED AT\tMACHINE ID\tDECISION\tUSER\tSHA256\tTEAM ID\tSIGNING ID\tPATH")
+	for _, item := range items {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			item.ExecutedAt,
+			item.MachineID,
+			item.Decision,
+			item.ExecutingUser,
+			item.FileSHA256,
+			item.TeamID,
+			item.SigningID,
+			strings.TrimSuffix(item.FilePath, "/")+"/"+item.FileName,
+		)
+	}
+	writer.Flush()
+}
+
+func printEventsJSON(items []eventlog.EventRow) error {
+	events := make([]eventlog.Event, len(items))
+	for i, item := range items {
+		events[i] = item.Event
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(events)
+}
@@ -0,0 +1,12 @@
+package events
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	EventsCmd = &cobra.Command{
+		Use:   "events",
+		Short: "Inspect events uploaded by Santa sensors to the event store",
+	}
+)
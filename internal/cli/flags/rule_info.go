@@ -20,7 +20,7 @@ func (r *RuleInfoFlags) AddRuleInfoFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&identifierArg, "identifier", "i", "", `The Identifier/SHA256 for a file, application, teamID, or signingID`)
 
 	// rule-type should be one of "binary" or "cert" ("bin" and "certificate" also work)
-	cmd.Flags().VarP(&ruleTypeArg, "rule-type", "t", `type of rule being applied. valid options are: "binary", "bin", "certificate", "cert", "teamid", "signingid"`)
+	cmd.Flags().VarP(&ruleTypeArg, "rule-type", "t", `type of rule being applied. valid options are: "binary", "bin", "certificate", "cert", "teamid", "signingid", "cdhash"`)
 	_ = cmd.MarkFlagRequired("rule-type")
 
 	// If we want to make the `rule-type` flag optional with a default (say "binary"),
@@ -0,0 +1,31 @@
+package flags
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+)
+
+// ParseSince accepts either a Go duration, a number of days suffixed with "d", or an RFC3339 timestamp, and returns
+// the point in time it refers to. Durations and days count back from now.
+func ParseSince(timeProvider clock.TimeProvider, since string) (time.Time, error) {
+	if t, err := clock.ParseRFC3339(since); err == nil {
+		return t, nil
+	}
+
+	if strings.HasSuffix(since, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(since, "d"))
+		if err == nil && days >= 0 {
+			return timeProvider.Now().UTC().AddDate(0, 0, -days), nil
+		}
+	}
+
+	duration, err := time.ParseDuration(since)
+	if err != nil || duration < 0 {
+		return time.Time{}, fmt.Errorf("invalid time %q; use a duration like 24h, a number of days like 7d, or an RFC3339 timestamp", since)
+	}
+	return timeProvider.Now().UTC().Add(-duration), nil
+}
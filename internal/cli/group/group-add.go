@@ -0,0 +1,37 @@
+package group
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/machinegroups"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var groupAddCmd = &cobra.Command{
+		Use:   "add <group-name> <machine-id>...",
+		Short: "Add one or more machines to a group",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+			timeProvider := clock.ConcreteTimeProvider{}
+
+			groupName := args[0]
+			for _, machineID := range args[1:] {
+				err := machinegroups.AddMachineToGroup(dynamodbClient, timeProvider, groupName, machineID)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Added %s to group %s\n", machineID, groupName)
+			}
+			return nil
+		},
+	}
+
+	GroupCmd.AddCommand(groupAddCmd)
+}
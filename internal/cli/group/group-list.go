@@ -0,0 +1,34 @@
+package group
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/machinegroups"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var groupListCmd = &cobra.Command{
+		Use:   "list <group-name>",
+		Short: "List the machines in a group",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+
+			machineIDs, err := machinegroups.GetGroupMachineIDs(dynamodbClient, args[0])
+			if err != nil {
+				return err
+			}
+			for _, machineID := range machineIDs {
+				fmt.Println(machineID)
+			}
+			return nil
+		},
+	}
+
+	GroupCmd.AddCommand(groupListCmd)
+}
@@ -0,0 +1,35 @@
+package group
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/machinegroups"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var groupRemoveCmd = &cobra.Command{
+		Use:   "remove <group-name> <machine-id>...",
+		Short: "Remove one or more machines from a group",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+
+			groupName := args[0]
+			for _, machineID := range args[1:] {
+				err := machinegroups.RemoveMachineFromGroup(dynamodbClient, groupName, machineID)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Removed %s from group %s\n", machineID, groupName)
+			}
+			return nil
+		},
+	}
+
+	GroupCmd.AddCommand(groupRemoveCmd)
+}
@@ -0,0 +1,12 @@
+package group
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	GroupCmd = &cobra.Command{
+		Use:   "group",
+		Short: "Manage named groups of machines",
+	}
+)
@@ -0,0 +1,76 @@
+package lockdown
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/modetransitions"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		machineID  string
+		days       int
+		jsonOutput bool
+	)
+
+	var historyCmd = &cobra.Command{
+		Use:   "history [--days 7] [--machine <machine-id>]",
+		Short: "List recorded MONITOR/LOCKDOWN transitions, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+			timeProvider := clock.ConcreteTimeProvider{}
+
+			if days <= 0 {
+				return errors.New("--days must be positive")
+			}
+
+			since := timeProvider.Now().UTC().Add(-time.Duration(days) * 24 * time.Hour)
+			rows, err := modetransitions.GetTransitionsSince(dynamodbClient, timeProvider, since)
+			if err != nil {
+				return err
+			}
+
+			transitions := make([]modetransitions.ModeTransition, 0, len(rows))
+			for i := len(rows) - 1; i >= 0; i-- {
+				if machineID != "" && rows[i].MachineID != machineID {
+					continue
+				}
+				transitions = append(transitions, rows[i].ModeTransition)
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(transitions)
+			}
+
+			writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+			fmt.Fprintln(writer, "TIME\tMACHINE ID\tFROM\tTO\tACTOR\tREASON")
+			for _, transition := range transitions {
+				fromMode, _ := transition.FromMode.MarshalText()
+				toMode, _ := transition.ToMode.MarshalText()
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", transition.TransitionedAt, transition.MachineID, fromMode, toMode, transition.Actor, transition.Reason)
+			}
+			writer.Flush()
+			return nil
+		},
+	}
+
+	historyCmd.Flags().StringVarP(&machineID, "machine", "m", "", "Only show transitions of this machine")
+	historyCmd.Flags().IntVar(&days, "days", 7, "Number of days of history to show")
+	historyCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the transitions as JSON")
+
+	LockdownCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,123 @@
+package lockdown
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/lockdown"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		policy     = lockdown.DefaultPromotionPolicy()
+		jsonOutput bool
+		verbose    bool
+	)
+
+	var promoteCmd = &cobra.Command{
+		Use:   "promote [--dry-run] [--group <group-name>]... [--exclude-group <group-name>]...",
+		Short: "Promote machines that have been clean in MONITOR to LOCKDOWN, and demote recent promotions that are being blocked",
+		Long: `Promote machines that have been clean in MONITOR to LOCKDOWN, and demote recent promotions that are being blocked.
+
+This runs the same logic as the scheduled lockdown promotion job. A dry run is always shown first; unless --dry-run
+is given, the changes are applied after confirmation.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+			timeProvider := clock.ConcreteTimeProvider{}
+			store := lockdown.GetPromotionStore(dynamodbClient, timeProvider)
+
+			if err := policy.Validate(); err != nil {
+				return err
+			}
+
+			applyChanges := !policy.DryRun
+			policy.DryRun = true
+			result, err := lockdown.RunPromotion(store, timeProvider, policy)
+			if err != nil {
+				return err
+			}
+
+			if !applyChanges || (len(result.Promoted) == 0 && len(result.Demoted) == 0) {
+				return printPromotionResult(result, jsonOutput, verbose)
+			}
+
+			printPromotionResult(result, false, verbose)
+			fmt.Println()
+			fmt.Println(`Apply changes? (Enter: "yes" or "ok")`)
+			fmt.Print("> ")
+
+			reader := bufio.NewReader(os.Stdin)
+			text, _ := reader.ReadString('\n')
+			text = strings.TrimSpace(text)
+			if text != "ok" && text != "yes" {
+				fmt.Println("Confirmation not successful...")
+				return nil
+			}
+
+			policy.DryRun = false
+			result, err = lockdown.RunPromotion(store, timeProvider, policy)
+			if err != nil {
+				return err
+			}
+			return printPromotionResult(result, jsonOutput, verbose)
+		},
+	}
+
+	promoteCmd.Flags().IntVar(&policy.MonitorDays, "monitor-days", policy.MonitorDays, "Days of uploaded events, without a would-be-blocked execution, required before promotion")
+	promoteCmd.Flags().IntVar(&policy.DailyPromotionCap, "daily-cap", policy.DailyPromotionCap, "Maximum number of machines promoted per UTC day")
+	promoteCmd.Flags().StringArrayVar(&policy.CandidateGroups, "group", nil, "Only consider members of this group (repeatable)")
+	promoteCmd.Flags().StringArrayVar(&policy.ExcludedGroups, "exclude-group", nil, "Never promote members of this group (repeatable)")
+	promoteCmd.Flags().DurationVar(&policy.DemotionWindow, "demotion-window", policy.DemotionWindow, "How long promoted machines are watched for blocks")
+	promoteCmd.Flags().IntVar(&policy.DemotionBlockThreshold, "demotion-threshold", policy.DemotionBlockThreshold, "Number of blocks within the demotion window that sends a machine back to MONITOR")
+	promoteCmd.Flags().BoolVar(&policy.DryRun, "dry-run", false, "Only show what would change")
+	promoteCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Also list the machines that were skipped, and why")
+	promoteCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the result as JSON")
+
+	LockdownCmd.AddCommand(promoteCmd)
+}
+
+func printPromotionResult(result lockdown.PromotionResult, jsonOutput bool, verbose bool) error {
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	if result.DryRun {
+		fmt.Println("Dry run; no changes were made")
+		fmt.Println()
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(writer, "ACTION\tMACHINE ID\tREASON")
+	for _, outcome := range result.Demoted {
+		fmt.Fprintf(writer, "demote\t%s\t%s\n", outcome.MachineID, outcome.Reason)
+	}
+	for _, outcome := range result.Promoted {
+		fmt.Fprintf(writer, "promote\t%s\t%s\n", outcome.MachineID, outcome.Reason)
+	}
+	if verbose {
+		for _, outcome := range result.Skipped {
+			fmt.Fprintf(writer, "skip\t%s\t%s\n", outcome.MachineID, outcome.Reason)
+		}
+	}
+	writer.Flush()
+
+	fmt.Println()
+	fmt.Printf("%d demoted, %d promoted, %d skipped\n", len(result.Demoted), len(result.Promoted), len(result.Skipped))
+	if result.CapReached {
+		fmt.Println("The daily promotion cap was reached; remaining machines will be considered tomorrow")
+	}
+	return nil
+}
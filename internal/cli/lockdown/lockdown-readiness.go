@@ -0,0 +1,204 @@
+package lockdown
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/lockdown"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/model/machinegroups"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		machineID   string
+		groupName   string
+		days        int
+		jsonOutput  bool
+		granularity = flags.RuleType(types.RuleTypeTeamID)
+	)
+
+	var readinessCmd = &cobra.Command{
+		Use:   "readiness (--machine <machine-id>|--group <group-name>) [--days 14]",
+		Short: "Predict what would be blocked if machines were moved to LOCKDOWN, and suggest the rules to prevent it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+			timeProvider := clock.ConcreteTimeProvider{}
+
+			if (machineID == "") == (groupName == "") {
+				return errors.New("exactly one of --machine or --group must be provided")
+			}
+			if days <= 0 {
+				return errors.New("--days must be positive")
+			}
+
+			machineIDs := []string{machineID}
+			if groupName != "" {
+				var err error
+				machineIDs, err = machinegroups.GetGroupMachineIDs(dynamodbClient, groupName)
+				if err != nil {
+					return err
+				}
+				if len(machineIDs) == 0 {
+					return fmt.Errorf("group %q has no machines", groupName)
+				}
+			}
+
+			report, err := buildReadinessReport(dynamodbClient, timeProvider, machineIDs, days, granularity.AsRuleType())
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			}
+			printReadinessReport(report, machineID != "")
+			return nil
+		},
+	}
+
+	readinessCmd.Flags().StringVarP(&machineID, "machine", "m", "", "Assess a single machine")
+	readinessCmd.Flags().StringVar(&groupName, "group", "", "Assess every machine in a group")
+	readinessCmd.Flags().IntVar(&days, "days", 14, "Number of days of uploaded events to replay")
+	readinessCmd.Flags().Var(&granularity, "granularity", "Broadest rule type to suggest (one of: [binary|signingid|cert|teamid])")
+	readinessCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the report as JSON")
+
+	LockdownCmd.AddCommand(readinessCmd)
+}
+
+type machineReport struct {
+	lockdown.MachineReadiness
+	ClientMode string `json:"client_mode"`
+}
+
+type readinessReport struct {
+	Since          string            `json:"since"`
+	Machines       []machineReport   `json:"machines"`
+	SuggestedRules []rules.SantaRule `json:"suggested_rules"`
+}
+
+func buildReadinessReport(
+	client dynamodb.DynamoDBClient,
+	timeProvider clock.TimeProvider,
+	machineIDs []string,
+	days int,
+	granularity types.RuleType,
+) (report readinessReport, err error) {
+	since := timeProvider.Now().UTC().Add(-time.Duration(days) * 24 * time.Hour)
+	report.Since = clock.RFC3339(since)
+
+	globalRules, err := ruleset.LoadGlobalRules(client)
+	if err != nil {
+		return
+	}
+
+	var blocked []lockdown.BlockedBinary
+	for _, machineID := range machineIDs {
+		if err = types.ValidateMachineID(machineID); err != nil {
+			return
+		}
+
+		var rs ruleset.Ruleset
+		rs, err = ruleset.ForMachine(client, globalRules, machineID)
+		if err != nil {
+			return
+		}
+
+		var events []eventlog.EventRow
+		events, err = eventlog.GetEventsByMachineID(client, machineID, since, lockdown.MaxEventsPerMachine)
+		if err != nil {
+			return
+		}
+
+		readiness := lockdown.AssessMachine(machineID, rs, events)
+		blocked = append(blocked, readiness.WouldBlock...)
+
+		clientMode := "UNKNOWN"
+		var sensorData *sensordata.SensorData
+		sensorData, err = sensordata.GetSensorData(client, machineID)
+		if err != nil {
+			return
+		}
+		if sensorData != nil {
+			if text, merr := sensorData.ClientMode.MarshalText(); merr == nil {
+				clientMode = string(text)
+			}
+		}
+
+		report.Machines = append(report.Machines, machineReport{
+			MachineReadiness: readiness,
+			ClientMode:       clientMode,
+		})
+	}
+
+	report.SuggestedRules = lockdown.SuggestRules(blocked, granularity)
+	return
+}
+
+func printReadinessReport(report readinessReport, detailed bool) {
+	fmt.Printf("Replaying uploaded events since %s\n\n", report.Since)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(writer, "MACHINE ID\tMODE\tSCORE\tBINARIES\tWOULD BLOCK\tSTATUS")
+	for _, machine := range report.Machines {
+		status := "not ready"
+		if machine.Ready() {
+			status = "ready"
+		} else if !machine.HasEvents {
+			status = "no events"
+		}
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%.1f\t%d\t%d\t%s\n",
+			machine.MachineID,
+			machine.ClientMode,
+			machine.Score,
+			machine.DistinctBinaries,
+			len(machine.WouldBlock),
+			status,
+		)
+	}
+	writer.Flush()
+
+	if detailed && len(report.Machines) == 1 && len(report.Machines[0].WouldBlock) > 0 {
+		fmt.Println("\nWould have been blocked:")
+		writer = tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+		fmt.Fprintln(writer, "SHA256\tNAME\tTEAM ID\tSIGNING ID\tEXECUTIONS\tLAST SEEN")
+		for _, binary := range report.Machines[0].WouldBlock {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%s\n", binary.FileSHA256, binary.FileName, binary.TeamID, binary.SigningID, binary.Executions, binary.LastSeen)
+		}
+		writer.Flush()
+	}
+
+	if len(report.SuggestedRules) == 0 {
+		return
+	}
+
+	fmt.Println("\nSuggested rules:")
+	writer = tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(writer, "TYPE\tPOLICY\tIDENTIFIER")
+	for _, rule := range report.SuggestedRules {
+		ruleType, _ := rule.RuleType.MarshalText()
+		policy, _ := rule.Policy.MarshalText()
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", ruleType, policy, rule.Identifier)
+	}
+	writer.Flush()
+}
@@ -0,0 +1,12 @@
+package lockdown
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	LockdownCmd = &cobra.Command{
+		Use:   "lockdown",
+		Short: "Plan and track the move of machines from MONITOR to LOCKDOWN",
+	}
+)
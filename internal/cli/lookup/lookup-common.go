@@ -1,6 +1,10 @@
 package lookup
 
 import (
+	"github.com/airbnb/rudolph/internal/cli/remote"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/rbac"
 	"github.com/spf13/cobra"
 )
 
@@ -10,3 +14,15 @@ var (
 		Short: "Perform various lookup/search operations on sensordata",
 	}
 )
+
+// getFinder returns the admin API client when the CLI was pointed at it, and searches DynamoDB otherwise
+func getFinder(cmd *cobra.Command) (sensordata.SensorDataFinder, error) {
+	if remote.Enabled(cmd) {
+		return remote.Client(cmd, rbac.View)
+	}
+
+	region, _ := cmd.Flags().GetString("region")
+	table, _ := cmd.Flags().GetString("dynamodb_table")
+
+	return sensordata.GetSensorDataFinder(dynamodb.GetClient(table, region)), nil
+}
@@ -4,26 +4,23 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/airbnb/rudolph/pkg/dynamodb"
-	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/internal/cli/remote"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 
-	var lookupMachineIDsCmd = &cobra.Command{
+	var lookupMachineIDsCmd = remote.Supported(&cobra.Command{
 		Use:   "machine-ids",
 		Short: "Attempts to search for a machine ID given a prefix or entire machine ID",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			region, _ := cmd.Flags().GetString("region")
-			table, _ := cmd.Flags().GetString("dynamodb_table")
-
-			dynamodbClient := dynamodb.GetClient(table, region)
-
 			prefix := strings.Join(args, "")
 
-			sensorDataFinderService := sensordata.GetSensorDataFinder(dynamodbClient)
+			sensorDataFinderService, err := getFinder(cmd)
+			if err != nil {
+				return err
+			}
 
 			machineIDs, err := sensorDataFinderService.GetMachineIDsStartingWith(prefix, 10)
 			if err != nil {
@@ -32,7 +29,7 @@ func init() {
 			fmt.Println(machineIDs)
 			return nil
 		},
-	}
+	})
 
 	LookupCmd.AddCommand(lookupMachineIDsCmd)
 }
@@ -0,0 +1,201 @@
+package machine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/compliance"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		policy   = compliance.DefaultPolicy()
+		all      bool
+		format   string
+		filename string
+	)
+
+	var machineComplianceCmd = &cobra.Command{
+		Use:   "compliance [--min-santa-version 2023.10] [--rule-count-tolerance 5] [--rule-count-tolerance-percent 1] [--all] [--format table|json|csv]",
+		Short: "Report the machines that drifted from their intended configuration",
+		Long: `Compare what every sensor last reported in preflight with its intended configuration and effective ruleset:
+
+  client_mode       the machine reports MONITOR but should be in LOCKDOWN
+  rule_count        a reported rule count is off from the effective ruleset by more than the tolerance
+  transitive_rules  the machine holds transitive rules although they are disabled for it
+  santa_version     the machine runs a Santa version below --min-santa-version
+
+A count is flagged when it is off by more than --rule-count-tolerance rules, or --rule-count-tolerance-percent of
+the expected count, whichever is larger. Transitive rules are not counted against the ruleset.
+
+Only non-compliant machines are listed unless --all is set; the summary always covers the whole fleet.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+			timeProvider := clock.ConcreteTimeProvider{}
+
+			if format != "table" && format != "json" && format != "csv" {
+				return errors.New(`--format must be one of: "table", "json", "csv"`)
+			}
+
+			report, err := compliance.Run(compliance.GetStore(dynamodbClient, timeProvider), timeProvider, policy)
+			if err != nil {
+				return err
+			}
+
+			if !all {
+				machines := []compliance.Machine{}
+				for _, machine := range report.Machines {
+					if !machine.Compliant {
+						machines = append(machines, machine)
+					}
+				}
+				report.Machines = machines
+			}
+
+			var out io.Writer = os.Stdout
+			if filename != "" {
+				f, err := os.Create(filename)
+				if err != nil {
+					return fmt.Errorf("failed to create %q: %w", filename, err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			switch format {
+			case "json":
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			case "csv":
+				return writeComplianceCsv(out, report.Machines)
+			default:
+				printCompliance(out, report)
+				return nil
+			}
+		},
+	}
+
+	machineComplianceCmd.Flags().StringVar(&policy.MinimumSantaVersion, "min-santa-version", "", "Flag machines running a Santa version below this one")
+	machineComplianceCmd.Flags().IntVar(&policy.RuleCountTolerance, "rule-count-tolerance", policy.RuleCountTolerance, "Flag rule counts that are off by more than this many rules")
+	machineComplianceCmd.Flags().Float64Var(&policy.RuleCountTolerancePercent, "rule-count-tolerance-percent", policy.RuleCountTolerancePercent, "Flag rule counts that are off by more than this percent of the expected count")
+	machineComplianceCmd.Flags().BoolVar(&all, "all", false, "List compliant machines too")
+	machineComplianceCmd.Flags().StringVarP(&format, "format", "t", "table", "Output format (one of: [table|json|csv])")
+	machineComplianceCmd.Flags().StringVarP(&filename, "filename", "f", "", "Write the output to this file instead of stdout")
+
+	MachineCmd.AddCommand(machineComplianceCmd)
+}
+
+func printCompliance(out io.Writer, report compliance.Report) {
+	summary := report.Summary
+	fmt.Fprintf(out, "Checked %d machines at %s: %d compliant, %d non-compliant\n", summary.MachinesChecked, clock.RFC3339(report.GeneratedAt), summary.Compliant, summary.NonCompliant)
+	writer := tabwriter.NewWriter(out, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(writer, "  MONITOR instead of LOCKDOWN:\t%d\n", summary.ByCheck[compliance.CheckClientMode])
+	fmt.Fprintf(writer, "  Rule counts off (tolerance %d or %g%%):\t%d\n", report.Policy.RuleCountTolerance, report.Policy.RuleCountTolerancePercent, summary.ByCheck[compliance.CheckRuleCount])
+	fmt.Fprintf(writer, "  Transitive rules while disabled:\t%d\n", summary.ByCheck[compliance.CheckTransitiveRules])
+	if report.Policy.MinimumSantaVersion != "" {
+		fmt.Fprintf(writer, "  Santa below %s:\t%d\n", report.Policy.MinimumSantaVersion, summary.ByCheck[compliance.CheckSantaVersion])
+	}
+	writer.Flush()
+	fmt.Fprintln(out, "")
+
+	if len(report.Machines) == 0 {
+		fmt.Fprintln(out, "No machines to list.")
+		return
+	}
+
+	writer = tabwriter.NewWriter(out, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(writer, "MACHINE ID\tSERIAL\tUSER\tSANTA\tREPORTED\tINTENDED\tLAST SEEN\tFINDINGS")
+	for _, machine := range report.Machines {
+		details := make([]string, len(machine.Findings))
+		for i, finding := range machine.Findings {
+			details[i] = finding.Detail
+		}
+		if machine.Compliant {
+			details = []string{"compliant"}
+		}
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			machine.MachineID,
+			machine.SerialNum,
+			machine.PrimaryUser,
+			machine.SantaVersion,
+			machine.ReportedMode,
+			machine.IntendedMode,
+			machine.LastSeen,
+			strings.Join(details, "; "),
+		)
+	}
+	writer.Flush()
+}
+
+func writeComplianceCsv(out io.Writer, machines []compliance.Machine) error {
+	writer := csv.NewWriter(out)
+	err := writer.Write([]string{
+		"machine_id",
+		"serial_num",
+		"primary_user",
+		"santa_version",
+		"reported_mode",
+		"intended_mode",
+		"last_seen",
+		"expected_rule_count",
+		"reported_rule_count",
+		"transitive_rule_count",
+		"compliant",
+		"failed_checks",
+		"findings",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, machine := range machines {
+		checks := []string{}
+		for _, check := range compliance.Checks {
+			if machine.Failed(check) {
+				checks = append(checks, string(check))
+			}
+		}
+		details := make([]string, len(machine.Findings))
+		for i, finding := range machine.Findings {
+			details[i] = finding.Detail
+		}
+		err = writer.Write([]string{
+			machine.MachineID,
+			machine.SerialNum,
+			machine.PrimaryUser,
+			machine.SantaVersion,
+			machine.ReportedMode,
+			machine.IntendedMode,
+			machine.LastSeen,
+			strconv.Itoa(machine.ExpectedRuleCounts.Total),
+			strconv.Itoa(machine.ReportedRuleCounts.Total),
+			strconv.Itoa(machine.TransitiveRuleCount),
+			strconv.FormatBool(machine.Compliant),
+			strings.Join(checks, ","),
+			strings.Join(details, "; "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
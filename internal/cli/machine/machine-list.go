@@ -0,0 +1,220 @@
+package machine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/internal/cli/remote"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/inventory"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		filter        inventory.Filter
+		reportedMode  string
+		intendedMode  string
+		seenWithin    string
+		notSeenWithin string
+		sortBy        string
+		descending    bool
+		limit         int
+		after         string
+		format        string
+		filename      string
+	)
+
+	var machineListCmd = remote.Supported(&cobra.Command{
+		Use:   "list [--os-version 14] [--santa-version 2023.10] [--mode-mismatch] [--sort last_seen] [--format table|json|csv]",
+		Short: "List the machines that have checked in, with filters",
+		Long: `List the machines that have checked in within the last 90 days, as last reported by their sensors.
+
+Listings sorted by machine ID are paginated: when --limit cuts a listing short, it ends with the --after value that
+resumes it. Any other sort order reads the whole fleet before sorting.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			timeProvider := clock.ConcreteTimeProvider{}
+
+			if format != "table" && format != "json" && format != "csv" {
+				return errors.New(`--format must be one of: "table", "json", "csv"`)
+			}
+
+			options := inventory.Options{
+				Filter:     filter,
+				Descending: descending,
+				Limit:      limit,
+				After:      after,
+			}
+
+			var err error
+			options.SortBy, err = inventory.ParseSortBy(sortBy)
+			if err != nil {
+				return err
+			}
+			if reportedMode != "" {
+				var mode flags.ClientMode
+				if err = mode.Set(strings.ToLower(reportedMode)); err != nil {
+					return fmt.Errorf("invalid --reported-mode value: %w", err)
+				}
+				options.Filter.ReportedMode = mode.AsClientMode()
+			}
+			if intendedMode != "" {
+				var mode flags.ClientMode
+				if err = mode.Set(strings.ToLower(intendedMode)); err != nil {
+					return fmt.Errorf("invalid --intended-mode value: %w", err)
+				}
+				options.Filter.IntendedMode = mode.AsClientMode()
+			}
+			if seenWithin != "" {
+				options.Filter.SeenAfter, err = flags.ParseSince(timeProvider, seenWithin)
+				if err != nil {
+					return fmt.Errorf("invalid --seen-within value: %w", err)
+				}
+			}
+			if notSeenWithin != "" {
+				options.Filter.SeenBefore, err = flags.ParseSince(timeProvider, notSeenWithin)
+				if err != nil {
+					return fmt.Errorf("invalid --not-seen-within value: %w", err)
+				}
+			}
+
+			reader, err := getMachineReader(cmd)
+			if err != nil {
+				return err
+			}
+			page, err := reader.ListMachines(options)
+			if err != nil {
+				return err
+			}
+
+			var out io.Writer = os.Stdout
+			if filename != "" {
+				f, err := os.Create(filename)
+				if err != nil {
+					return fmt.Errorf("failed to create %q: %w", filename, err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			switch format {
+			case "json":
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(page)
+			case "csv":
+				err = writeInventoryCsv(out, page.Machines)
+			default:
+				printInventory(out, page)
+			}
+			if err == nil && page.Next != "" && format != "table" {
+				fmt.Fprintf(os.Stderr, "More machines match; resume with --after %s\n", page.Next)
+			}
+			return err
+		},
+	})
+
+	machineListCmd.Flags().StringVar(&filter.OSVersion, "os-version", "", `Only list machines on this macOS version; "14" matches every 14.x`)
+	machineListCmd.Flags().StringVar(&filter.OSBuild, "os-build", "", "Only list machines on this macOS build")
+	machineListCmd.Flags().StringVar(&filter.SantaVersion, "santa-version", "", `Only list machines on this Santa version; "2023" matches every 2023.x`)
+	machineListCmd.Flags().StringVar(&filter.PrimaryUser, "user", "", "Only list the machines of this primary user")
+	machineListCmd.Flags().StringVar(&filter.ModelIdentifier, "model", "", `Only list machines whose model identifier starts with this, e.g. "MacBookPro"`)
+	machineListCmd.Flags().StringVar(&reportedMode, "reported-mode", "", `Only list machines whose sensor reports this mode: "monitor" or "lockdown"`)
+	machineListCmd.Flags().StringVar(&intendedMode, "intended-mode", "", `Only list machines configured for this mode: "monitor" or "lockdown"`)
+	machineListCmd.Flags().BoolVar(&filter.ModeMismatch, "mode-mismatch", false, "Only list machines whose sensor reports a different mode than the one configured")
+	machineListCmd.Flags().StringVar(&seenWithin, "seen-within", "", "Only list machines seen within this long, e.g. 24h or 7d, or since an RFC3339 time")
+	machineListCmd.Flags().StringVar(&notSeenWithin, "not-seen-within", "", "Only list machines not seen within this long, e.g. 24h or 7d, or since an RFC3339 time")
+	machineListCmd.Flags().StringVar(&sortBy, "sort", string(inventory.SortByMachineID), "Sort by one of: [machine_id|serial_num|primary_user|model_identifier|os_version|santa_version|last_seen]")
+	machineListCmd.Flags().BoolVar(&descending, "desc", false, "Sort in descending order")
+	machineListCmd.Flags().IntVar(&limit, "limit", 0, "List at most this many machines; 0 lists every match")
+	machineListCmd.Flags().StringVar(&after, "after", "", "Resume a listing sorted by machine ID after this machine ID")
+	machineListCmd.Flags().StringVarP(&format, "format", "t", "table", "Output format (one of: [table|json|csv])")
+	machineListCmd.Flags().StringVarP(&filename, "filename", "f", "", "Write the output to this file instead of stdout")
+
+	MachineCmd.AddCommand(machineListCmd)
+}
+
+func printInventory(out io.Writer, page inventory.Page) {
+	if len(page.Machines) == 0 {
+		fmt.Fprintf(out, "No machines found (%d scanned).\n", page.Scanned)
+		return
+	}
+
+	writer := tabwriter.NewWriter(out, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(writer, "MACHINE ID\tSERIAL\tUSER\tMODEL\tOS\tSANTA\tREPORTED\tINTENDED\tRULES\tLAST SEEN")
+	for _, machine := range page.Machines {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s (%s)\t%s\t%s\t%s\t%d\t%s\n",
+			machine.MachineID,
+			machine.SerialNum,
+			machine.PrimaryUser,
+			machine.ModelIdentifier,
+			machine.OSVersion,
+			machine.OSBuild,
+			machine.SantaVersion,
+			machine.ReportedMode,
+			machine.IntendedMode,
+			machine.RuleCount,
+			machine.LastSeen,
+		)
+	}
+	writer.Flush()
+
+	fmt.Fprintln(out, "")
+	fmt.Fprintf(out, "%d machines listed, %d scanned.\n", len(page.Machines), page.Scanned)
+	if page.Next != "" {
+		fmt.Fprintf(out, "More machines match; resume with --after %s\n", page.Next)
+	}
+}
+
+func writeInventoryCsv(out io.Writer, machines []inventory.Machine) error {
+	writer := csv.NewWriter(out)
+	err := writer.Write([]string{
+		"machine_id",
+		"serial_num",
+		"primary_user",
+		"model_identifier",
+		"os_version",
+		"os_build",
+		"santa_version",
+		"reported_mode",
+		"intended_mode",
+		"rule_count",
+		"last_seen",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, machine := range machines {
+		err = writer.Write([]string{
+			machine.MachineID,
+			machine.SerialNum,
+			machine.PrimaryUser,
+			machine.ModelIdentifier,
+			machine.OSVersion,
+			machine.OSBuild,
+			machine.SantaVersion,
+			machine.ReportedMode,
+			machine.IntendedMode,
+			strconv.Itoa(machine.RuleCount),
+			machine.LastSeen,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
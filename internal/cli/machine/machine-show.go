@@ -0,0 +1,170 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/airbnb/rudolph/internal/cli/remote"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/machineview"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		mf         machineFlags
+		staleAfter time.Duration
+		eventLimit int
+		eventDays  int
+		jsonOutput bool
+	)
+
+	var machineShowCmd = remote.Supported(&cobra.Command{
+		Use:   "show [--machine <machine-id>|--serial <serial>|--user <primary-user>] [--json]",
+		Short: "Show everything Rudolph knows about a machine",
+		Long: `Show everything Rudolph knows about a machine: what its sensor reported in its last preflight, how far its
+last sync got and how long each stage took, the configuration intended for it and where that comes from, its
+machine rules, and its most recent events if uploaded events are stored.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reader, err := getMachineReader(cmd)
+			if err != nil {
+				return err
+			}
+			timeProvider := clock.ConcreteTimeProvider{}
+
+			machineID, err := mf.resolve(reader)
+			if err != nil {
+				return err
+			}
+
+			machine, err := reader.GetMachine(machineID, machineview.Options{
+				StaleAfter:  staleAfter,
+				EventLimit:  eventLimit,
+				EventsSince: timeProvider.Now().UTC().AddDate(0, 0, -eventDays),
+			})
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(machine)
+			}
+			printMachine(machine)
+			return nil
+		},
+	})
+
+	mf.addMachineFlags(machineShowCmd)
+	machineShowCmd.Flags().DurationVar(&staleAfter, "stale-after", machineview.DefaultStaleAfter, "Warn if the machine has not synced for this long")
+	machineShowCmd.Flags().IntVar(&eventLimit, "events", 10, "Number of recent events to show; 0 to skip events")
+	machineShowCmd.Flags().IntVar(&eventDays, "event-days", 7, "Only show events from the last number of days")
+	machineShowCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	MachineCmd.AddCommand(machineShowCmd)
+}
+
+func printMachine(machine machineview.Machine) {
+	fmt.Println("Machine", machine.MachineID)
+	fmt.Println()
+
+	for _, warning := range machine.Warnings {
+		fmt.Println("WARNING:", warning)
+	}
+	if len(machine.Warnings) > 0 {
+		fmt.Println()
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(writer, "Sensor")
+	if sensor := machine.Sensor; sensor != nil {
+		fmt.Fprintf(writer, "  Serial number:\t%s\n", sensor.SerialNum)
+		fmt.Fprintf(writer, "  Primary user:\t%s\n", sensor.PrimaryUser)
+		fmt.Fprintf(writer, "  OS:\t%s (%s)\n", sensor.OSVersion, sensor.OSBuild)
+		fmt.Fprintf(writer, "  Santa:\t%s\n", sensor.SantaVersion)
+		fmt.Fprintf(writer, "  Reported mode:\t%s\n", sensor.ClientMode)
+		fmt.Fprintf(writer, "  Reported rules:\t%d\n", sensor.RuleCount)
+		fmt.Fprintf(writer, "  Last seen:\t%s\n", sensor.LastSeen)
+	} else {
+		fmt.Fprintln(writer, "  (none)")
+	}
+
+	fmt.Fprintln(writer, "\t")
+	fmt.Fprintln(writer, "Last sync")
+	if sync := machine.Sync; sync != nil {
+		fmt.Fprintf(writer, "  Status:\t%s\n", sync.Status)
+		fmt.Fprintf(writer, "  Clean sync:\t%t\n", sync.CleanSync)
+		if sync.LastCleanSync != "" {
+			fmt.Fprintf(writer, "  Last clean sync:\t%s\n", sync.LastCleanSync)
+		}
+		for _, stage := range sync.Stages {
+			took := ""
+			if stage.Duration > 0 {
+				took = fmt.Sprintf("(+%s)", stage.Duration)
+			}
+			fmt.Fprintf(writer, "  %s:\t%s %s\n", stage.Stage, stage.At.Format(time.RFC3339), took)
+		}
+	} else {
+		fmt.Fprintln(writer, "  (none)")
+	}
+
+	config := machine.Config
+	fmt.Fprintln(writer, "\t")
+	fmt.Fprintf(writer, "Intended config (from %s)\n", config.Source)
+	fmt.Fprintf(writer, "  Client mode:\t%s\n", config.ClientMode)
+	fmt.Fprintf(writer, "  Batch size:\t%d\n", config.Configuration.BatchSize)
+	fmt.Fprintf(writer, "  Full sync interval:\t%d\n", config.Configuration.FullSyncInterval)
+	fmt.Fprintf(writer, "  Blocked path regex:\t%q\n", config.Configuration.BlockedPathRegex)
+	fmt.Fprintf(writer, "  Allowed path regex:\t%q\n", config.Configuration.AllowedPathRegex)
+	fmt.Fprintf(writer, "  Bundles:\t%t\n", config.Configuration.EnableBundles)
+	fmt.Fprintf(writer, "  Transitive rules:\t%t\n", config.Configuration.EnabledTransitiveRules)
+	fmt.Fprintf(writer, "  Clean sync:\t%t\n", config.Configuration.CleanSync)
+	writer.Flush()
+
+	fmt.Println()
+	fmt.Printf("Machine rules (%d)\n", len(machine.MachineRules))
+	if len(machine.MachineRules) > 0 {
+		writer = tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+		fmt.Fprintln(writer, "  TYPE\tPOLICY\tIDENTIFIER\tEXPIRES\tSTATE\tDESCRIPTION")
+		for _, rule := range machine.MachineRules {
+			var state []string
+			if rule.Expired {
+				state = append(state, "expired")
+			}
+			if rule.DeleteOnNextSync {
+				state = append(state, "pending deletion")
+			}
+			expires := "-"
+			if !rule.ExpiresAt.IsZero() {
+				expires = rule.ExpiresAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(writer, "  %s\t%s\t%s\t%s\t%s\t%s\n", rule.RuleType, rule.Policy, rule.Identifier, expires, strings.Join(state, ", "), rule.Description)
+		}
+		writer.Flush()
+	}
+
+	fmt.Println()
+	fmt.Printf("Recent events (%d)\n", len(machine.Events))
+	if len(machine.Events) > 0 {
+		writer = tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+		fmt.Fprintln(writer, "  EXECUTED AT\tDECISION\tUSER\tSHA256\tPATH")
+		for _, event := range machine.Events {
+			fmt.Fprintf(
+				writer,
+				"  %s\t%s\t%s\t%s\t%s\n",
+				event.ExecutedAt,
+				event.Decision,
+				event.ExecutingUser,
+				event.FileSHA256,
+				strings.TrimSuffix(event.FilePath, "/")+"/"+event.FileName,
+			)
+		}
+		writer.Flush()
+	}
+}
@@ -0,0 +1,117 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/synchealth"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	var (
+		thresholds = synchealth.DefaultThresholds()
+		problem    string
+		jsonOutput bool
+	)
+
+	var machineSyncReportCmd = &cobra.Command{
+		Use:   "sync-report [--stale-days 7] [--repeated-clean-syncs 3] [--problem stale|incomplete|repeated_clean_syncs] [--json]",
+		Short: "Report the machines whose syncs have stopped working",
+		Long: `Report the machines whose syncs have silently stopped working:
+
+  stale                 the machine has not started a sync for --stale-days
+  incomplete            the machine's last sync stalled after preflight, during ruledownload or before postflight
+  repeated_clean_syncs  the machine's last --repeated-clean-syncs syncs were all clean syncs
+
+The same report can be run on a schedule; see sync_health_enabled in the deployment variables.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+
+			switch synchealth.Problem(problem) {
+			case "", synchealth.ProblemStale, synchealth.ProblemIncomplete, synchealth.ProblemRepeatedCleanSyncs:
+			default:
+				return fmt.Errorf("unknown problem %q; must be one of: stale, incomplete, repeated_clean_syncs", problem)
+			}
+
+			report, err := synchealth.Check(synchealth.GetStore(dynamodbClient), clock.ConcreteTimeProvider{}, thresholds)
+			if err != nil {
+				return err
+			}
+
+			if problem != "" {
+				findings := []synchealth.Finding{}
+				for _, finding := range report.Findings {
+					if finding.Has(synchealth.Problem(problem)) {
+						findings = append(findings, finding)
+					}
+				}
+				report.Findings = findings
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			}
+			printSyncReport(report)
+			return nil
+		},
+	}
+
+	machineSyncReportCmd.Flags().IntVar(&thresholds.StaleDays, "stale-days", thresholds.StaleDays, "Report machines that have not synced for this many days")
+	machineSyncReportCmd.Flags().IntVar(&thresholds.RepeatedCleanSyncs, "repeated-clean-syncs", thresholds.RepeatedCleanSyncs, "Report machines that clean synced this many times in a row")
+	machineSyncReportCmd.Flags().StringVar(&problem, "problem", "", "Only list machines with this problem (one of: [stale|incomplete|repeated_clean_syncs])")
+	machineSyncReportCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	MachineCmd.AddCommand(machineSyncReportCmd)
+}
+
+func printSyncReport(report synchealth.Report) {
+	fmt.Printf("Checked %d machines\n", report.MachinesChecked)
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(writer, "  Stale (no sync for %d days):\t%d\n", report.Thresholds.StaleDays, report.Counts[synchealth.ProblemStale])
+	fmt.Fprintf(writer, "  Incomplete (last sync stalled):\t%d\n", report.Counts[synchealth.ProblemIncomplete])
+	fmt.Fprintf(writer, "  Repeated clean syncs (%d or more in a row):\t%d\n", report.Thresholds.RepeatedCleanSyncs, report.Counts[synchealth.ProblemRepeatedCleanSyncs])
+	writer.Flush()
+	fmt.Println()
+
+	if len(report.Findings) == 0 {
+		fmt.Println("No machines with sync problems found.")
+		return
+	}
+
+	writer = tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(writer, "MACHINE ID\tSERIAL\tUSER\tPROBLEMS\tLAST SYNC\tSTATUS\tCLEAN SYNCS IN A ROW")
+	for _, finding := range report.Findings {
+		problems := make([]string, len(finding.Problems))
+		for i, problem := range finding.Problems {
+			problems[i] = string(problem)
+		}
+		lastSync := finding.LastSyncAt
+		if lastSync == "" {
+			lastSync = "never"
+		}
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			finding.MachineID,
+			finding.SerialNum,
+			finding.PrimaryUser,
+			strings.Join(problems, ","),
+			lastSync,
+			finding.SyncStatus,
+			finding.ConsecutiveCleanSyncs,
+		)
+	}
+	writer.Flush()
+}
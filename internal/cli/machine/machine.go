@@ -0,0 +1,118 @@
+package machine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/airbnb/rudolph/internal/cli/remote"
+	"github.com/airbnb/rudolph/internal/cli/santa_sensor"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/inventory"
+	"github.com/airbnb/rudolph/pkg/machineview"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	MachineCmd = &cobra.Command{
+		Use:     "machine",
+		Aliases: []string{"machines"},
+		Short:   "Inspect the machines that sync with Rudolph",
+	}
+)
+
+// machineReader reads machines either from DynamoDB or through the admin API
+type machineReader interface {
+	sensordata.SensorDataFinder
+	GetMachine(machineID string, options machineview.Options) (machineview.Machine, error)
+	ListMachines(options inventory.Options) (inventory.Page, error)
+}
+
+type dynamodbMachineReader struct {
+	sensordata.SensorDataFinder
+	timeProvider clock.TimeProvider
+	client       dynamodb.DynamoDBClient
+}
+
+func (r dynamodbMachineReader) GetMachine(machineID string, options machineview.Options) (machineview.Machine, error) {
+	return machineview.Load(r.client, r.timeProvider, machineID, options)
+}
+
+func (r dynamodbMachineReader) ListMachines(options inventory.Options) (inventory.Page, error) {
+	return inventory.List(inventory.GetStore(r.client, r.timeProvider), options)
+}
+
+// getMachineReader returns the admin API client when the CLI was pointed at it, and reads DynamoDB otherwise
+func getMachineReader(cmd *cobra.Command) (machineReader, error) {
+	if remote.Enabled(cmd) {
+		return remote.Client(cmd, rbac.View)
+	}
+
+	region, _ := cmd.Flags().GetString("region")
+	table, _ := cmd.Flags().GetString("dynamodb_table")
+
+	dynamodbClient := dynamodb.GetClient(table, region)
+	return dynamodbMachineReader{
+		SensorDataFinder: sensordata.GetSensorDataFinder(dynamodbClient),
+		timeProvider:     clock.ConcreteTimeProvider{},
+		client:           dynamodbClient,
+	}, nil
+}
+
+// machineFlags address a machine by machine ID, serial number or primary user
+type machineFlags struct {
+	machineID   string
+	serial      string
+	primaryUser string
+}
+
+func (f *machineFlags) addMachineFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.machineID, "machine", "m", "", "The machine ID. Omit all of [--machine|--serial|--user] to use the current machine.")
+	cmd.Flags().StringVar(&f.serial, "serial", "", "Find the machine by serial number")
+	cmd.Flags().StringVar(&f.primaryUser, "user", "", "Find the machine by primary user")
+}
+
+// resolve returns the ID of the addressed machine. A serial number or primary user must match exactly one machine that
+// has checked in.
+func (f machineFlags) resolve(finder sensordata.SensorDataFinder) (string, error) {
+	set := 0
+	for _, value := range []string{f.machineID, f.serial, f.primaryUser} {
+		if value != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return "", errors.New("provide at most one of [--machine|--serial|--user]")
+	}
+
+	var what string
+	var machineIDs []string
+	var err error
+	switch {
+	case f.machineID != "":
+		return f.machineID, types.ValidateMachineID(f.machineID)
+	case f.serial != "":
+		what = fmt.Sprintf("serial number %q", f.serial)
+		machineIDs, err = finder.GetMachineIDsFromSerialNumber(f.serial, 10)
+	case f.primaryUser != "":
+		what = fmt.Sprintf("primary user %q", f.primaryUser)
+		machineIDs, err = finder.GetMachineIDsFromPrimaryUser(f.primaryUser, 10)
+	default:
+		return santa_sensor.GetSelfMachineID()
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up %s: %w", what, err)
+	}
+
+	switch len(machineIDs) {
+	case 0:
+		return "", fmt.Errorf("no machine with %s has checked in", what)
+	case 1:
+		return machineIDs[0], nil
+	}
+	return "", fmt.Errorf("%s matches several machines; pick one with --machine: %s", what, strings.Join(machineIDs, ", "))
+}
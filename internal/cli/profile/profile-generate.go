@@ -0,0 +1,163 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/airbnb/rudolph/pkg/mobileconfig"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+func init() {
+	var (
+		outputDir           string
+		identifierPrefix    string
+		clientMode          string
+		terraformOutputs    string
+		machineMappingPlist string
+		machineIDKey        string
+		machineOwnerKey     string
+		syncHeaders         []string
+		eventDetailURL      string
+		eventDetailText     string
+	)
+
+	var profileGenerateCmd = &cobra.Command{
+		Use:   "generate [--output-dir <dir>] [--client-mode MONITOR|LOCKDOWN] [--sync-header <name>=<value>]",
+		Short: "Generate the Santa, system extension and TCC configuration profiles",
+		Long: `Generate the Santa, system extension and TCC configuration profiles for this deployment.
+
+SyncBaseURL is taken from --sync_base_url, else from the sync_base_url output of "terraform output -json" when
+--terraform-outputs is given, else from the prefix and route53_zone_name of the environment configuration.
+
+By default, Santa reads the machine ID and owner from the machine-mapping plist (see
+configs/com.google.santa.machine-mapping.plist), which has to be installed on every machine; pass
+--machine-mapping-plist "" to use the hardware UUID instead.
+
+The profiles are unsigned. Their PayloadUUIDs are derived from their contents, so generating them again with the same
+options produces identical files.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			syncBaseURL, _ := cmd.Flags().GetString("sync_base_url")
+			organization, _ := cmd.Flags().GetString("org")
+
+			if !cmd.Flags().Changed("sync_base_url") && terraformOutputs != "" {
+				url, err := readSyncBaseURL(terraformOutputs)
+				if err != nil {
+					return err
+				}
+				syncBaseURL = url
+			}
+
+			var mode types.ClientMode
+			if err := mode.UnmarshalText([]byte(strings.ToUpper(clientMode))); err != nil {
+				return err
+			}
+
+			headers, err := parseSyncHeaders(syncHeaders)
+			if err != nil {
+				return err
+			}
+
+			configuration := mobileconfig.SantaConfiguration{
+				SyncBaseURL:         syncBaseURL,
+				ClientMode:          mode,
+				MachineMappingPlist: machineMappingPlist,
+				MachineIDKey:        machineIDKey,
+				MachineOwnerKey:     machineOwnerKey,
+				SyncExtraHeaders:    headers,
+				EventDetailURL:      eventDetailURL,
+				EventDetailText:     eventDetailText,
+			}
+
+			return runGenerate(outputDir, identifierPrefix, organization, configuration)
+		},
+	}
+
+	profileGenerateCmd.Flags().StringVarP(&outputDir, "output-dir", "o", ".", "Directory in which the profiles are written")
+	profileGenerateCmd.Flags().StringVar(&identifierPrefix, "identifier-prefix", "com.google.santa", "Prefix of the PayloadIdentifiers of the profiles")
+	profileGenerateCmd.Flags().StringVar(&clientMode, "client-mode", "MONITOR", "Initial client mode (one of: [MONITOR|LOCKDOWN]); Rudolph sets it on every sync afterwards")
+	profileGenerateCmd.Flags().StringVar(&terraformOutputs, "terraform-outputs", "", `File holding the output of "terraform output -json"`)
+	profileGenerateCmd.Flags().StringVar(&machineMappingPlist, "machine-mapping-plist", mobileconfig.DefaultMachineMappingPlist, "Plist mapping the machine to its machine ID and owner")
+	profileGenerateCmd.Flags().StringVar(&machineIDKey, "machine-id-key", mobileconfig.DefaultMachineIDKey, "Key of the machine ID in the machine-mapping plist")
+	profileGenerateCmd.Flags().StringVar(&machineOwnerKey, "machine-owner-key", mobileconfig.DefaultMachineOwnerKey, "Key of the machine owner in the machine-mapping plist")
+	profileGenerateCmd.Flags().StringArrayVar(&syncHeaders, "sync-header", nil, `Header sent with every sync request, as "<name>=<value>"; can be repeated`)
+	profileGenerateCmd.Flags().StringVar(&eventDetailURL, "event-detail-url", "", "URL opened from block notifications, e.g. https://example.com/event/%machine_id%/%file_sha%")
+	profileGenerateCmd.Flags().StringVar(&eventDetailText, "event-detail-text", "", "Text of the button that opens the event detail URL")
+
+	ProfileCmd.AddCommand(profileGenerateCmd)
+}
+
+func runGenerate(outputDir string, identifierPrefix string, organization string, configuration mobileconfig.SantaConfiguration) error {
+	santaProfile, err := mobileconfig.SantaConfigurationProfile(identifierPrefix, organization, configuration)
+	if err != nil {
+		return fmt.Errorf("invalid Santa configuration: %w", err)
+	}
+
+	profiles := []struct {
+		filename string
+		profile  mobileconfig.Profile
+	}{
+		{"santa-configuration.mobileconfig", santaProfile},
+		{"santa-sysext.mobileconfig", mobileconfig.SystemExtensionProfile(identifierPrefix+".sysext", organization)},
+		{"santa-tcc.mobileconfig", mobileconfig.TCCProfile(identifierPrefix+".tcc", organization)},
+	}
+
+	if err = os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	for _, p := range profiles {
+		contents, err := p.profile.Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to generate %s: %w", p.filename, err)
+		}
+		filename := filepath.Join(outputDir, p.filename)
+		if err = os.WriteFile(filename, contents, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %s\n", filename)
+	}
+	return nil
+}
+
+// readSyncBaseURL reads the sync_base_url output of deployments/environments/*/_outputs.tf
+func readSyncBaseURL(filename string) (string, error) {
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to read terraform outputs: %w", err)
+	}
+
+	var outputs map[string]struct {
+		Value interface{} `json:"value"`
+	}
+	if err = json.Unmarshal(contents, &outputs); err != nil {
+		return "", fmt.Errorf("failed to parse terraform outputs %q: %w", filename, err)
+	}
+
+	syncBaseURL, ok := outputs["sync_base_url"].Value.(string)
+	if !ok || syncBaseURL == "" {
+		return "", errors.New("the terraform outputs have no sync_base_url")
+	}
+	return syncBaseURL, nil
+}
+
+func parseSyncHeaders(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	headers := make(map[string]string, len(values))
+	for _, value := range values {
+		name, headerValue, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("sync header %q must be formatted as <name>=<value>", value)
+		}
+		headers[strings.TrimSpace(name)] = headerValue
+	}
+	return headers, nil
+}
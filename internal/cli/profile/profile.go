@@ -0,0 +1,12 @@
+package profile
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	ProfileCmd = &cobra.Command{
+		Use:   "profile",
+		Short: "Generate the configuration profiles that set up Santa sensors for this deployment",
+	}
+)
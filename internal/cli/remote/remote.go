@@ -0,0 +1,76 @@
+// Package remote lets CLI commands call the admin API instead of reading and writing the DynamoDB table, for
+// operators that have a token from the identity provider but no AWS credentials for the deployment.
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// URLFlag is the persistent flag that switches the CLI to the admin API
+	URLFlag = "admin_api_url"
+	// URLEnv sets the admin API URL when the flag is not given
+	URLEnv = "RUDOLPH_ADMIN_API_URL"
+	// TokenEnv holds the identity provider token sent to the admin API
+	TokenEnv = "RUDOLPH_ADMIN_TOKEN"
+
+	supportedAnnotation = "rudolph.remote"
+)
+
+// Supported marks a command as able to run against the admin API
+func Supported(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[supportedAnnotation] = "true"
+	return cmd
+}
+
+// Enabled reports whether the CLI was pointed at the admin API
+func Enabled(cmd *cobra.Command) bool {
+	url, _ := cmd.Flags().GetString(URLFlag)
+	return url != ""
+}
+
+// Check fails commands that cannot run against the admin API when the CLI was pointed at it, rather than letting them
+// fall back to DynamoDB
+func Check(cmd *cobra.Command) error {
+	if !Enabled(cmd) || cmd.Annotations[supportedAnnotation] == "true" {
+		return nil
+	}
+	return fmt.Errorf("%q does not support --%s; it needs direct access to DynamoDB", cmd.CommandPath(), URLFlag)
+}
+
+// Client returns a client for the admin API that the CLI was pointed at, once it has checked that the caller's roles
+// allow the permission. The admin API checks every request too; checking here fails before asking for confirmation.
+func Client(cmd *cobra.Command, permission rbac.Permission) (*adminapi.Client, error) {
+	url, _ := cmd.Flags().GetString(URLFlag)
+	if url == "" {
+		return nil, errors.New("no admin API URL was given")
+	}
+	token := os.Getenv(TokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("set %s to a token from your identity provider to use the admin API", TokenEnv)
+	}
+	client, err := adminapi.NewClient(url, token)
+	if err != nil {
+		return nil, err
+	}
+
+	caller, err := client.WhoAmI()
+	if err != nil {
+		return nil, fmt.Errorf("could not authenticate with the admin API: %w", err)
+	}
+	roles := rbac.ParseRoles(strings.Join(caller.Roles, ","))
+	if !roles.Can(permission) {
+		return nil, fmt.Errorf("%s is not allowed to do this: it requires one of the roles %s, but has the roles %q", caller.Principal, rbac.RolesWith(permission), roles.String())
+	}
+	return client, nil
+}
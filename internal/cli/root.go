@@ -3,9 +3,17 @@ package cli
 import (
 	"os"
 
+	"github.com/airbnb/rudolph/internal/cli/bulk"
+	"github.com/airbnb/rudolph/internal/cli/catalog"
 	"github.com/airbnb/rudolph/internal/cli/config"
+	"github.com/airbnb/rudolph/internal/cli/events"
+	"github.com/airbnb/rudolph/internal/cli/group"
 	"github.com/airbnb/rudolph/internal/cli/info"
+	"github.com/airbnb/rudolph/internal/cli/lockdown"
 	"github.com/airbnb/rudolph/internal/cli/lookup"
+	"github.com/airbnb/rudolph/internal/cli/machine"
+	"github.com/airbnb/rudolph/internal/cli/profile"
+	"github.com/airbnb/rudolph/internal/cli/remote"
 	"github.com/airbnb/rudolph/internal/cli/repair"
 	"github.com/airbnb/rudolph/internal/cli/rule"
 	"github.com/airbnb/rudolph/internal/cli/rules"
@@ -32,9 +40,65 @@ export ENV={YOUR_ENVIRONMENT}
      ./rudolph rules [--global]
        Queries DDB and returns all rules pertinent to either your machine or available globally.
 
+	 ./rudolph rules explain (--sha <sha256>|--file <path>) [--machine <machine-id>]
+		Explains whether a binary would run on a machine, which rule decides it and which rules it shadows.
+
+	 ./rudolph rules lint [--min-severity warning] [--fail-on error] [--json]
+		Reports conflicting, redundant and shadowed rules across the global rules and all machine rules.
+
+	 ./rudolph rules effective [--machine <machine-id>] [--format table|json|csv]
+		Computes the rules a machine should hold after a clean sync, and compares them with its sensor's rule counts.
+
+	 ./rudolph rules plan -f rules.yaml [--out plan.json]
+		Shows the changes that applying a declarative rules file would make to the global rules.
+
+	 ./rudolph rules apply (-f rules.yaml|--plan plan.json) [--auto-approve]
+		Applies a declarative rules file, or a saved plan, to the global rules; removed rules are sent to sensors as REMOVE.
+
+	 ./rudolph rules export -t mobileconfig -f staticrules.mobileconfig [--rule-type TEAMID] [--managed-by <source>]
+		Exports the global rules as a configuration profile that sets Santa's StaticRules.
+
 	 ./rudolph config [--global]
 		Creates, modifies, or retrieves the current configuration for either a machine or globally.
 
+	 ./rudolph machine show [--machine <machine-id>|--serial <serial>|--user <primary-user>] [--json]
+		Shows a machine's sensor data, last sync with stage timings, intended config and its source, machine rules and recent events.
+
+	 ./rudolph machines list [--os-version 14] [--santa-version 2023.10] [--mode-mismatch] [--seen-within 7d] [--sort last_seen] [--format table|json|csv]
+		Lists the machines that have checked in, filtered by OS, Santa version, model, user, client mode or last seen time.
+
+	 ./rudolph machines compliance [--min-santa-version 2023.10] [--rule-count-tolerance 5] [--all] [--format table|json|csv]
+		Reports machines in MONITOR that should be in LOCKDOWN, with rule counts off from their ruleset, unwanted transitive rules or an old Santa.
+
+	 ./rudolph machines sync-report [--stale-days 7] [--repeated-clean-syncs 3] [--json]
+		Reports machines that stopped syncing, whose last sync stalled before postflight, or that keep clean syncing.
+
+	 ./rudolph bulk rule -f serials.csv -i <sha256> -t binary -p allowlist [--days 7] [--results results.csv] [--dry-run]
+		Creates the same machine rule on every machine listed by machine ID, serial number or primary user in a csv or json file.
+
+	 ./rudolph bulk config -f serials.csv -c lockdown [--results results.csv] [--dry-run]
+		Sets the same configuration on every machine listed in a csv or json file.
+
+	 ./rudolph events list (--machine <machine-id>|--sha <sha256>) [--since 24h]
+		Lists recent events uploaded to the event store, for a single machine or for a single binary.
+
+	 ./rudolph catalog top [--type binary] [--by machines]
+		Lists the most widespread binaries, signing IDs, team IDs or certificates seen in uploaded events.
+
+	 ./rudolph lockdown readiness (--machine <machine-id>|--group <group-name>) [--days 14]
+		Predicts what would break if machines were moved to LOCKDOWN, and suggests the rules to prevent it.
+
+	 ./rudolph lockdown promote [--dry-run]
+		Promotes machines that have been clean in MONITOR to LOCKDOWN, and demotes recent promotions that are being blocked.
+
+	 ./rudolph profile generate [--output-dir profiles] [--client-mode LOCKDOWN] [--sync-header "X-Api-Key=..."]
+		Generates the Santa, system extension and TCC configuration profiles for this deployment.
+
+	 ./rudolph --admin_api_url https://rudolph.example.com/prod [COMMAND]
+		Calls the admin API instead of DynamoDB, authenticating with the identity provider token in $RUDOLPH_ADMIN_TOKEN.
+		The URL can also be set with $RUDOLPH_ADMIN_API_URL. Supported by rule allow/deny/silent/compiler/transitive/remove,
+		config get/set/update, lookup, machine show and machines list.
+
 */
 
 func init() {
@@ -42,6 +106,9 @@ func init() {
 	RootCmd.PersistentFlags().StringVar(&region, "region", "", ".")
 	RootCmd.PersistentFlags().StringVar(&prefix, "prefix", "", ".")
 	RootCmd.PersistentFlags().StringVar(&dynamodbTableName, "dynamodb_table", "", ".")
+	RootCmd.PersistentFlags().StringVar(&syncBaseURL, "sync_base_url", "", "URL of the Rudolph API that Santa syncs with")
+	RootCmd.PersistentFlags().StringVar(&org, "org", "", "Organization name")
+	RootCmd.PersistentFlags().StringVar(&adminAPIURL, remote.URLFlag, "", "URL of the admin API to call instead of DynamoDB, e.g. https://rudolph.example.com/prod; the token is read from "+remote.TokenEnv)
 
 	// Add subcommands
 	RootCmd.AddCommand(info.InfoCmd)
@@ -50,6 +117,13 @@ func init() {
 	RootCmd.AddCommand(config.ConfigCmd)
 	RootCmd.AddCommand(repair.RepairCmd)
 	RootCmd.AddCommand(lookup.LookupCmd)
+	RootCmd.AddCommand(events.EventsCmd)
+	RootCmd.AddCommand(catalog.CatalogCmd)
+	RootCmd.AddCommand(group.GroupCmd)
+	RootCmd.AddCommand(lockdown.LockdownCmd)
+	RootCmd.AddCommand(profile.ProfileCmd)
+	RootCmd.AddCommand(bulk.BulkCmd)
+	RootCmd.AddCommand(machine.MachineCmd)
 }
 
 var (
@@ -57,6 +131,9 @@ var (
 	region            string
 	prefix            string
 	dynamodbTableName string
+	syncBaseURL       string
+	org               string
+	adminAPIURL       string
 )
 
 // RootCmd is the entry point command for the CLI, exported for use elsewhere
@@ -66,6 +143,13 @@ var RootCmd = &cobra.Command{
 	SilenceUsage: true,
 	Long:         "cli for interacting with Santa server",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed(remote.URLFlag) && os.Getenv(remote.URLEnv) != "" {
+			cmd.Flags().Set(remote.URLFlag, os.Getenv(remote.URLEnv))
+		}
+		if remote.Enabled(cmd) {
+			// Commands that call the admin API do not need the deployment's configuration or AWS credentials
+			return remote.Check(cmd)
+		}
 
 		_, err := retrieveConfig(cmd)
 		if err != nil {
@@ -2,8 +2,8 @@ package rule
 
 import (
 	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/internal/cli/remote"
 	"github.com/airbnb/rudolph/pkg/clock"
-	"github.com/airbnb/rudolph/pkg/dynamodb"
 	"github.com/airbnb/rudolph/pkg/types"
 	"github.com/spf13/cobra"
 )
@@ -12,21 +12,20 @@ func init() {
 	tf := flags.TargetFlags{}
 	rf := flags.RuleInfoFlags{}
 
-	var ruleAllowCmd = &cobra.Command{
+	var ruleAllowCmd = remote.Supported(&cobra.Command{
 		Use:   "allow [-f <file-path>|-i <identifier/sha256>] -t <rule-type> [-m <machine-id>|--global]",
 		Short: "Create a rule that applies the Allowlist policy to the specified file",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 
-			region, _ := cmd.Flags().GetString("region")
-			table, _ := cmd.Flags().GetString("dynamodb_table")
+			creator, err := getRuleCreator(cmd, tf, types.Allowlist)
+			if err != nil {
+				return err
+			}
 
-			dynamodbClient := dynamodb.GetClient(table, region)
-			time := clock.ConcreteTimeProvider{}
-
-			return applyPolicyForPath(time, dynamodbClient, types.Allowlist, tf, rf)
+			return applyPolicyForPath(clock.ConcreteTimeProvider{}, creator, types.Allowlist, tf, rf)
 		},
-	}
+	})
 
 	tf.AddTargetFlags(ruleAllowCmd)
 	rf.AddRuleInfoFlags(ruleAllowCmd)
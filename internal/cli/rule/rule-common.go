@@ -9,11 +9,13 @@ import (
 	"time"
 
 	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/internal/cli/remote"
 	"github.com/airbnb/rudolph/internal/cli/santa_sensor"
 	"github.com/airbnb/rudolph/pkg/clock"
 	"github.com/airbnb/rudolph/pkg/dynamodb"
 	"github.com/airbnb/rudolph/pkg/model/globalrules"
 	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/rbac"
 	"github.com/airbnb/rudolph/pkg/types"
 	"github.com/spf13/cobra"
 )
@@ -28,7 +30,52 @@ var (
 	}
 )
 
-func applyPolicyForPath(timeProvider clock.TimeProvider, client dynamodb.DynamoDBClient, policy types.Policy, tf flags.TargetFlags, rf flags.RuleInfoFlags) (err error) {
+// ruleCreator creates rules either in DynamoDB or through the admin API
+type ruleCreator interface {
+	AddGlobalRule(identifier string, ruleType types.RuleType, policy types.Policy, description string) error
+	AddMachineRule(machineID string, identifier string, ruleType types.RuleType, policy types.Policy, description string, expires time.Time) error
+}
+
+type dynamodbRuleCreator struct {
+	timeProvider clock.TimeProvider
+	client       dynamodb.DynamoDBClient
+}
+
+func (c dynamodbRuleCreator) AddGlobalRule(identifier string, ruleType types.RuleType, policy types.Policy, description string) error {
+	return globalrules.AddNewGlobalRule(c.timeProvider, c.client, identifier, ruleType, policy, description)
+}
+
+func (c dynamodbRuleCreator) AddMachineRule(machineID string, identifier string, ruleType types.RuleType, policy types.Policy, description string, expires time.Time) error {
+	return machinerules.AddNewMachineRule(c.client, machineID, identifier, ruleType, policy, description, expires)
+}
+
+// getRuleCreator returns the admin API client when the CLI was pointed at it, and DynamoDB otherwise
+// rulePermission is what the admin API caller needs to create a rule: allowlisting on a single machine is an unblock,
+// which responders may do
+func rulePermission(tf flags.TargetFlags, policy types.Policy) rbac.Permission {
+	switch {
+	case tf.IsGlobal:
+		return rbac.ManageGlobalRules
+	case policy == types.RulePolicyAllowlist:
+		return rbac.Unblock
+	default:
+		return rbac.ManageMachineRules
+	}
+}
+
+func getRuleCreator(cmd *cobra.Command, tf flags.TargetFlags, policy types.Policy) (ruleCreator, error) {
+	if remote.Enabled(cmd) {
+		return remote.Client(cmd, rulePermission(tf, policy))
+	}
+	region, _ := cmd.Flags().GetString("region")
+	table, _ := cmd.Flags().GetString("dynamodb_table")
+	return dynamodbRuleCreator{
+		timeProvider: clock.ConcreteTimeProvider{},
+		client:       dynamodb.GetClient(table, region),
+	}, nil
+}
+
+func applyPolicyForPath(timeProvider clock.TimeProvider, creator ruleCreator, policy types.Policy, tf flags.TargetFlags, rf flags.RuleInfoFlags) (err error) {
 	// Second, determine the rule type and identifier
 	ruleType := (*rf.RuleType).AsRuleType()
 	var description string
@@ -62,6 +109,8 @@ func applyPolicyForPath(timeProvider clock.TimeProvider, client dynamodb.DynamoD
 			identifier = fileInfo.TeamID
 		case types.RuleTypeSigningID:
 			identifier = fileInfo.SigningID
+		case types.RuleTypeCDHash:
+			identifier = fileInfo.CDHash
 		default:
 			log.Printf("error (recovered): encountered unknown ruleType: (%+v)", ruleType)
 			return fmt.Errorf("error (recovered): encountered unknown ruleType: (%+v)", ruleType)
@@ -119,15 +168,15 @@ func applyPolicyForPath(timeProvider clock.TimeProvider, client dynamodb.DynamoD
 	if strings.ToLower(text) == "ok" || strings.ToLower(text) == "yes" {
 		// Do rule creation
 		if tf.IsGlobal {
-			err = globalrules.AddNewGlobalRule(timeProvider, client, identifier, ruleType, policy, description)
+			err = creator.AddGlobalRule(identifier, ruleType, policy, description)
 		} else {
 			expires := timeProvider.Now().Add(time.Hour * machinerules.MachineRuleDefaultExpirationHours).UTC()
-			err = machinerules.AddNewMachineRule(client, machineID, identifier, ruleType, policy, description, expires)
+			err = creator.AddMachineRule(machineID, identifier, ruleType, policy, description, expires)
 		}
 		if err != nil {
-			return fmt.Errorf("could not upload rule to DynamoDB: %w", err)
+			return fmt.Errorf("could not upload rule: %w", err)
 		}
-		fmt.Println("Successfully sent a rule to dynamodb")
+		fmt.Println("Successfully sent a rule")
 	} else {
 		fmt.Println("Well ok then")
 	}
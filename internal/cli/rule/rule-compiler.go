@@ -2,8 +2,8 @@ package rule
 
 import (
 	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/internal/cli/remote"
 	"github.com/airbnb/rudolph/pkg/clock"
-	"github.com/airbnb/rudolph/pkg/dynamodb"
 	"github.com/airbnb/rudolph/pkg/types"
 	"github.com/spf13/cobra"
 )
@@ -12,22 +12,21 @@ func init() {
 	tf := flags.TargetFlags{}
 	rf := flags.RuleInfoFlags{}
 
-	var ruleCompilerCmd = &cobra.Command{
+	var ruleCompilerCmd = remote.Supported(&cobra.Command{
 		Use:     "compiler  <file-path>",
 		Aliases: []string{"allow-complier"},
 		Short:   "Create a rule that applies the AllowlistCompiler policy to the specified file",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// args[0] has already been validated as a file before this
-			region, _ := cmd.Flags().GetString("region")
-			table, _ := cmd.Flags().GetString("dynamodb_table")
+			creator, err := getRuleCreator(cmd, tf, types.AllowlistCompiler)
+			if err != nil {
+				return err
+			}
 
-			dynamodbClient := dynamodb.GetClient(table, region)
-			time := clock.ConcreteTimeProvider{}
-
-			return applyPolicyForPath(time, dynamodbClient, types.AllowlistCompiler, tf, rf)
+			return applyPolicyForPath(clock.ConcreteTimeProvider{}, creator, types.AllowlistCompiler, tf, rf)
 		},
-	}
+	})
 
 	tf.AddTargetFlags(ruleCompilerCmd)
 	rf.AddRuleInfoFlags(ruleCompilerCmd)
@@ -7,10 +7,12 @@ import (
 	"strings"
 
 	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/internal/cli/remote"
 	"github.com/airbnb/rudolph/pkg/clock"
 	"github.com/airbnb/rudolph/pkg/dynamodb"
 	"github.com/airbnb/rudolph/pkg/model/globalrules"
 	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/rbac"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 )
@@ -20,13 +22,27 @@ import (
 func init() {
 	tf := flags.TargetFlags{}
 
-	var removeRuleCmd = &cobra.Command{
+	var removeRuleCmd = remote.Supported(&cobra.Command{
 		Use:     `remove <rule-name> ex: 'TeamID#1234567'`,
 		Aliases: []string{"delete"},
 		Short:   "Removes/deletes a rule from the backing store",
 		Long:    `<rule-name> | <RuleType: Binary,Certificate,TeamID,SigningID>#<Rule Identifier/SHA256: abcdef12345-12345-12345> | 'TeamID#1234567'`,
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if remote.Enabled(cmd) {
+				// The admin API checks the policy of the removed rule; removing a machine's allowlist rule takes only an
+				// unblock
+				permission := rbac.Unblock
+				if tf.IsGlobal {
+					permission = rbac.ManageGlobalRules
+				}
+				client, err := remote.Client(cmd, permission)
+				if err != nil {
+					return err
+				}
+				return removeRule(client, client, args[0], tf)
+			}
+
 			region, _ := cmd.Flags().GetString("region")
 			table, _ := cmd.Flags().GetString("dynamodb_table")
 
@@ -44,7 +60,7 @@ func init() {
 
 			return removeRule(globalRemover, machineRuleRemover, args[0], tf)
 		},
-	}
+	})
 
 	tf.AddTargetFlags(removeRuleCmd)
 	RuleCmd.AddCommand(removeRuleCmd)
@@ -91,7 +107,7 @@ func removeRule(globalRemover globalrules.RuleRemovalService, machineRuleRemover
 			return fmt.Errorf("failed to remove global rule: %v", err)
 		}
 
-		fmt.Println("Successfully sent a rule to dynamodb")
+		fmt.Println("Successfully removed the rule")
 	} else {
 		fmt.Println("Well ok then")
 	}
@@ -2,8 +2,8 @@ package rule
 
 import (
 	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/internal/cli/remote"
 	"github.com/airbnb/rudolph/pkg/clock"
-	"github.com/airbnb/rudolph/pkg/dynamodb"
 	"github.com/airbnb/rudolph/pkg/types"
 	"github.com/spf13/cobra"
 )
@@ -12,22 +12,21 @@ func init() {
 	tf := flags.TargetFlags{}
 	rf := flags.RuleInfoFlags{}
 
-	var ruleTransitiveCmd = &cobra.Command{
+	var ruleTransitiveCmd = remote.Supported(&cobra.Command{
 		Use:     "transitive",
 		Aliases: []string{"allow-transitive"},
 		Short:   "Create a rule that applies the AllowlistTransitive policy to the specified file",
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// args[0] has already been validated as a file before this
-			region, _ := cmd.Flags().GetString("region")
-			table, _ := cmd.Flags().GetString("dynamodb_table")
+			creator, err := getRuleCreator(cmd, tf, types.AllowlistTransitive)
+			if err != nil {
+				return err
+			}
 
-			dynamodbClient := dynamodb.GetClient(table, region)
-			time := clock.ConcreteTimeProvider{}
-
-			return applyPolicyForPath(time, dynamodbClient, types.AllowlistTransitive, tf, rf)
+			return applyPolicyForPath(clock.ConcreteTimeProvider{}, creator, types.AllowlistTransitive, tf, rf)
 		},
-	}
+	})
 
 	tf.AddTargetFlags(ruleTransitiveCmd)
 	rf.AddRuleInfoFlags(ruleTransitiveCmd)
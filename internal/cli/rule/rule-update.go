@@ -101,6 +101,8 @@ func (rh *ruleHandler) updateRulePolicy(tf flags.TargetFlags, rf flags.RuleInfoF
 			identifier = fileInfo.TeamID
 		case types.RuleTypeSigningID:
 			identifier = fileInfo.SigningID
+		case types.RuleTypeCDHash:
+			identifier = fileInfo.CDHash
 		default:
 			log.Printf("error (recovered): encountered unknown ruleType: (%+v)", ruleType)
 			return fmt.Errorf("error (recovered): encountered unknown ruleType: (%+v)", ruleType)
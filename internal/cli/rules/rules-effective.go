@@ -0,0 +1,200 @@
+package rules
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+func addRuleEffectiveCommand() {
+	var (
+		format   string
+		filename string
+	)
+	tf := flags.TargetFlags{}
+
+	var ruleEffectiveCmd = &cobra.Command{
+		Use:   "effective [--machine <machine-id>] [--format table|json|csv] [--filename <file>]",
+		Short: "Compute the rules a machine should hold after a clean sync, and compare them with what its sensor reports",
+		Long: `Compute the rules a machine should hold after a clean sync, and compare them with what its sensor reports.
+
+The effective ruleset is every global rule, with the machine's own rules overlaid and the machine's pending removals
+taken out. Its per-type counts are compared with the counts the sensor last reported in preflight.
+
+The table format only shows the comparison; the json and csv formats also list every effective rule.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+
+			machineID, err := tf.GetMachineID()
+			if err != nil {
+				return fmt.Errorf("failed to get MachineID: %w", err)
+			}
+			if err = types.ValidateMachineID(machineID); err != nil {
+				return err
+			}
+
+			globalRules, err := ruleset.LoadGlobalRules(dynamodbClient)
+			if err != nil {
+				return err
+			}
+			rs, err := ruleset.ForMachine(dynamodbClient, globalRules, machineID)
+			if err != nil {
+				return err
+			}
+
+			sensorData, err := sensordata.GetSensorData(dynamodbClient, machineID)
+			if err != nil {
+				return fmt.Errorf("failed to get the sensor data of machine %q: %w", machineID, err)
+			}
+
+			output := effectiveOutput{
+				MachineID: machineID,
+				Expected:  rs.Counts(),
+			}
+			if sensorData != nil {
+				reported, transitive := ruleset.ReportedCounts(*sensorData)
+				output.Reported = &reported
+				output.ReportedTransitive = transitive
+				output.ReportedAt = sensorData.Time
+			}
+
+			var out io.Writer = os.Stdout
+			if filename != "" {
+				f, err := os.Create(filename)
+				if err != nil {
+					return fmt.Errorf("failed to create %q: %w", filename, err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			switch format {
+			case "table":
+				printEffectiveCounts(out, output)
+				return nil
+			case "json":
+				output.Rules = sortedRules(rs)
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(output)
+			case "csv":
+				return writeEffectiveCsv(out, sortedRules(rs))
+			}
+			return errors.New(`--format must be one of: "table", "json", "csv"`)
+		},
+	}
+
+	ruleEffectiveCmd.Flags().StringVarP(&tf.MachineID, "machine", "m", "", "The machine to compute the ruleset of. Omit to use the current machine.")
+	ruleEffectiveCmd.Flags().StringVarP(&format, "format", "t", "table", "Output format (one of: [table|json|csv])")
+	ruleEffectiveCmd.Flags().StringVarP(&filename, "filename", "f", "", "Write the output to this file instead of stdout")
+
+	RulesCmd.AddCommand(ruleEffectiveCmd)
+}
+
+type effectiveOutput struct {
+	MachineID string             `json:"machine_id"`
+	Expected  ruleset.RuleCounts `json:"expected_counts"`
+	// Reported is nil when the machine has never completed a preflight
+	Reported           *ruleset.RuleCounts `json:"reported_counts"`
+	ReportedTransitive int                 `json:"reported_transitive_count"`
+	ReportedAt         string              `json:"reported_at,omitempty"`
+	Rules              []ruleset.Rule      `json:"rules,omitempty"`
+}
+
+// sortedRules orders the effective rules by type, then identifier, so that exports can be diffed
+func sortedRules(rs ruleset.Ruleset) []ruleset.Rule {
+	out := rs.Rules()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].RuleType != out[j].RuleType {
+			return out[i].RuleType < out[j].RuleType
+		}
+		return out[i].Identifier < out[j].Identifier
+	})
+	return out
+}
+
+func printEffectiveCounts(out io.Writer, output effectiveOutput) {
+	fmt.Fprintf(out, "Effective ruleset of %s after a clean sync\n", output.MachineID)
+	fmt.Fprintln(out, "")
+
+	if output.Reported == nil {
+		writer := tabwriter.NewWriter(out, 0, 8, 1, ' ', 0)
+		fmt.Fprintln(writer, "TYPE\tEXPECTED")
+		for _, difference := range output.Expected.Compare(ruleset.RuleCounts{}) {
+			fmt.Fprintf(writer, "%s\t%d\n", difference.Name, difference.Expected)
+		}
+		writer.Flush()
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, "The sensor has not reported any rule counts yet")
+		return
+	}
+
+	writer := tabwriter.NewWriter(out, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(writer, "TYPE\tEXPECTED\tREPORTED\tDELTA")
+	for _, difference := range output.Expected.Compare(*output.Reported) {
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%+d\n", difference.Name, difference.Expected, difference.Reported, difference.Delta())
+	}
+	fmt.Fprintf(writer, "Transitive\t-\t%d\t\n", output.ReportedTransitive)
+	writer.Flush()
+
+	fmt.Fprintln(out, "")
+	fmt.Fprintf(out, "Counts were reported at %s. Rules added since then are only picked up by the next sync.\n", output.ReportedAt)
+	if output.ReportedTransitive > 0 {
+		fmt.Fprintln(out, "The sensor's binary and total counts include the transitive rules it created itself.")
+	}
+}
+
+func writeEffectiveCsv(out io.Writer, effectiveRules []ruleset.Rule) error {
+	writer := csv.NewWriter(out)
+	err := writer.Write([]string{
+		"identifier",
+		"type",
+		"policy",
+		"custom_msg",
+		"source",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range effectiveRules {
+		ruleType, err := rule.RuleType.MarshalText()
+		if err != nil {
+			return err
+		}
+		policy, err := rule.Policy.MarshalText()
+		if err != nil {
+			return err
+		}
+		err = writer.Write([]string{
+			rule.Identifier,
+			string(ruleType),
+			string(policy),
+			rule.CustomMessage,
+			string(rule.Source),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
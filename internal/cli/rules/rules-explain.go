@@ -0,0 +1,228 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/airbnb/rudolph/internal/cli/flags"
+	"github.com/airbnb/rudolph/internal/cli/santa_sensor"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/catalog"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	modelrules "github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+func addRuleExplainCommand() {
+	var (
+		subject    ruleset.Subject
+		filePath   string
+		clientMode flags.ClientMode
+		jsonOutput bool
+	)
+	tf := flags.TargetFlags{}
+
+	var ruleExplainCmd = &cobra.Command{
+		Use:   "explain (--sha <sha256>|--file <path>) [--machine <machine-id>]",
+		Short: "Explain whether a binary would run on a machine, and which rules decide it",
+		Long: `Explain whether a binary would run on a machine, and which rules decide it.
+
+The binary is evaluated against the machine's effective ruleset (global rules, overridden by the machine's own rules)
+using Santa's precedence: CDHash, Binary, Signing ID, Certificate and finally Team ID. When no rule matches, the
+machine's client mode decides.
+
+With --file, the binary's identifiers are read with santactl fileinfo. With --sha, any identifiers that are not given
+as flags are filled in from the binary catalog, if the binary has been seen in uploaded events.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+			timeProvider := clock.ConcreteTimeProvider{}
+
+			if (filePath == "") == (subject.FileSHA256 == "") {
+				return errors.New("exactly one of --sha or --file must be provided")
+			}
+
+			machineID, err := tf.GetMachineID()
+			if err != nil {
+				return fmt.Errorf("failed to get MachineID: %w", err)
+			}
+			if err = types.ValidateMachineID(machineID); err != nil {
+				return err
+			}
+
+			var notes []string
+			if filePath != "" {
+				subject, err = subjectFromFile(filePath)
+				if err != nil {
+					return err
+				}
+			} else {
+				subject.FileSHA256 = strings.ToLower(subject.FileSHA256)
+				if err = types.ValidateSha256(subject.FileSHA256); err != nil {
+					return err
+				}
+				var filled bool
+				subject, filled, err = fillSubjectFromCatalog(dynamodbClient, subject)
+				if err != nil {
+					return err
+				}
+				if filled {
+					notes = append(notes, "identifiers not given as flags were taken from the binary catalog")
+				}
+			}
+
+			mode := clientMode.AsClientMode()
+			if !cmd.Flags().Changed("client-mode") {
+				service := machineconfiguration.GetMachineConfigurationService(dynamodbClient, timeProvider)
+				config, err := service.GetIntendedConfig(machineID)
+				if err != nil {
+					return fmt.Errorf("failed to get the configuration of machine %q: %w", machineID, err)
+				}
+				mode = config.ClientMode
+			} else {
+				notes = append(notes, "the client mode was overridden with --client-mode")
+			}
+
+			globalRules, err := ruleset.LoadGlobalRules(dynamodbClient)
+			if err != nil {
+				return err
+			}
+			rs, err := ruleset.ForMachine(dynamodbClient, globalRules, machineID)
+			if err != nil {
+				return err
+			}
+
+			explanation := rs.Explain(subject, mode)
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(explainOutput{
+					MachineID:   machineID,
+					Subject:     subject,
+					Explanation: explanation,
+					Notes:       notes,
+				})
+			}
+			printExplanation(machineID, subject, explanation, notes)
+			return nil
+		},
+	}
+
+	ruleExplainCmd.Flags().StringVarP(&tf.MachineID, "machine", "m", "", "The machine to evaluate the binary on. Omit to use the current machine.")
+	ruleExplainCmd.Flags().StringVar(&subject.FileSHA256, "sha", "", "SHA-256 of the binary")
+	ruleExplainCmd.Flags().StringVarP(&filePath, "file", "f", "", "Path to a local binary; its identifiers are read with santactl fileinfo")
+	ruleExplainCmd.Flags().StringVar(&subject.CDHash, "cdhash", "", "CDHash of the binary (with --sha)")
+	ruleExplainCmd.Flags().StringVar(&subject.SigningID, "signing-id", "", "Signing ID of the binary, e.g. EQHXZ8M8AV:com.google.Chrome (with --sha)")
+	ruleExplainCmd.Flags().StringVar(&subject.CertificateSHA256, "cert", "", "SHA-256 of the binary's leaf signing certificate (with --sha)")
+	ruleExplainCmd.Flags().StringVar(&subject.TeamID, "team-id", "", "Team ID of the binary (with --sha)")
+	ruleExplainCmd.Flags().Var(&clientMode, "client-mode", `Evaluate in this client mode instead of the machine's ("monitor" or "lockdown")`)
+	ruleExplainCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the explanation as JSON")
+
+	RulesCmd.AddCommand(ruleExplainCmd)
+}
+
+type explainOutput struct {
+	MachineID string          `json:"machine_id"`
+	Subject   ruleset.Subject `json:"subject"`
+	ruleset.Explanation
+	Notes []string `json:"notes,omitempty"`
+}
+
+func subjectFromFile(filePath string) (subject ruleset.Subject, err error) {
+	fileInfo, err := santa_sensor.RunSantaFileInfo(filePath)
+	if err != nil {
+		err = fmt.Errorf("encountered an error while attempting to get file information for %q: %w", filePath, err)
+		return
+	}
+
+	subject = ruleset.Subject{
+		CDHash:     fileInfo.CDHash,
+		FileSHA256: fileInfo.SHA256,
+		SigningID:  fileInfo.SigningID,
+		TeamID:     fileInfo.TeamID,
+	}
+	if len(fileInfo.SigningChain) > 0 {
+		subject.CertificateSHA256 = fileInfo.SigningChain[0].SHA256
+	}
+	return
+}
+
+// fillSubjectFromCatalog completes the identifiers of a binary that were not provided, using its catalog entry
+func fillSubjectFromCatalog(client dynamodb.GetItemAPI, subject ruleset.Subject) (filled ruleset.Subject, ok bool, err error) {
+	filled = subject
+
+	entry, err := catalog.GetCatalogEntry(client, catalog.EntryTypeBinary, subject.FileSHA256)
+	if err != nil || entry == nil {
+		return
+	}
+
+	if filled.SigningID == "" && entry.SigningID != "" {
+		filled.SigningID = entry.SigningID
+		ok = true
+	}
+	if filled.CertificateSHA256 == "" && entry.CertificateSHA256 != "" {
+		filled.CertificateSHA256 = entry.CertificateSHA256
+		ok = true
+	}
+	if filled.TeamID == "" && entry.TeamID != "" {
+		filled.TeamID = entry.TeamID
+		ok = true
+	}
+	return
+}
+
+func printExplanation(machineID string, subject ruleset.Subject, explanation ruleset.Explanation, notes []string) {
+	clientMode, _ := explanation.ClientMode.MarshalText()
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(writer, "Machine:\t%s (%s)\n", machineID, clientMode)
+	fmt.Fprintf(writer, "SHA-256:\t%s\n", subject.FileSHA256)
+	for _, identifier := range []struct{ name, value string }{
+		{"CDHash", subject.CDHash},
+		{"Signing ID", subject.SigningID},
+		{"Certificate", subject.CertificateSHA256},
+		{"Team ID", subject.TeamID},
+	} {
+		if identifier.value != "" {
+			fmt.Fprintf(writer, "%s:\t%s\n", identifier.name, identifier.value)
+		}
+	}
+	fmt.Fprintln(writer, "")
+	fmt.Fprintf(writer, "Decision:\t%s\n", explanation.Decision)
+	if explanation.Winner == nil {
+		fmt.Fprintf(writer, "Decided by:\tno matching rule; the %s client mode decides\n", clientMode)
+	} else {
+		fmt.Fprintf(writer, "Decided by:\t%s\n", describeRule(explanation.Winner.SantaRule, explanation.Winner.Source))
+	}
+	writer.Flush()
+
+	if len(explanation.Shadowed) > 0 {
+		fmt.Println()
+		fmt.Println("Shadowed rules:")
+		writer = tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+		for _, shadowed := range explanation.Shadowed {
+			fmt.Fprintf(writer, "  %s\t%s\n", describeRule(shadowed.SantaRule, shadowed.Source), shadowed.Reason)
+		}
+		writer.Flush()
+	}
+
+	for _, note := range notes {
+		fmt.Printf("\nNote: %s\n", note)
+	}
+}
+
+func describeRule(rule modelrules.SantaRule, source ruleset.Source) string {
+	return fmt.Sprintf("%s (%s rule)", renderRule(rule), source)
+}
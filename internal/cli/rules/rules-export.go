@@ -4,23 +4,38 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/airbnb/rudolph/internal/csv"
 	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/mobileconfig"
 	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	modelrules "github.com/airbnb/rudolph/pkg/model/rules"
 	"github.com/airbnb/rudolph/pkg/types"
 )
 
 func addRuleExportCommand() {
 	var filename string
 	var format string
+	var ruleTypes []string
+	var policies []string
+	var managedBy string
+	var profileIdentifier string
 	var ruleExportCmd = &cobra.Command{
 		Use:     "export  <file-name>",
 		Aliases: []string{"rules-export"},
-		Short:   "Export all rules into a csv file",
-		Args:    cobra.NoArgs,
+		Short:   "Export all rules into a csv, json or mobileconfig file",
+		Long: `Export the global rules into a csv, json or mobileconfig file.
+
+The mobileconfig format is an unsigned configuration profile that sets Santa's StaticRules to the exported rules, for
+machines that cannot reach the sync server. Its PayloadUUIDs are derived from the rules, so exporting the same rules
+twice produces the same file. REMOVE rules are not exported as static rules.
+
+The rules can be filtered by --rule-type, --policy and --managed-by, the source that owns rules applied from a
+declarative rules file.`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// args[0] has already been validated as a file before this
 			region, _ := cmd.Flags().GetString("region")
@@ -28,14 +43,23 @@ func addRuleExportCommand() {
 
 			dynamodbClient := dynamodb.GetClient(table, region)
 
-			return runExport(dynamodbClient, filename, format)
+			filter, err := newExportFilter(ruleTypes, policies, managedBy)
+			if err != nil {
+				return err
+			}
+
+			return runExport(dynamodbClient, filename, format, filter, profileIdentifier)
 		},
 	}
 
 	ruleExportCmd.Flags().StringVarP(&filename, "filename", "f", "", "The filename")
 	_ = ruleExportCmd.MarkFlagRequired("filename")
 
-	ruleExportCmd.Flags().StringVarP(&format, "fileformat", "t", "csv", "File format (one of: [json|csv])")
+	ruleExportCmd.Flags().StringVarP(&format, "fileformat", "t", "csv", "File format (one of: [json|csv|mobileconfig])")
+	ruleExportCmd.Flags().StringSliceVar(&ruleTypes, "rule-type", nil, "Only export rules of these types, e.g. TEAMID,SIGNINGID")
+	ruleExportCmd.Flags().StringSliceVar(&policies, "policy", nil, "Only export rules with these policies, e.g. ALLOWLIST")
+	ruleExportCmd.Flags().StringVar(&managedBy, "managed-by", "", `Only export rules owned by this source, e.g. "git:santa-rules"`)
+	ruleExportCmd.Flags().StringVar(&profileIdentifier, "profile-identifier", "com.google.santa.staticrules", "The PayloadIdentifier of the mobileconfig profile")
 
 	RulesCmd.AddCommand(ruleExportCmd)
 }
@@ -44,16 +68,73 @@ func runExport(
 	client dynamodb.QueryAPI,
 	filename string,
 	format string,
+	filter exportFilter,
+	profileIdentifier string,
 ) (err error) {
+	getFilteredRules := func(callback func(globalrules.GlobalRuleRow) error) (int64, error) {
+		return getRules(client, func(rule globalrules.GlobalRuleRow) error {
+			if !filter.matches(rule) {
+				return nil
+			}
+			return callback(rule)
+		})
+	}
+
 	switch format {
 	case "json":
-		return runJsonExport(client, filename)
+		return runJsonExport(getFilteredRules, filename)
 	case "csv":
-		return runCsvExport(client, filename)
+		return runCsvExport(getFilteredRules, filename)
+	case "mobileconfig":
+		return runMobileconfigExport(getFilteredRules, filename, profileIdentifier)
+	}
+	return fmt.Errorf("unknown file format %q; must be one of: json, csv, mobileconfig", format)
+}
+
+// ruleGetter calls the callback with every exported rule and returns the number of rules discovered
+type ruleGetter func(callback func(globalrules.GlobalRuleRow) error) (total int64, err error)
+
+type exportFilter struct {
+	ruleTypes map[types.RuleType]bool
+	policies  map[types.Policy]bool
+	managedBy string
+}
+
+func newExportFilter(ruleTypeNames []string, policyNames []string, managedBy string) (filter exportFilter, err error) {
+	filter.managedBy = managedBy
+	if len(ruleTypeNames) > 0 {
+		filter.ruleTypes = make(map[types.RuleType]bool)
+		for _, name := range ruleTypeNames {
+			var ruleType types.RuleType
+			if err = ruleType.UnmarshalText([]byte(strings.ToUpper(name))); err != nil {
+				return
+			}
+			filter.ruleTypes[ruleType] = true
+		}
+	}
+	if len(policyNames) > 0 {
+		filter.policies = make(map[types.Policy]bool)
+		for _, name := range policyNames {
+			var policy types.Policy
+			if err = policy.UnmarshalText([]byte(strings.ToUpper(name))); err != nil {
+				return
+			}
+			filter.policies[policy] = true
+		}
 	}
 	return
 }
 
+func (f exportFilter) matches(rule globalrules.GlobalRuleRow) bool {
+	if f.ruleTypes != nil && !f.ruleTypes[rule.RuleType] {
+		return false
+	}
+	if f.policies != nil && !f.policies[rule.Policy] {
+		return false
+	}
+	return f.managedBy == "" || f.managedBy == rule.ManagedBy
+}
+
 type fileRule struct {
 	RuleType      types.RuleType `json:"type"`
 	Policy        types.Policy   `json:"policy"`
@@ -62,10 +143,10 @@ type fileRule struct {
 	Description   string         `json:"description"`
 }
 
-func runJsonExport(client dynamodb.QueryAPI, filename string) (err error) {
+func runJsonExport(listRules ruleGetter, filename string) (err error) {
 	var jsonRules []fileRule
 	fmt.Println("Querying rules from DynamoDB...")
-	total, err := getRules(client, func(rule globalrules.GlobalRuleRow) (err error) {
+	total, err := listRules(func(rule globalrules.GlobalRuleRow) (err error) {
 		jsonRules = append(jsonRules, fileRule{
 			Identifier:    rule.Identifier,
 			RuleType:      rule.RuleType,
@@ -94,7 +175,7 @@ func runJsonExport(client dynamodb.QueryAPI, filename string) (err error) {
 }
 
 func runCsvExport(
-	client dynamodb.QueryAPI,
+	listRules ruleGetter,
 	filename string,
 ) (err error) {
 	csvRules := make(chan []string)
@@ -114,7 +195,7 @@ func runCsvExport(
 
 	fmt.Println("Querying rules from DynamoDB...")
 	var totalWritten int64
-	total, err := getRules(client, func(rule globalrules.GlobalRuleRow) (err error) {
+	total, err := listRules(func(rule globalrules.GlobalRuleRow) (err error) {
 		ruleType, err := rule.RuleType.MarshalText()
 		if err != nil {
 			return
@@ -150,6 +231,35 @@ func runCsvExport(
 	return
 }
 
+func runMobileconfigExport(listRules ruleGetter, filename string, profileIdentifier string) (err error) {
+	var santaRules []modelrules.SantaRule
+	fmt.Println("Querying rules from DynamoDB...")
+	total, err := listRules(func(rule globalrules.GlobalRuleRow) (err error) {
+		santaRules = append(santaRules, rule.SantaRule)
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	profile, skipped, err := mobileconfig.StaticRulesProfile(profileIdentifier, santaRules)
+	if err != nil {
+		return
+	}
+	contents, err := profile.Marshal()
+	if err != nil {
+		return
+	}
+	err = os.WriteFile(filename, contents, 0644)
+	if err != nil {
+		return
+	}
+
+	fmt.Printf("rules discovered: %d, rules written: %d, rules skipped: %d\n", total, len(santaRules)-skipped, skipped)
+
+	return
+}
+
 func getRules(client dynamodb.QueryAPI, callback func(globalrules.GlobalRuleRow) error) (total int64, err error) {
 	var key *dynamodb.PrimaryKey
 	for {
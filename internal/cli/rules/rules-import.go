@@ -2,21 +2,19 @@ package rules
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
+	"hash/fnv"
 	"os"
-	"strings"
 	"sync"
 	"sync/atomic"
 
 	"github.com/spf13/cobra"
 
-	"github.com/airbnb/rudolph/internal/csv"
 	"github.com/airbnb/rudolph/pkg/clock"
 	"github.com/airbnb/rudolph/pkg/dynamodb"
 	"github.com/airbnb/rudolph/pkg/model/globalrules"
 	rudolphrules "github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/ruleimport"
 	"github.com/airbnb/rudolph/pkg/types"
 )
 
@@ -27,248 +25,422 @@ const (
 	maxWorkers     = 2 << defaultWorkers // 2048 default, relative to defaultWorkers
 )
 
+const (
+	// checkpointInterval is the number of imported rules between two writes of the checkpoint file
+	checkpointInterval = 100
+	// importBatchSize is the number of rules written by a worker at once; with their feed entries, they fill a
+	// single transaction
+	importBatchSize = 50
+)
+
 func addRuleImportCommand() {
 	var filename string
+	var format string
 	var workers int
+	var dryRun bool
+	var validateOnly bool
+	var jsonReport bool
+	var checkpointFile string
 
 	var ruleImportCmd = &cobra.Command{
 		Use:     "import <file-name>",
 		Aliases: []string{"rules-import"},
-		Short:   "Imports riles from a csv file",
-		Args:    cobra.NoArgs,
+		Short:   "Imports rules from a csv, json, Moroz TOML or yaml file",
+		Long: `Imports rules from a file into the global rules.
+
+Supported formats, detected from the file extension unless --format is given:
+  csv       Rudolph's csv export (.csv)
+  json      Rudolph's json export, or the output of santactl rule --export (.json)
+  moroz     a Moroz TOML configuration (.toml); only its [[rules]] are imported
+  yaml      a list of rules, optionally under a top-level "rules" key (.yaml, .yml)
+
+Only rules whose policy, custom message or description differ from the live global rules are written, so importing
+the same file again changes nothing and does not resend the rules to sensors. Rules are written in batches, and
+slowed down automatically when DynamoDB throttles the writes.
+
+Rules with the REMOVE policy delete the matching global rule. Entries that cannot be converted into a rule are reported
+with their line number and skipped; use --validate-only to check a whole file without touching DynamoDB.
+
+With --checkpoint, the progress of the import is saved to the given file. If the import is interrupted or some rules
+fail to be written, running the same command again resumes after the last rules that were all imported.`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// args[0] has already been validated as a file before this
 			region, _ := cmd.Flags().GetString("region")
 			table, _ := cmd.Flags().GetString("dynamodb_table")
 
+			var ruleFormat ruleimport.Format
+			var err error
+			if format != "" {
+				ruleFormat, err = ruleimport.ParseFormat(format)
+			} else {
+				ruleFormat, err = ruleimport.DetectFormat(filename)
+			}
+			if err != nil {
+				return err
+			}
+
+			if validateOnly {
+				return runValidate(filename, ruleFormat, jsonReport)
+			}
+
 			dynamodbClient := dynamodb.GetClient(table, region)
 
+			if dryRun {
+				return runImportDryRun(dynamodbClient, filename, ruleFormat)
+			}
+
 			// Try to prevent stupidity
 			if workers < minWorkers || workers > maxWorkers {
 				fmt.Printf("[WARNING] invalid worker count (%d); using default: %d\n", workers, defaultWorkers)
 				workers = defaultWorkers
 			}
 
-			return runImport(dynamodbClient, clock.ConcreteTimeProvider{}, filename, workers)
+			return runImport(dynamodbClient, clock.ConcreteTimeProvider{}, filename, ruleFormat, workers, checkpointFile)
 		},
 	}
 
 	ruleImportCmd.Flags().StringVarP(&filename, "filename", "f", "", "The filename")
+	ruleImportCmd.Flags().StringVarP(&format, "format", "t", "", "File format (one of: [csv|json|santactl|moroz|yaml]); detected from the file extension by default")
 	ruleImportCmd.Flags().IntVarP(&workers, "workers", "w", defaultWorkers, "Number of workers")
+	ruleImportCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only summarize what the import would change")
+	ruleImportCmd.Flags().BoolVar(&validateOnly, "validate-only", false, "Only validate every entry of the file and report all errors, without connecting to DynamoDB")
+	ruleImportCmd.Flags().BoolVar(&jsonReport, "json", false, "Output the --validate-only report as JSON")
+	ruleImportCmd.Flags().StringVar(&checkpointFile, "checkpoint", "", "Save the progress of the import to this file, and resume from it if it exists")
 	_ = ruleImportCmd.MarkFlagRequired("filename")
 
 	RulesCmd.AddCommand(ruleImportCmd)
 }
 
-func runImport(
-	client dynamodb.DynamoDBClient,
-	timeProvider clock.TimeProvider,
-	filename string,
-	numWorkers int,
-) error {
-	if strings.HasSuffix(filename, ".csv") {
-		return runCsvImport(client, timeProvider, filename, numWorkers)
-	} else if strings.HasSuffix(filename, ".json") {
-		return runJsonImport(client, timeProvider, filename, numWorkers)
-	}
-
-	return errors.New("unrecognized file extension")
+type validationReport struct {
+	Filename string                     `json:"filename"`
+	Format   ruleimport.Format          `json:"format"`
+	Valid    int                        `json:"valid"`
+	Invalid  int                        `json:"invalid"`
+	Errors   []ruleimport.Unconvertible `json:"errors"`
 }
 
-func runJsonImport(
-	client dynamodb.DynamoDBClient,
-	timeProvider clock.TimeProvider,
-	filename string,
-	numWorkers int,
-) (err error) {
-	fp, err := os.Open(filename)
+func runValidate(filename string, format ruleimport.Format, jsonOutput bool) error {
+	f, err := os.Open(filename)
 	if err != nil {
-		return
-	}
-	defer fp.Close()
-	contents, err := ioutil.ReadAll(fp)
-	if err != nil {
-		return
+		return err
 	}
+	defer f.Close()
 
-	var rules []fileRule
-	err = json.Unmarshal(contents, &rules)
+	report := validationReport{Filename: filename, Format: format}
+	report.Errors, err = ruleimport.Stream(format, f, func(rule ruleimport.ImportedRule) error {
+		report.Valid++
+		return nil
+	})
 	if err != nil {
-		return
+		return err
+	}
+	report.Invalid = len(report.Errors)
+	if report.Errors == nil {
+		report.Errors = []ruleimport.Unconvertible{}
 	}
 
-	// Track a total number of lines processed
-	// This gets passed to workers and atomic.Add is
-	// used to increment in a thread-safe way
-	var total uint64
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, unconvertible := range report.Errors {
+			fmt.Printf("[ERROR] %s: %s\n", unconvertible.Position, unconvertible.Reason)
+		}
+		fmt.Printf("%s: %d valid rules, %d invalid entries\n", filename, report.Valid, report.Invalid)
+	}
 
-	// Start the workers
-	rulesBuffer := make(chan fileRule)
-	var wg sync.WaitGroup
-	for w := 0; w < numWorkers; w++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done() // ensure Done is called after this worker is complete
-			ddbWriter(
-				client,
-				timeProvider,
-				rulesBuffer,
-				&total,
-			)
-		}()
+	if report.Invalid > 0 {
+		return fmt.Errorf("%d entries of %s are invalid", report.Invalid, filename)
 	}
+	return nil
+}
 
-	// Shovel all the json-parsed rules into the worker queue
-	for _, rule := range rules {
-		rulesBuffer <- rule
+func runImportDryRun(client dynamodb.QueryAPI, filename string, format ruleimport.Format) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
 	}
-	close(rulesBuffer)
+	defer f.Close()
+
+	var imported []ruleimport.ImportedRule
+	unconvertible, err := ruleimport.Stream(format, f, func(rule ruleimport.ImportedRule) error {
+		imported = append(imported, rule)
+		return nil
+	})
+	printUnconvertible(unconvertible)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("rules converted: %d, entries skipped: %d\n", len(imported), len(unconvertible))
 
-	// Chill
-	wg.Wait()
+	liveRules, err := globalrules.ListGlobalRules(client)
+	if err != nil {
+		return fmt.Errorf("failed to get global rules: %w", err)
+	}
+	summary := ruleimport.Summarize(imported, liveRules)
+
+	fmt.Println()
+	fmt.Println("Dry run; no changes were made. The import would:")
+	fmt.Printf("  add %d rules\n", summary.Add)
+	fmt.Printf("  update %d rules\n", summary.Update)
+	fmt.Printf("  remove %d rules\n", summary.Remove)
+	fmt.Printf("  leave %d rules unchanged\n", summary.Unchanged)
+	if summary.RemoveMissing > 0 {
+		fmt.Printf("  skip %d removals of rules that do not exist\n", summary.RemoveMissing)
+	}
+	return nil
+}
 
-	fmt.Println("processed lines:", total)
+func printUnconvertible(unconvertible []ruleimport.Unconvertible) {
+	for _, entry := range unconvertible {
+		fmt.Printf("[ERROR] skipping %s: %s\n", entry.Position, entry.Reason)
+	}
+}
 
-	return
+// importJob is a rule to import, and its index among the converted rules of the file
+type importJob struct {
+	index int
+	rule  ruleimport.ImportedRule
+	// managedBy is the source that owns the live rule, which the import keeps
+	managedBy string
 }
 
-func runCsvImport(
+func runImport(
 	client dynamodb.DynamoDBClient,
 	timeProvider clock.TimeProvider,
 	filename string,
+	format ruleimport.Format,
 	numWorkers int,
+	checkpointFile string,
 ) error {
-	// ParseCsvFile returns a data channel and an optional error if any issues
-	// occurred while opening the file for reading
-	data, err := csv.ParseCsvFile(filename)
+	var checkpoint ruleimport.Checkpoint
+	if checkpointFile != "" {
+		fileHash, err := ruleimport.HashFile(filename)
+		if err != nil {
+			return err
+		}
+		var found bool
+		checkpoint, found, err = ruleimport.ReadCheckpoint(checkpointFile)
+		if err != nil {
+			return err
+		}
+		if found && checkpoint.SHA256 != fileHash {
+			return fmt.Errorf("checkpoint %s was saved for a different version of %s; delete it to import the file from the start", checkpointFile, checkpoint.Filename)
+		}
+		if found {
+			fmt.Printf("Resuming from %s: skipping the first %d rules, which were already imported\n", checkpointFile, checkpoint.Completed)
+		}
+		checkpoint.Filename = filename
+		checkpoint.SHA256 = fileHash
+	}
+
+	// Only rules that change the live global rules are written, so that importing the same file again does not
+	// rewrite every rule and send all of them to sensors through the feed
+	fmt.Println("Querying rules from DynamoDB...")
+	liveRules, err := globalrules.ListGlobalRules(client)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get global rules: %w", err)
 	}
+	live := ruleimport.IndexLiveRules(liveRules)
 
-	// Channel for csv parsing and workers to communicate over
-	rules := make(chan fileRule)
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-	// Track a total number of lines processed
+	// Track a total number of rules written
 	// This gets passed to workers and atomic.Add is
 	// used to increment in a thread-safe way
 	var total uint64
+	var converted int
+	var unchanged int
+
+	progress := ruleimport.NewProgress(checkpoint.Completed)
+	var mu sync.Mutex
+	var failures []string
+	lastSaved := checkpoint.Completed
+	saveCheckpoint := func(completed int) {
+		checkpoint.Completed = completed
+		if err := ruleimport.WriteCheckpoint(checkpointFile, checkpoint); err != nil {
+			fmt.Printf("[WARNING] failed to save checkpoint %s: %s\n", checkpointFile, err)
+			return
+		}
+		lastSaved = completed
+	}
+	done := func(job importJob, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", job.rule.Position, err))
+			return
+		}
+		completed := progress.Done(job.index)
+		if checkpointFile != "" && completed-lastSaved >= checkpointInterval {
+			saveCheckpoint(completed)
+		}
+	}
 
 	// Start the workers
 	// Fanning out workers allows us to make multiple HTTP requests concurrently which can
 	// improve performance assuming we aren't network I/O bottlenecked or something.
+	// Every worker has its own queue, and all the rules with the same key go to the same worker, which writes them in
+	// file order; the last line of the file for a rule wins.
+	rulesBuffers := make([]chan []importJob, numWorkers)
 	var wg sync.WaitGroup
-	for w := 0; w < numWorkers; w++ {
+	for w := range rulesBuffers {
+		rulesBuffer := make(chan []importJob)
+		rulesBuffers[w] = rulesBuffer
 		wg.Add(1)
 		go func() {
 			defer wg.Done() // ensure Done is called after this worker is complete
 			ddbWriter(
 				client,
 				timeProvider,
-				rules,
+				rulesBuffer,
 				&total,
+				done,
 			)
 		}()
 	}
 
-	// Start taking lines from the csv and shoveling them into the workers
-	for line := range data {
-		identifier, ok := line["identifier"]
-		if !ok {
-			panic("no identifier")
-		}
-		ruleTypeStr, ok := line["type"]
-		if !ok {
-			panic("no type")
-		}
-		policyStr, ok := line["policy"]
-		if !ok {
-			panic("no policy")
-		}
-		description, ok := line["description"]
-		if !ok {
-			description = ""
-		}
-		customMsg, ok := line["custom_msg"]
-		if !ok {
-			customMsg = ""
+	// Shovel the changed rules into the worker queues in batches as the file is read
+	batches := make([][]importJob, numWorkers)
+	batchKeys := make([]map[string]bool, numWorkers)
+	flush := func(w int) {
+		if len(batches[w]) > 0 {
+			rulesBuffers[w] <- batches[w]
 		}
+		batches[w] = nil
+		batchKeys[w] = make(map[string]bool, importBatchSize)
+	}
+	for w := range batches {
+		flush(w)
+	}
 
-		var ruleType types.RuleType
-		err := ruleType.UnmarshalText([]byte(ruleTypeStr))
-		if err != nil {
-			panic("invalid ruletype")
+	index := 0
+	unconvertible, streamErr := ruleimport.Stream(format, f, func(rule ruleimport.ImportedRule) error {
+		job := importJob{index: index, rule: rule}
+		index++
+		if job.index < checkpoint.Completed {
+			return nil
 		}
-		var policy types.Policy
-		err = policy.UnmarshalText([]byte(policyStr))
-		if err != nil {
-			panic("invalid policy")
+		converted++
+
+		switch live.Apply(rule) {
+		case ruleimport.ChangeNone, ruleimport.ChangeRemoveMissing:
+			unchanged++
+			done(job, nil)
+			return nil
 		}
+		job.managedBy = live.ManagedBy(rule)
 
-		rules <- fileRule{
-			Identifier:    identifier,
-			RuleType:      ruleType,
-			Policy:        policy,
-			Description:   description,
-			CustomMessage: customMsg,
+		// A transaction cannot write the same rule twice
+		key := rudolphrules.RuleSortKeyFromTypeIdentifier(rule.Identifier, rule.RuleType)
+		w := importWorker(key, numWorkers)
+		if batchKeys[w][key] {
+			flush(w)
 		}
+		batches[w] = append(batches[w], job)
+		batchKeys[w][key] = true
+		if len(batches[w]) == importBatchSize {
+			flush(w)
+		}
+		return nil
+	})
+	for w, rulesBuffer := range rulesBuffers {
+		flush(w)
+		close(rulesBuffer)
 	}
-	close(rules)
 
-	// chill
+	// Chill
 	wg.Wait()
 
-	fmt.Println("processed lines:", total)
+	printUnconvertible(unconvertible)
+	fmt.Printf("rules converted: %d, entries skipped: %d\n", converted, len(unconvertible))
+	fmt.Printf("rules written: %d, unchanged rules skipped: %d\n", total, unchanged)
 
+	if checkpointFile != "" {
+		if streamErr == nil && len(failures) == 0 {
+			_ = os.Remove(checkpointFile)
+		} else {
+			saveCheckpoint(progress.Completed())
+		}
+	}
+
+	if streamErr != nil {
+		return streamErr
+	}
+	if len(failures) > 0 {
+		for _, failure := range failures {
+			fmt.Printf("[ERROR] failed to import %s\n", failure)
+		}
+		if checkpointFile != "" {
+			return fmt.Errorf("%d rules failed to import; run the same command again to resume from %s", len(failures), checkpointFile)
+		}
+		return fmt.Errorf("%d rules failed to import", len(failures))
+	}
 	return nil
 }
 
+// importWorker returns the worker that writes the rules with the given key
+func importWorker(key string, numWorkers int) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+	return int(hash.Sum32() % uint32(numWorkers))
+}
+
 func ddbWriter(
 	client dynamodb.DynamoDBClient,
 	timeProvider clock.TimeProvider,
-	rules chan fileRule,
+	batches <-chan []importJob,
 	total *uint64,
+	done func(importJob, error),
 ) {
-	for rule := range rules {
-		var err error
-		atomic.AddUint64(total, 1)
-
-		var suffix string
-		switch rule.RuleType {
-		case types.RuleTypeCertificate:
-			suffix = " (Cert)"
-		case types.RuleTypeTeamID:
-			suffix = " (TeamID)"
-		case types.RuleTypeSigningID:
-			suffix = " (SigningID)"
-		default:
-			suffix = ""
-		}
+	for batch := range batches {
+		rows := make([]globalrules.GlobalRuleRow, 0, len(batch))
+		for _, job := range batch {
+			rule := job.rule
+
+			var suffix string
+			switch rule.RuleType {
+			case types.RuleTypeCertificate:
+				suffix = " (Cert)"
+			case types.RuleTypeTeamID:
+				suffix = " (TeamID)"
+			case types.RuleTypeSigningID:
+				suffix = " (SigningID)"
+			case types.RuleTypeCDHash:
+				suffix = " (CDHash)"
+			default:
+				suffix = ""
+			}
 
-		if rule.Policy == types.RulePolicyRemove {
-			fmt.Printf("  Removing rule: [%s]\n", rule.Identifier)
-			sortkey := rudolphrules.RuleSortKeyFromTypeIdentifier(rule.Identifier, rule.RuleType)
-			err = globalrules.RemoveGlobalRule(
-				timeProvider,
-				client,
-				client,
-				sortkey,
-				"",
-			)
+			if rule.Policy == types.RulePolicyRemove {
+				fmt.Printf("  Removing rule: [%s]%s\n", rule.Identifier, suffix)
+			} else {
+				fmt.Printf("  Writing rule: [%+v] %s%s\n", rule.Policy, rule.Identifier, suffix)
+			}
 
-		} else {
-			fmt.Printf("  Writing rule: [%+v] %s%s\n", rule.Policy, rule.Identifier, suffix)
-			err = globalrules.AddNewGlobalRule(
-				timeProvider,
-				client,
-				rule.Identifier,
-				rule.RuleType,
-				rule.Policy,
-				rule.Description,
-			)
+			rows = append(rows, globalrules.GlobalRuleRow{
+				SantaRule:   rule.SantaRule,
+				Description: rule.Description,
+				ManagedBy:   job.managedBy,
+			})
 		}
 
-		if err != nil {
-			panic(err)
+		written, err := globalrules.WriteGlobalRules(timeProvider, client, rows)
+		atomic.AddUint64(total, uint64(written))
+		for i, job := range batch {
+			if i < written {
+				done(job, nil)
+			} else {
+				done(job, err)
+			}
 		}
 	}
 }
@@ -0,0 +1,131 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/rulelint"
+	"github.com/airbnb/rudolph/pkg/scan"
+)
+
+func addRuleLintCommand() {
+	var (
+		minSeverity string
+		failOn      string
+		jsonOutput  bool
+	)
+
+	var ruleLintCmd = &cobra.Command{
+		Use:   "lint [--min-severity warning] [--fail-on error] [--json]",
+		Short: "Report conflicting, redundant and shadowed rules across the global and machine rules",
+		Long: `Report conflicting, redundant and shadowed rules across the global and machine rules.
+
+Scans the GlobalRules partition and every MachineRules partition, and reports:
+  error     conflict   an allow rule under a block rule that covers the same executables, or vice versa
+  warning   conflict   a BLOCKLIST rule under a SILENT_BLOCKLIST rule that covers the same executables, or vice versa
+  warning   redundant  a rule fully covered by a broader rule with the same policy, or a machine rule identical to a global rule
+  info      shadowed   a global rule that a machine rule overrides or removes
+
+Binary rules are related to signing ID, certificate and team ID rules through the binary catalog, so they can only be
+checked for binaries that have been seen in uploaded events.
+
+The command fails when there are findings at or above --fail-on, so it can be used in CI.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+			scanService := scan.GetScanService(dynamodbClient)
+
+			shown, err := rulelint.ParseSeverity(minSeverity)
+			if err != nil {
+				return err
+			}
+			var failSeverity rulelint.Severity
+			if failOn != "none" {
+				failSeverity, err = rulelint.ParseSeverity(failOn)
+				if err != nil {
+					return err
+				}
+			}
+
+			inventory, err := rulelint.LoadInventory(scanService, dynamodbClient)
+			if err != nil {
+				return err
+			}
+
+			findings := []rulelint.Finding{}
+			for _, finding := range rulelint.Lint(inventory) {
+				if finding.Severity.AtLeast(shown) {
+					findings = append(findings, finding)
+				}
+			}
+			summary := rulelint.Summarize(findings)
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				err = encoder.Encode(lintOutput{
+					Findings: findings,
+					Summary:  summary,
+				})
+				if err != nil {
+					return err
+				}
+			} else {
+				printFindings(findings, summary, inventory)
+			}
+
+			if failSeverity != "" {
+				for _, finding := range findings {
+					if finding.Severity.AtLeast(failSeverity) {
+						return fmt.Errorf("found findings at or above severity %q", failSeverity)
+					}
+				}
+			}
+			return nil
+		},
+	}
+
+	ruleLintCmd.Flags().StringVar(&minSeverity, "min-severity", "info", "Only report findings at or above this severity (error, warning or info)")
+	ruleLintCmd.Flags().StringVar(&failOn, "fail-on", "error", `Exit with an error when there are findings at or above this severity (error, warning, info or "none")`)
+	ruleLintCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the findings as JSON")
+
+	RulesCmd.AddCommand(ruleLintCmd)
+}
+
+type lintOutput struct {
+	Findings []rulelint.Finding `json:"findings"`
+	Summary  rulelint.Summary   `json:"summary"`
+}
+
+func printFindings(findings []rulelint.Finding, summary rulelint.Summary, inventory rulelint.Inventory) {
+	fmt.Printf("Linted %d global rules and the rules of %d machines\n", len(inventory.GlobalRules), len(inventory.MachineRules))
+	fmt.Println()
+
+	if len(findings) > 0 {
+		writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+		fmt.Fprintln(writer, "SEVERITY\tKIND\tSCOPE\tRULE\tMESSAGE\tRELATED RULE")
+		for _, finding := range findings {
+			scope := "global"
+			if finding.MachineID != "" {
+				scope = finding.MachineID
+			}
+			var related string
+			if finding.Related != nil {
+				related = describeRule(finding.Related.SantaRule, finding.Related.Source)
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", finding.Severity, finding.Kind, scope, describeRule(finding.Rule.SantaRule, finding.Rule.Source), finding.Message, related)
+		}
+		writer.Flush()
+		fmt.Println()
+	}
+
+	fmt.Printf("%d errors, %d warnings, %d infos\n", summary.Errors, summary.Warnings, summary.Infos)
+}
@@ -0,0 +1,274 @@
+package rules
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/ruleplan"
+)
+
+// planChangesExitCode is returned by "rules plan" when the rules file differs from the live rules, like
+// terraform plan -detailed-exitcode
+const planChangesExitCode = 2
+
+func addRulePlanCommand() {
+	var (
+		filename   string
+		outFile    string
+		jsonOutput bool
+		adopt      bool
+	)
+
+	var rulePlanCmd = &cobra.Command{
+		Use:   "plan -f <rules.yaml> [--adopt] [--out <plan.json>] [--json]",
+		Short: "Show the changes that applying a declarative rules file would make to the global rules",
+		Long: `Show the changes that applying a declarative rules file would make to the global rules.
+
+The rules file (.yaml, .yml or .json) lists every global rule owned by the source named in its managed_by field:
+
+  managed_by: git:santa-rules
+  rules:
+    - identifier: EQHXZ8M8AV
+      type: TEAMID
+      policy: ALLOWLIST
+      custom_msg: ""
+      description: Google
+
+Declared rules are added or updated; rules owned by the same source that are no longer declared are removed. Declared
+rules that were created by hand, or that are owned by other sources, are reported as conflicts. With --adopt, rules
+created by hand are taken over by the source instead, so that deleting them from the file later removes them.
+
+Exits with 0 when there is nothing to change, 2 when there are changes and 1 on errors or conflicts.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+
+			plan, err := computePlan(dynamodbClient, filename, adopt)
+			if err != nil {
+				return err
+			}
+
+			if outFile != "" {
+				if err = writePlanFile(outFile, plan); err != nil {
+					return err
+				}
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err = encoder.Encode(plan); err != nil {
+					return err
+				}
+			} else {
+				printPlan(plan)
+				if outFile != "" {
+					fmt.Printf("\nThe plan was saved to %s; apply exactly these changes with: rudolph rules apply --plan %s\n", outFile, outFile)
+				}
+			}
+
+			if len(plan.Conflicts) > 0 {
+				return fmt.Errorf("%d declared rules are owned by other sources or were created by hand", len(plan.Conflicts))
+			}
+			if plan.HasChanges() {
+				os.Exit(planChangesExitCode)
+			}
+			return nil
+		},
+	}
+
+	rulePlanCmd.Flags().StringVarP(&filename, "filename", "f", "", "The declarative rules file")
+	_ = rulePlanCmd.MarkFlagRequired("filename")
+	rulePlanCmd.Flags().StringVarP(&outFile, "out", "o", "", "Save the plan to this file, so that exactly these changes can be applied later")
+	rulePlanCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the plan as JSON")
+	rulePlanCmd.Flags().BoolVar(&adopt, "adopt", false, "Take over declared rules that were created by hand, instead of reporting them as conflicts")
+
+	RulesCmd.AddCommand(rulePlanCmd)
+}
+
+func addRuleApplyCommand() {
+	var (
+		filename    string
+		planFile    string
+		autoApprove bool
+		adopt       bool
+	)
+
+	var ruleApplyCmd = &cobra.Command{
+		Use:   "apply (-f <rules.yaml> [--adopt]|--plan <plan.json>) [--auto-approve]",
+		Short: "Apply a declarative rules file, or a saved plan, to the global rules",
+		Long: `Apply a declarative rules file, or a saved plan, to the global rules.
+
+With -f, the plan is computed like "rules plan" does, shown, and applied after confirmation. With --plan, the plan
+saved by "rules plan --out" is applied as is. Either way, apply stops if any rule changed in the table since the plan
+was computed.
+
+Removed rules are also added to the feed as REMOVE rules, so that sensors drop them without a clean sync.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			region, _ := cmd.Flags().GetString("region")
+			table, _ := cmd.Flags().GetString("dynamodb_table")
+
+			dynamodbClient := dynamodb.GetClient(table, region)
+			timeProvider := clock.ConcreteTimeProvider{}
+
+			if (filename == "") == (planFile == "") {
+				return errors.New("exactly one of --filename or --plan must be provided")
+			}
+
+			var plan ruleplan.Plan
+			var err error
+			if filename != "" {
+				plan, err = computePlan(dynamodbClient, filename, adopt)
+			} else {
+				plan, err = readPlanFile(planFile)
+			}
+			if err != nil {
+				return err
+			}
+
+			printPlan(plan)
+			if len(plan.Conflicts) > 0 {
+				return fmt.Errorf("%d declared rules are owned by other sources or were created by hand", len(plan.Conflicts))
+			}
+			if !plan.HasChanges() {
+				return nil
+			}
+
+			if !autoApprove {
+				fmt.Println()
+				fmt.Println(`Apply changes? (Enter: "yes" or "ok")`)
+				fmt.Print("> ")
+
+				reader := bufio.NewReader(os.Stdin)
+				text, _ := reader.ReadString('\n')
+				text = strings.TrimSpace(text)
+				if text != "ok" && text != "yes" {
+					fmt.Println("Confirmation not successful...")
+					return nil
+				}
+			}
+
+			applied, err := ruleplan.Apply(plan, ruleplan.GetRuleStore(dynamodbClient, timeProvider))
+			fmt.Printf("\n%d of %d changes applied\n", applied, len(plan.Changes))
+			return err
+		},
+	}
+
+	ruleApplyCmd.Flags().StringVarP(&filename, "filename", "f", "", "The declarative rules file")
+	ruleApplyCmd.Flags().StringVar(&planFile, "plan", "", `A plan saved with "rules plan --out"`)
+	ruleApplyCmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Apply without asking for confirmation, e.g. in CI")
+	ruleApplyCmd.Flags().BoolVar(&adopt, "adopt", false, "Take over declared rules that were created by hand, instead of reporting them as conflicts")
+
+	RulesCmd.AddCommand(ruleApplyCmd)
+}
+
+func computePlan(client dynamodb.QueryAPI, filename string, adopt bool) (plan ruleplan.Plan, err error) {
+	rulesFile, err := ruleplan.ParseRulesFile(filename)
+	if err != nil {
+		return
+	}
+
+	liveRules, err := globalrules.ListGlobalRules(client)
+	if err != nil {
+		err = fmt.Errorf("failed to get global rules: %w", err)
+		return
+	}
+
+	return ruleplan.ComputePlan(rulesFile, liveRules, adopt)
+}
+
+func writePlanFile(filename string, plan ruleplan.Plan) error {
+	contents, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, contents, 0644)
+}
+
+func readPlanFile(filename string) (plan ruleplan.Plan, err error) {
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		err = fmt.Errorf("failed to read plan file: %w", err)
+		return
+	}
+	err = json.Unmarshal(contents, &plan)
+	if err != nil {
+		err = fmt.Errorf("failed to parse plan file %q: %w", filename, err)
+	}
+	return
+}
+
+func printPlan(plan ruleplan.Plan) {
+	fmt.Printf("Plan for the global rules managed by %q:\n", plan.ManagedBy)
+	fmt.Println()
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', 0)
+	for _, change := range plan.Changes {
+		rule := change.Rule()
+		ruleType, _ := rule.RuleType.MarshalText()
+
+		switch change.Action {
+		case ruleplan.ActionAdd:
+			policy, _ := change.After.Policy.MarshalText()
+			fmt.Fprintf(writer, "  + add\t%s\t%s\t%s\n", ruleType, rule.Identifier, policy)
+		case ruleplan.ActionUpdate:
+			fmt.Fprintf(writer, "  ~ update\t%s\t%s\t%s\n", ruleType, rule.Identifier, strings.Join(describeUpdate(*change.Before, *change.After), ", "))
+		case ruleplan.ActionRemove:
+			policy, _ := change.Before.Policy.MarshalText()
+			fmt.Fprintf(writer, "  - remove\t%s\t%s\t%s\n", ruleType, rule.Identifier, policy)
+		}
+	}
+	for _, conflict := range plan.Conflicts {
+		ruleType, _ := conflict.Declared.RuleType.MarshalText()
+		if conflict.OwnedBy == "" {
+			fmt.Fprintf(writer, "  ! conflict\t%s\t%s\tcreated by hand; adopt it with --adopt\n", ruleType, conflict.Declared.Identifier)
+		} else {
+			fmt.Fprintf(writer, "  ! conflict\t%s\t%s\towned by %q\n", ruleType, conflict.Declared.Identifier, conflict.OwnedBy)
+		}
+	}
+	writer.Flush()
+
+	if len(plan.Changes) > 0 || len(plan.Conflicts) > 0 {
+		fmt.Println()
+	}
+	fmt.Printf(
+		"Plan: %d to add, %d to update, %d to remove, %d unchanged, %d conflicts\n",
+		plan.Count(ruleplan.ActionAdd),
+		plan.Count(ruleplan.ActionUpdate),
+		plan.Count(ruleplan.ActionRemove),
+		plan.Unchanged,
+		len(plan.Conflicts),
+	)
+}
+
+func describeUpdate(before ruleplan.ManagedRule, after ruleplan.ManagedRule) (changes []string) {
+	if before.Policy != after.Policy {
+		beforePolicy, _ := before.Policy.MarshalText()
+		afterPolicy, _ := after.Policy.MarshalText()
+		changes = append(changes, fmt.Sprintf("policy %s -> %s", beforePolicy, afterPolicy))
+	}
+	if before.CustomMessage != after.CustomMessage {
+		changes = append(changes, fmt.Sprintf("custom_msg %q -> %q", before.CustomMessage, after.CustomMessage))
+	}
+	if before.Description != after.Description {
+		changes = append(changes, fmt.Sprintf("description %q -> %q", before.Description, after.Description))
+	}
+	if before.ManagedBy == "" {
+		changes = append(changes, "adopt rule created by hand")
+	}
+	return
+}
@@ -41,6 +41,11 @@ func init() {
 
 	addRuleExportCommand()
 	addRuleImportCommand()
+	addRuleExplainCommand()
+	addRuleLintCommand()
+	addRuleEffectiveCommand()
+	addRulePlanCommand()
+	addRuleApplyCommand()
 }
 
 func rules(client dynamodb.QueryAPI, tf flags.TargetFlags, limit int) error {
@@ -112,6 +117,8 @@ func renderRule(item modelrules.SantaRule) string {
 		predicate = "teamID"
 	case types.RuleTypeSigningID:
 		predicate = "signingID"
+	case types.RuleTypeCDHash:
+		predicate = "cdhash"
 	default:
 		predicate = "?"
 	}
@@ -13,6 +13,7 @@ type santaFileInfo struct {
 	Path                  string             `json:"Path"`
 	SHA256                string             `json:"SHA-256"`
 	SHA1                  string             `json:"SHA-1"`
+	CDHash                string             `json:"CDHash"`
 	TeamID                string             `json:"Team ID"`
 	SigningID             string             `json:"Signing ID"`
 	BundleName            string             `json:"Bundle Name"`
@@ -0,0 +1,150 @@
+package console
+
+import (
+	"crypto/hmac"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/oidc"
+)
+
+const (
+	sessionCookie = "rudolph_console_session"
+	loginCookie   = "rudolph_console_login"
+	// loginCookieMaxAge is how long users have to log in with the identity provider
+	loginCookieMaxAge = 10 * 60
+)
+
+func (s *Server) setCookie(w http.ResponseWriter, name string, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// handleLogin sends the user to the identity provider, remembering where to return to
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	state, err := oidc.NewState()
+	if err != nil {
+		http.Error(w, "Failed to start the login", http.StatusInternalServerError)
+		return
+	}
+	verifier, err := oidc.NewState()
+	if err != nil {
+		http.Error(w, "Failed to start the login", http.StatusInternalServerError)
+		return
+	}
+	s.setCookie(w, loginCookie, state+"."+verifier+"."+url.QueryEscape(localPath(r.URL.Query().Get("next"))), loginCookieMaxAge)
+	http.Redirect(w, r, s.login.AuthCodeURL(state, verifier), http.StatusFound)
+}
+
+// handleCallback finishes the login when the identity provider sends the user back
+func (s *Server) handleCallback(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(loginCookie)
+	if err != nil {
+		http.Error(w, "The login expired; please try again", http.StatusBadRequest)
+		return
+	}
+	parts := strings.SplitN(cookie.Value, ".", 3)
+	if len(parts) != 3 || !hmac.Equal([]byte(parts[0]), []byte(r.URL.Query().Get("state"))) {
+		http.Error(w, "The login does not match; please try again", http.StatusBadRequest)
+		return
+	}
+	s.setCookie(w, loginCookie, "", -1)
+
+	if errorCode := r.URL.Query().Get("error"); errorCode != "" {
+		http.Error(w, "The identity provider refused the login: "+errorCode, http.StatusForbidden)
+		return
+	}
+	token, err := s.login.Exchange(r.URL.Query().Get("code"), parts[1])
+	if err != nil {
+		slog.Warn("Console login failed", "error", err)
+		http.Error(w, "The login failed; please try again", http.StatusBadGateway)
+		return
+	}
+
+	s.setCookie(w, sessionCookie, token, 0)
+	next, _ := url.QueryUnescape(parts[2])
+	http.Redirect(w, r, localPath(next), http.StatusFound)
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	s.setCookie(w, sessionCookie, "", -1)
+	http.Redirect(w, r, "/login", http.StatusFound)
+}
+
+// localPath only lets logins return to pages of the console, so that login links cannot redirect elsewhere
+func localPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return "/"
+	}
+	return path
+}
+
+// authenticated sends users without a valid session to log in, and checks the CSRF token of every form
+func (s *Server) authenticated(handler func(http.ResponseWriter, *http.Request, *session)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil || cookie.Value == "" {
+			s.redirectToLogin(w, r)
+			return
+		}
+
+		sess := &session{token: cookie.Value, csrf: s.csrfToken(cookie.Value)}
+		if r.Method == http.MethodPost && !hmac.Equal([]byte(r.PostFormValue("csrf")), []byte(sess.csrf)) {
+			http.Error(w, "The form expired; please go back and try again", http.StatusForbidden)
+			return
+		}
+
+		if sess.client, err = adminapi.NewClient(s.adminAPIURL, sess.token); err != nil {
+			s.renderError(w, nil, err)
+			return
+		}
+		if sess.caller, err = sess.client.WhoAmI(); err != nil {
+			s.renderError(w, r, err)
+			return
+		}
+		handler(w, r, sess)
+	}
+}
+
+func (s *Server) redirectToLogin(w http.ResponseWriter, r *http.Request) {
+	next := "/"
+	if r.Method == http.MethodGet {
+		next = r.URL.RequestURI()
+	}
+	http.Redirect(w, r, "/login?next="+url.QueryEscape(next), http.StatusFound)
+}
+
+// renderError shows what went wrong. Expired tokens send the user to log in again, and denials show which roles are
+// needed.
+func (s *Server) renderError(w http.ResponseWriter, r *http.Request, err error) {
+	var apiErr *adminapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized:
+			if r != nil {
+				s.setCookie(w, sessionCookie, "", -1)
+				s.redirectToLogin(w, r)
+				return
+			}
+		case http.StatusForbidden:
+			s.render(w, http.StatusForbidden, "error.html", page{Error: "You are not allowed to do this: " + apiErr.Message})
+			return
+		case http.StatusBadRequest, http.StatusNotFound, http.StatusConflict:
+			s.render(w, apiErr.StatusCode, "error.html", page{Error: apiErr.Message})
+			return
+		}
+	}
+	slog.Error("Console request failed", "error", err)
+	s.render(w, http.StatusBadGateway, "error.html", page{Error: "The admin API could not be reached; please try again"})
+}
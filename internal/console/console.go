@@ -0,0 +1,184 @@
+// Package console is a web console for the helpdesk and responders, who cannot use a CLI that needs AWS credentials.
+// It renders pages on the server from templates embedded in the binary, and does everything through the admin API
+// with the token of the logged in user, so the admin API's roles apply to the console as they do to the CLI.
+package console
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/oidc"
+	"github.com/airbnb/rudolph/pkg/rbac"
+)
+
+//go:embed templates static
+var content embed.FS
+
+// Config configures the console
+type Config struct {
+	// AdminAPIURL is the URL of the admin API, e.g. https://rudolph.example.com/prod
+	AdminAPIURL string
+	// URL is where users reach the console; the identity provider sends them back to its /callback
+	URL string
+	// Login logs users in with the identity provider. Its client ID must be the audience of the admin API.
+	Login *oidc.Login
+	// SessionKey signs the tokens that protect forms from cross-site requests. A random key is used when it is empty,
+	// which invalidates forms that are open when the console restarts. Sessions are not affected: the session cookie
+	// holds the ID token itself, which the admin API verifies on every request.
+	SessionKey []byte
+	// Contact is who users should reach out to about a block, e.g. a Slack channel or email address
+	Contact string
+}
+
+// Server serves the console
+type Server struct {
+	adminAPIURL  string
+	login        *oidc.Login
+	secure       bool
+	sessionKey   []byte
+	contact      string
+	templates    map[string]*template.Template
+	timeProvider clock.TimeProvider
+	mux          *http.ServeMux
+}
+
+// New returns a console for the admin API
+func New(config Config) (*Server, error) {
+	if config.AdminAPIURL == "" {
+		return nil, errors.New("an admin API URL is required")
+	}
+	if config.Login == nil {
+		return nil, errors.New("a login is required")
+	}
+	sessionKey := config.SessionKey
+	if len(sessionKey) == 0 {
+		sessionKey = make([]byte, 32)
+		if _, err := rand.Read(sessionKey); err != nil {
+			return nil, err
+		}
+	}
+	templates, err := parseTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		adminAPIURL:  config.AdminAPIURL,
+		login:        config.Login,
+		secure:       strings.HasPrefix(config.URL, "https://"),
+		sessionKey:   sessionKey,
+		contact:      config.Contact,
+		templates:    templates,
+		timeProvider: clock.ConcreteTimeProvider{},
+		mux:          http.NewServeMux(),
+	}
+	s.routes()
+	return s, nil
+}
+
+func (s *Server) routes() {
+	static, _ := fs.Sub(content, "static")
+	s.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(static))))
+
+	s.mux.HandleFunc("GET /login", s.handleLogin)
+	s.mux.HandleFunc("GET /callback", s.handleCallback)
+	s.mux.HandleFunc("POST /logout", s.handleLogout)
+
+	s.mux.HandleFunc("GET /{$}", s.authenticated(s.handleSearch))
+	s.mux.HandleFunc("GET /machines/{machine_id}", s.authenticated(s.handleMachine))
+	s.mux.HandleFunc("POST /machines/{machine_id}/rules", s.authenticated(s.handleCreateRule))
+	s.mux.HandleFunc("POST /machines/{machine_id}/rules/remove", s.authenticated(s.handleRemoveRule))
+
+	// Santa's block dialog links to the event page, which users without any role can use for their own machines
+	s.mux.HandleFunc("GET /event/{machine_id}/{file_sha}", s.authenticated(s.handleEvent))
+	s.mux.HandleFunc("POST /event/{machine_id}/{file_sha}/request", s.authenticated(s.handleRequestUnblock))
+	s.mux.HandleFunc("GET /unblock-requests", s.authenticated(s.handleUnblockRequests))
+	s.mux.HandleFunc("POST /unblock-requests/{machine_id}/{file_sha}", s.authenticated(s.handleDecideUnblockRequest))
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	w.Header().Set("Referrer-Policy", "same-origin")
+	s.mux.ServeHTTP(w, r)
+}
+
+// parseTemplates pairs every page with the layout, so that each page can define the same blocks
+func parseTemplates() (map[string]*template.Template, error) {
+	pages, err := fs.Glob(content, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+	templates := map[string]*template.Template{}
+	for _, page := range pages {
+		if page == "templates/layout.html" {
+			continue
+		}
+		t, err := template.New("layout.html").Funcs(templateFuncs).ParseFS(content, "templates/layout.html", page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", page, err)
+		}
+		templates[strings.TrimPrefix(page, "templates/")] = t
+	}
+	return templates, nil
+}
+
+var templateFuncs = template.FuncMap{
+	"formatTime": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format("2006-01-02 15:04 UTC")
+	},
+}
+
+// session is a logged in user, and what the admin API lets them do
+type session struct {
+	token  string
+	client *adminapi.Client
+	caller adminapi.Caller
+	csrf   string
+}
+
+func (sess *session) roles() rbac.Roles {
+	return rbac.ParseRoles(strings.Join(sess.caller.Roles, ","))
+}
+
+// page is what every template gets
+type page struct {
+	Caller adminapi.Caller
+	// CanReview shows the link to the unblock requests
+	CanReview bool
+	CSRF      string
+	Message   string
+	Error     string
+	Data      interface{}
+}
+
+func (s *Server) render(w http.ResponseWriter, status int, name string, p page) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := s.templates[name].Execute(w, p); err != nil {
+		// The status is already written, so all that is left is to stop
+		fmt.Fprint(w, "Failed to render the page")
+	}
+}
+
+// csrfToken ties forms to the session, so that other sites cannot submit them on behalf of the user
+func (s *Server) csrfToken(token string) string {
+	mac := hmac.New(sha256.New, s.sessionKey)
+	mac.Write([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
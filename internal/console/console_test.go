@@ -0,0 +1,312 @@
+package console
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAdminAPI answers as the admin API would for a responder with the token "responder-token", and for bob, who has
+// no roles, with the token "user-token"
+type fakeAdminAPI struct {
+	created   []adminapi.CreateMachineRule
+	removed   []string
+	requested []adminapi.CreateUnblockRequest
+	decided   []adminapi.DecideUnblockRequest
+}
+
+func (f *fakeAdminAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "Bearer user-token" {
+		f.serveUser(w, r)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer responder-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"Unauthorized"}`))
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/admin/whoami":
+		_ = json.NewEncoder(w).Encode(adminapi.Caller{Principal: "alice@example.com", Roles: []string{"responder"}})
+	case r.Method == http.MethodGet && r.URL.Path == "/admin/unblock-requests":
+		_, _ = w.Write([]byte(`{"requests": [{
+			"machine_id": "machine-1", "file_sha256": "abc123", "file_name": "tool", "requester": "bob@example.com",
+			"justification": "I build this tool", "requested_at": "2000-01-01T00:00:00Z", "status": "` + r.URL.Query().Get("status") + `"
+		}]}`))
+	case r.Method == http.MethodPut && r.URL.Path == "/admin/unblock-requests/machine-1/abc123":
+		var decision adminapi.DecideUnblockRequest
+		_ = json.NewDecoder(r.Body).Decode(&decision)
+		f.decided = append(f.decided, decision)
+		_, _ = w.Write([]byte(`{}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/admin/machines/lookup":
+		if r.URL.Query().Get("serial_num") == "C02ABC" {
+			_ = json.NewEncoder(w).Encode(adminapi.MachineIDs{MachineIDs: []string{"machine-1"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(adminapi.MachineIDs{MachineIDs: []string{"machine-1", "machine-2"}})
+	case r.Method == http.MethodGet && r.URL.Path == "/admin/machines/machine-1":
+		_, _ = w.Write([]byte(`{
+			"machine_id": "machine-1",
+			"sensor": {"serial_num": "C02ABC", "primary_user": "alice"},
+			"recent_events": [
+				{"decision": "BLOCK_BINARY", "file_name": "tool", "file_sha256": "abc123", "signing_id": "TEAM:com.example.tool"},
+				{"decision": "ALLOW_BINARY", "file_name": "other", "file_sha256": "def456"}
+			],
+			"machine_rules": [{"rule_type": "BINARY", "policy": "ALLOWLIST", "identifier": "fed987"}]
+		}`))
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/admin/machines/"):
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"machine not found"}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/admin/machines/machine-1/rules":
+		var rule adminapi.CreateMachineRule
+		_ = json.NewDecoder(r.Body).Decode(&rule)
+		if rule.Policy != "ALLOWLIST" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":"requires one of the roles rule-admin"}`))
+			return
+		}
+		f.created = append(f.created, rule)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/admin/machines/machine-1/rules/"):
+		f.removed = append(f.removed, strings.TrimPrefix(r.URL.Path, "/admin/machines/machine-1/rules/"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeAdminAPI) serveUser(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/admin/whoami":
+		_ = json.NewEncoder(w).Encode(adminapi.Caller{Principal: "bob@example.com", Roles: []string{}})
+	case r.Method == http.MethodGet && r.URL.Path == "/admin/blocks/machine-1/abc123":
+		unblockRequest := ""
+		if len(f.requested) > 0 {
+			unblockRequest = `, "unblock_request": {"requester": "bob@example.com", "justification": "I build this tool", "status": "pending"}`
+		}
+		_, _ = w.Write([]byte(`{
+			"machine_id": "machine-1",
+			"subject": {"file_sha256": "abc123", "signing_id": "TEAM:com.example.tool"},
+			"events": [{"decision": "BLOCK_BINARY", "file_name": "tool", "file_sha256": "abc123", "executing_user": "bob"}],
+			"rules": {"decision": "BLOCK_UNKNOWN", "client_mode": "LOCKDOWN", "winner": null, "shadowed": []}` + unblockRequest + `
+		}`))
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/admin/blocks/"):
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"only the owner of a machine may see what was blocked on it"}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/admin/unblock-requests":
+		var request adminapi.CreateUnblockRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		f.requested = append(f.requested, request)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":"requires one of the roles viewer, responder, rule-admin, fleet-admin"}`))
+	}
+}
+
+func newTestServer(t *testing.T) (*Server, *fakeAdminAPI) {
+	api := &fakeAdminAPI{}
+	apiServer := httptest.NewServer(api)
+	t.Cleanup(apiServer.Close)
+
+	server, err := New(Config{
+		AdminAPIURL: apiServer.URL,
+		URL:         "https://console.example.com",
+		Login: &oidc.Login{
+			AuthorizationEndpoint: "https://idp.example.com/authorize",
+			ClientID:              "rudolph",
+			RedirectURL:           "https://console.example.com/callback",
+		},
+		SessionKey: []byte("test-key"),
+		Contact:    "#help-santa",
+	})
+	require.NoError(t, err)
+	server.timeProvider = clock.Y2K{}
+	return server, api
+}
+
+func serve(server *Server, request *http.Request, token string) *httptest.ResponseRecorder {
+	if token != "" {
+		request.AddCookie(&http.Cookie{Name: sessionCookie, Value: token})
+	}
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func postForm(path string, form url.Values) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return request
+}
+
+func Test_Console_LoginRedirects(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	response := serve(server, httptest.NewRequest(http.MethodGet, "/machines/machine-1", nil), "")
+	assert.Equal(t, http.StatusFound, response.Code)
+	assert.Equal(t, "/login?next=%2Fmachines%2Fmachine-1", response.Header().Get("Location"))
+
+	response = serve(server, httptest.NewRequest(http.MethodGet, "/login?next=//evil.example.com", nil), "")
+	assert.Equal(t, http.StatusFound, response.Code)
+	assert.True(t, strings.HasPrefix(response.Header().Get("Location"), "https://idp.example.com/authorize?"))
+	cookie := response.Result().Cookies()[0]
+	assert.Equal(t, loginCookie, cookie.Name)
+	assert.True(t, cookie.HttpOnly)
+	assert.True(t, cookie.Secure)
+	assert.True(t, strings.HasSuffix(cookie.Value, ".%2F"))
+
+	// A callback without the state from the login cookie is refused
+	request := httptest.NewRequest(http.MethodGet, "/callback?state=forged&code=code", nil)
+	request.AddCookie(cookie)
+	response = serve(server, request, "")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	// An expired token sends the user to log in again
+	response = serve(server, httptest.NewRequest(http.MethodGet, "/", nil), "expired-token")
+	assert.Equal(t, http.StatusFound, response.Code)
+	assert.Equal(t, "/login?next=%2F", response.Header().Get("Location"))
+}
+
+func Test_Console_Search(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	response := serve(server, httptest.NewRequest(http.MethodGet, "/?q=C02ABC&by=serial", nil), "responder-token")
+	assert.Equal(t, http.StatusFound, response.Code)
+	assert.Equal(t, "/machines/machine-1", response.Header().Get("Location"))
+
+	response = serve(server, httptest.NewRequest(http.MethodGet, "/?q=machine", nil), "responder-token")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `href="/machines/machine-2"`)
+	assert.Contains(t, response.Body.String(), "alice@example.com")
+}
+
+func Test_Console_Machine(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	response := serve(server, httptest.NewRequest(http.MethodGet, "/machines/machine-1", nil), "responder-token")
+	require.Equal(t, http.StatusOK, response.Code)
+	body := response.Body.String()
+	assert.Contains(t, body, "C02ABC")
+	assert.Contains(t, body, "Unblock by BINARY")
+	assert.Contains(t, body, "Unblock by SIGNINGID")
+	assert.Equal(t, 2, strings.Count(body, `class="unblock"`), "only the blocked event can be unblocked")
+	assert.Contains(t, body, server.csrfToken("responder-token"))
+	assert.NotContains(t, body, "BLOCKLIST</option>", "responders cannot choose the policy")
+
+	response = serve(server, httptest.NewRequest(http.MethodGet, "/machines/unknown", nil), "responder-token")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Contains(t, response.Body.String(), "machine not found")
+}
+
+func Test_Console_Unblock(t *testing.T) {
+	server, api := newTestServer(t)
+	csrf := server.csrfToken("responder-token")
+
+	response := serve(server, postForm("/machines/machine-1/rules", url.Values{
+		"csrf": {csrf}, "rule_type": {"BINARY"}, "identifier": {"abc123"}, "policy": {"ALLOWLIST"}, "duration": {"8h0m0s"},
+	}), "responder-token")
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.True(t, strings.HasPrefix(response.Header().Get("Location"), "/machines/machine-1?message="))
+	require.Len(t, api.created, 1)
+	assert.Equal(t, "abc123", api.created[0].Identifier)
+	assert.Equal(t, "Added in the console by alice@example.com", api.created[0].Description)
+	assert.Equal(t, clock.Y2KTime().Add(8*60*60*1e9), api.created[0].ExpiresAt.UTC())
+
+	// The admin API decides what the caller may do
+	response = serve(server, postForm("/machines/machine-1/rules", url.Values{
+		"csrf": {csrf}, "rule_type": {"BINARY"}, "identifier": {"abc123"}, "policy": {"BLOCKLIST"}, "duration": {"8h0m0s"},
+	}), "responder-token")
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	body, _ := io.ReadAll(response.Body)
+	assert.Contains(t, string(body), "rule-admin")
+
+	// Forms without the CSRF token of the session are refused
+	response = serve(server, postForm("/machines/machine-1/rules", url.Values{
+		"csrf": {"forged"}, "rule_type": {"BINARY"}, "identifier": {"abc123"},
+	}), "responder-token")
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	assert.Len(t, api.created, 1)
+
+	response = serve(server, postForm("/machines/machine-1/rules/remove", url.Values{
+		"csrf": {csrf}, "rule_type": {"BINARY"}, "identifier": {"fed987"},
+	}), "responder-token")
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.Equal(t, []string{"BINARY/fed987"}, api.removed)
+}
+
+func Test_Console_Event(t *testing.T) {
+	server, api := newTestServer(t)
+
+	response := serve(server, httptest.NewRequest(http.MethodGet, "/event/machine-1/abc123", nil), "user-token")
+	require.Equal(t, http.StatusOK, response.Code)
+	body := response.Body.String()
+	assert.Contains(t, body, "<h1>tool</h1>")
+	assert.Contains(t, body, "TEAM:com.example.tool")
+	assert.Contains(t, body, "#help-santa")
+	assert.Contains(t, body, `action="/event/machine-1/abc123/request"`)
+	assert.NotContains(t, body, `href="/machines/machine-1"`, "users without roles cannot see the machine")
+	assert.NotContains(t, body, `href="/unblock-requests"`)
+
+	response = serve(server, postForm("/event/machine-1/abc123/request", url.Values{
+		"csrf": {server.csrfToken("user-token")}, "justification": {" I build this tool "},
+	}), "user-token")
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	assert.True(t, strings.HasPrefix(response.Header().Get("Location"), "/event/machine-1/abc123?message="))
+	require.Len(t, api.requested, 1)
+	assert.Equal(t, adminapi.CreateUnblockRequest{MachineID: "machine-1", FileSHA256: "abc123", Justification: "I build this tool"}, api.requested[0])
+
+	// Once requested, the page shows the request instead of the form
+	response = serve(server, httptest.NewRequest(http.MethodGet, "/event/machine-1/abc123", nil), "user-token")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), "pending")
+	assert.NotContains(t, response.Body.String(), "/request")
+
+	response = serve(server, httptest.NewRequest(http.MethodGet, "/event/machine-2/abc123", nil), "user-token")
+	assert.Equal(t, http.StatusForbidden, response.Code)
+}
+
+func Test_Console_UnblockRequests(t *testing.T) {
+	server, api := newTestServer(t)
+	csrf := server.csrfToken("responder-token")
+
+	response := serve(server, httptest.NewRequest(http.MethodGet, "/unblock-requests", nil), "responder-token")
+	require.Equal(t, http.StatusOK, response.Code)
+	body := response.Body.String()
+	assert.Contains(t, body, "I build this tool")
+	assert.Equal(t, 2, strings.Count(body, `class="decide"`))
+
+	response = serve(server, httptest.NewRequest(http.MethodGet, "/unblock-requests?status=denied", nil), "responder-token")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.NotContains(t, response.Body.String(), `class="decide"`)
+
+	response = serve(server, postForm("/unblock-requests/machine-1/abc123", url.Values{
+		"csrf": {csrf}, "status": {"approved"}, "duration": {"24h0m0s"}, "reason": {"known tool"},
+	}), "responder-token")
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	response = serve(server, postForm("/unblock-requests/machine-1/abc123", url.Values{
+		"csrf": {csrf}, "status": {"denied"},
+	}), "responder-token")
+	assert.Equal(t, http.StatusSeeOther, response.Code)
+	require.Len(t, api.decided, 2)
+	assert.Equal(t, "approved", api.decided[0].Status)
+	assert.Equal(t, "known tool", api.decided[0].Reason)
+	assert.Equal(t, clock.Y2KTime().Add(24*60*60*1e9), api.decided[0].ExpiresAt.UTC())
+	assert.Equal(t, "denied", api.decided[1].Status)
+	assert.Nil(t, api.decided[1].ExpiresAt)
+
+	response = serve(server, postForm("/unblock-requests/machine-1/abc123", url.Values{
+		"csrf": {csrf}, "status": {"approved"},
+	}), "responder-token")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
@@ -0,0 +1,215 @@
+package console
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/machineview"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+const (
+	// searchLimit is how many machines a search lists
+	searchLimit = 50
+	// eventLimit and eventDays bound the events shown for a machine
+	eventLimit = 50
+	eventDays  = 14
+	// staleAfter is how long since the last sync before a machine gets a warning
+	staleAfter = 7 * 24 * time.Hour
+)
+
+// unblockDurations are what responders can choose from; none is longer than rbac.MaxUnblockDuration
+var unblockDurations = []struct {
+	Label    string
+	Duration time.Duration
+}{
+	{"1 hour", time.Hour},
+	{"8 hours", 8 * time.Hour},
+	{"1 day", 24 * time.Hour},
+	{"3 days", 72 * time.Hour},
+	{"7 days", rbac.MaxUnblockDuration},
+}
+
+type searchData struct {
+	Query      string
+	By         string
+	Searched   bool
+	MachineIDs []string
+}
+
+// handleSearch finds machines by machine ID prefix, serial number or primary user, and goes straight to the machine
+// when only one matches
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request, sess *session) {
+	data := searchData{
+		Query: strings.TrimSpace(r.URL.Query().Get("q")),
+		By:    r.URL.Query().Get("by"),
+	}
+	if data.Query != "" {
+		var err error
+		switch data.By {
+		case "serial":
+			data.MachineIDs, err = sess.client.GetMachineIDsFromSerialNumber(data.Query, searchLimit)
+		case "user":
+			data.MachineIDs, err = sess.client.GetMachineIDsFromPrimaryUser(data.Query, searchLimit)
+		default:
+			data.By = "prefix"
+			data.MachineIDs, err = sess.client.GetMachineIDsStartingWith(data.Query, searchLimit)
+		}
+		if err != nil {
+			s.renderError(w, r, err)
+			return
+		}
+		if len(data.MachineIDs) == 1 {
+			http.Redirect(w, r, machinePath(data.MachineIDs[0]), http.StatusFound)
+			return
+		}
+		data.Searched = true
+	}
+	s.render(w, http.StatusOK, "search.html", s.page(r, sess, data))
+}
+
+type machineData struct {
+	Machine   machineview.Machine
+	Events    []eventView
+	RuleTypes []string
+	Durations interface{}
+	// CanUnblock and CanManageRules decide which forms are shown; the admin API checks the roles again either way
+	CanUnblock     bool
+	CanManageRules bool
+}
+
+// eventView is an event, and the identifiers a rule could use to unblock it
+type eventView struct {
+	eventlog.Event
+	Blocked     bool
+	Identifiers []identifierView
+}
+
+type identifierView struct {
+	RuleType   string
+	Identifier string
+}
+
+func (s *Server) handleMachine(w http.ResponseWriter, r *http.Request, sess *session) {
+	machine, err := sess.client.GetMachine(r.PathValue("machine_id"), machineview.Options{
+		StaleAfter:  staleAfter,
+		EventLimit:  eventLimit,
+		EventsSince: s.timeProvider.Now().UTC().AddDate(0, 0, -eventDays),
+	})
+	if err != nil {
+		s.renderError(w, r, err)
+		return
+	}
+
+	roles := sess.roles()
+	data := machineData{
+		Machine:        machine,
+		RuleTypes:      []string{"BINARY", "CERTIFICATE", "SIGNINGID", "TEAMID", "CDHASH"},
+		Durations:      unblockDurations,
+		CanUnblock:     roles.Can(rbac.Unblock),
+		CanManageRules: roles.Can(rbac.ManageMachineRules),
+	}
+	for _, event := range machine.Events {
+		data.Events = append(data.Events, newEventView(event))
+	}
+	s.render(w, http.StatusOK, "machine.html", s.page(r, sess, data))
+}
+
+func newEventView(event eventlog.Event) eventView {
+	view := eventView{Event: event, Blocked: strings.HasPrefix(event.Decision, "BLOCK")}
+	for _, identifier := range []identifierView{
+		{"BINARY", event.FileSHA256},
+		{"CDHASH", event.CDHash},
+		{"SIGNINGID", event.SigningID},
+		{"TEAMID", event.TeamID},
+	} {
+		if identifier.Identifier != "" {
+			view.Identifiers = append(view.Identifiers, identifier)
+		}
+	}
+	return view
+}
+
+// handleCreateRule adds a rule for the machine. Allowlist rules that expire within rbac.MaxUnblockDuration are
+// unblocks, which responders may add; anything else needs a rule admin.
+func (s *Server) handleCreateRule(w http.ResponseWriter, r *http.Request, sess *session) {
+	machineID := r.PathValue("machine_id")
+
+	ruleType, err := adminapi.ParseRuleType(r.PostFormValue("rule_type"))
+	if err != nil {
+		s.render(w, http.StatusBadRequest, "error.html", page{Error: err.Error()})
+		return
+	}
+	policy := types.RulePolicyAllowlist
+	if text := r.PostFormValue("policy"); text != "" {
+		if policy, err = adminapi.ParsePolicy(text); err != nil {
+			s.render(w, http.StatusBadRequest, "error.html", page{Error: err.Error()})
+			return
+		}
+	}
+	identifier := strings.TrimSpace(r.PostFormValue("identifier"))
+	if identifier == "" {
+		s.render(w, http.StatusBadRequest, "error.html", page{Error: "an identifier is required"})
+		return
+	}
+
+	duration, err := time.ParseDuration(r.PostFormValue("duration"))
+	if err != nil || duration <= 0 {
+		s.render(w, http.StatusBadRequest, "error.html", page{Error: "a valid duration is required"})
+		return
+	}
+	expires := s.timeProvider.Now().UTC().Add(duration)
+
+	description := strings.TrimSpace(r.PostFormValue("description"))
+	if description == "" {
+		description = "Added in the console by " + sess.caller.Principal
+	}
+
+	if err = sess.client.AddMachineRule(machineID, identifier, ruleType, policy, description, expires); err != nil {
+		s.renderError(w, r, err)
+		return
+	}
+	redirectWithMessage(w, r, machinePath(machineID), "Added the rule; it applies at the machine's next sync")
+}
+
+func (s *Server) handleRemoveRule(w http.ResponseWriter, r *http.Request, sess *session) {
+	machineID := r.PathValue("machine_id")
+
+	ruleType, err := adminapi.ParseRuleType(r.PostFormValue("rule_type"))
+	if err != nil {
+		s.render(w, http.StatusBadRequest, "error.html", page{Error: err.Error()})
+		return
+	}
+	identifier := r.PostFormValue("identifier")
+
+	if err = sess.client.RemoveMachineRule(machineID, rules.RuleSortKeyFromTypeIdentifier(identifier, ruleType)); err != nil {
+		s.renderError(w, r, err)
+		return
+	}
+	redirectWithMessage(w, r, machinePath(machineID), "Removed the rule; it is deleted from the machine at its next sync")
+}
+
+func (s *Server) page(r *http.Request, sess *session, data interface{}) page {
+	return page{
+		Caller:    sess.caller,
+		CanReview: sess.roles().Can(rbac.View),
+		CSRF:      sess.csrf,
+		Message:   r.URL.Query().Get("message"),
+		Data:      data,
+	}
+}
+
+func machinePath(machineID string) string {
+	return "/machines/" + url.PathEscape(machineID)
+}
+
+// redirectWithMessage follows a form with a GET, so that reloading the page does not submit the form again
+func redirectWithMessage(w http.ResponseWriter, r *http.Request, path string, message string) {
+	http.Redirect(w, r, path+"?message="+url.QueryEscape(message), http.StatusSeeOther)
+}
@@ -0,0 +1,128 @@
+package console
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/model/unblockrequests"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+)
+
+type eventData struct {
+	Block adminapi.Block
+	// Blocked is whether the rules block the binary on the machine now; it may have been unblocked since the event
+	Blocked bool
+	// CanRequest shows the request form, unless a request is already waiting for a decision
+	CanRequest       bool
+	Contact          string
+	MaxJustification int
+	CanViewMachine   bool
+	MachinePath      string
+	EventPath        string
+}
+
+// handleEvent is the page Santa's block dialog opens: what was blocked and why, and a form to ask for an unblock
+func (s *Server) handleEvent(w http.ResponseWriter, r *http.Request, sess *session) {
+	machineID := r.PathValue("machine_id")
+	block, err := sess.client.GetBlock(machineID, r.PathValue("file_sha"))
+	if err != nil {
+		s.renderError(w, r, err)
+		return
+	}
+
+	data := eventData{
+		Block:            block,
+		Blocked:          ruleset.IsBlock(block.Rules.Decision),
+		Contact:          s.contact,
+		MaxJustification: unblockrequests.MaxJustificationLength,
+		CanViewMachine:   sess.roles().Can(rbac.View),
+		MachinePath:      machinePath(machineID),
+		EventPath:        eventPath(machineID, block.Subject.FileSHA256),
+	}
+	data.CanRequest = data.Blocked && (block.UnblockRequest == nil || block.UnblockRequest.Status != unblockrequests.StatusPending)
+	s.render(w, http.StatusOK, "event.html", s.page(r, sess, data))
+}
+
+func (s *Server) handleRequestUnblock(w http.ResponseWriter, r *http.Request, sess *session) {
+	machineID, fileSHA256 := r.PathValue("machine_id"), r.PathValue("file_sha")
+
+	justification := strings.TrimSpace(r.PostFormValue("justification"))
+	if justification == "" {
+		s.render(w, http.StatusBadRequest, "error.html", page{Error: "please say why you need to run the file"})
+		return
+	}
+
+	if _, err := sess.client.RequestUnblock(machineID, fileSHA256, justification); err != nil {
+		s.renderError(w, r, err)
+		return
+	}
+	redirectWithMessage(w, r, eventPath(machineID, fileSHA256), "Requested the unblock; you will see the decision on this page")
+}
+
+type unblockRequestsData struct {
+	Status    string
+	Requests  []unblockrequests.UnblockRequest
+	Durations interface{}
+	Pending   bool
+}
+
+// handleUnblockRequests is the review queue; it lists pending requests unless another status is asked for
+func (s *Server) handleUnblockRequests(w http.ResponseWriter, r *http.Request, sess *session) {
+	status := unblockrequests.Status(r.URL.Query().Get("status"))
+	if status == "" {
+		status = unblockrequests.StatusPending
+	}
+	list, err := sess.client.ListUnblockRequests(status)
+	if err != nil {
+		s.renderError(w, r, err)
+		return
+	}
+
+	data := unblockRequestsData{
+		Status:    string(status),
+		Requests:  list.Requests,
+		Durations: unblockDurations,
+		Pending:   status == unblockrequests.StatusPending && sess.roles().Can(rbac.Unblock),
+	}
+	s.render(w, http.StatusOK, "unblock_requests.html", s.page(r, sess, data))
+}
+
+// handleDecideUnblockRequest approves or denies a request. Approving it allowlists the binary on the machine for the
+// chosen duration, which is never longer than rbac.MaxUnblockDuration.
+func (s *Server) handleDecideUnblockRequest(w http.ResponseWriter, r *http.Request, sess *session) {
+	machineID, fileSHA256 := r.PathValue("machine_id"), r.PathValue("file_sha")
+
+	var expires time.Time
+	status := unblockrequests.Status(r.PostFormValue("status"))
+	switch status {
+	case unblockrequests.StatusApproved:
+		duration, err := time.ParseDuration(r.PostFormValue("duration"))
+		if err != nil || duration <= 0 {
+			s.render(w, http.StatusBadRequest, "error.html", page{Error: "a valid duration is required"})
+			return
+		}
+		expires = s.timeProvider.Now().UTC().Add(duration)
+	case unblockrequests.StatusDenied:
+	default:
+		s.render(w, http.StatusBadRequest, "error.html", page{Error: "the request can only be approved or denied"})
+		return
+	}
+
+	if _, err := sess.client.DecideUnblockRequest(machineID, fileSHA256, status, strings.TrimSpace(r.PostFormValue("reason")), expires); err != nil {
+		s.renderError(w, r, err)
+		return
+	}
+	message := "Denied the request"
+	if status == unblockrequests.StatusApproved {
+		message = "Approved the request; the file is unblocked at the machine's next sync"
+	}
+	redirectWithMessage(w, r, "/unblock-requests", message)
+}
+
+func eventPath(machineID string, fileSHA256 string) string {
+	return "/event/" + url.PathEscape(machineID) + "/" + url.PathEscape(fileSHA256)
+}
@@ -2,47 +2,79 @@ package csv
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"sync"
 )
 
+// Record is a line of a csv file, keyed by the header row. Err is set instead of Data when the line cannot be read.
+type Record struct {
+	// Line is the line number in the file, starting at 1 for the header row
+	Line int
+	Data map[string]string
+	Err  error
+}
+
 // ParseCsvFile takes the full path to a .csv file and parses it. Each line yielded gets fed to an
 // output channel that is expected to "do stuff" with it. The csv file MUST:
 // * have a header row
 // * be valid csv (consistent number of columns per row)
 // * be newline delimited
-func ParseCsvFile(filepath string) (output chan map[string]string, err error) {
-	output = make(chan map[string]string)
-
+// Lines that break these rules are yielded with an error, so that every bad line can be reported.
+func ParseCsvFile(filepath string) (output chan Record, err error) {
 	f, err := os.Open(filepath)
 	if err != nil {
 		err = fmt.Errorf("failed to open file: %s: %w", filepath, err)
 		return
 	}
 
-	reader := csv.NewReader(f)
-	header, err := reader.Read()
+	output, err = ParseCsv(f)
 	if err != nil {
+		f.Close()
 		err = fmt.Errorf("failed to read csv file: %s: %w", filepath, err)
+	}
+	return
+}
+
+// ParseCsv is ParseCsvFile for any reader; the reader is closed once it has been read if it is an io.Closer
+func ParseCsv(r io.Reader) (output chan Record, err error) {
+	reader := csv.NewReader(r)
+	// The number of columns is checked below, so that the line can be reported
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
 		return
 	}
 
+	output = make(chan Record)
 	go func() {
-		defer f.Close() // this needs to be done here, or will result in error reading a closed file
+		lineNumber := 1
+		if closer, ok := r.(io.Closer); ok {
+			defer closer.Close() // this needs to be done here, or will result in error reading a closed file
+		}
 		for {
 			line, err := reader.Read()
 			if err == io.EOF {
 				break // reached EOF
+			}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				output <- Record{Line: parseErr.StartLine, Err: parseErr.Err}
+				continue
 			} else if err != nil {
-				panic(err) // This is likely an unrecoverable error, so just quit
+				// Reading the file itself failed, so there is nothing more to read
+				output <- Record{Line: lineNumber + 1, Err: err}
+				break
 			}
 
+			lineNumber, _ = reader.FieldPos(0)
 			if len(line) != len(header) {
-				// Log the error. consider using a struct with err atttribute, or
-				// list of errors, to track overall errors
-				fmt.Println("[ERROR] incorrect number of columns in line:", line)
+				output <- Record{
+					Line: lineNumber,
+					Err:  fmt.Errorf("incorrect number of columns: expected %d, got %d", len(header), len(line)),
+				}
 				continue
 			}
 			data := make(map[string]string, len(line))
@@ -50,7 +82,7 @@ func ParseCsvFile(filepath string) (output chan map[string]string, err error) {
 				data[header[columnNumber]] = item
 			}
 
-			output <- data
+			output <- Record{Line: lineNumber, Data: data}
 		}
 		close(output) // Calling close here ensures workers exit properly
 	}()
@@ -0,0 +1,140 @@
+package admin
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/model/catalog"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	apiRequest "github.com/airbnb/rudolph/pkg/request"
+	"github.com/airbnb/rudolph/pkg/response"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const blockResource = "/admin/blocks/{machine_id}/{file_sha256}"
+
+// BlocksHandler shows what was blocked on a machine, for the page that Santa's block dialog links to. Unlike the rest
+// of the admin API, callers without any role may use it for their own machines.
+type BlocksHandler struct {
+	booted bool
+	blocks blockService
+}
+
+func (h *BlocksHandler) Boot() (err error) {
+	if h.booted {
+		return
+	}
+
+	h.blocks = concreteBlockService{client: getClient(), timeProvider: clock.ConcreteTimeProvider{}}
+
+	h.booted = true
+	return
+}
+
+func (h *BlocksHandler) Handles(request events.APIGatewayProxyRequest) bool {
+	return matches([]route{{blockResource, []string{http.MethodGet}}}, request)
+}
+
+func (h *BlocksHandler) Handle(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	machineID, errResponse, err := apiRequest.GetMachineID(request)
+	if errResponse != nil || err != nil {
+		return errResponse, err
+	}
+	fileSHA256 := strings.ToLower(request.PathParameters["file_sha256"])
+	if err = types.ValidateSha256(fileSHA256); err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+
+	sensorData, blockEvents, errResponse, err := authorizeMachineOwner(request, h.blocks, machineID, fileSHA256)
+	if errResponse != nil || err != nil {
+		return errResponse, err
+	}
+	if sensorData == nil && len(blockEvents) == 0 {
+		return notFoundResponse("machine " + machineID)
+	}
+
+	block := adminapi.Block{MachineID: machineID, Events: blockEvents}
+	if block.Catalog, err = h.blocks.getCatalogEntry(fileSHA256); err != nil {
+		return internalErrorResponse(err)
+	}
+	block.Subject = subjectOf(fileSHA256, blockEvents, block.Catalog)
+	if block.Rules, err = h.blocks.explain(machineID, block.Subject); err != nil {
+		return internalErrorResponse(err)
+	}
+	unblockRequest, err := h.blocks.getUnblockRequest(machineID, fileSHA256)
+	if err != nil {
+		return internalErrorResponse(err)
+	}
+	if unblockRequest != nil {
+		block.UnblockRequest = &unblockRequest.UnblockRequest
+	}
+	return response.APIResponse(http.StatusOK, block)
+}
+
+// authorizeMachineOwner lets callers who may view everything through, and otherwise only the owner of the machine. It
+// responds with a 403 to anyone else, and returns what it read about the machine either way.
+func authorizeMachineOwner(request events.APIGatewayProxyRequest, blocks blockService, machineID string, fileSHA256 string) (sensorData *sensordata.SensorData, blockEvents []eventlog.Event, errResponse *events.APIGatewayProxyResponse, err error) {
+	if sensorData, err = blocks.getSensorData(machineID); err != nil {
+		errResponse, err = internalErrorResponse(err)
+		return
+	}
+	if blockEvents, err = blocks.getEvents(machineID, fileSHA256); err != nil {
+		errResponse, err = internalErrorResponse(err)
+		return
+	}
+	if roles(request).Can(rbac.View) {
+		return
+	}
+
+	caller := principal(request)
+	if sensorData != nil && isMachineOwner(caller, sensorData.PrimaryUser) {
+		return
+	}
+
+	slog.Warn("Admin API denied",
+		"audit", true,
+		"principal", caller,
+		"reason", "not the owner of the machine",
+		"method", request.HTTPMethod,
+		"resource", request.Resource,
+		"machine_id", machineID,
+	)
+	errResponse, err = errorResponse(http.StatusForbidden, errors.New("only the owner of a machine may see what was blocked on it"))
+	return
+}
+
+// isMachineOwner reports whether the caller owns the machine whose primary user is given. The primary user is Santa's
+// MachineOwner, which is set by MDM, so it has to be the caller's full principal, exactly. Local user names, and the
+// users that events report, are not trusted: anyone can create a local user with any name.
+func isMachineOwner(caller string, primaryUser string) bool {
+	return caller != "" && caller == primaryUser
+}
+
+// subjectOf reads the identifiers of the binary from its newest event, or else from its catalog entry
+func subjectOf(fileSHA256 string, blockEvents []eventlog.Event, entry *catalog.CatalogEntry) ruleset.Subject {
+	subject := ruleset.Subject{FileSHA256: fileSHA256}
+	if len(blockEvents) > 0 {
+		event := blockEvents[0]
+		subject.CDHash = event.CDHash
+		subject.SigningID = event.SigningID
+		subject.TeamID = event.TeamID
+		if len(event.SigningChain) > 0 {
+			subject.CertificateSHA256 = event.SigningChain[0].SHA256
+		}
+		return subject
+	}
+	if entry != nil {
+		subject.SigningID = entry.SigningID
+		subject.TeamID = entry.TeamID
+		subject.CertificateSHA256 = entry.CertificateSHA256
+	}
+	return subject
+}
@@ -0,0 +1,141 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	apiRequest "github.com/airbnb/rudolph/pkg/request"
+	"github.com/airbnb/rudolph/pkg/response"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	globalConfigResource  = "/admin/config"
+	machineConfigResource = "/admin/machines/{machine_id}/config"
+)
+
+// ConfigHandler manages the global config and the configs of single machines
+type ConfigHandler struct {
+	booted  bool
+	configs configService
+}
+
+func (h *ConfigHandler) Boot() (err error) {
+	if h.booted {
+		return
+	}
+
+	client := getClient()
+	timeProvider := clock.ConcreteTimeProvider{}
+	h.configs = concreteConfigService{
+		client:       client,
+		timeProvider: timeProvider,
+		service:      machineconfiguration.GetUncachedMachineConfigurationService(client, timeProvider),
+	}
+
+	h.booted = true
+	return
+}
+
+func (h *ConfigHandler) Handles(request events.APIGatewayProxyRequest) bool {
+	methods := []string{http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	return matches([]route{
+		{globalConfigResource, methods},
+		{machineConfigResource, methods},
+	}, request)
+}
+
+func (h *ConfigHandler) Handle(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	permission := rbac.ManageConfigs
+	if request.HTTPMethod == http.MethodGet {
+		permission = rbac.View
+	}
+	if errResponse, err := authorize(request, permission); errResponse != nil || err != nil {
+		return errResponse, err
+	}
+
+	// An empty machine ID is the global config
+	var machineID string
+	target := "global"
+	if request.Resource == machineConfigResource {
+		var errResponse *events.APIGatewayProxyResponse
+		var err error
+		machineID, errResponse, err = apiRequest.GetMachineID(request)
+		if errResponse != nil || err != nil {
+			return errResponse, err
+		}
+		target = machineID
+	}
+
+	var previous machineconfiguration.MachineConfiguration
+	if request.HTTPMethod != http.MethodGet {
+		var err error
+		if previous, _, err = h.configs.getIntendedConfig(machineID); err != nil {
+			return internalErrorResponse(err)
+		}
+	}
+
+	switch request.HTTPMethod {
+	case http.MethodPut:
+		var body adminapi.Config
+		if err := decodeBody(request, &body); err != nil {
+			return errorResponse(http.StatusBadRequest, err)
+		}
+		config, err := body.MachineConfiguration()
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, err)
+		}
+		if err = h.configs.setConfig(machineID, config); err != nil {
+			return configErrorResponse(err)
+		}
+		audit(request, "set_config", target, "client_mode", body.ClientMode)
+
+	case http.MethodPatch:
+		var body adminapi.UpdateConfig
+		if err := decodeBody(request, &body); err != nil {
+			return errorResponse(http.StatusBadRequest, err)
+		}
+		updateRequest, err := body.UpdateRequest()
+		if err != nil {
+			return errorResponse(http.StatusBadRequest, err)
+		}
+		if _, err = h.configs.updateConfig(machineID, updateRequest); err != nil {
+			return configErrorResponse(err)
+		}
+		audit(request, "update_config", target)
+
+	case http.MethodDelete:
+		if err := h.configs.deleteConfig(machineID); err != nil {
+			return internalErrorResponse(err)
+		}
+		audit(request, "delete_config", target)
+	}
+
+	// Every method responds with the config that is now intended, and where it comes from
+	config, source, err := h.configs.getIntendedConfig(machineID)
+	if err != nil {
+		return internalErrorResponse(err)
+	}
+
+	if machineID != "" && request.HTTPMethod != http.MethodGet && config.ClientMode != previous.ClientMode {
+		if err = h.configs.recordTransition(machineID, previous.ClientMode, config.ClientMode, "admin API, by "+principal(request)); err != nil {
+			return internalErrorResponse(fmt.Errorf("config was changed, but the transition could not be recorded: %w", err))
+		}
+	}
+	return response.APIResponse(http.StatusOK, adminapi.IntendedConfig{
+		Source: source,
+		Config: adminapi.NewConfig(config),
+	})
+}
+
+func configErrorResponse(err error) (*events.APIGatewayProxyResponse, error) {
+	if errors.Is(err, machineconfiguration.ErrGlobalLockdownDisabled) {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	return internalErrorResponse(err)
+}
@@ -0,0 +1,639 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/inventory"
+	"github.com/airbnb/rudolph/pkg/machineview"
+	"github.com/airbnb/rudolph/pkg/model/catalog"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/model/unblockrequests"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testMachineID = "AAAAAAAA-A00A-1234-1234-5864377B4831"
+	testTeamID    = "EQHXZ8M8AV"
+	testSHA256    = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+)
+
+var testNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func adminRequest(method string, resource string, pathParameters map[string]string, body string) events.APIGatewayProxyRequest {
+	return events.APIGatewayProxyRequest{
+		HTTPMethod:     method,
+		Resource:       resource,
+		PathParameters: pathParameters,
+		Body:           body,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Authorizer: map[string]interface{}{"principal": "alice@example.com", "roles": "rule-admin,fleet-admin"},
+		},
+	}
+}
+
+func withRoles(request events.APIGatewayProxyRequest, roles ...rbac.Role) events.APIGatewayProxyRequest {
+	request.RequestContext.Authorizer = map[string]interface{}{"principal": "bob@example.com", "roles": rbac.Roles(roles).String()}
+	return request
+}
+
+func withQuery(request events.APIGatewayProxyRequest, query map[string]string) events.APIGatewayProxyRequest {
+	request.QueryStringParameters = query
+	return request
+}
+
+func decodeResponse(t *testing.T, resp *events.APIGatewayProxyResponse, out interface{}) {
+	require.NoError(t, json.Unmarshal([]byte(resp.Body), out))
+}
+
+// mockGlobalRules keeps global rules in memory, by sort key
+type mockGlobalRules struct {
+	rows           map[string]globalrules.GlobalRuleRow
+	idempotencyKey string
+}
+
+func (m *mockGlobalRules) listGlobalRules(limit int, after string) ([]globalrules.GlobalRuleRow, string, error) {
+	var rows []globalrules.GlobalRuleRow
+	for _, row := range m.rows {
+		rows = append(rows, row)
+	}
+	return rows, "", nil
+}
+
+func (m *mockGlobalRules) getGlobalRule(identifier string, ruleType types.RuleType) (*globalrules.GlobalRuleRow, error) {
+	row, ok := m.rows[rules.RuleSortKeyFromTypeIdentifier(identifier, ruleType)]
+	if !ok {
+		return nil, nil
+	}
+	return &row, nil
+}
+
+func (m *mockGlobalRules) putGlobalRule(rule rules.SantaRule, description string) error {
+	m.rows[rules.RuleSortKeyFromTypeIdentifier(rule.Identifier, rule.RuleType)] = globalrules.GlobalRuleRow{SantaRule: rule, Description: description}
+	return nil
+}
+
+func (m *mockGlobalRules) updateGlobalRule(identifier string, ruleType types.RuleType, policy types.Policy) error {
+	sortKey := rules.RuleSortKeyFromTypeIdentifier(identifier, ruleType)
+	row := m.rows[sortKey]
+	row.Policy = policy
+	m.rows[sortKey] = row
+	return nil
+}
+
+func (m *mockGlobalRules) removeGlobalRule(sortKey string, idempotencyKey string) error {
+	delete(m.rows, sortKey)
+	m.idempotencyKey = idempotencyKey
+	return nil
+}
+
+var _ globalRulesService = &mockGlobalRules{}
+
+func Test_RulesHandler_Handles(t *testing.T) {
+	h := &RulesHandler{}
+	assert.True(t, h.Handles(adminRequest(http.MethodPost, rulesResource, nil, "")))
+	assert.True(t, h.Handles(adminRequest(http.MethodDelete, ruleResource, nil, "")))
+	assert.False(t, h.Handles(adminRequest(http.MethodDelete, rulesResource, nil, "")))
+	assert.False(t, h.Handles(adminRequest(http.MethodGet, "/preflight/{machine_id}", nil, "")))
+}
+
+func Test_RulesHandler_CreateGetUpdateRemove(t *testing.T) {
+	service := &mockGlobalRules{rows: map[string]globalrules.GlobalRuleRow{}}
+	h := &RulesHandler{booted: true, rules: service}
+	path := map[string]string{"rule_type": "teamid", "identifier": testTeamID}
+
+	resp, err := h.Handle(adminRequest(http.MethodPost, rulesResource, nil, `{"rule_type":"teamid","policy":"allowlist","identifier":"EQHXZ8M8AV","description":"Google"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, types.RulePolicyAllowlist, service.rows["TeamID#"+testTeamID].Policy)
+
+	resp, err = h.Handle(adminRequest(http.MethodGet, ruleResource, path, ""))
+	require.NoError(t, err)
+	var rule adminapi.Rule
+	decodeResponse(t, resp, &rule)
+	assert.Equal(t, adminapi.Rule{RuleType: "TEAMID", Policy: "ALLOWLIST", Identifier: testTeamID, Description: "Google"}, rule)
+
+	resp, err = h.Handle(adminRequest(http.MethodPut, ruleResource, path, `{"policy":"blocklist"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, types.RulePolicyBlocklist, service.rows["TeamID#"+testTeamID].Policy)
+
+	request := adminRequest(http.MethodDelete, ruleResource, path, "")
+	request.Headers = map[string]string{"idempotency-key": "retry-1"}
+	resp, err = h.Handle(request)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, service.rows)
+	assert.Equal(t, "retry-1", service.idempotencyKey)
+
+	resp, err = h.Handle(adminRequest(http.MethodGet, ruleResource, path, ""))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func Test_RulesHandler_Roles(t *testing.T) {
+	service := &mockGlobalRules{rows: map[string]globalrules.GlobalRuleRow{}}
+	h := &RulesHandler{booted: true, rules: service}
+	create := adminRequest(http.MethodPost, rulesResource, nil, `{"rule_type":"teamid","policy":"allowlist","identifier":"EQHXZ8M8AV"}`)
+
+	for _, role := range []rbac.Role{rbac.Viewer, rbac.Responder, rbac.FleetAdmin} {
+		resp, err := h.Handle(withRoles(adminRequest(http.MethodGet, rulesResource, nil, ""), role))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = h.Handle(withRoles(create, role))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, role)
+	}
+	assert.Empty(t, service.rows)
+
+	// Requests that come without roles can do nothing
+	resp, err := h.Handle(withRoles(adminRequest(http.MethodGet, rulesResource, nil, "")))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	var body adminapi.ErrorResponse
+	decodeResponse(t, resp, &body)
+	assert.Equal(t, "this requires one of the roles viewer,responder,rule-admin,fleet-admin", body.Error)
+}
+
+func Test_RulesHandler_InvalidRequests(t *testing.T) {
+	h := &RulesHandler{booted: true, rules: &mockGlobalRules{rows: map[string]globalrules.GlobalRuleRow{}}}
+
+	tests := []struct {
+		name    string
+		request events.APIGatewayProxyRequest
+	}{
+		{"no body", adminRequest(http.MethodPost, rulesResource, nil, "")},
+		{"unknown field", adminRequest(http.MethodPost, rulesResource, nil, `{"rule_type":"teamid","policy":"allowlist","identifier":"EQHXZ8M8AV","polcy":"x"}`)},
+		{"invalid rule type", adminRequest(http.MethodPost, rulesResource, nil, `{"rule_type":"hash","policy":"allowlist","identifier":"EQHXZ8M8AV"}`)},
+		{"invalid policy", adminRequest(http.MethodPost, rulesResource, nil, `{"rule_type":"teamid","policy":"maybe","identifier":"EQHXZ8M8AV"}`)},
+		{"invalid identifier", adminRequest(http.MethodPost, rulesResource, nil, `{"rule_type":"binary","policy":"allowlist","identifier":"not-a-sha"}`)},
+		{"invalid limit", withQuery(adminRequest(http.MethodGet, rulesResource, nil, ""), map[string]string{"limit": "5000"})},
+		{"invalid cursor", withQuery(adminRequest(http.MethodGet, rulesResource, nil, ""), map[string]string{"after": "Nope#1"})},
+		{"invalid path rule type", adminRequest(http.MethodGet, ruleResource, map[string]string{"rule_type": "hash", "identifier": testTeamID}, "")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := h.Handle(test.request)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
+
+// mockMachineRules keeps the rules of machines in memory
+type mockMachineRules struct {
+	machinerules.MachineRulesService
+	rows    map[string]machinerules.MachineRuleRow
+	removed []string
+}
+
+func (m *mockMachineRules) Get(machineID string, identifier string, ruleType types.RuleType) (*machinerules.MachineRuleRow, error) {
+	row, ok := m.rows[rules.RuleSortKeyFromTypeIdentifier(identifier, ruleType)]
+	if !ok {
+		return nil, nil
+	}
+	return &row, nil
+}
+
+func (m *mockMachineRules) Add(machineID string, identifier string, ruleType types.RuleType, policy types.Policy, description string, expires time.Time) error {
+	row, err := machinerules.NewMachineRuleRow(machineID, identifier, ruleType, policy, description, expires)
+	m.rows[row.SortKey] = row
+	return err
+}
+
+func (m *mockMachineRules) Update(machineID string, identifier string, ruleType types.RuleType, policy types.Policy, expires time.Time) error {
+	sortKey := rules.RuleSortKeyFromTypeIdentifier(identifier, ruleType)
+	row := m.rows[sortKey]
+	row.Policy = policy
+	row.ExpiresAfter = expires.Unix()
+	m.rows[sortKey] = row
+	return nil
+}
+
+func (m *mockMachineRules) GetMachineRules(machineID string) (*[]machinerules.MachineRuleRow, error) {
+	var rows []machinerules.MachineRuleRow
+	for _, row := range m.rows {
+		rows = append(rows, row)
+	}
+	return &rows, nil
+}
+
+func (m *mockMachineRules) RemoveBySortKey(machineID string, sortKey string) error {
+	m.removed = append(m.removed, sortKey)
+	return nil
+}
+
+func Test_MachineRulesHandler_CreateDefaultsExpiry(t *testing.T) {
+	service := &mockMachineRules{rows: map[string]machinerules.MachineRuleRow{}}
+	h := &MachineRulesHandler{booted: true, rules: service, timeProvider: clock.FrozenTimeProvider{Current: testNow}}
+
+	resp, err := h.Handle(adminRequest(
+		http.MethodPost,
+		machineRulesResource,
+		map[string]string{"machine_id": testMachineID},
+		`{"rule_type":"binary","policy":"allowlist","identifier":"`+testSHA256+`"}`,
+	))
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	var rule adminapi.MachineRule
+	decodeResponse(t, resp, &rule)
+	assert.Equal(t, testMachineID, rule.MachineID)
+	assert.Equal(t, testNow.Add(adminapi.DefaultMachineRuleExpiration), rule.ExpiresAt)
+	assert.Contains(t, service.rows, "Binary#"+testSHA256)
+}
+
+func Test_MachineRulesHandler_UpdateAndRemove(t *testing.T) {
+	service := &mockMachineRules{rows: map[string]machinerules.MachineRuleRow{}}
+	require.NoError(t, service.Add(testMachineID, testSHA256, types.RuleTypeBinary, types.RulePolicyAllowlist, "", testNow.Add(time.Hour)))
+	h := &MachineRulesHandler{booted: true, rules: service, timeProvider: clock.FrozenTimeProvider{Current: testNow}}
+	path := map[string]string{"machine_id": testMachineID, "rule_type": "binary", "identifier": testSHA256}
+
+	resp, err := h.Handle(adminRequest(http.MethodPut, machineRuleResource, path, `{"expires_at":"2024-03-01T11:00:00Z"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = h.Handle(adminRequest(http.MethodPut, machineRuleResource, path, `{"expires_at":"2024-03-02T12:00:00Z"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, types.RulePolicyAllowlist, service.rows["Binary#"+testSHA256].Policy)
+	assert.Equal(t, testNow.Add(24*time.Hour).Unix(), service.rows["Binary#"+testSHA256].ExpiresAfter)
+
+	resp, err = h.Handle(adminRequest(http.MethodDelete, machineRuleResource, path, ""))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"Binary#" + testSHA256}, service.removed)
+}
+
+func Test_MachineRulesHandler_Responder(t *testing.T) {
+	service := &mockMachineRules{rows: map[string]machinerules.MachineRuleRow{}}
+	require.NoError(t, service.Add(testMachineID, testTeamID, types.RuleTypeTeamID, types.RulePolicyBlocklist, "", testNow.Add(time.Hour)))
+	h := &MachineRulesHandler{booted: true, rules: service, timeProvider: clock.FrozenTimeProvider{Current: testNow}}
+	rulesPath := map[string]string{"machine_id": testMachineID}
+	binaryPath := map[string]string{"machine_id": testMachineID, "rule_type": "binary", "identifier": testSHA256}
+	teamPath := map[string]string{"machine_id": testMachineID, "rule_type": "teamid", "identifier": testTeamID}
+
+	tests := []struct {
+		name     string
+		request  events.APIGatewayProxyRequest
+		expected int
+	}{
+		{"list", adminRequest(http.MethodGet, machineRulesResource, rulesPath, ""), http.StatusOK},
+		{"blocklist", adminRequest(http.MethodPost, machineRulesResource, rulesPath, `{"rule_type":"binary","policy":"blocklist","identifier":"`+testSHA256+`"}`), http.StatusForbidden},
+		{"long unblock", adminRequest(http.MethodPost, machineRulesResource, rulesPath, `{"rule_type":"binary","policy":"allowlist","identifier":"`+testSHA256+`","expires_at":"2024-03-30T12:00:00Z"}`), http.StatusForbidden},
+		{"unblock", adminRequest(http.MethodPost, machineRulesResource, rulesPath, `{"rule_type":"binary","policy":"allowlist","identifier":"`+testSHA256+`"}`), http.StatusCreated},
+		{"extend unblock", adminRequest(http.MethodPut, machineRuleResource, binaryPath, `{"expires_at":"2024-03-04T12:00:00Z"}`), http.StatusOK},
+		{"remove unblock", adminRequest(http.MethodDelete, machineRuleResource, binaryPath, ""), http.StatusOK},
+		{"remove block", adminRequest(http.MethodDelete, machineRuleResource, teamPath, ""), http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := h.Handle(withRoles(test.request, rbac.Responder))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, resp.StatusCode, resp.Body)
+		})
+	}
+	assert.Equal(t, []string{"Binary#" + testSHA256}, service.removed)
+
+	// Viewers cannot unblock at all
+	resp, err := h.Handle(withRoles(tests[3].request, rbac.Viewer))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func Test_MachineRulesHandler_InvalidMachineID(t *testing.T) {
+	h := &MachineRulesHandler{booted: true, rules: &mockMachineRules{}, timeProvider: clock.FrozenTimeProvider{Current: testNow}}
+
+	resp, err := h.Handle(adminRequest(http.MethodGet, machineRulesResource, map[string]string{"machine_id": "nope"}, ""))
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// mockConfigs keeps configs in memory; the empty machine ID is the global config
+type mockConfigs struct {
+	configs     map[string]machineconfiguration.MachineConfiguration
+	transitions []string
+}
+
+func (m *mockConfigs) getIntendedConfig(machineID string) (machineconfiguration.MachineConfiguration, adminapi.ConfigSource, error) {
+	if config, ok := m.configs[machineID]; ok && machineID != "" {
+		return config, adminapi.ConfigSourceMachine, nil
+	}
+	if config, ok := m.configs[""]; ok {
+		return config, adminapi.ConfigSourceGlobal, nil
+	}
+	return machineconfiguration.GetUniversalDefaultConfig(), adminapi.ConfigSourceDefault, nil
+}
+
+func (m *mockConfigs) setConfig(machineID string, config machineconfiguration.MachineConfiguration) error {
+	if machineID == "" && config.ClientMode == types.Lockdown {
+		return machineconfiguration.ErrGlobalLockdownDisabled
+	}
+	m.configs[machineID] = config
+	return nil
+}
+
+func (m *mockConfigs) updateConfig(machineID string, request machineconfiguration.MachineConfigurationUpdateRequest) (*machineconfiguration.MachineConfiguration, error) {
+	config, _, _ := m.getIntendedConfig(machineID)
+	if request.ClientMode != nil {
+		config.ClientMode = *request.ClientMode
+	}
+	m.configs[machineID] = config
+	return &config, nil
+}
+
+func (m *mockConfigs) deleteConfig(machineID string) error {
+	delete(m.configs, machineID)
+	return nil
+}
+
+func (m *mockConfigs) recordTransition(machineID string, from types.ClientMode, to types.ClientMode, reason string) error {
+	fromText, _ := from.MarshalText()
+	toText, _ := to.MarshalText()
+	m.transitions = append(m.transitions, string(fromText)+" -> "+string(toText)+" "+reason)
+	return nil
+}
+
+var _ configService = &mockConfigs{}
+
+func Test_ConfigHandler(t *testing.T) {
+	service := &mockConfigs{configs: map[string]machineconfiguration.MachineConfiguration{}}
+	h := &ConfigHandler{booted: true, configs: service}
+	machinePath := map[string]string{"machine_id": testMachineID}
+
+	resp, err := h.Handle(adminRequest(http.MethodGet, machineConfigResource, machinePath, ""))
+	require.NoError(t, err)
+	var intended adminapi.IntendedConfig
+	decodeResponse(t, resp, &intended)
+	assert.Equal(t, adminapi.ConfigSourceDefault, intended.Source)
+
+	resp, err = h.Handle(adminRequest(http.MethodPatch, machineConfigResource, machinePath, `{"client_mode":"lockdown"}`))
+	require.NoError(t, err)
+	decodeResponse(t, resp, &intended)
+	assert.Equal(t, adminapi.ConfigSourceMachine, intended.Source)
+	assert.Equal(t, "LOCKDOWN", intended.Config.ClientMode)
+	assert.Equal(t, []string{"MONITOR -> LOCKDOWN admin API, by alice@example.com"}, service.transitions)
+
+	resp, err = h.Handle(adminRequest(http.MethodPut, globalConfigResource, nil, `{"client_mode":"lockdown","batch_size":50}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = h.Handle(adminRequest(http.MethodPut, globalConfigResource, nil, `{"client_mode":"monitor","batch_size":0}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = h.Handle(adminRequest(http.MethodDelete, machineConfigResource, machinePath, ""))
+	require.NoError(t, err)
+	decodeResponse(t, resp, &intended)
+	assert.Equal(t, adminapi.ConfigSourceDefault, intended.Source)
+	assert.Len(t, service.transitions, 2)
+}
+
+func Test_ConfigHandler_Roles(t *testing.T) {
+	service := &mockConfigs{configs: map[string]machineconfiguration.MachineConfiguration{}}
+	h := &ConfigHandler{booted: true, configs: service}
+
+	for _, role := range []rbac.Role{rbac.Viewer, rbac.Responder, rbac.RuleAdmin} {
+		resp, err := h.Handle(withRoles(adminRequest(http.MethodGet, globalConfigResource, nil, ""), role))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = h.Handle(withRoles(adminRequest(http.MethodPatch, globalConfigResource, nil, `{"client_mode":"monitor"}`), role))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, role)
+	}
+	assert.Empty(t, service.configs)
+
+	resp, err := h.Handle(withRoles(adminRequest(http.MethodPatch, globalConfigResource, nil, `{"client_mode":"monitor"}`), rbac.FleetAdmin))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+type mockMachines struct {
+	lookups map[string]string
+}
+
+func (m mockMachines) GetMachineIDsStartingWith(prefix string, limit int32) ([]string, error) {
+	m.lookups["prefix"] = prefix
+	return []string{testMachineID}, nil
+}
+
+func (m mockMachines) GetMachineIDsFromSerialNumber(serialNumber string, limit int32) ([]string, error) {
+	m.lookups["serial_num"] = serialNumber
+	return nil, nil
+}
+
+func (m mockMachines) GetMachineIDsFromPrimaryUser(primaryUser string, limit int32) ([]string, error) {
+	m.lookups["primary_user"] = primaryUser
+	return nil, nil
+}
+
+func (m mockMachines) listMachines(options inventory.Options) (inventory.Page, error) {
+	return inventory.Page{Machines: []inventory.Machine{}}, nil
+}
+
+func (m mockMachines) loadMachine(machineID string, options machineview.Options) (machineview.Machine, error) {
+	return machineview.Machine{MachineID: machineID}, nil
+}
+
+func Test_MachinesHandler_Lookup(t *testing.T) {
+	service := mockMachines{lookups: map[string]string{}}
+	h := &MachinesHandler{booted: true, machines: service}
+
+	request := adminRequest(http.MethodGet, machineLookupResource, nil, "")
+	request.QueryStringParameters = map[string]string{"serial_num": "C02XXXXXXXXX"}
+	resp, err := h.Handle(request)
+	require.NoError(t, err)
+	assert.Equal(t, `{"machine_ids":[]}`, resp.Body)
+	assert.Equal(t, map[string]string{"serial_num": "C02XXXXXXXXX"}, service.lookups)
+
+	request.QueryStringParameters = map[string]string{"serial_num": "C02XXXXXXXXX", "prefix": "AAAA"}
+	resp, err = h.Handle(request)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func Test_MachinesHandler_ListRejectsInvalidQuery(t *testing.T) {
+	h := &MachinesHandler{booted: true, machines: mockMachines{}}
+
+	request := adminRequest(http.MethodGet, machinesResource, nil, "")
+	request.QueryStringParameters = map[string]string{"sort": "os_version", "after": testMachineID}
+	resp, err := h.Handle(request)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func Test_WhoAmIHandler(t *testing.T) {
+	h := &WhoAmIHandler{}
+
+	resp, err := h.Handle(adminRequest(http.MethodGet, whoAmIResource, nil, ""))
+	require.NoError(t, err)
+	assert.Equal(t, `{"principal":"alice@example.com","roles":["rule-admin","fleet-admin"]}`, resp.Body)
+
+	resp, err = h.Handle(withRoles(adminRequest(http.MethodGet, whoAmIResource, nil, "")))
+	require.NoError(t, err)
+	assert.Equal(t, `{"principal":"bob@example.com","roles":[]}`, resp.Body)
+}
+
+// mockBlocks knows one machine of bob's, which blocked the binary testSHA256
+type mockBlocks struct {
+	requests map[string]unblockrequests.UnblockRequestRow
+}
+
+func (m *mockBlocks) getSensorData(machineID string) (*sensordata.SensorData, error) {
+	if machineID != testMachineID {
+		return nil, nil
+	}
+	return &sensordata.SensorData{MachineID: machineID, PrimaryUser: "bob@example.com"}, nil
+}
+
+func (m *mockBlocks) getEvents(machineID string, fileSHA256 string) ([]eventlog.Event, error) {
+	if machineID != testMachineID || fileSHA256 != testSHA256 {
+		return nil, nil
+	}
+	return []eventlog.Event{{Decision: "BLOCK_BINARY", FileName: "tool", FileSHA256: fileSHA256, TeamID: testTeamID, ExecutingUser: "bob"}}, nil
+}
+
+func (m *mockBlocks) getCatalogEntry(fileSHA256 string) (*catalog.CatalogEntry, error) {
+	return nil, nil
+}
+
+func (m *mockBlocks) explain(machineID string, subject ruleset.Subject) (ruleset.Explanation, error) {
+	return ruleset.Explanation{Decision: "BLOCK_BINARY", ClientMode: types.Lockdown}, nil
+}
+
+func (m *mockBlocks) getUnblockRequest(machineID string, fileSHA256 string) (*unblockrequests.UnblockRequestRow, error) {
+	row, ok := m.requests[machineID+"#"+fileSHA256]
+	if !ok {
+		return nil, nil
+	}
+	return &row, nil
+}
+
+func (m *mockBlocks) listUnblockRequests(status unblockrequests.Status) ([]unblockrequests.UnblockRequestRow, error) {
+	var rows []unblockrequests.UnblockRequestRow
+	for _, row := range m.requests {
+		if status == "" || row.Status == status {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockBlocks) fileUnblockRequest(request unblockrequests.UnblockRequest) (unblockrequests.UnblockRequestRow, error) {
+	request.Status = unblockrequests.StatusPending
+	row := unblockrequests.UnblockRequestRow{UnblockRequest: request}
+	m.requests[request.MachineID+"#"+request.FileSHA256] = row
+	return row, nil
+}
+
+func (m *mockBlocks) decideUnblockRequest(row unblockrequests.UnblockRequestRow, status unblockrequests.Status, decidedBy string, reason string) (unblockrequests.UnblockRequestRow, error) {
+	row.Status, row.DecidedBy, row.Reason = status, decidedBy, reason
+	m.requests[row.MachineID+"#"+row.FileSHA256] = row
+	return row, nil
+}
+
+func Test_BlocksHandler(t *testing.T) {
+	h := &BlocksHandler{booted: true, blocks: &mockBlocks{}}
+	path := map[string]string{"machine_id": testMachineID, "file_sha256": testSHA256}
+
+	// bob has no roles, but the machine is his
+	resp, err := h.Handle(withRoles(adminRequest(http.MethodGet, blockResource, path, "")))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+	var block adminapi.Block
+	decodeResponse(t, resp, &block)
+	assert.Equal(t, testTeamID, block.Subject.TeamID)
+	assert.Equal(t, "BLOCK_BINARY", block.Rules.Decision)
+	assert.Len(t, block.Events, 1)
+
+	// alice has no roles that view everything, and the machine is not hers
+	request := adminRequest(http.MethodGet, blockResource, path, "")
+	request.RequestContext.Authorizer["roles"] = ""
+	resp, err = h.Handle(request)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// Running the binary on the machine does not make a user its owner
+	request = withRoles(adminRequest(http.MethodGet, blockResource, path, ""))
+	request.RequestContext.Authorizer["principal"] = "bob"
+	resp, err = h.Handle(request)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// Viewers see every machine
+	resp, err = h.Handle(withRoles(adminRequest(http.MethodGet, blockResource, map[string]string{"machine_id": "BBBBBBBB-A00A-1234-1234-5864377B4831", "file_sha256": testSHA256}, ""), rbac.Viewer))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func Test_IsMachineOwner(t *testing.T) {
+	assert.True(t, isMachineOwner("bob@example.com", "bob@example.com"))
+	assert.False(t, isMachineOwner("Bob@example.com", "bob@example.com"))
+	assert.False(t, isMachineOwner("bob@example.com", "bob"))
+	assert.False(t, isMachineOwner("bob@example.com", "bob@example.org"))
+	assert.False(t, isMachineOwner("", ""))
+}
+
+func Test_UnblockRequestsHandler(t *testing.T) {
+	blocks := &mockBlocks{requests: map[string]unblockrequests.UnblockRequestRow{}}
+	machineRules := &mockMachineRules{rows: map[string]machinerules.MachineRuleRow{}}
+	h := &UnblockRequestsHandler{booted: true, blocks: blocks, rules: machineRules, timeProvider: clock.FrozenTimeProvider{Current: testNow}}
+	path := map[string]string{"machine_id": testMachineID, "file_sha256": testSHA256}
+
+	resp, err := h.Handle(withRoles(adminRequest(http.MethodPost, unblockRequestsResource, nil, `{"machine_id":"`+testMachineID+`","file_sha256":"`+testSHA256+`","justification":"I build this tool"}`)))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, resp.Body)
+	var filed unblockrequests.UnblockRequest
+	decodeResponse(t, resp, &filed)
+	assert.Equal(t, "bob@example.com", filed.Requester)
+	assert.Equal(t, "tool", filed.FileName)
+
+	// Only responders see the queue and decide
+	resp, err = h.Handle(withRoles(adminRequest(http.MethodGet, unblockRequestsResource, nil, "")))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp, err = h.Handle(withRoles(adminRequest(http.MethodPut, unblockRequestResource, path, `{"status":"approved"}`), rbac.Viewer))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, err = h.Handle(withRoles(withQuery(adminRequest(http.MethodGet, unblockRequestsResource, nil, ""), map[string]string{"status": "pending"}), rbac.Responder))
+	require.NoError(t, err)
+	var list adminapi.UnblockRequestList
+	decodeResponse(t, resp, &list)
+	assert.Len(t, list.Requests, 1)
+
+	// Responders cannot approve for longer than an unblock
+	resp, err = h.Handle(withRoles(adminRequest(http.MethodPut, unblockRequestResource, path, `{"status":"approved","expires_at":"2024-03-30T12:00:00Z"}`), rbac.Responder))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, err = h.Handle(withRoles(adminRequest(http.MethodPut, unblockRequestResource, path, `{"status":"approved","reason":"known tool"}`), rbac.Responder))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, resp.Body)
+	assert.Equal(t, unblockrequests.StatusApproved, blocks.requests[testMachineID+"#"+testSHA256].Status)
+	rule := machineRules.rows["Binary#"+testSHA256]
+	assert.Equal(t, types.RulePolicyAllowlist, rule.Policy)
+	assert.Equal(t, testNow.Add(adminapi.DefaultMachineRuleExpiration).Unix(), rule.ExpiresAfter)
+
+	resp, err = h.Handle(withRoles(adminRequest(http.MethodPut, unblockRequestResource, path, `{"status":"denied"}`), rbac.Responder))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+}
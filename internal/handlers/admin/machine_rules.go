@@ -0,0 +1,207 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	apiRequest "github.com/airbnb/rudolph/pkg/request"
+	"github.com/airbnb/rudolph/pkg/response"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	machineRulesResource = "/admin/machines/{machine_id}/rules"
+	machineRuleResource  = "/admin/machines/{machine_id}/rules/{rule_type}/{identifier}"
+)
+
+// MachineRulesHandler manages the rules of a single machine
+type MachineRulesHandler struct {
+	booted       bool
+	rules        machineRulesService
+	timeProvider clock.TimeProvider
+}
+
+func (h *MachineRulesHandler) Boot() (err error) {
+	if h.booted {
+		return
+	}
+
+	h.rules = machinerules.GetMachineRulesService(getClient())
+	h.timeProvider = clock.ConcreteTimeProvider{}
+
+	h.booted = true
+	return
+}
+
+func (h *MachineRulesHandler) Handles(request events.APIGatewayProxyRequest) bool {
+	return matches([]route{
+		{machineRulesResource, []string{http.MethodGet, http.MethodPost}},
+		{machineRuleResource, []string{http.MethodPut, http.MethodDelete}},
+	}, request)
+}
+
+func (h *MachineRulesHandler) Handle(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	// Every change needs at least the permission to unblock; changes that are more than an unblock are checked once the
+	// rule is known
+	permission := rbac.Unblock
+	if request.HTTPMethod == http.MethodGet {
+		permission = rbac.View
+	}
+	if errResponse, err := authorize(request, permission); errResponse != nil || err != nil {
+		return errResponse, err
+	}
+
+	machineID, errResponse, err := apiRequest.GetMachineID(request)
+	if errResponse != nil || err != nil {
+		return errResponse, err
+	}
+
+	if request.Resource == machineRulesResource {
+		if request.HTTPMethod == http.MethodGet {
+			return h.list(machineID)
+		}
+		return h.create(request, machineID)
+	}
+
+	key, err := ruleKeyFromPath(request)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	existing, err := h.rules.Get(machineID, key.Identifier, key.RuleType)
+	if err != nil {
+		return internalErrorResponse(err)
+	}
+	if existing == nil {
+		return notFoundResponse("rule " + key.sortKey)
+	}
+
+	if request.HTTPMethod == http.MethodPut {
+		return h.update(request, machineID, key, *existing)
+	}
+	return h.remove(request, machineID, key, *existing)
+}
+
+func (h *MachineRulesHandler) list(machineID string) (*events.APIGatewayProxyResponse, error) {
+	rows, err := h.rules.GetMachineRules(machineID)
+	if err != nil {
+		return internalErrorResponse(err)
+	}
+	list := adminapi.MachineRuleList{Rules: []adminapi.MachineRule{}}
+	if rows != nil {
+		for _, row := range *rows {
+			list.Rules = append(list.Rules, adminapi.NewMachineRule(machineID, row))
+		}
+	}
+	return response.APIResponse(http.StatusOK, list)
+}
+
+func (h *MachineRulesHandler) rulePermission(policy types.Policy, expires time.Time) rbac.Permission {
+	return machineRulePermission(h.timeProvider.Now(), policy, expires)
+}
+
+// machineRulePermission is what it takes to leave a machine with a rule: responders may only allowlist, and not for
+// long
+func machineRulePermission(now time.Time, policy types.Policy, expires time.Time) rbac.Permission {
+	if policy == types.RulePolicyAllowlist && !expires.After(now.Add(rbac.MaxUnblockDuration)) {
+		return rbac.Unblock
+	}
+	return rbac.ManageMachineRules
+}
+
+// expiresAt defaults a machine rule to expire after the default expiration, and refuses expiries in the past
+func (h *MachineRulesHandler) expiresAt(requested *time.Time, fallback time.Time) (time.Time, error) {
+	if requested == nil {
+		return fallback, nil
+	}
+	if !requested.After(h.timeProvider.Now()) {
+		return time.Time{}, errors.New("expires_at must be in the future")
+	}
+	return *requested, nil
+}
+
+func (h *MachineRulesHandler) create(request events.APIGatewayProxyRequest, machineID string) (*events.APIGatewayProxyResponse, error) {
+	var body adminapi.CreateMachineRule
+	if err := decodeBody(request, &body); err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	rule, err := parseRule(body.RuleType, body.Policy, body.Identifier)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	expires, err := h.expiresAt(body.ExpiresAt, h.timeProvider.Now().Add(adminapi.DefaultMachineRuleExpiration))
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	row, err := machinerules.NewMachineRuleRow(machineID, rule.Identifier, rule.RuleType, rule.Policy, body.Description, expires)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+
+	if errResponse, err := authorize(request, h.rulePermission(rule.Policy, expires)); errResponse != nil || err != nil {
+		return errResponse, err
+	}
+
+	if err = h.rules.Add(machineID, rule.Identifier, rule.RuleType, rule.Policy, body.Description, expires); err != nil {
+		return internalErrorResponse(err)
+	}
+
+	audit(request, "put_machine_rule", row.SortKey, "machine_id", machineID, "policy", body.Policy, "expires_at", expires)
+	return response.APIResponse(http.StatusCreated, adminapi.NewMachineRule(machineID, row))
+}
+
+func (h *MachineRulesHandler) update(request events.APIGatewayProxyRequest, machineID string, key ruleKey, existing machinerules.MachineRuleRow) (*events.APIGatewayProxyResponse, error) {
+	var body adminapi.UpdateRule
+	if err := decodeBody(request, &body); err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	if body.Policy == "" && body.ExpiresAt == nil {
+		return errorResponse(http.StatusBadRequest, errors.New("policy or expires_at is required"))
+	}
+
+	policy := existing.Policy
+	if body.Policy != "" {
+		var err error
+		if policy, err = adminapi.ParsePolicy(body.Policy); err != nil {
+			return errorResponse(http.StatusBadRequest, errors.New("a valid policy is required"))
+		}
+	}
+	expires, err := h.expiresAt(body.ExpiresAt, time.Unix(existing.ExpiresAfter, 0))
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+
+	if errResponse, err := authorize(request, h.rulePermission(policy, expires)); errResponse != nil || err != nil {
+		return errResponse, err
+	}
+
+	if err = h.rules.Update(machineID, key.Identifier, key.RuleType, policy, expires); err != nil {
+		return internalErrorResponse(err)
+	}
+
+	audit(request, "update_machine_rule", key.sortKey, "machine_id", machineID, "policy", body.Policy, "expires_at", expires)
+	existing.Policy = policy
+	existing.ExpiresAfter = expires.Unix()
+	return response.APIResponse(http.StatusOK, adminapi.NewMachineRule(machineID, existing))
+}
+
+// remove does not delete the rule right away; the machine is told to remove it, or to fall back to the global rule,
+// on its next sync, after which the rule is deleted
+func (h *MachineRulesHandler) remove(request events.APIGatewayProxyRequest, machineID string, key ruleKey, existing machinerules.MachineRuleRow) (*events.APIGatewayProxyResponse, error) {
+	if errResponse, err := authorize(request, h.rulePermission(existing.Policy, time.Unix(existing.ExpiresAfter, 0))); errResponse != nil || err != nil {
+		return errResponse, err
+	}
+
+	if err := h.rules.RemoveBySortKey(machineID, key.sortKey); err != nil {
+		return internalErrorResponse(err)
+	}
+
+	audit(request, "remove_machine_rule", key.sortKey, "machine_id", machineID)
+	existing.DeleteOnNextSync = true
+	return response.APIResponse(http.StatusOK, adminapi.NewMachineRule(machineID, existing))
+}
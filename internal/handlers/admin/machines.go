@@ -0,0 +1,159 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/machineview"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	apiRequest "github.com/airbnb/rudolph/pkg/request"
+	"github.com/airbnb/rudolph/pkg/response"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	machinesResource      = "/admin/machines"
+	machineLookupResource = "/admin/machines/lookup"
+	machineResource       = "/admin/machines/{machine_id}"
+
+	// defaultEventLimit is how many recent events a machine is shown with, unless the events parameter says otherwise
+	defaultEventLimit = 10
+)
+
+// MachinesHandler lists, looks up and shows machines
+type MachinesHandler struct {
+	booted   bool
+	machines machineService
+}
+
+func (h *MachinesHandler) Boot() (err error) {
+	if h.booted {
+		return
+	}
+
+	client := getClient()
+	h.machines = concreteMachineService{
+		SensorDataFinder: sensordata.GetSensorDataFinder(client),
+		client:           client,
+		timeProvider:     clock.ConcreteTimeProvider{},
+	}
+
+	h.booted = true
+	return
+}
+
+func (h *MachinesHandler) Handles(request events.APIGatewayProxyRequest) bool {
+	return matches([]route{
+		{machinesResource, []string{http.MethodGet}},
+		{machineLookupResource, []string{http.MethodGet}},
+		{machineResource, []string{http.MethodGet}},
+	}, request)
+}
+
+func (h *MachinesHandler) Handle(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	if errResponse, err := authorize(request, rbac.View); errResponse != nil || err != nil {
+		return errResponse, err
+	}
+
+	switch request.Resource {
+	case machinesResource:
+		return h.list(request)
+	case machineLookupResource:
+		return h.lookup(request)
+	default:
+		return h.show(request)
+	}
+}
+
+func (h *MachinesHandler) list(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	options, err := adminapi.ParseMachineListQuery(request.QueryStringParameters)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	page, err := h.machines.listMachines(options)
+	if err != nil {
+		return internalErrorResponse(err)
+	}
+	return response.APIResponse(http.StatusOK, page)
+}
+
+func (h *MachinesHandler) lookup(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	limit, err := adminapi.ParseLimit(request.QueryStringParameters["limit"])
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+
+	lookups := map[string]func(string, int32) ([]string, error){
+		"prefix":       h.machines.GetMachineIDsStartingWith,
+		"serial_num":   h.machines.GetMachineIDsFromSerialNumber,
+		"primary_user": h.machines.GetMachineIDsFromPrimaryUser,
+	}
+	var find func(string, int32) ([]string, error)
+	var value string
+	given := 0
+	for parameter, lookup := range lookups {
+		if v := request.QueryStringParameters[parameter]; v != "" {
+			find, value = lookup, v
+			given++
+		}
+	}
+	if given != 1 {
+		return errorResponse(http.StatusBadRequest, errors.New("exactly one of prefix, serial_num or primary_user is required"))
+	}
+
+	machineIDs, err := find(value, int32(limit))
+	if err != nil {
+		return internalErrorResponse(err)
+	}
+	if machineIDs == nil {
+		machineIDs = []string{}
+	}
+	return response.APIResponse(http.StatusOK, adminapi.MachineIDs{MachineIDs: machineIDs})
+}
+
+func (h *MachinesHandler) show(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	machineID, errResponse, err := apiRequest.GetMachineID(request)
+	if errResponse != nil || err != nil {
+		return errResponse, err
+	}
+	options, err := parseMachineViewOptions(request.QueryStringParameters)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+
+	machine, err := h.machines.loadMachine(machineID, options)
+	if err != nil {
+		return internalErrorResponse(err)
+	}
+	return response.APIResponse(http.StatusOK, machine)
+}
+
+func parseMachineViewOptions(query map[string]string) (options machineview.Options, err error) {
+	options.StaleAfter = machineview.DefaultStaleAfter
+	if text := query["stale_after"]; text != "" {
+		if options.StaleAfter, err = time.ParseDuration(text); err != nil || options.StaleAfter <= 0 {
+			err = errors.New("invalid stale_after: must be a positive duration such as 72h")
+			return
+		}
+	}
+	options.EventLimit = defaultEventLimit
+	if text := query["events"]; text != "" {
+		if options.EventLimit, err = strconv.Atoi(text); err != nil || options.EventLimit < 0 || options.EventLimit > adminapi.MaxPageSize {
+			err = fmt.Errorf("invalid events: must be between 0 and %d", adminapi.MaxPageSize)
+			return
+		}
+	}
+	if text := query["events_since"]; text != "" {
+		if options.EventsSince, err = time.Parse(time.RFC3339, text); err != nil {
+			err = errors.New("invalid events_since: must be an RFC3339 time")
+			return
+		}
+	}
+	return
+}
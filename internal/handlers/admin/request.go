@@ -0,0 +1,149 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	"github.com/airbnb/rudolph/pkg/response"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// principal is who made the request, as set by the admin authorizer from the caller's token
+func principal(request events.APIGatewayProxyRequest) string {
+	if p, ok := request.RequestContext.Authorizer["principal"].(string); ok && p != "" {
+		return p
+	}
+	return "unknown"
+}
+
+// roles are what the caller may do, as set by the admin authorizer from the caller's groups
+func roles(request events.APIGatewayProxyRequest) rbac.Roles {
+	text, _ := request.RequestContext.Authorizer["roles"].(string)
+	return rbac.ParseRoles(text)
+}
+
+// authorize responds with a 403 when none of the caller's roles allows the permission, and records the denial in the
+// audit trail
+func authorize(request events.APIGatewayProxyRequest, permission rbac.Permission) (*events.APIGatewayProxyResponse, error) {
+	callerRoles := roles(request)
+	if callerRoles.Can(permission) {
+		return nil, nil
+	}
+	slog.Warn("Admin API denied",
+		"audit", true,
+		"principal", principal(request),
+		"roles", callerRoles.String(),
+		"permission", permission,
+		"method", request.HTTPMethod,
+		"resource", request.Resource,
+		"path_parameters", request.PathParameters,
+	)
+	return errorResponse(http.StatusForbidden, fmt.Errorf("this requires one of the roles %s", rbac.RolesWith(permission)))
+}
+
+// audit logs every change made through the admin API, along with who made it
+func audit(request events.APIGatewayProxyRequest, action string, target string, attrs ...any) {
+	attrs = append([]any{"audit", true, "principal", principal(request), "action", action, "target", target}, attrs...)
+	slog.Info("Admin API change", attrs...)
+}
+
+func errorResponse(status int, err error) (*events.APIGatewayProxyResponse, error) {
+	return response.APIResponse(status, adminapi.ErrorResponse{Error: err.Error()})
+}
+
+func internalErrorResponse(err error) (*events.APIGatewayProxyResponse, error) {
+	slog.Error("Admin API request failed", "error", err)
+	return response.APIResponse(http.StatusInternalServerError, adminapi.ErrorResponse{Error: response.ErrInternalServerErrorResponse.Error})
+}
+
+func notFoundResponse(what string) (*events.APIGatewayProxyResponse, error) {
+	return errorResponse(http.StatusNotFound, fmt.Errorf("%s not found", what))
+}
+
+// decodeBody reads a JSON body, rejecting unknown fields so that typos are not silently ignored
+func decodeBody(request events.APIGatewayProxyRequest, body interface{}) error {
+	if request.Body == "" {
+		return errors.New("a JSON body is required")
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(request.Body)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(body); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// ruleKey is a rule named by the {rule_type} and {identifier} path parameters
+type ruleKey struct {
+	rules.SantaRule
+	sortKey string
+}
+
+func ruleKeyFromPath(request events.APIGatewayProxyRequest) (key ruleKey, err error) {
+	if key.RuleType, err = adminapi.ParseRuleType(request.PathParameters["rule_type"]); err != nil {
+		err = fmt.Errorf("invalid rule_type %q", request.PathParameters["rule_type"])
+		return
+	}
+	// API Gateway passes path parameters as they were sent, so escaped characters still need decoding
+	if key.Identifier, err = url.PathUnescape(request.PathParameters["identifier"]); err != nil || key.Identifier == "" {
+		err = errors.New("a valid identifier is required")
+		return
+	}
+	key.sortKey = rules.RuleSortKeyFromTypeIdentifier(key.Identifier, key.RuleType)
+	return
+}
+
+// parseRule validates the type, policy and identifier of a rule from a request body
+func parseRule(ruleType string, policy string, identifier string) (rule rules.SantaRule, err error) {
+	if rule.RuleType, err = adminapi.ParseRuleType(ruleType); err != nil {
+		err = fmt.Errorf("invalid rule_type %q", ruleType)
+		return
+	}
+	if rule.Policy, err = adminapi.ParsePolicy(policy); err != nil {
+		err = fmt.Errorf("invalid policy %q", policy)
+		return
+	}
+	rule.Identifier = identifier
+	err = globalrules.ValidateRule(rule)
+	return
+}
+
+// route is an API Gateway resource and the methods a handler serves on it
+type route struct {
+	resource string
+	methods  []string
+}
+
+func matches(routes []route, request events.APIGatewayProxyRequest) bool {
+	for _, r := range routes {
+		if r.resource != request.Resource {
+			continue
+		}
+		for _, method := range r.methods {
+			if method == request.HTTPMethod {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// header reads a request header case insensitively, since clients and API Gateway do not agree on the casing
+func header(request events.APIGatewayProxyRequest, name string) string {
+	for key, value := range request.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
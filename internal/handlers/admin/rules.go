@@ -0,0 +1,161 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	"github.com/airbnb/rudolph/pkg/response"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	rulesResource = "/admin/rules"
+	ruleResource  = "/admin/rules/{rule_type}/{identifier}"
+)
+
+// RulesHandler manages global rules
+type RulesHandler struct {
+	booted bool
+	rules  globalRulesService
+}
+
+func (h *RulesHandler) Boot() (err error) {
+	if h.booted {
+		return
+	}
+
+	h.rules = concreteGlobalRulesService{
+		client:       getClient(),
+		timeProvider: clock.ConcreteTimeProvider{},
+	}
+
+	h.booted = true
+	return
+}
+
+func (h *RulesHandler) Handles(request events.APIGatewayProxyRequest) bool {
+	return matches([]route{
+		{rulesResource, []string{http.MethodGet, http.MethodPost}},
+		{ruleResource, []string{http.MethodGet, http.MethodPut, http.MethodDelete}},
+	}, request)
+}
+
+func (h *RulesHandler) Handle(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	permission := rbac.ManageGlobalRules
+	if request.HTTPMethod == http.MethodGet {
+		permission = rbac.View
+	}
+	if errResponse, err := authorize(request, permission); errResponse != nil || err != nil {
+		return errResponse, err
+	}
+
+	if request.Resource == rulesResource {
+		if request.HTTPMethod == http.MethodGet {
+			return h.list(request)
+		}
+		return h.create(request)
+	}
+
+	key, err := ruleKeyFromPath(request)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	existing, err := h.rules.getGlobalRule(key.Identifier, key.RuleType)
+	if err != nil {
+		return internalErrorResponse(err)
+	}
+	if existing == nil {
+		return notFoundResponse("rule " + key.sortKey)
+	}
+
+	switch request.HTTPMethod {
+	case http.MethodPut:
+		return h.update(request, key, *existing)
+	case http.MethodDelete:
+		return h.remove(request, key, *existing)
+	default:
+		return response.APIResponse(http.StatusOK, adminapi.NewRule(*existing))
+	}
+}
+
+func (h *RulesHandler) list(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	limit, err := adminapi.ParseLimit(request.QueryStringParameters["limit"])
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	after := request.QueryStringParameters["after"]
+	if after != "" {
+		if _, _, err = rules.ParseRuleSortKey(after); err != nil {
+			return errorResponse(http.StatusBadRequest, err)
+		}
+	}
+
+	rows, next, err := h.rules.listGlobalRules(limit, after)
+	if err != nil {
+		return internalErrorResponse(err)
+	}
+	list := adminapi.RuleList{Rules: []adminapi.Rule{}, Next: next}
+	for _, row := range rows {
+		list.Rules = append(list.Rules, adminapi.NewRule(row))
+	}
+	return response.APIResponse(http.StatusOK, list)
+}
+
+func (h *RulesHandler) create(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	var body adminapi.CreateRule
+	if err := decodeBody(request, &body); err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	rule, err := parseRule(body.RuleType, body.Policy, body.Identifier)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	rule.CustomMessage = body.CustomMessage
+
+	if err = h.rules.putGlobalRule(rule, body.Description); err != nil {
+		return internalErrorResponse(err)
+	}
+
+	sortKey := rules.RuleSortKeyFromTypeIdentifier(rule.Identifier, rule.RuleType)
+	audit(request, "put_global_rule", sortKey, "policy", body.Policy)
+	return response.APIResponse(http.StatusCreated, adminapi.NewRule(globalrules.GlobalRuleRow{
+		SantaRule:   rule,
+		Description: body.Description,
+	}))
+}
+
+func (h *RulesHandler) update(request events.APIGatewayProxyRequest, key ruleKey, existing globalrules.GlobalRuleRow) (*events.APIGatewayProxyResponse, error) {
+	var body adminapi.UpdateRule
+	if err := decodeBody(request, &body); err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	if body.ExpiresAt != nil {
+		return errorResponse(http.StatusBadRequest, errors.New("global rules do not expire"))
+	}
+	policy, err := adminapi.ParsePolicy(body.Policy)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, errors.New("a valid policy is required"))
+	}
+
+	if err = h.rules.updateGlobalRule(key.Identifier, key.RuleType, policy); err != nil {
+		return internalErrorResponse(err)
+	}
+
+	audit(request, "update_global_rule", key.sortKey, "policy", body.Policy)
+	existing.Policy = policy
+	return response.APIResponse(http.StatusOK, adminapi.NewRule(existing))
+}
+
+func (h *RulesHandler) remove(request events.APIGatewayProxyRequest, key ruleKey, existing globalrules.GlobalRuleRow) (*events.APIGatewayProxyResponse, error) {
+	if err := h.rules.removeGlobalRule(key.sortKey, header(request, adminapi.IdempotencyKeyHeader)); err != nil {
+		return internalErrorResponse(err)
+	}
+
+	audit(request, "remove_global_rule", key.sortKey)
+	return response.APIResponse(http.StatusOK, adminapi.NewRule(existing))
+}
@@ -0,0 +1,248 @@
+package admin
+
+import (
+	"os"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/inventory"
+	"github.com/airbnb/rudolph/pkg/machineview"
+	"github.com/airbnb/rudolph/pkg/model/catalog"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/model/modetransitions"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/model/unblockrequests"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// getClient returns the DynamoDB client that every admin handler boots with
+func getClient() dynamodb.DynamoDBClient {
+	return dynamodb.GetClient(os.Getenv("DYNAMODB_NAME"), os.Getenv("REGION"))
+}
+
+//
+// Global rules
+//
+
+type globalRulesService interface {
+	listGlobalRules(limit int, after string) (rows []globalrules.GlobalRuleRow, next string, err error)
+	getGlobalRule(identifier string, ruleType types.RuleType) (*globalrules.GlobalRuleRow, error)
+	putGlobalRule(rule rules.SantaRule, description string) error
+	updateGlobalRule(identifier string, ruleType types.RuleType, policy types.Policy) error
+	removeGlobalRule(sortKey string, idempotencyKey string) error
+}
+
+type concreteGlobalRulesService struct {
+	client       dynamodb.DynamoDBClient
+	timeProvider clock.TimeProvider
+}
+
+func (s concreteGlobalRulesService) listGlobalRules(limit int, after string) ([]globalrules.GlobalRuleRow, string, error) {
+	return globalrules.ListGlobalRulesPage(s.client, limit, after)
+}
+
+func (s concreteGlobalRulesService) getGlobalRule(identifier string, ruleType types.RuleType) (*globalrules.GlobalRuleRow, error) {
+	return globalrules.GetGlobalRuleByIdentifier(s.client, identifier, ruleType)
+}
+
+func (s concreteGlobalRulesService) putGlobalRule(rule rules.SantaRule, description string) error {
+	return globalrules.PutManagedGlobalRule(s.timeProvider, s.client, rule, description, "")
+}
+
+func (s concreteGlobalRulesService) updateGlobalRule(identifier string, ruleType types.RuleType, policy types.Policy) error {
+	return globalrules.UpdateGlobalRule(s.timeProvider, s.client, identifier, ruleType, policy)
+}
+
+func (s concreteGlobalRulesService) removeGlobalRule(sortKey string, idempotencyKey string) error {
+	return globalrules.RemoveGlobalRule(s.timeProvider, s.client, s.client, sortKey, idempotencyKey)
+}
+
+//
+// Configs
+//
+
+// configService reads and writes the global config when machineID is empty, and the config of a single machine
+// otherwise
+type configService interface {
+	getIntendedConfig(machineID string) (config machineconfiguration.MachineConfiguration, source adminapi.ConfigSource, err error)
+	setConfig(machineID string, config machineconfiguration.MachineConfiguration) error
+	updateConfig(machineID string, request machineconfiguration.MachineConfigurationUpdateRequest) (*machineconfiguration.MachineConfiguration, error)
+	deleteConfig(machineID string) error
+	// recordTransition records a change of a machine's client mode, so that automatic promotion leaves it alone
+	recordTransition(machineID string, from types.ClientMode, to types.ClientMode, reason string) error
+}
+
+type concreteConfigService struct {
+	client       dynamodb.DynamoDBClient
+	timeProvider clock.TimeProvider
+	service      machineconfiguration.MachineConfigurationService
+}
+
+// getIntendedConfig falls back from the machine's config to the global config to the defaults, the same way preflight
+// does. The global config is read without the cache, so that changes made through this API show up right away.
+func (s concreteConfigService) getIntendedConfig(machineID string) (config machineconfiguration.MachineConfiguration, source adminapi.ConfigSource, err error) {
+	if machineID != "" {
+		machineConfig, inerr := machineconfiguration.GetMachineConfigurationFetcher(s.client).GetMachineSpecificConfig(machineID)
+		if inerr != nil {
+			err = inerr
+			return
+		}
+		if machineConfig != nil {
+			return *machineConfig, adminapi.ConfigSourceMachine, nil
+		}
+	}
+
+	globalConfig, err := machineconfiguration.GetUncachedGlobalConfigurationFetcher(s.client, s.timeProvider).GetGlobalConfig()
+	if err != nil {
+		return
+	}
+	if globalConfig != nil {
+		return *globalConfig, adminapi.ConfigSourceGlobal, nil
+	}
+	return machineconfiguration.GetUniversalDefaultConfig(), adminapi.ConfigSourceDefault, nil
+}
+
+func (s concreteConfigService) setConfig(machineID string, config machineconfiguration.MachineConfiguration) error {
+	if machineID == "" {
+		return s.service.SetGlobalConfig(config)
+	}
+	return s.service.SetMachineConfig(machineID, config)
+}
+
+func (s concreteConfigService) updateConfig(machineID string, request machineconfiguration.MachineConfigurationUpdateRequest) (*machineconfiguration.MachineConfiguration, error) {
+	if machineID == "" {
+		return s.service.UpdateGlobalConfig(request)
+	}
+	return s.service.UpdateMachineConfig(machineID, request)
+}
+
+func (s concreteConfigService) deleteConfig(machineID string) error {
+	if machineID == "" {
+		return s.service.DeleteGlobalConfig()
+	}
+	return s.service.DeleteMachineConfig(machineID)
+}
+
+func (s concreteConfigService) recordTransition(machineID string, from types.ClientMode, to types.ClientMode, reason string) error {
+	_, err := modetransitions.RecordTransition(s.client, s.timeProvider, machineID, from, to, modetransitions.ActorAdminAPI, reason)
+	return err
+}
+
+//
+// Machines
+//
+
+type machineService interface {
+	sensordata.SensorDataFinder
+	listMachines(options inventory.Options) (inventory.Page, error)
+	loadMachine(machineID string, options machineview.Options) (machineview.Machine, error)
+}
+
+type concreteMachineService struct {
+	sensordata.SensorDataFinder
+	client       dynamodb.DynamoDBClient
+	timeProvider clock.TimeProvider
+}
+
+func (s concreteMachineService) listMachines(options inventory.Options) (inventory.Page, error) {
+	return inventory.List(inventory.GetStore(s.client, s.timeProvider), options)
+}
+
+func (s concreteMachineService) loadMachine(machineID string, options machineview.Options) (machineview.Machine, error) {
+	return machineview.Load(s.client, s.timeProvider, machineID, options)
+}
+
+// machineRulesService is the same service the CLI uses to manage machine rules
+type machineRulesService = machinerules.MachineRulesService
+
+//
+// Blocks and unblock requests
+//
+
+type blockService interface {
+	getSensorData(machineID string) (*sensordata.SensorData, error)
+	// getEvents returns the machine's recent events of the binary, newest first
+	getEvents(machineID string, fileSHA256 string) ([]eventlog.Event, error)
+	getCatalogEntry(fileSHA256 string) (*catalog.CatalogEntry, error)
+	explain(machineID string, subject ruleset.Subject) (ruleset.Explanation, error)
+	getUnblockRequest(machineID string, fileSHA256 string) (*unblockrequests.UnblockRequestRow, error)
+	listUnblockRequests(status unblockrequests.Status) ([]unblockrequests.UnblockRequestRow, error)
+	fileUnblockRequest(request unblockrequests.UnblockRequest) (unblockrequests.UnblockRequestRow, error)
+	decideUnblockRequest(row unblockrequests.UnblockRequestRow, status unblockrequests.Status, decidedBy string, reason string) (unblockrequests.UnblockRequestRow, error)
+}
+
+const (
+	// blockEventDays and blockEventLimit bound the search of a machine's events for those of a single binary; events
+	// are only kept for 30 days anyway
+	blockEventDays  = 30
+	blockEventLimit = 500
+)
+
+type concreteBlockService struct {
+	client       dynamodb.DynamoDBClient
+	timeProvider clock.TimeProvider
+}
+
+func (s concreteBlockService) getSensorData(machineID string) (*sensordata.SensorData, error) {
+	return sensordata.GetSensorData(s.client, machineID)
+}
+
+func (s concreteBlockService) getEvents(machineID string, fileSHA256 string) ([]eventlog.Event, error) {
+	since := s.timeProvider.Now().UTC().AddDate(0, 0, -blockEventDays)
+	rows, err := eventlog.GetEventsByMachineID(s.client, machineID, since, blockEventLimit)
+	if err != nil {
+		return nil, err
+	}
+	events := []eventlog.Event{}
+	for _, row := range rows {
+		if strings.EqualFold(row.FileSHA256, fileSHA256) {
+			events = append(events, row.Event)
+		}
+	}
+	return events, nil
+}
+
+func (s concreteBlockService) getCatalogEntry(fileSHA256 string) (*catalog.CatalogEntry, error) {
+	row, err := catalog.GetCatalogEntry(s.client, catalog.EntryTypeBinary, fileSHA256)
+	if err != nil || row == nil {
+		return nil, err
+	}
+	return &row.CatalogEntry, nil
+}
+
+// explain evaluates the binary against the machine's rules and intended client mode, as the sensor would after its
+// next sync
+func (s concreteBlockService) explain(machineID string, subject ruleset.Subject) (explanation ruleset.Explanation, err error) {
+	config, _, err := concreteConfigService{client: s.client, timeProvider: s.timeProvider}.getIntendedConfig(machineID)
+	if err != nil {
+		return
+	}
+	rs, err := ruleset.ForSubject(s.client, machineID, subject)
+	if err != nil {
+		return
+	}
+	return rs.Explain(subject, config.ClientMode), nil
+}
+
+func (s concreteBlockService) getUnblockRequest(machineID string, fileSHA256 string) (*unblockrequests.UnblockRequestRow, error) {
+	return unblockrequests.GetUnblockRequest(s.client, machineID, fileSHA256)
+}
+
+func (s concreteBlockService) listUnblockRequests(status unblockrequests.Status) ([]unblockrequests.UnblockRequestRow, error) {
+	return unblockrequests.ListUnblockRequests(s.client, status)
+}
+
+func (s concreteBlockService) fileUnblockRequest(request unblockrequests.UnblockRequest) (unblockrequests.UnblockRequestRow, error) {
+	return unblockrequests.FileUnblockRequest(s.client, s.timeProvider, request)
+}
+
+func (s concreteBlockService) decideUnblockRequest(row unblockrequests.UnblockRequestRow, status unblockrequests.Status, decidedBy string, reason string) (unblockrequests.UnblockRequestRow, error) {
+	return unblockrequests.DecideUnblockRequest(s.client, s.timeProvider, row, status, decidedBy, reason)
+}
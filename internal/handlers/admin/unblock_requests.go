@@ -0,0 +1,204 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/model/unblockrequests"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	apiRequest "github.com/airbnb/rudolph/pkg/request"
+	"github.com/airbnb/rudolph/pkg/response"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	unblockRequestsResource = "/admin/unblock-requests"
+	unblockRequestResource  = "/admin/unblock-requests/{machine_id}/{file_sha256}"
+)
+
+// UnblockRequestsHandler files requests to unblock a binary on a machine, and lets responders approve or deny them.
+// Approving a request allowlists the binary on the machine for a while.
+type UnblockRequestsHandler struct {
+	booted       bool
+	blocks       blockService
+	rules        machineRulesService
+	timeProvider clock.TimeProvider
+}
+
+func (h *UnblockRequestsHandler) Boot() (err error) {
+	if h.booted {
+		return
+	}
+
+	h.timeProvider = clock.ConcreteTimeProvider{}
+	h.blocks = concreteBlockService{client: getClient(), timeProvider: h.timeProvider}
+	h.rules = machinerules.GetMachineRulesService(getClient())
+
+	h.booted = true
+	return
+}
+
+func (h *UnblockRequestsHandler) Handles(request events.APIGatewayProxyRequest) bool {
+	return matches([]route{
+		{unblockRequestsResource, []string{http.MethodGet, http.MethodPost}},
+		{unblockRequestResource, []string{http.MethodPut}},
+	}, request)
+}
+
+func (h *UnblockRequestsHandler) Handle(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	switch request.HTTPMethod {
+	case http.MethodGet:
+		return h.list(request)
+	case http.MethodPost:
+		return h.create(request)
+	default:
+		return h.decide(request)
+	}
+}
+
+func (h *UnblockRequestsHandler) list(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	if errResponse, err := authorize(request, rbac.View); errResponse != nil || err != nil {
+		return errResponse, err
+	}
+
+	status := unblockrequests.Status(request.QueryStringParameters["status"])
+	switch status {
+	case "", unblockrequests.StatusPending, unblockrequests.StatusApproved, unblockrequests.StatusDenied:
+	default:
+		return errorResponse(http.StatusBadRequest, errors.New("status must be pending, approved or denied"))
+	}
+
+	rows, err := h.blocks.listUnblockRequests(status)
+	if err != nil {
+		return internalErrorResponse(err)
+	}
+	list := adminapi.UnblockRequestList{Requests: []unblockrequests.UnblockRequest{}}
+	for _, row := range rows {
+		list.Requests = append(list.Requests, row.UnblockRequest)
+	}
+	return response.APIResponse(http.StatusOK, list)
+}
+
+// create files a request on behalf of the caller, who must be a user of the machine unless they may view everything
+func (h *UnblockRequestsHandler) create(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	var body adminapi.CreateUnblockRequest
+	if err := decodeBody(request, &body); err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	if err := types.ValidateMachineID(body.MachineID); err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	fileSHA256 := strings.ToLower(body.FileSHA256)
+	if err := types.ValidateSha256(fileSHA256); err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+
+	sensorData, blockEvents, errResponse, err := authorizeMachineOwner(request, h.blocks, body.MachineID, fileSHA256)
+	if errResponse != nil || err != nil {
+		return errResponse, err
+	}
+	if sensorData == nil && len(blockEvents) == 0 {
+		return notFoundResponse("machine " + body.MachineID)
+	}
+
+	unblockRequest := unblockrequests.UnblockRequest{
+		MachineID:     body.MachineID,
+		FileSHA256:    fileSHA256,
+		Requester:     principal(request),
+		Justification: body.Justification,
+	}
+	if len(blockEvents) > 0 {
+		unblockRequest.FileName = blockEvents[0].FileName
+	} else {
+		entry, err := h.blocks.getCatalogEntry(fileSHA256)
+		if err != nil {
+			return internalErrorResponse(err)
+		}
+		if entry != nil {
+			unblockRequest.FileName = entry.FileName
+		}
+	}
+
+	row, err := h.blocks.fileUnblockRequest(unblockRequest)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+
+	audit(request, "file_unblock_request", fileSHA256, "machine_id", body.MachineID)
+	return response.APIResponse(http.StatusCreated, row.UnblockRequest)
+}
+
+// decide approves or denies a pending request. Approving it adds the same allowlist rule that a responder could add by
+// hand, so it takes the same permission.
+func (h *UnblockRequestsHandler) decide(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	if errResponse, err := authorize(request, rbac.Unblock); errResponse != nil || err != nil {
+		return errResponse, err
+	}
+
+	machineID, errResponse, err := apiRequest.GetMachineID(request)
+	if errResponse != nil || err != nil {
+		return errResponse, err
+	}
+	fileSHA256 := strings.ToLower(request.PathParameters["file_sha256"])
+	if err = types.ValidateSha256(fileSHA256); err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+
+	var body adminapi.DecideUnblockRequest
+	if err = decodeBody(request, &body); err != nil {
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	status := unblockrequests.Status(body.Status)
+	if status != unblockrequests.StatusApproved && status != unblockrequests.StatusDenied {
+		return errorResponse(http.StatusBadRequest, errors.New("status must be approved or denied"))
+	}
+
+	existing, err := h.blocks.getUnblockRequest(machineID, fileSHA256)
+	if err != nil {
+		return internalErrorResponse(err)
+	}
+	if existing == nil {
+		return notFoundResponse("unblock request")
+	}
+	if existing.Status != unblockrequests.StatusPending {
+		return errorResponse(http.StatusConflict, errors.New("the request was already "+string(existing.Status)))
+	}
+
+	var expires time.Time
+	if status == unblockrequests.StatusApproved {
+		now := h.timeProvider.Now()
+		expires = now.Add(adminapi.DefaultMachineRuleExpiration)
+		if body.ExpiresAt != nil {
+			if !body.ExpiresAt.After(now) {
+				return errorResponse(http.StatusBadRequest, errors.New("expires_at must be in the future"))
+			}
+			expires = *body.ExpiresAt
+		}
+		if errResponse, err := authorize(request, machineRulePermission(now, types.RulePolicyAllowlist, expires)); errResponse != nil || err != nil {
+			return errResponse, err
+		}
+
+		description := "Unblock request of " + existing.Requester
+		if err = h.rules.Add(machineID, fileSHA256, types.RuleTypeBinary, types.RulePolicyAllowlist, description, expires); err != nil {
+			return internalErrorResponse(err)
+		}
+	}
+
+	row, err := h.blocks.decideUnblockRequest(*existing, status, principal(request), strings.TrimSpace(body.Reason))
+	if err != nil {
+		return internalErrorResponse(err)
+	}
+
+	if status == unblockrequests.StatusApproved {
+		audit(request, "approve_unblock_request", fileSHA256, "machine_id", machineID, "requester", existing.Requester, "expires_at", expires)
+	} else {
+		audit(request, "deny_unblock_request", fileSHA256, "machine_id", machineID, "requester", existing.Requester)
+	}
+	return response.APIResponse(http.StatusOK, row.UnblockRequest)
+}
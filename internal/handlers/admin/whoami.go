@@ -0,0 +1,30 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/airbnb/rudolph/pkg/adminapi"
+	"github.com/airbnb/rudolph/pkg/response"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const whoAmIResource = "/admin/whoami"
+
+// WhoAmIHandler tells callers who they are authenticated as, and which roles they have
+type WhoAmIHandler struct{}
+
+func (h *WhoAmIHandler) Boot() error {
+	return nil
+}
+
+func (h *WhoAmIHandler) Handles(request events.APIGatewayProxyRequest) bool {
+	return matches([]route{{whoAmIResource, []string{http.MethodGet}}}, request)
+}
+
+func (h *WhoAmIHandler) Handle(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	caller := adminapi.Caller{Principal: principal(request), Roles: []string{}}
+	for _, role := range roles(request) {
+		caller.Roles = append(caller.Roles, string(role))
+	}
+	return response.APIResponse(http.StatusOK, caller)
+}
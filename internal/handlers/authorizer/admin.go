@@ -0,0 +1,150 @@
+package authorizer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/logging"
+	"github.com/airbnb/rudolph/pkg/oidc"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	defaultPrincipalClaim = "email"
+	defaultGroupsClaim    = "groups"
+)
+
+// errUnauthorized makes API Gateway respond with 401 instead of 403, telling the caller to get a new token
+var errUnauthorized = errors.New("Unauthorized")
+
+var (
+	adminVerifierOnce sync.Once
+	adminVerifier     *oidc.Verifier
+	adminVerifierErr  error
+	// principalClaim is the claim that names the caller in the audit log; tokens without it fall back to "sub"
+	principalClaim = defaultPrincipalClaim
+	// groupsClaim lists the identity provider groups of the caller, which roleMapping turns into roles
+	groupsClaim = defaultGroupsClaim
+	roleMapping = rbac.DefaultGroupMapping()
+)
+
+// bootAdminVerifier reads the identity provider settings once, so that its signing keys are cached across requests
+func bootAdminVerifier() (*oidc.Verifier, error) {
+	adminVerifierOnce.Do(func() {
+		// Tests set the verifier directly
+		if adminVerifier != nil {
+			return
+		}
+		if claim := os.Getenv("OIDC_PRINCIPAL_CLAIM"); claim != "" {
+			principalClaim = claim
+		}
+		if claim := os.Getenv("OIDC_GROUPS_CLAIM"); claim != "" {
+			groupsClaim = claim
+		}
+		if roleMapping, adminVerifierErr = rbac.ParseGroupMapping(os.Getenv("ADMIN_ROLE_GROUPS")); adminVerifierErr != nil {
+			return
+		}
+		adminVerifier, adminVerifierErr = oidc.NewVerifier(
+			os.Getenv("OIDC_ISSUER"),
+			os.Getenv("OIDC_AUDIENCE"),
+			os.Getenv("OIDC_JWKS_URL"),
+			clock.ConcreteTimeProvider{},
+		)
+	})
+	return adminVerifier, adminVerifierErr
+}
+
+// HandleAdminAuthorizerRequest is the handler used by the admin API's authorizer function. Callers send a token from
+// the identity provider as "Authorization: Bearer <token>"; the caller it names is passed on to the admin handlers as
+// the principal.
+func HandleAdminAuthorizerRequest(request events.APIGatewayProxyRequest) (*events.APIGatewayCustomAuthorizerResponse, error) {
+	logger := logging.ForRequest(request.RequestContext.RequestID, "")
+	logger.Info("lambda request - HandleAdminAuthorizerRequest", "method", request.HTTPMethod, "path", request.Path)
+
+	verifier, err := bootAdminVerifier()
+	if err != nil {
+		logger.Error("Admin authorizer is misconfigured", "error", err)
+		return denyResponse("Misconfigured"), nil
+	}
+
+	token, ok := bearerToken(request)
+	if !ok {
+		return nil, errUnauthorized
+	}
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		logger.Warn("Rejected admin API token", "error", err)
+		return nil, errUnauthorized
+	}
+
+	principal := claims.String(principalClaim)
+	if principal == "" {
+		principal = claims.String("sub")
+	}
+	if principal == "" {
+		return denyResponse("No principal"), nil
+	}
+
+	// Callers without any role may only see and request unblocks of what was blocked on their own machines, so they are
+	// limited to those paths here; the admin handlers check each request against the roles
+	roles := roleMapping.Roles(claims.Strings(groupsClaim))
+	if len(roles) == 0 {
+		logger.Debug("Authorized admin API caller without roles", "principal", principal, "groups", claims.Strings(groupsClaim))
+		return adminAllowResponse(principal, roles, noRolePaths...), nil
+	}
+	logger.Debug("Authorized admin API caller", "principal", principal, "roles", roles.String())
+	return adminAllowResponse(principal, roles, "*/admin/*"), nil
+}
+
+// noRolePaths are the methods and paths of the admin API that callers without any role may invoke
+var noRolePaths = []string{
+	"GET/admin/whoami",
+	"GET/admin/blocks/*",
+	"POST/admin/unblock-requests",
+}
+
+func bearerToken(request events.APIGatewayProxyRequest) (string, bool) {
+	for name, value := range request.Headers {
+		if !strings.EqualFold(name, "Authorization") {
+			continue
+		}
+		scheme, token, found := strings.Cut(value, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			return "", false
+		}
+		return strings.TrimSpace(token), true
+	}
+	return "", false
+}
+
+// adminAllowResponse allows the caller to invoke the given methods and paths of the admin API, and nothing else
+func adminAllowResponse(principal string, roles rbac.Roles, paths ...string) *events.APIGatewayCustomAuthorizerResponse {
+	context := make(map[string]interface{}, 2)
+	context["principal"] = principal
+	context["roles"] = roles.String()
+
+	//<RANDOM_KEY>/<STAGE>/<HTTP_METHOD>/admin/<URLPATH>
+	resourceArns := make([]string, 0, len(paths))
+	for _, path := range paths {
+		resourceArns = append(resourceArns, fmt.Sprintf("arn:aws:execute-api:%s:%s:%s/*/%s", authorizerEnv.Region, authorizerEnv.AccountID, authorizerEnv.GatewayID, path))
+	}
+	return &events.APIGatewayCustomAuthorizerResponse{
+		PrincipalID: principal,
+		PolicyDocument: events.APIGatewayCustomAuthorizerPolicy{
+			Version: "2012-10-17",
+			Statement: []events.IAMPolicyStatement{
+				{
+					Action:   []string{"execute-api:Invoke"},
+					Effect:   "Allow",
+					Resource: resourceArns,
+				},
+			},
+		},
+		Context: context,
+	}
+}
@@ -0,0 +1,91 @@
+package authorizer
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/oidc"
+	"github.com/airbnb/rudolph/pkg/rbac"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signToken(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	encode := func(v interface{}) string {
+		raw, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+	signingInput := encode(map[string]string{"alg": "RS256", "kid": "key-1"}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func Test_HandleAdminAuthorizerRequest(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	adminVerifier = &oidc.Verifier{
+		Issuer:   "https://idp.example.com",
+		Audience: "rudolph-admin",
+		Keys:     oidc.StaticKeySet{"key-1": &key.PublicKey},
+		Time:     clock.FrozenTimeProvider{Current: now},
+	}
+	roleMapping = rbac.GroupMapping{rbac.Viewer: {"everyone"}, rbac.FleetAdmin: {"endpoint-engineering"}}
+	authorizerEnv = authorizerEnvironment{Region: "us-east-1", GatewayID: "abc123", AccountID: "123456789012"}
+
+	claims := map[string]interface{}{
+		"iss":    "https://idp.example.com",
+		"aud":    "rudolph-admin",
+		"sub":    "00u1abcd",
+		"email":  "alice@example.com",
+		"groups": []string{"everyone", "endpoint-engineering"},
+		"exp":    now.Add(time.Hour).Unix(),
+	}
+	request := func(authorization string) events.APIGatewayProxyRequest {
+		return events.APIGatewayProxyRequest{
+			HTTPMethod: "GET",
+			Path:       "/admin/rules",
+			Headers:    map[string]string{"authorization": authorization},
+		}
+	}
+
+	resp, err := HandleAdminAuthorizerRequest(request("Bearer " + signToken(t, key, claims)))
+	require.NoError(t, err)
+	assert.Equal(t, "Allow", resp.PolicyDocument.Statement[0].Effect)
+	assert.Equal(t, []string{"arn:aws:execute-api:us-east-1:123456789012:abc123/*/*/admin/*"}, resp.PolicyDocument.Statement[0].Resource)
+	assert.Equal(t, "alice@example.com", resp.Context["principal"])
+	assert.Equal(t, "viewer,fleet-admin", resp.Context["roles"])
+
+	delete(claims, "email")
+	resp, err = HandleAdminAuthorizerRequest(request("Bearer " + signToken(t, key, claims)))
+	require.NoError(t, err)
+	assert.Equal(t, "00u1abcd", resp.Context["principal"])
+
+	// Callers without roles may only look at and request unblocks of what was blocked on their machines
+	claims["groups"] = []string{"contractors"}
+	resp, err = HandleAdminAuthorizerRequest(request("Bearer " + signToken(t, key, claims)))
+	require.NoError(t, err)
+	assert.Equal(t, "Allow", resp.PolicyDocument.Statement[0].Effect)
+	assert.Equal(t, []string{
+		"arn:aws:execute-api:us-east-1:123456789012:abc123/*/GET/admin/whoami",
+		"arn:aws:execute-api:us-east-1:123456789012:abc123/*/GET/admin/blocks/*",
+		"arn:aws:execute-api:us-east-1:123456789012:abc123/*/POST/admin/unblock-requests",
+	}, resp.PolicyDocument.Statement[0].Resource)
+	assert.Equal(t, "", resp.Context["roles"])
+
+	for _, authorization := range []string{"", "Basic abc", "Bearer not-a-token"} {
+		_, err = HandleAdminAuthorizerRequest(request(authorization))
+		assert.Equal(t, errUnauthorized, err, authorization)
+	}
+}
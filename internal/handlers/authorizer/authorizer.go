@@ -3,9 +3,9 @@ package authorizer
 import (
 	"errors"
 	"fmt"
-	"log"
 	"os"
 
+	"github.com/airbnb/rudolph/pkg/logging"
 	"github.com/aws/aws-lambda-go/events"
 )
 
@@ -33,7 +33,8 @@ func init() {
 
 // HandleAuthorizerRequest is the handler to be used by the authorizer function
 func HandleAuthorizerRequest(request events.APIGatewayProxyRequest) (*events.APIGatewayCustomAuthorizerResponse, error) {
-	log.Printf("lambda request - HandleAuthorizerRequest:\n%+v\n", request)
+	logger := logging.ForRequest(request.RequestContext.RequestID, request.PathParameters["machine_id"])
+	logger.Info("lambda request - HandleAuthorizerRequest", "method", request.HTTPMethod, "path", request.Path)
 
 	if request.HTTPMethod == "GET" && request.Path == "/health" {
 		return allowResponse("HEALTH_CHECK"), nil
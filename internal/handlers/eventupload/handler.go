@@ -2,13 +2,17 @@ package eventupload
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"strings"
 
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
 	"github.com/airbnb/rudolph/pkg/firehose"
 	"github.com/airbnb/rudolph/pkg/kinesis"
 	"github.com/airbnb/rudolph/pkg/lambda"
+	"github.com/airbnb/rudolph/pkg/metrics"
 
 	"github.com/airbnb/rudolph/pkg/response"
 	"github.com/aws/aws-lambda-go/events"
@@ -23,6 +27,14 @@ type PostEventuploadHandler struct {
 
 	lambdaClient lambda.LambdaClient
 	enableLambda bool
+
+	// The event store keeps a short-lived copy of uploaded events in DynamoDB, so they
+	// can be inspected with the CLI without querying a data lake. The catalog aggregates
+	// the same events into fleet-wide statistics per binary, signing ID, team ID and certificate.
+	dynamodbClient   dynamodb.DynamoDBClient
+	timeProvider     clock.TimeProvider
+	enableEventStore bool
+	enableCatalog    bool
 }
 
 func (h *PostEventuploadHandler) Boot() (err error) {
@@ -54,6 +66,14 @@ func (h *PostEventuploadHandler) Boot() (err error) {
 		h.lambdaClient = lambda.GetClient(lambdaName, "$LATEST", region)
 	}
 
+	h.enableEventStore = strings.EqualFold(os.Getenv("STORE_EVENTS"), "true")
+	h.enableCatalog = strings.EqualFold(os.Getenv("UPDATE_CATALOG"), "true")
+	if h.enableEventStore || h.enableCatalog {
+		dynamodbTableName := os.Getenv("DYNAMODB_NAME")
+		h.dynamodbClient = dynamodb.GetClient(dynamodbTableName, region)
+		h.timeProvider = clock.ConcreteTimeProvider{}
+	}
+
 	h.booted = true
 	return
 }
@@ -63,7 +83,6 @@ func (h *PostEventuploadHandler) Handles(request events.APIGatewayProxyRequest)
 }
 
 func (h *PostEventuploadHandler) Handle(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
-	log.Printf("EventUploadHandler request:\n%+v\n", request)
 	ctx := context.Background()
 
 	machineID, eventsRequest, errorResponse, err := parseRequest(request)
@@ -71,27 +90,48 @@ func (h *PostEventuploadHandler) Handle(request events.APIGatewayProxyRequest) (
 		return errorResponse, err
 	}
 
-	if !h.enableFirehose && !h.enableKinesis && !h.enableLambda {
+	slog.Debug("EventUploadHandler request", "events", len(eventsRequest.Events))
+	metrics.Count("EventsPerUpload", len(eventsRequest.Events))
+
+	if !h.enableFirehose && !h.enableKinesis && !h.enableLambda && !h.enableEventStore && !h.enableCatalog {
 		// Shortcircuit if no handlers are enabled
-		log.Printf("No eventupload handlers are enabled")
+		slog.Info("No eventupload handlers are enabled")
 		return response.APIResponse(http.StatusOK, map[string]string{"status": "ok"})
 	}
 
 	if h.enableFirehose {
-		err = sendToFirehose(h.firehoseClient, machineID, eventsRequest.Events)
+		err = recordSinkFailure("firehose", sendToFirehose(h.firehoseClient, machineID, eventsRequest.Events))
 	}
 
 	if err == nil && h.enableKinesis {
-		err = sendToKinesis(h.kinesisClient, machineID, eventsRequest.Events)
+		err = recordSinkFailure("kinesis", sendToKinesis(h.kinesisClient, machineID, eventsRequest.Events))
 	}
 
 	if err == nil && h.enableLambda {
-		err = sendToLambda(ctx, h.lambdaClient, machineID, eventsRequest.Events)
+		err = recordSinkFailure("lambda", sendToLambda(ctx, h.lambdaClient, machineID, eventsRequest.Events))
 	}
 
 	if err != nil {
 		return response.APIResponse(http.StatusInternalServerError, response.ErrInternalServerErrorResponse)
 	}
 
+	// The event store and the catalog are best effort. Once the events have been delivered to the sinks above, failing
+	// the upload would only make the sensor retry, and deliver the same events again.
+	if h.enableEventStore {
+		_ = recordSinkFailure("event_store", sendToEventStore(h.dynamodbClient, h.timeProvider, machineID, eventsRequest.Events))
+	}
+
+	if h.enableCatalog {
+		_ = recordSinkFailure("catalog", sendToCatalog(h.dynamodbClient, h.timeProvider, machineID, eventsRequest.Events))
+	}
+
 	return response.APIResponse(http.StatusOK, map[string]string{"status": "ok"})
 }
+
+// recordSinkFailure counts the uploads that a sink failed to accept, and passes the error through
+func recordSinkFailure(sink string, err error) error {
+	if err != nil {
+		metrics.Count("SinkFailures", 1, metrics.Dim("Sink", sink))
+	}
+	return err
+}
@@ -2,10 +2,17 @@ package eventupload
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
 	"github.com/airbnb/rudolph/pkg/kinesis"
+	"github.com/airbnb/rudolph/pkg/metrics"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -148,10 +155,16 @@ func TestEventuploadHandler_Kinesis_InternalServerError(t *testing.T) {
 }]}`,
 		}
 
+		collector := &metrics.Collector{}
+		metrics.SetRecorder(collector)
+		defer metrics.SetRecorder(metrics.NopRecorder{})
+
 		resp, _ := h.Handle(request)
 
 		assert.Equal(t, 500, resp.StatusCode)
 		assert.Equal(t, `{"error":"Internal server error"}`, resp.Body)
+		assert.Equal(t, float64(1), collector.Sum("EventsPerUpload"))
+		assert.Equal(t, float64(1), collector.Sum("SinkFailures", metrics.Dim("Sink", "kinesis")))
 	})
 }
 
@@ -242,3 +255,166 @@ func TestEventuploadHandler_Kinesis_OK(t *testing.T) {
 		assert.Equal(t, `{"status":"ok"}`, resp.Body)
 	})
 }
+
+type testPutItemClient func(item interface{}) (*awsdynamodb.PutItemOutput, error)
+
+func (c testPutItemClient) PutItem(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+	return c(item)
+}
+
+type testDynamodbClient struct {
+	dynamodb.DynamoDBClient
+	testPutItemClient
+	testUpsertItemClient
+}
+
+func (c testDynamodbClient) PutItem(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+	return c.testPutItemClient(item)
+}
+
+func (c testDynamodbClient) UpsertItem(key dynamodb.PrimaryKey, update expression.UpdateBuilder) (*awsdynamodb.UpdateItemOutput, error) {
+	return c.testUpsertItemClient(key, update)
+}
+
+func (c testDynamodbClient) UpsertItemWithOptions(key dynamodb.PrimaryKey, update expression.UpdateBuilder, options dynamodb.UpsertOptions) (*awsdynamodb.UpdateItemOutput, error) {
+	return c.testUpsertItemClient(key, update)
+}
+
+type testUpsertItemClient func(key dynamodb.PrimaryKey, update expression.UpdateBuilder) (*awsdynamodb.UpdateItemOutput, error)
+
+func TestEventuploadHandler_EventStore_OK(t *testing.T) {
+	var stored []eventlog.EventRow
+	h := &PostEventuploadHandler{
+		enableEventStore: true,
+		timeProvider:     clock.Y2K{},
+		dynamodbClient: testDynamodbClient{
+			testPutItemClient: func(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+				stored = append(stored, item.(eventlog.EventRow))
+				return &awsdynamodb.PutItemOutput{}, nil
+			},
+		},
+	}
+
+	var request = events.APIGatewayProxyRequest{
+		HTTPMethod:     "POST",
+		Resource:       "/eventupload/{machine_id}",
+		PathParameters: map[string]string{"machine_id": "AAAAAAAA-A00A-1234-1234-5864377B4831"},
+		Headers:        map[string]string{"Content-Type": "application/json"},
+		Body: `{"events": [{
+	"file_path": "/usr/local/bin",
+	"file_name": "malware",
+	"executing_user": "john_doe",
+	"execution_time": 1619729340.537646,
+	"file_sha256": "35de834c7f280df703f57ff75b3486b9a04d73c0df96f9f6968db15fa86b8962",
+	"decision": "BLOCK_BINARY",
+	"team_id": "FNN8Z5JMFP",
+	"signing_chain": [
+		{
+			"cn":"Developer ID Application: My Application, Inc. (FNN8Z5JMFP)",
+			"sha256":"0000000b28b738354c43a11486651ca33266e2b7454477d6b351df09c2e97faf"
+		}
+	]
+}]}`,
+	}
+
+	resp, _ := h.Handle(request)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 1, len(stored))
+	assert.Equal(t, "AAAAAAAA-A00A-1234-1234-5864377B4831", stored[0].MachineID)
+	assert.Equal(t, "BLOCK_BINARY", stored[0].Decision)
+	assert.Equal(t, "2021-04-29T20:49:00Z", stored[0].ExecutedAt)
+	assert.Equal(t, "FNN8Z5JMFP", stored[0].TeamID)
+	assert.Equal(t, "0000000b28b738354c43a11486651ca33266e2b7454477d6b351df09c2e97faf", stored[0].SigningChain[0].SHA256)
+}
+
+func TestEventuploadHandler_EventStore_Failed(t *testing.T) {
+	h := &PostEventuploadHandler{
+		enableEventStore: true,
+		timeProvider:     clock.Y2K{},
+		dynamodbClient: testDynamodbClient{
+			testPutItemClient: func(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+				return nil, errors.New("throttled")
+			},
+		},
+	}
+
+	var request = events.APIGatewayProxyRequest{
+		HTTPMethod:     "POST",
+		Resource:       "/eventupload/{machine_id}",
+		PathParameters: map[string]string{"machine_id": "AAAAAAAA-A00A-1234-1234-5864377B4831"},
+		Headers:        map[string]string{"Content-Type": "application/json"},
+		Body:           `{"events": [{"file_sha256": "35de834c7f280df703f57ff75b3486b9a04d73c0df96f9f6968db15fa86b8962", "decision": "BLOCK_BINARY"}]}`,
+	}
+
+	resp, _ := h.Handle(request)
+
+	// The event store is best effort, so the upload still succeeds
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestEventuploadHandler_Catalog_OK(t *testing.T) {
+	var keys []dynamodb.PrimaryKey
+	h := &PostEventuploadHandler{
+		enableCatalog: true,
+		timeProvider:  clock.Y2K{},
+		dynamodbClient: testDynamodbClient{
+			testUpsertItemClient: func(key dynamodb.PrimaryKey, update expression.UpdateBuilder) (*awsdynamodb.UpdateItemOutput, error) {
+				keys = append(keys, key)
+				return &awsdynamodb.UpdateItemOutput{}, nil
+			},
+		},
+	}
+
+	var request = events.APIGatewayProxyRequest{
+		HTTPMethod:     "POST",
+		Resource:       "/eventupload/{machine_id}",
+		PathParameters: map[string]string{"machine_id": "AAAAAAAA-A00A-1234-1234-5864377B4831"},
+		Headers:        map[string]string{"Content-Type": "application/json"},
+		Body: `{"events": [
+	{"file_sha256": "35de834c7f280df703f57ff75b3486b9a04d73c0df96f9f6968db15fa86b8962", "decision": "ALLOW_UNKNOWN", "team_id": "FNN8Z5JMFP", "execution_time": 1619729340.5},
+	{"file_sha256": "35de834c7f280df703f57ff75b3486b9a04d73c0df96f9f6968db15fa86b8962", "decision": "ALLOW_UNKNOWN", "team_id": "FNN8Z5JMFP", "execution_time": 1619729345.5}
+]}`,
+	}
+
+	resp, _ := h.Handle(request)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	// Both events aggregate into the same binary and team ID entries, each of which records the machine once
+	var entries, members int
+	for _, key := range keys {
+		switch {
+		case strings.HasPrefix(key.PartitionKey, "CatalogMember#"):
+			members++
+		case strings.HasPrefix(key.PartitionKey, "Catalog#"):
+			entries++
+		}
+	}
+	assert.Equal(t, 2, entries)
+	assert.Equal(t, 2, members)
+}
+
+func TestEventuploadHandler_Catalog_Failed(t *testing.T) {
+	h := &PostEventuploadHandler{
+		enableCatalog: true,
+		timeProvider:  clock.Y2K{},
+		dynamodbClient: testDynamodbClient{
+			testUpsertItemClient: func(key dynamodb.PrimaryKey, update expression.UpdateBuilder) (*awsdynamodb.UpdateItemOutput, error) {
+				return nil, errors.New("item size has exceeded the maximum allowed size")
+			},
+		},
+	}
+
+	var request = events.APIGatewayProxyRequest{
+		HTTPMethod:     "POST",
+		Resource:       "/eventupload/{machine_id}",
+		PathParameters: map[string]string{"machine_id": "AAAAAAAA-A00A-1234-1234-5864377B4831"},
+		Headers:        map[string]string{"Content-Type": "application/json"},
+		Body:           `{"events": [{"file_sha256": "35de834c7f280df703f57ff75b3486b9a04d73c0df96f9f6968db15fa86b8962", "decision": "BLOCK_BINARY"}]}`,
+	}
+
+	resp, _ := h.Handle(request)
+
+	// The catalog is best effort, so the upload still succeeds
+	assert.Equal(t, 200, resp.StatusCode)
+}
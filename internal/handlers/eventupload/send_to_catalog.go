@@ -0,0 +1,62 @@
+package eventupload
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/catalog"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+)
+
+func sendToCatalog(
+	client dynamodb.ConditionalUpsertItemAPI,
+	timeProvider clock.TimeProvider,
+	machineID string,
+	events []EventUploadEvent,
+) error {
+	err := catalog.RecordObservations(client, timeProvider, convertRequestEventsToObservations(timeProvider, machineID, events))
+	if err != nil {
+		slog.Error("Catalog Failed", "error", err)
+		return fmt.Errorf("failed to record events in catalog: %w", err)
+	}
+
+	return nil
+}
+
+func convertRequestEventsToObservations(timeProvider clock.TimeProvider, machineID string, events []EventUploadEvent) []catalog.Observation {
+	var observations []catalog.Observation
+
+	for _, event := range events {
+		executedAt := clock.RFC3339(timeProvider.Now())
+		if event.ExecutionTime > 0 {
+			executedAt = eventlog.ExecutionTimeToRFC3339(event.ExecutionTime)
+		}
+
+		observation := catalog.Observation{
+			MachineID:     machineID,
+			ExecutingUser: event.ExecutingUser,
+			Decision:      event.Decision,
+			ExecutedAt:    executedAt,
+			FileSHA256:    event.FileSHA256,
+			FileName:      event.FileName,
+			FilePath:      event.FilePath,
+			SigningID:     event.SigningIDs,
+			TeamID:        event.TeamID,
+			BundleID:      event.FileBundleID,
+			BundleName:    event.FileBundleName,
+			BundleVersion: event.FileBundleShortVersionString,
+		}
+
+		// The leaf certificate is the one that certificate rules match against
+		if len(event.SigningChain) > 0 {
+			observation.CertificateSHA256 = event.SigningChain[0].SHA256
+			observation.CertificateCN = event.SigningChain[0].CertificateName
+		}
+
+		observations = append(observations, observation)
+	}
+
+	return observations
+}
@@ -0,0 +1,66 @@
+package eventupload
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+)
+
+func sendToEventStore(
+	client dynamodb.PutItemAPI,
+	timeProvider clock.TimeProvider,
+	machineID string,
+	events []EventUploadEvent,
+) error {
+	err := eventlog.AddEvents(client, timeProvider, machineID, convertRequestEventsToStoredEvents(events))
+	if err != nil {
+		slog.Error("Event store Failed", "error", err)
+		return fmt.Errorf("failed to store events in DynamoDB: %w", err)
+	}
+
+	return nil
+}
+
+func convertRequestEventsToStoredEvents(events []EventUploadEvent) []eventlog.Event {
+	var storedEvents []eventlog.Event
+
+	for _, event := range events {
+		var signingChain []eventlog.SigningCertificate
+		for _, entry := range event.SigningChain {
+			signingChain = append(signingChain, eventlog.SigningCertificate{
+				SHA256:             entry.SHA256,
+				CommonName:         entry.CertificateName,
+				Organization:       entry.Organization,
+				OrganizationalUnit: entry.OrganizationalUnit,
+				ValidFrom:          entry.ValidFrom,
+				ValidUntil:         entry.ValidUntil,
+			})
+		}
+
+		var executedAt string
+		if event.ExecutionTime > 0 {
+			executedAt = eventlog.ExecutionTimeToRFC3339(event.ExecutionTime)
+		}
+
+		storedEvents = append(storedEvents, eventlog.Event{
+			Decision:      event.Decision,
+			FileSHA256:    event.FileSHA256,
+			FilePath:      event.FilePath,
+			FileName:      event.FileName,
+			ExecutingUser: event.ExecutingUser,
+			ExecutedAt:    executedAt,
+			ExecutionTime: event.ExecutionTime,
+			SigningID:     event.SigningIDs,
+			TeamID:        event.TeamID,
+			CDHash:        event.CDHash,
+			BundleID:      event.FileBundleID,
+			ParentName:    event.ParentName,
+			SigningChain:  signingChain,
+		})
+	}
+
+	return storedEvents
+}
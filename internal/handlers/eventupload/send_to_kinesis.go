@@ -2,7 +2,7 @@ package eventupload
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/airbnb/rudolph/pkg/kinesis"
 )
@@ -11,7 +11,7 @@ func sendToKinesis(kinesisClient kinesis.KinesisClient, machineID string, events
 	var forwardedEvents = convertRequestEventsToUploadEvents(machineID, events)
 	err := kinesisClient.Send(machineID, kinesis.KinesisEvents{Items: forwardedEvents})
 	if err != nil {
-		log.Printf("Kinesis Failed: %s", err)
+		slog.Error("Kinesis Failed", "error", err)
 		return fmt.Errorf("failed to events to AWS kinesis: %w", err)
 	}
 
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/airbnb/rudolph/internal/handlers/jobs"
+	"github.com/airbnb/rudolph/internal/handlers/lockdownpromotion"
+	"github.com/airbnb/rudolph/internal/handlers/synchealth"
+	"github.com/airbnb/rudolph/pkg/logging"
+	"github.com/airbnb/rudolph/pkg/metrics"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+var (
+	jobHandlers []JobHandlerInterface
+)
+
+func init() {
+	jobHandlers = []JobHandlerInterface{
+		&lockdownpromotion.LockdownPromotionHandler{},
+		&synchealth.SyncHealthHandler{},
+	}
+}
+
+// JobRouter handles scheduled invocations. The schedule passes a constant input naming the job to run, and every
+// line logged by the job carries the Lambda request ID.
+func JobRouter(ctx context.Context, request jobs.JobRequest) error {
+	var requestID string
+	if lambdaContext, ok := lambdacontext.FromContext(ctx); ok {
+		requestID = lambdaContext.AwsRequestID
+	}
+	logger := logging.ForRequest(requestID, "").With("job", request.Job)
+	slog.SetDefault(logger)
+	logger.Info("Job Request")
+
+	for _, h := range jobHandlers {
+		if h.Handles(request) {
+			start := time.Now()
+			err := h.Boot()
+			if err == nil {
+				err = h.Handle(request)
+			}
+			if err != nil {
+				logger.Error("Job ERROR", "error", err)
+			}
+			recordJobMetrics(request, err, time.Since(start))
+			return err
+		}
+	}
+
+	logger.Error("JobRouter failure: unknown job")
+	return fmt.Errorf("unknown job %q", request.Job)
+}
+
+// recordJobMetrics counts every job run by job and result, and times it by job
+func recordJobMetrics(request jobs.JobRequest, err error, duration time.Duration) {
+	job := metrics.Dim("Job", request.Job)
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	metrics.Count("JobRuns", 1, job, metrics.Dim("Result", result))
+	metrics.Duration("JobDuration", duration, job)
+}
+
+type JobHandlerInterface interface {
+	Handles(request jobs.JobRequest) bool
+	Boot() error
+	Handle(request jobs.JobRequest) error
+}
@@ -0,0 +1,12 @@
+package jobs
+
+// JobRequest is the payload of a scheduled invocation, set as the constant input of the schedule's target
+type JobRequest struct {
+	Job string `json:"job"`
+}
+
+// Names of the jobs that can be scheduled
+const (
+	JobLockdownPromotion = "lockdown_promotion"
+	JobSyncHealth        = "sync_health"
+)
@@ -0,0 +1,121 @@
+package lockdownpromotion
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/internal/handlers/jobs"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/lockdown"
+)
+
+// LockdownPromotionHandler periodically promotes ready MONITOR machines to LOCKDOWN, and demotes recent promotions
+// that are being blocked. Every guardrail is configured through the environment; see lockdown.PromotionPolicy.
+type LockdownPromotionHandler struct {
+	booted       bool
+	store        lockdown.PromotionStore
+	timeProvider clock.TimeProvider
+	policy       lockdown.PromotionPolicy
+}
+
+func (h *LockdownPromotionHandler) Boot() (err error) {
+	if h.booted {
+		return
+	}
+
+	region := os.Getenv("REGION")
+	dynamodbTableName := os.Getenv("DYNAMODB_NAME")
+
+	policy, err := policyFromEnv(os.Getenv)
+	if err != nil {
+		return
+	}
+
+	h.timeProvider = clock.ConcreteTimeProvider{}
+	h.store = lockdown.GetPromotionStore(dynamodb.GetClient(dynamodbTableName, region), h.timeProvider)
+	h.policy = policy
+	h.booted = true
+	return
+}
+
+func (h *LockdownPromotionHandler) Handles(request jobs.JobRequest) bool {
+	return request.Job == jobs.JobLockdownPromotion
+}
+
+func (h *LockdownPromotionHandler) Handle(request jobs.JobRequest) error {
+	result, err := lockdown.RunPromotion(h.store, h.timeProvider, h.policy)
+	if err != nil {
+		return fmt.Errorf("lockdown promotion failed: %w", err)
+	}
+
+	for _, outcome := range result.Demoted {
+		log.Printf("Demoted %s to MONITOR: %s", outcome.MachineID, outcome.Reason)
+	}
+	for _, outcome := range result.Promoted {
+		log.Printf("Promoted %s to LOCKDOWN: %s", outcome.MachineID, outcome.Reason)
+	}
+	log.Printf(
+		"Lockdown promotion complete (dry run: %t): %d demoted, %d promoted, %d skipped, daily cap reached: %t",
+		result.DryRun,
+		len(result.Demoted),
+		len(result.Promoted),
+		len(result.Skipped),
+		result.CapReached,
+	)
+	return nil
+}
+
+// policyFromEnv starts from the default policy and overrides every value that is set in the environment
+func policyFromEnv(getenv func(string) string) (policy lockdown.PromotionPolicy, err error) {
+	policy = lockdown.DefaultPromotionPolicy()
+
+	if policy.MonitorDays, err = intFromEnv(getenv, "PROMOTION_MONITOR_DAYS", policy.MonitorDays); err != nil {
+		return
+	}
+	if policy.DailyPromotionCap, err = intFromEnv(getenv, "PROMOTION_DAILY_CAP", policy.DailyPromotionCap); err != nil {
+		return
+	}
+	if policy.DemotionBlockThreshold, err = intFromEnv(getenv, "DEMOTION_BLOCK_THRESHOLD", policy.DemotionBlockThreshold); err != nil {
+		return
+	}
+	demotionWindowHours, err := intFromEnv(getenv, "DEMOTION_WINDOW_HOURS", int(policy.DemotionWindow/time.Hour))
+	if err != nil {
+		return
+	}
+	policy.DemotionWindow = time.Duration(demotionWindowHours) * time.Hour
+
+	policy.CandidateGroups = listFromEnv(getenv, "PROMOTION_GROUPS")
+	policy.ExcludedGroups = listFromEnv(getenv, "PROMOTION_EXCLUDED_GROUPS")
+	policy.DryRun = strings.EqualFold(getenv("PROMOTION_DRY_RUN"), "true")
+
+	err = policy.Validate()
+	return
+}
+
+func intFromEnv(getenv func(string) string, name string, defaultValue int) (int, error) {
+	value := getenv(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+	return parsed, nil
+}
+
+// listFromEnv splits a comma separated list, ignoring blank entries
+func listFromEnv(getenv func(string) string, name string) (values []string) {
+	for _, value := range strings.Split(getenv(name), ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return
+}
@@ -0,0 +1,58 @@
+package lockdownpromotion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/internal/handlers/jobs"
+	"github.com/airbnb/rudolph/pkg/lockdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func envOf(values map[string]string) func(string) string {
+	return func(name string) string {
+		return values[name]
+	}
+}
+
+func Test_PolicyFromEnv_Defaults(t *testing.T) {
+	policy, err := policyFromEnv(envOf(nil))
+
+	assert.Empty(t, err)
+	assert.Equal(t, lockdown.DefaultPromotionPolicy(), policy)
+}
+
+func Test_PolicyFromEnv_Overrides(t *testing.T) {
+	policy, err := policyFromEnv(envOf(map[string]string{
+		"PROMOTION_MONITOR_DAYS":    "21",
+		"PROMOTION_DAILY_CAP":       "100",
+		"PROMOTION_GROUPS":          "wave-1, wave-2",
+		"PROMOTION_EXCLUDED_GROUPS": "executives,,",
+		"DEMOTION_WINDOW_HOURS":     "6",
+		"DEMOTION_BLOCK_THRESHOLD":  "3",
+		"PROMOTION_DRY_RUN":         "true",
+	}))
+
+	assert.Empty(t, err)
+	assert.Equal(t, 21, policy.MonitorDays)
+	assert.Equal(t, 100, policy.DailyPromotionCap)
+	assert.Equal(t, []string{"wave-1", "wave-2"}, policy.CandidateGroups)
+	assert.Equal(t, []string{"executives"}, policy.ExcludedGroups)
+	assert.Equal(t, 6*time.Hour, policy.DemotionWindow)
+	assert.Equal(t, 3, policy.DemotionBlockThreshold)
+	assert.True(t, policy.DryRun)
+}
+
+func Test_PolicyFromEnv_Invalid(t *testing.T) {
+	_, err := policyFromEnv(envOf(map[string]string{"PROMOTION_DAILY_CAP": "lots"}))
+	assert.Error(t, err)
+
+	_, err = policyFromEnv(envOf(map[string]string{"PROMOTION_MONITOR_DAYS": "0"}))
+	assert.Error(t, err)
+}
+
+func Test_Handles(t *testing.T) {
+	h := &LockdownPromotionHandler{}
+	assert.True(t, h.Handles(jobs.JobRequest{Job: jobs.JobLockdownPromotion}))
+	assert.False(t, h.Handles(jobs.JobRequest{Job: "something_else"}))
+}
@@ -1,7 +1,7 @@
 package postflight
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 
@@ -59,7 +59,7 @@ func (h *PostPostflightHandler) Handle(request events.APIGatewayProxyRequest) (*
 	// like "10 minutes ago and leaving it like that. Pretty dumb, derek!"
 	err = h.syncStateUpdater.updatePostflightDate(machineID)
 	if err != nil {
-		log.Printf("Failed to set final PostflightAt")
+		slog.Error("Failed to set final PostflightAt", "error", err)
 		return response.APIResponse(http.StatusInternalServerError, err)
 	}
 
@@ -2,7 +2,7 @@ package postflight
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 
 	apirequest "github.com/airbnb/rudolph/pkg/request"
@@ -27,7 +27,7 @@ func parseRequest(request events.APIGatewayProxyRequest) (machineID string, pars
 		// Parse the request
 		err = json.Unmarshal([]byte(request.Body), &parsedRequest)
 		if err != nil {
-			log.Printf("%s\n%s", err.Error(), "request body unmarshal was not successful")
+			slog.Warn("request body unmarshal was not successful", "error", err)
 			errorResponse, err = response.APIResponse(http.StatusBadRequest, response.ErrInvalidBodyResponse)
 			return
 		}
@@ -1,7 +1,7 @@
 package postflight
 
 import (
-	"log"
+	"log/slog"
 
 	"github.com/airbnb/rudolph/pkg/clock"
 	"github.com/airbnb/rudolph/pkg/dynamodb"
@@ -17,7 +17,7 @@ func archiveSyncState(client dynamodb.DynamoDBClient, machineID string) (err err
 
 	err = syncstate.Archive(client, *syncState)
 	if err != nil {
-		log.Printf("Failed to archive syncState")
+		slog.Error("Failed to archive syncState", "error", err)
 		return
 	}
 
@@ -47,14 +47,14 @@ type concreteRuleDestroyer struct {
 }
 
 func (c concreteRuleDestroyer) destroyMachineRulesMarkedForDeletion(machineID string) (err error) {
-	log.Printf("Now evicting stale machine rules")
+	slog.Debug("Now evicting stale machine rules")
 
 	keysToDelete, err := machinerules.GetPrimaryKeysByMachineIDWhereMarkedForDeletion(c.queryer, machineID)
 	if err != nil {
 		return
 	}
 
-	log.Printf("Found %d stale machine rules to delete", len(*keysToDelete))
+	slog.Info("Found stale machine rules to delete", "count", len(*keysToDelete))
 
 	for _, keyToDelete := range *keysToDelete {
 		_, err = c.deleter.DeleteItem(keyToDelete)
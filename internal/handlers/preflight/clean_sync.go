@@ -5,12 +5,13 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math"
 	"math/big"
 	"strconv"
 
 	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/metrics"
 	"github.com/airbnb/rudolph/pkg/model/syncstate"
 )
 
@@ -22,6 +23,29 @@ const (
 	daysToElapseUntilRefreshCleanSync = 7
 )
 
+// Reasons for a clean sync, as reported in the Syncs metric
+const (
+	// cleanSyncReasonRequested is a sensor that asked for a clean sync itself
+	cleanSyncReasonRequested = "requested"
+	// cleanSyncReasonNoFeedSyncCursor is a machine without a previous sync state, usually a new machine
+	cleanSyncReasonNoFeedSyncCursor = "no_feed_sync_cursor"
+	// cleanSyncReasonNoRules is a sensor that reported holding no rules at all
+	cleanSyncReasonNoRules = "no_rules"
+	// cleanSyncReasonRefresh is the periodic refresh of a machine's rules
+	cleanSyncReasonRefresh = "periodic_refresh"
+)
+
+// recordSyncMetrics counts every sync started by preflight, by type and by the reason for clean syncs. Incremental
+// syncs have the reason "none", so that both types share the same dimensions.
+func recordSyncMetrics(performCleanSync bool, reason string) {
+	syncType := "clean"
+	if !performCleanSync {
+		syncType = "incremental"
+		reason = "none"
+	}
+	metrics.Count("Syncs", 1, metrics.Dim("SyncType", syncType), metrics.Dim("Reason", reason))
+}
+
 // Steps to determine if a Clean Sync must be forced upon a requesting machine
 // 1. Determined via the rules counts, ie if the returned number of rules from the machine equals zero, force a clean sync
 //    Note: the DB may also have zero rules but this is fine then as the resulting sync time is the same
@@ -78,7 +102,7 @@ func daysSinceLastCleanSync(timeProvider clock.TimeProvider, prevSyncState *sync
 	lastCleanSyncTime, err := clock.ParseRFC3339(prevSyncState.LastCleanSync)
 	if err != nil {
 		// Disregard the error
-		log.Printf("failed to determine number of days since last sync from value: (%s); going to clean sync anyway", prevSyncState.LastCleanSync)
+		slog.Warn("failed to determine number of days since last sync; going to clean sync anyway", "last_clean_sync", prevSyncState.LastCleanSync, "error", err)
 		return infinity
 	}
 
@@ -96,14 +96,17 @@ func (h *PostPreflightHandler) handlePreflight(machineID string, preflightReques
 	// Determine if a Clean sync should be performed based on the preflight request
 	// if the machine needs a periodic refresh
 	// or if the machine is new
+	var cleanSyncReason string
 	switch preflightRequest.RequestCleanSync {
 	case true:
 		performCleanSync = true
+		cleanSyncReason = cleanSyncReasonRequested
 	case false:
 		// Retrieve the current feed sync cursor
 		feedSyncCursor, performCleanSync = h.stateTrackingService.getFeedSyncStateCursor(prevSyncState)
 		// If a clean sync should be forced, break out and do it now
 		if performCleanSync {
+			cleanSyncReason = cleanSyncReasonNoFeedSyncCursor
 			break
 		}
 		// Determine if a refresh clean sync should be performed
@@ -115,16 +118,29 @@ func (h *PostPreflightHandler) handlePreflight(machineID string, preflightReques
 		if err != nil {
 			return response.APIResponse(http.StatusInternalServerError, err)
 		}
+		if performCleanSync {
+			cleanSyncReason = cleanSyncReasonRefresh
+			if determineCleanSyncByRuleCount(preflightRequest) {
+				cleanSyncReason = cleanSyncReasonNoRules
+			}
+		}
 	default:
 		performCleanSync = true
+		cleanSyncReason = cleanSyncReasonRequested
 	}
 
 	// Set up a syncState object which will track the progress of the currently requested sync
 	// Here we use dynamodb:PutItem to restart the whole process, wipe out any previous sync
 	var lastCleanSyncTime string
+	var consecutiveCleanSyncs int
 	switch performCleanSync {
 	case true:
 		lastCleanSyncTime = clock.RFC3339(h.timeProvider.Now())
+		// Count clean syncs in a row, so that machines stuck clean syncing over and over can be reported
+		consecutiveCleanSyncs = 1
+		if prevSyncState != nil {
+			consecutiveCleanSyncs += prevSyncState.ConsecutiveCleanSyncs
+		}
 	case false:
 		lastCleanSyncTime = prevSyncState.LastCleanSync
 	}
@@ -135,6 +151,7 @@ func (h *PostPreflightHandler) handlePreflight(machineID string, preflightReques
 		// in the /ruledownload step which strategy to use
 		performCleanSync,
 		lastCleanSyncTime,
+		consecutiveCleanSyncs,
 		machineConfiguration.BatchSize,
 		feedSyncCursor,
 	)
@@ -144,6 +161,8 @@ func (h *PostPreflightHandler) handlePreflight(machineID string, preflightReques
 		return response.APIResponse(http.StatusInternalServerError, err)
 	}
 
+	recordSyncMetrics(performCleanSync, cleanSyncReason)
+
 	// Construct the response
 	preflightResponse := ConstructPreflightResponse(machineConfiguration, performCleanSync)
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/airbnb/rudolph/pkg/clock"
 	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/metrics"
 	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
 	"github.com/airbnb/rudolph/pkg/model/sensordata"
 	"github.com/airbnb/rudolph/pkg/model/syncstate"
@@ -194,7 +195,7 @@ func TestHandler_OK(t *testing.T) {
 	}, nil)
 
 	mockedStateTracking.On("PutItem", mock.MatchedBy(func(syncState syncstate.SyncStateRow) bool {
-		return syncState.MachineID == inputMachineID && syncState.BatchSize == 37 && syncState.LastCleanSync == "2000-01-01T00:00:00Z" && syncState.FeedSyncCursor == "2000-01-01T00:00:00Z"
+		return syncState.MachineID == inputMachineID && syncState.BatchSize == 37 && syncState.LastCleanSync == "2000-01-01T00:00:00Z" && syncState.FeedSyncCursor == "2000-01-01T00:00:00Z" && syncState.ConsecutiveCleanSyncs == 1
 	})).Return(&awsdynamodb.PutItemOutput{}, nil)
 
 	mockedStateTracking.On("PutItem", mock.MatchedBy(func(sensorData sensordata.SensorData) bool {
@@ -328,7 +329,7 @@ func TestHandler_OK_Refresh_CleanSync(t *testing.T) {
 
 	// mockedStateTracking.On("PutItem", mock.MatchedBy(func(item interface{}) bool {
 	mockedStateTracking.On("PutItem", mock.MatchedBy(func(syncState syncstate.SyncStateRow) bool {
-		return syncState.PrimaryKey.PartitionKey == "Machine#AAAAAAAA-A00A-1234-1234-5864377B4831" && syncState.MachineID == inputMachineID && syncState.BatchSize == 37 && syncState.LastCleanSync == "2001-01-01T00:00:00Z" && syncState.FeedSyncCursor == "2000-12-15T00:00:00Z" && syncState.CleanSync == true
+		return syncState.PrimaryKey.PartitionKey == "Machine#AAAAAAAA-A00A-1234-1234-5864377B4831" && syncState.MachineID == inputMachineID && syncState.BatchSize == 37 && syncState.LastCleanSync == "2001-01-01T00:00:00Z" && syncState.FeedSyncCursor == "2000-12-15T00:00:00Z" && syncState.CleanSync == true && syncState.ConsecutiveCleanSyncs == 1
 	})).Return(&awsdynamodb.PutItemOutput{}, nil)
 
 	mockedStateTracking.On("PutItem", mock.MatchedBy(func(sensorData sensordata.SensorData) bool {
@@ -378,6 +379,10 @@ func TestHandler_OK_Refresh_CleanSync(t *testing.T) {
 		cleanSyncService:            getCleanSyncService(timeProvider),
 	}
 
+	collector := &metrics.Collector{}
+	metrics.SetRecorder(collector)
+	defer metrics.SetRecorder(metrics.NopRecorder{})
+
 	resp, err := h.Handle(request)
 
 	assert.Empty(t, err)
@@ -385,6 +390,7 @@ func TestHandler_OK_Refresh_CleanSync(t *testing.T) {
 
 	// Ensure that the response matches the configuration returned
 	assert.Equal(t, `{"client_mode":"LOCKDOWN","blocked_path_regex":"","allowed_path_regex":"(^/Applications)","batch_size":37,"enable_bundles":true,"enable_transitive_rules":false,"upload_logs_url":"/aaa","sync_type":"clean"}`, resp.Body)
+	assert.Equal(t, float64(1), collector.Sum("Syncs", metrics.Dim("SyncType", "clean"), metrics.Dim("Reason", "periodic_refresh")))
 }
 
 func TestHandler_OK_No_Refresh_CleanSync(t *testing.T) {
@@ -452,7 +458,7 @@ func TestHandler_OK_No_Refresh_CleanSync(t *testing.T) {
 
 	// mockedStateTracking.On("PutItem", mock.MatchedBy(func(item interface{}) bool {
 	mockedStateTracking.On("PutItem", mock.MatchedBy(func(syncState syncstate.SyncStateRow) bool {
-		return syncState.PrimaryKey.PartitionKey == "Machine#AAAAAAAA-A00A-1234-1234-5864377B4831" && syncState.MachineID == inputMachineID && syncState.BatchSize == 37 && syncState.LastCleanSync == "2000-12-31T00:00:00Z" && syncState.FeedSyncCursor == "2000-12-31T00:00:00Z" && syncState.CleanSync == false
+		return syncState.PrimaryKey.PartitionKey == "Machine#AAAAAAAA-A00A-1234-1234-5864377B4831" && syncState.MachineID == inputMachineID && syncState.BatchSize == 37 && syncState.LastCleanSync == "2000-12-31T00:00:00Z" && syncState.FeedSyncCursor == "2000-12-31T00:00:00Z" && syncState.CleanSync == false && syncState.ConsecutiveCleanSyncs == 0
 	})).Return(&awsdynamodb.PutItemOutput{}, nil)
 
 	mockedStateTracking.On("PutItem", mock.MatchedBy(func(sensorData sensordata.SensorData) bool {
@@ -466,6 +472,10 @@ func TestHandler_OK_No_Refresh_CleanSync(t *testing.T) {
 		cleanSyncService:            getCleanSyncService(timeProvider),
 	}
 
+	collector := &metrics.Collector{}
+	metrics.SetRecorder(collector)
+	defer metrics.SetRecorder(metrics.NopRecorder{})
+
 	resp, err := h.Handle(request)
 
 	assert.Empty(t, err)
@@ -473,4 +483,5 @@ func TestHandler_OK_No_Refresh_CleanSync(t *testing.T) {
 
 	// Ensure that the response matches the configuration returned
 	assert.Equal(t, `{"client_mode":"LOCKDOWN","blocked_path_regex":"","allowed_path_regex":"","batch_size":37,"enable_bundles":true,"enable_transitive_rules":false,"upload_logs_url":"/aaa","sync_type":"normal"}`, resp.Body)
+	assert.Equal(t, float64(1), collector.Sum("Syncs", metrics.Dim("SyncType", "incremental"), metrics.Dim("Reason", "none")))
 }
@@ -24,7 +24,7 @@ func getCleanSyncService(timeProvider clock.TimeProvider) cleanSyncService {
 type stateTrackingService interface {
 	saveSensorDataFromPreflightRequest(machineID string, request *PreflightRequest) error
 	getSyncState(machineID string) (syncState *syncstate.SyncStateRow, err error)
-	saveSyncState(machineID string, requestCleanSync bool, lastCleanSync string, batchSize int, feedSyncCursor string) error
+	saveSyncState(machineID string, requestCleanSync bool, lastCleanSync string, consecutiveCleanSyncs int, batchSize int, feedSyncCursor string) error
 	getFeedSyncStateCursor(syncState *syncstate.SyncStateRow) (string, bool)
 }
 
@@ -53,6 +53,7 @@ func (c concreteStateTrackingService) saveSensorDataFromPreflightRequest(machine
 		request.ClientMode,
 		request.RequestCleanSync,
 		request.PrimaryUser,
+		request.ModelIdentifier,
 		request.CertificateRuleCount,
 		request.BinaryRuleCount,
 		request.CDHashRuleCount,
@@ -69,12 +70,13 @@ func (c concreteStateTrackingService) getSyncState(machineID string) (syncState
 	return syncstate.GetByMachineID(c.getter, machineID)
 }
 
-func (c concreteStateTrackingService) saveSyncState(machineID string, requestCleanSync bool, lastCleanSync string, batchSize int, feedSyncCursor string) error {
+func (c concreteStateTrackingService) saveSyncState(machineID string, requestCleanSync bool, lastCleanSync string, consecutiveCleanSyncs int, batchSize int, feedSyncCursor string) error {
 	syncState := syncstate.CreateNewSyncState(
 		c.timeProvider,
 		machineID,
 		requestCleanSync,
 		lastCleanSync,
+		consecutiveCleanSyncs,
 		batchSize,
 		feedSyncCursor,
 	)
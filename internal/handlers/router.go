@@ -1,15 +1,20 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/airbnb/rudolph/internal/handlers/admin"
 	"github.com/airbnb/rudolph/internal/handlers/eventupload"
 	"github.com/airbnb/rudolph/internal/handlers/health"
 	"github.com/airbnb/rudolph/internal/handlers/postflight"
 	"github.com/airbnb/rudolph/internal/handlers/preflight"
 	"github.com/airbnb/rudolph/internal/handlers/ruledownload"
 	"github.com/airbnb/rudolph/internal/handlers/xsrf"
+	"github.com/airbnb/rudolph/pkg/logging"
+	"github.com/airbnb/rudolph/pkg/metrics"
 	"github.com/airbnb/rudolph/pkg/response"
 	"github.com/aws/aws-lambda-go/events"
 )
@@ -26,33 +31,61 @@ func init() {
 		&ruledownload.PostRuledownloadHandler{},
 		&postflight.PostPostflightHandler{},
 		&xsrf.PostXSRFHandler{},
+		&admin.RulesHandler{},
+		&admin.MachineRulesHandler{},
+		&admin.ConfigHandler{},
+		&admin.MachinesHandler{},
+		&admin.WhoAmIHandler{},
+		&admin.BlocksHandler{},
+		&admin.UnblockRequestsHandler{},
 	}
 }
 
 func ApiRouter(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
-	log.Printf("Api Request: %+v", request)
+	logger := logging.ForRequest(request.RequestContext.RequestID, request.PathParameters["machine_id"])
+	logger.Info("Api Request", "method", request.HTTPMethod, "resource", request.Resource, "body_bytes", len(request.Body))
+	logger.Debug("Api Request body", "body", request.Body)
 
+	start := time.Now()
 	response, err := getResponse(request)
+	latency := time.Since(start)
 
 	if err != nil {
-		log.Printf("Api ERROR: %+v", err)
+		logger.Error("Api ERROR", "error", err)
 	}
-	log.Printf("Api Response: %+v", response)
+	if response != nil {
+		logger.Info("Api Response", "status", response.StatusCode, "body_bytes", len(response.Body), "latency_ms", latency.Milliseconds())
+		logger.Debug("Api Response body", "body", response.Body)
+	}
+	recordRequestMetrics(request, response, err, latency)
 
 	return response, err
 }
 
+// recordRequestMetrics counts every request by endpoint and status code, and times it by endpoint. The endpoint is
+// the API Gateway resource, such as /preflight/{machine_id}, so that it never contains a machine ID.
+func recordRequestMetrics(request events.APIGatewayProxyRequest, response *events.APIGatewayProxyResponse, err error, latency time.Duration) {
+	endpoint := metrics.Dim("Endpoint", request.Resource)
+	status := "error"
+	if err == nil && response != nil {
+		status = strconv.Itoa(response.StatusCode)
+	}
+	metrics.Count("Requests", 1, endpoint, metrics.Dim("StatusCode", status))
+	metrics.Duration("Latency", latency, endpoint)
+}
+
 func getResponse(request events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
 	for _, h := range handlers {
 		if h.Handles(request) {
-			if h.Boot() != nil {
+			if err := h.Boot(); err != nil {
+				slog.Error("Api ERROR: failed to boot handler", "error", err)
 				return response.APIResponse(http.StatusInternalServerError, nil)
 			}
 			return h.Handle(request)
 		}
 	}
 
-	log.Printf("ERROR: ApiRouter failure: unrouteable request: [%+v] %+v", request.HTTPMethod, request.Resource)
+	slog.Error("ApiRouter failure: unrouteable request", "method", request.HTTPMethod, "resource", request.Resource)
 	return response.APIResponse(http.StatusMethodNotAllowed, nil)
 }
 
@@ -2,7 +2,7 @@ package ruledownload
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 
 	"github.com/airbnb/rudolph/pkg/dynamodb"
@@ -27,18 +27,18 @@ func (d concreteGlobalRuleDownloader) handle(machineID string, cursor ruledownlo
 	ddbCursor := cursor.GetLastEvaluatedKey()
 	globalRules, lastEvaluatedKey, err := globalrules.GetPaginatedGlobalRules(d.queryer, cursor.BatchSize, ddbCursor)
 	if err != nil {
-		log.Printf("  GetPaginatedGlobalRules Error %s", err.Error())
+		slog.Error("GetPaginatedGlobalRules Error", "error", err)
 		return response.APIResponse(http.StatusInternalServerError, err)
 	}
 
-	log.Printf("  lastEvaluatedKey %s", lastEvaluatedKey)
+	slog.Debug("Ruledownload clean page", "last_evaluated_key", lastEvaluatedKey)
 
 	nextCursor := cursor.CloneForNextPage()
 	if lastEvaluatedKey == nil {
-		log.Printf("     No more stuff to paginate over")
+		slog.Debug("No more stuff to paginate over")
 		nextCursor.SetStrategy(ruledownloadStrategyMachine)
 	} else {
-		log.Printf("     More stuff to paginate over")
+		slog.Debug("More stuff to paginate over")
 		nextCursor.SetDynamodbLastEvaluatedKey(lastEvaluatedKey)
 	}
 
@@ -50,10 +50,12 @@ func (d concreteGlobalRuleDownloader) handle(machineID string, cursor ruledownlo
 	// Marshal the cursor to a string
 	jsonCursor, err := json.Marshal(nextCursor)
 	if err != nil {
-		log.Printf("  json.Marshal Error %s", err.Error())
+		slog.Error("json.Marshal Error", "error", err)
 		return response.APIResponse(http.StatusInternalServerError, err)
 	}
 
+	recordRulesPerPage(ruledownloadStrategyClean, len(rules))
+
 	return response.APIResponse(
 		http.StatusOK,
 		RuledownloadResponse{
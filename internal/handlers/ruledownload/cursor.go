@@ -2,7 +2,7 @@ package ruledownload
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/airbnb/rudolph/pkg/clock"
 	"github.com/airbnb/rudolph/pkg/dynamodb"
@@ -73,6 +73,19 @@ const (
 	ruledownloadStrategyMachine
 )
 
+// String names the strategy in metrics
+func (s ruledownloadStrategy) String() string {
+	switch s {
+	case ruledownloadStrategyClean:
+		return "clean"
+	case ruledownloadStrategyIncremental:
+		return "incremental"
+	case ruledownloadStrategyMachine:
+		return "machine"
+	}
+	return "unknown"
+}
+
 type ruledownloadCursorService interface {
 	ConstructCursor(ruledownloadRequest RuledownloadRequest, machineID string) (cursor ruledownloadCursor, err error)
 }
@@ -95,7 +108,7 @@ func (c concreteRuledownloadCursorService) ConstructCursor(ruledownloadRequest R
 		//  * note down that ruledownload has started
 		//  * determine the ruledownload strategy
 		//  * embed any context, including the desired strategy, into the next cursor
-		log.Printf("  Ruledownload first page")
+		slog.Debug("Ruledownload first page")
 
 		// Get the sync state as set by /preflight. The sync state will contain the final result of the previous
 		syncState, eerr := syncstate.GetByMachineID(c.getter, machineID)
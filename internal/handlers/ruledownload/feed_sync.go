@@ -2,7 +2,7 @@ package ruledownload
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 
 	"github.com/airbnb/rudolph/pkg/dynamodb"
@@ -12,31 +12,6 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 )
 
-// func handleRuleDownloadFromFeed(machineID string, cursor *ruledownloadCursor) (*events.APIGatewayProxyResponse, error) {
-// 	ddbCursor := cursor.GetLastEvaluatedKey()
-// 	globalRules, lastEvaluatedKey, err := modelRules.GetPaginatedFeedRules(ddbCursor, int64(cursor.BatchSize))
-// 	if err != nil {
-// 		log.Printf("  GetPaginatedGlobalRules Error %s", err.Error())
-// 		return response.APIResponse(http.StatusInternalServerError, err)
-// 	}
-
-// 	log.Printf("  lastEvaluatedKey %s", lastEvaluatedKey)
-
-// 	nextCursor := cursor.CloneForNextPage()
-// 	if lastEvaluatedKey.Empty() {
-// 		log.Printf("     No more stuff to paginate over; returning magic cursor")
-// 		nextCursor.SetStrategy(ruledownloadStrategyMachine)
-// 	} else {
-// 		log.Printf("     More stuff to paginate over")
-// 		nextCursor.SetDynamodbLastEvaluatedKey(lastEvaluatedKey)
-// 	}
-
-// 	return response.APIResponse(
-// 		http.StatusOK,
-// 		RuledownloadResponse{Rules: DDBRulesToResponseRules(globalRules), Cursor: &nextCursor},
-// 	)
-// }
-
 type feedRuleDownloader interface {
 	handle(machineID string, cursor ruledownloadCursor) (*events.APIGatewayProxyResponse, error)
 }
@@ -50,18 +25,18 @@ func (d concreteFeedRuleDownloader) handle(machineID string, cursor ruledownload
 
 	feedRules, lastEvaluatedKey, err := feedrules.GetPaginatedFeedRules(d.queryer, cursor.BatchSize, ddbCursor)
 	if err != nil {
-		log.Printf("  GetPaginatedFeedRules Error %s", err.Error())
+		slog.Error("GetPaginatedFeedRules Error", "error", err)
 		return response.APIResponse(http.StatusInternalServerError, err)
 	}
 
-	log.Printf("  lastEvaluatedKey %s", lastEvaluatedKey)
+	slog.Debug("Ruledownload incremental page", "last_evaluated_key", lastEvaluatedKey)
 
 	nextCursor := cursor.CloneForNextPage()
 	if lastEvaluatedKey == nil {
-		log.Printf("     No more stuff to paginate over; returning magic cursor")
+		slog.Debug("No more stuff to paginate over; returning magic cursor")
 		nextCursor.SetStrategy(ruledownloadStrategyMachine)
 	} else {
-		log.Printf("     More stuff to paginate over")
+		slog.Debug("More stuff to paginate over")
 		// Here we inherit the preexisting cursor strategy
 		nextCursor.SetDynamodbLastEvaluatedKey(lastEvaluatedKey)
 	}
@@ -74,10 +49,12 @@ func (d concreteFeedRuleDownloader) handle(machineID string, cursor ruledownload
 	// Marshal the cursor to a string
 	jsonCursor, err := json.Marshal(nextCursor)
 	if err != nil {
-		log.Printf("  json.Marshal Error %s", err.Error())
+		slog.Error("json.Marshal Error", "error", err)
 		return response.APIResponse(http.StatusInternalServerError, err)
 	}
 
+	recordRulesPerPage(ruledownloadStrategyIncremental, len(rules))
+
 	return response.APIResponse(
 		http.StatusOK,
 		RuledownloadResponse{
@@ -2,7 +2,7 @@ package ruledownload
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 
@@ -67,7 +67,7 @@ func (h *PostRuledownloadHandler) Handle(request events.APIGatewayProxyRequest)
 	machineID, ok := request.PathParameters["machine_id"]
 	if !ok {
 		// Unreachable code; API Gateway will never allow {machine_id} to be blank
-		log.Printf("ASSERTION FAILED: Received blank {machine_id}")
+		slog.Error("ASSERTION FAILED: Received blank {machine_id}")
 		return response.APIResponse(http.StatusBadRequest, nil)
 	}
 
@@ -75,7 +75,7 @@ func (h *PostRuledownloadHandler) Handle(request events.APIGatewayProxyRequest)
 	var ruledownloadRequest *RuledownloadRequest
 	err := json.Unmarshal([]byte(request.Body), &ruledownloadRequest)
 	if err != nil {
-		log.Printf("  Failed to unmarshall ruledownload request Error %s", err.Error())
+		slog.Warn("Failed to unmarshall ruledownload request", "error", err)
 		return response.APIResponse(http.StatusBadRequest, response.ErrInvalidBodyResponse)
 	}
 
@@ -83,7 +83,7 @@ func (h *PostRuledownloadHandler) Handle(request events.APIGatewayProxyRequest)
 		ruledownloadRequest.Cursor = &ruledownloadCursor{}
 		err = json.Unmarshal([]byte(ruledownloadRequest.RawCursor), ruledownloadRequest.Cursor)
 		if err != nil {
-			log.Printf("  Failed to unmarshall cursor Error %s", err.Error())
+			slog.Warn("Failed to unmarshall cursor", "error", err)
 			return response.APIResponse(http.StatusBadRequest, response.ErrInvalidBodyResponse)
 		}
 	}
@@ -112,6 +112,6 @@ func (h *PostRuledownloadHandler) handleRuleDownload(machineID string, ruledownl
 	}
 
 	// How did you get here??
-	log.Printf("Unreachable code reached in handleRuleDownload()!")
+	slog.Error("Unreachable code reached in handleRuleDownload()!")
 	return response.APIResponse(http.StatusInternalServerError, response.ErrInternalServerErrorResponse)
 }
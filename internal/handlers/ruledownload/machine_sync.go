@@ -1,7 +1,7 @@
 package ruledownload
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 
 	"github.com/airbnb/rudolph/pkg/clock"
@@ -33,7 +33,7 @@ type concreteMachineRuleDownloader struct {
 // On the last page, we always re-send a copy of all machine-specific rules, regardless of whether the client has already received them or not.
 // In this way, we ensure that the machine-specific rules take precedence over any other rules in the system.
 func (d concreteMachineRuleDownloader) handle(machineID string, ruledownloadRequest *RuledownloadRequest) (*events.APIGatewayProxyResponse, error) {
-	log.Printf("  Ruledownload last page")
+	slog.Debug("Ruledownload last page")
 
 	machineRules, err := machinerules.GetMachineRules(d.queryer, machineID)
 	if err != nil {
@@ -44,18 +44,19 @@ func (d concreteMachineRuleDownloader) handle(machineID string, ruledownloadRequ
 	// Create a sensor sync object to log the FinishedAt time of the rule download process
 	err = syncstate.UpdateRuledownloadFinishedAt(d.timer, d.updater, machineID)
 	if err != nil {
-		log.Printf("Encountered error UpdateItem:")
-		log.Print(err.Error())
+		slog.Error("Encountered error UpdateItem", "error", err)
 
 		return response.APIResponse(http.StatusInternalServerError, err)
 	}
-	log.Printf("Updated RuledownloadFinishedAt")
+	slog.Debug("Updated RuledownloadFinishedAt")
 
 	rules := make([]rules.SantaRule, len(*machineRules))
 	for i, rule := range *machineRules {
 		rules[i] = rule.SantaRule
 	}
 
+	recordRulesPerPage(ruledownloadStrategyMachine, len(rules))
+
 	// The lack of a cursor in this response signals to the sensor that there is no more stuff to paginate over.
 	return response.APIResponse(
 		http.StatusOK,
@@ -1,6 +1,7 @@
 package ruledownload
 
 import (
+	"github.com/airbnb/rudolph/pkg/metrics"
 	"github.com/airbnb/rudolph/pkg/model/rules"
 	"github.com/airbnb/rudolph/pkg/types"
 )
@@ -40,3 +41,9 @@ func DDBRulesToResponseRules(rulesList []rules.SantaRule) (responseRules []Ruled
 	}
 	return
 }
+
+// recordRulesPerPage records how many rules a page of the given strategy served; the number of samples is the
+// number of pages
+func recordRulesPerPage(strategy ruledownloadStrategy, count int) {
+	metrics.Count("RulesPerPage", count, metrics.Dim("Strategy", strategy.String()))
+}
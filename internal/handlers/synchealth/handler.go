@@ -0,0 +1,96 @@
+package synchealth
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/airbnb/rudolph/internal/handlers/jobs"
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/synchealth"
+)
+
+// SyncHealthHandler periodically reports the machines whose syncs have stopped working. Each finding is logged as a
+// single JSON line, so that they can be queried and alerted on from CloudWatch Logs.
+type SyncHealthHandler struct {
+	booted       bool
+	store        synchealth.Store
+	timeProvider clock.TimeProvider
+	thresholds   synchealth.Thresholds
+}
+
+func (h *SyncHealthHandler) Boot() (err error) {
+	if h.booted {
+		return
+	}
+
+	region := os.Getenv("REGION")
+	dynamodbTableName := os.Getenv("DYNAMODB_NAME")
+
+	thresholds, err := thresholdsFromEnv(os.Getenv)
+	if err != nil {
+		return
+	}
+
+	h.timeProvider = clock.ConcreteTimeProvider{}
+	h.store = synchealth.GetStore(dynamodb.GetClient(dynamodbTableName, region))
+	h.thresholds = thresholds
+	h.booted = true
+	return
+}
+
+func (h *SyncHealthHandler) Handles(request jobs.JobRequest) bool {
+	return request.Job == jobs.JobSyncHealth
+}
+
+func (h *SyncHealthHandler) Handle(request jobs.JobRequest) error {
+	report, err := synchealth.Check(h.store, h.timeProvider, h.thresholds)
+	if err != nil {
+		return fmt.Errorf("sync health check failed: %w", err)
+	}
+
+	for _, finding := range report.Findings {
+		line, err := json.Marshal(finding)
+		if err != nil {
+			return err
+		}
+		log.Printf("Sync health finding: %s", line)
+	}
+
+	counts, err := json.Marshal(report.Counts)
+	if err != nil {
+		return err
+	}
+	log.Printf("Sync health check complete: %d machines checked, %d with problems: %s", report.MachinesChecked, len(report.Findings), counts)
+	return nil
+}
+
+// thresholdsFromEnv starts from the default thresholds and overrides every value that is set in the environment
+func thresholdsFromEnv(getenv func(string) string) (thresholds synchealth.Thresholds, err error) {
+	thresholds = synchealth.DefaultThresholds()
+
+	if thresholds.StaleDays, err = intFromEnv(getenv, "SYNC_STALE_DAYS", thresholds.StaleDays); err != nil {
+		return
+	}
+	if thresholds.RepeatedCleanSyncs, err = intFromEnv(getenv, "SYNC_REPEATED_CLEAN_SYNCS", thresholds.RepeatedCleanSyncs); err != nil {
+		return
+	}
+
+	err = thresholds.Validate()
+	return
+}
+
+func intFromEnv(getenv func(string) string, name string, defaultValue int) (int, error) {
+	value := getenv(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,40 @@
+package synchealth
+
+import (
+	"testing"
+
+	"github.com/airbnb/rudolph/internal/handlers/jobs"
+	"github.com/airbnb/rudolph/pkg/synchealth"
+	"github.com/stretchr/testify/assert"
+)
+
+func envOf(values map[string]string) func(string) string {
+	return func(name string) string {
+		return values[name]
+	}
+}
+
+func Test_ThresholdsFromEnv(t *testing.T) {
+	thresholds, err := thresholdsFromEnv(envOf(nil))
+	assert.Empty(t, err)
+	assert.Equal(t, synchealth.DefaultThresholds(), thresholds)
+
+	thresholds, err = thresholdsFromEnv(envOf(map[string]string{
+		"SYNC_STALE_DAYS":           "3",
+		"SYNC_REPEATED_CLEAN_SYNCS": "5",
+	}))
+	assert.Empty(t, err)
+	assert.Equal(t, synchealth.Thresholds{StaleDays: 3, RepeatedCleanSyncs: 5}, thresholds)
+
+	_, err = thresholdsFromEnv(envOf(map[string]string{"SYNC_STALE_DAYS": "a week"}))
+	assert.Error(t, err)
+
+	_, err = thresholdsFromEnv(envOf(map[string]string{"SYNC_REPEATED_CLEAN_SYNCS": "1"}))
+	assert.Error(t, err)
+}
+
+func Test_Handles(t *testing.T) {
+	h := &SyncHealthHandler{}
+	assert.True(t, h.Handles(jobs.JobRequest{Job: jobs.JobSyncHealth}))
+	assert.False(t, h.Handles(jobs.JobRequest{Job: jobs.JobLockdownPromotion}))
+}
@@ -0,0 +1,337 @@
+// Package adminapi defines the admin REST API, which manages global rules, machine rules and configurations and looks
+// up machines, and a client for it.
+//
+// The admin API is served by its own Lambda function behind its own authorizer, so that operators, internal tools and
+// chat bots can manage Rudolph with a token from the identity provider instead of write access to the DynamoDB table.
+// Every endpoint is under /admin:
+//
+//	GET    /admin/rules                                              List global rules; paginated
+//	POST   /admin/rules                                              Create or replace a global rule
+//	GET    /admin/rules/{rule_type}/{identifier}                     Get a global rule
+//	PUT    /admin/rules/{rule_type}/{identifier}                     Change the policy of a global rule
+//	DELETE /admin/rules/{rule_type}/{identifier}                     Remove a global rule
+//	GET    /admin/config                                             Get the global config
+//	PUT    /admin/config                                             Replace the global config
+//	PATCH  /admin/config                                             Update some fields of the global config
+//	DELETE /admin/config                                             Delete the global config, reverting to the defaults
+//	GET    /admin/machines                                           List machines, with filters; paginated
+//	GET    /admin/machines/lookup                                    Find machine IDs by prefix, serial number or user
+//	GET    /admin/machines/{machine_id}                              Show a machine
+//	GET    /admin/machines/{machine_id}/config                       Get the config intended for a machine
+//	PUT    /admin/machines/{machine_id}/config                       Replace the config of a machine
+//	PATCH  /admin/machines/{machine_id}/config                       Update some fields of the config of a machine
+//	DELETE /admin/machines/{machine_id}/config                       Delete the config of a machine
+//	GET    /admin/machines/{machine_id}/rules                        List the rules of a machine
+//	POST   /admin/machines/{machine_id}/rules                        Create or replace a machine rule
+//	PUT    /admin/machines/{machine_id}/rules/{rule_type}/{identifier}  Change the policy or expiry of a machine rule
+//	DELETE /admin/machines/{machine_id}/rules/{rule_type}/{identifier}  Remove a machine rule
+//	GET    /admin/blocks/{machine_id}/{file_sha256}                  Show a binary that was blocked on a machine
+//	GET    /admin/unblock-requests                                   List unblock requests
+//	POST   /admin/unblock-requests                                   Ask for a binary to be unblocked on a machine
+//	PUT    /admin/unblock-requests/{machine_id}/{file_sha256}        Approve or deny an unblock request
+//	GET    /admin/whoami                                             Show who the caller is, and the caller's roles
+//
+// Callers without any role may only use whoami, and see and request unblocks of what was blocked on their own
+// machines.
+//
+// Errors are returned as {"error": "..."} with a 4xx or 5xx status code.
+package adminapi
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+const (
+	// DefaultPageSize is how many items a listing returns when no limit is given
+	DefaultPageSize = 100
+	// MaxPageSize is the largest limit a listing accepts
+	MaxPageSize = 500
+	// DefaultMachineRuleExpiration is how long machine rules last when they are created without an expiry
+	DefaultMachineRuleExpiration = machinerules.MachineRuleDefaultExpirationHours * time.Hour
+)
+
+// ParseRuleType reads a rule type from a path or a body, case insensitively, e.g. "binary" or "TEAMID"
+func ParseRuleType(text string) (ruleType types.RuleType, err error) {
+	err = ruleType.UnmarshalText([]byte(strings.ToUpper(text)))
+	return
+}
+
+// ParsePolicy reads a policy from a body, case insensitively, e.g. "allowlist" or "BLOCKLIST"
+func ParsePolicy(text string) (policy types.Policy, err error) {
+	err = policy.UnmarshalText([]byte(strings.ToUpper(text)))
+	return
+}
+
+func ruleTypeText(ruleType types.RuleType) string {
+	text, _ := ruleType.MarshalText()
+	return string(text)
+}
+
+func policyText(policy types.Policy) string {
+	text, _ := policy.MarshalText()
+	return string(text)
+}
+
+// Rule is a global rule
+type Rule struct {
+	RuleType      string `json:"rule_type"`
+	Policy        string `json:"policy"`
+	Identifier    string `json:"identifier"`
+	CustomMessage string `json:"custom_msg,omitempty"`
+	Description   string `json:"description,omitempty"`
+	// ManagedBy is set on rules owned by a declarative rules file; they are overwritten the next time it is applied
+	ManagedBy string `json:"managed_by,omitempty"`
+}
+
+// NewRule converts a global rule row
+func NewRule(row globalrules.GlobalRuleRow) Rule {
+	return Rule{
+		RuleType:      ruleTypeText(row.RuleType),
+		Policy:        policyText(row.Policy),
+		Identifier:    row.Identifier,
+		CustomMessage: row.CustomMessage,
+		Description:   row.Description,
+		ManagedBy:     row.ManagedBy,
+	}
+}
+
+// RuleList is a page of global rules
+type RuleList struct {
+	Rules []Rule `json:"rules"`
+	// Next is passed as the after parameter to get the next page; it is empty on the last page
+	Next string `json:"next,omitempty"`
+}
+
+// CreateRule is the body that creates a global rule
+type CreateRule struct {
+	RuleType      string `json:"rule_type"`
+	Policy        string `json:"policy"`
+	Identifier    string `json:"identifier"`
+	CustomMessage string `json:"custom_msg,omitempty"`
+	Description   string `json:"description,omitempty"`
+}
+
+// UpdateRule is the body that changes a rule; fields that are left out are not changed
+type UpdateRule struct {
+	Policy string `json:"policy,omitempty"`
+	// ExpiresAt only applies to machine rules
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// MachineRule is a rule that only applies to a single machine
+type MachineRule struct {
+	MachineID        string    `json:"machine_id"`
+	RuleType         string    `json:"rule_type"`
+	Policy           string    `json:"policy"`
+	Identifier       string    `json:"identifier"`
+	CustomMessage    string    `json:"custom_msg,omitempty"`
+	Description      string    `json:"description,omitempty"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	DeleteOnNextSync bool      `json:"delete_on_next_sync"`
+}
+
+// NewMachineRule converts a machine rule row
+func NewMachineRule(machineID string, row machinerules.MachineRuleRow) MachineRule {
+	return MachineRule{
+		MachineID:        machineID,
+		RuleType:         ruleTypeText(row.RuleType),
+		Policy:           policyText(row.Policy),
+		Identifier:       row.Identifier,
+		CustomMessage:    row.CustomMessage,
+		Description:      row.Description,
+		ExpiresAt:        time.Unix(row.ExpiresAfter, 0).UTC(),
+		DeleteOnNextSync: row.DeleteOnNextSync,
+	}
+}
+
+// MachineRuleList is every rule of a machine
+type MachineRuleList struct {
+	Rules []MachineRule `json:"rules"`
+}
+
+// CreateMachineRule is the body that creates a machine rule
+type CreateMachineRule struct {
+	RuleType    string `json:"rule_type"`
+	Policy      string `json:"policy"`
+	Identifier  string `json:"identifier"`
+	Description string `json:"description,omitempty"`
+	// ExpiresAt defaults to DefaultMachineRuleExpiration from now
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Config is a sensor configuration, as set globally or for a single machine
+type Config struct {
+	ClientMode               string `json:"client_mode"`
+	BlockedPathRegex         string `json:"blocked_path_regex"`
+	AllowedPathRegex         string `json:"allowed_path_regex"`
+	BatchSize                int    `json:"batch_size"`
+	EnableBundles            bool   `json:"enable_bundles"`
+	EnableTransitiveRules    bool   `json:"enable_transitive_rules"`
+	CleanSync                bool   `json:"clean_sync"`
+	FullSyncInterval         int    `json:"full_sync_interval"`
+	UploadLogsURL            string `json:"upload_logs_url,omitempty"`
+	BlockUsbMount            bool   `json:"block_usb_mount"`
+	RemountUsbMode           string `json:"remount_usb_mode,omitempty"`
+	OverrideFileAccessAction string `json:"override_file_access_action,omitempty"`
+}
+
+// NewConfig converts a machine configuration
+func NewConfig(config machineconfiguration.MachineConfiguration) Config {
+	clientMode, _ := config.ClientMode.MarshalText()
+	return Config{
+		ClientMode:               string(clientMode),
+		BlockedPathRegex:         config.BlockedPathRegex,
+		AllowedPathRegex:         config.AllowedPathRegex,
+		BatchSize:                config.BatchSize,
+		EnableBundles:            config.EnableBundles,
+		EnableTransitiveRules:    config.EnabledTransitiveRules,
+		CleanSync:                config.CleanSync,
+		FullSyncInterval:         config.FullSyncInterval,
+		UploadLogsURL:            config.UploadLogsURL,
+		BlockUsbMount:            config.BlockUsbMount,
+		RemountUsbMode:           config.RemountUsbMode,
+		OverrideFileAccessAction: config.OverrideFileAccessAction,
+	}
+}
+
+// MachineConfiguration converts the config back, validating it
+func (c Config) MachineConfiguration() (config machineconfiguration.MachineConfiguration, err error) {
+	var clientMode types.ClientMode
+	if err = clientMode.UnmarshalText([]byte(strings.ToUpper(c.ClientMode))); err != nil {
+		return
+	}
+	if c.BatchSize <= 0 {
+		err = fmt.Errorf("batch_size must be positive, not %d", c.BatchSize)
+		return
+	}
+	if c.FullSyncInterval < 0 {
+		err = fmt.Errorf("full_sync_interval cannot be negative, not %d", c.FullSyncInterval)
+		return
+	}
+	config = machineconfiguration.MachineConfiguration{
+		ClientMode:               clientMode,
+		BlockedPathRegex:         c.BlockedPathRegex,
+		AllowedPathRegex:         c.AllowedPathRegex,
+		BatchSize:                c.BatchSize,
+		EnableBundles:            c.EnableBundles,
+		EnabledTransitiveRules:   c.EnableTransitiveRules,
+		CleanSync:                c.CleanSync,
+		FullSyncInterval:         c.FullSyncInterval,
+		UploadLogsURL:            c.UploadLogsURL,
+		BlockUsbMount:            c.BlockUsbMount,
+		RemountUsbMode:           c.RemountUsbMode,
+		OverrideFileAccessAction: c.OverrideFileAccessAction,
+	}
+	return
+}
+
+// ConfigSource is where an intended config comes from
+type ConfigSource string
+
+const (
+	ConfigSourceMachine ConfigSource = "machine"
+	ConfigSourceGlobal  ConfigSource = "global"
+	// ConfigSourceDefault is the hardcoded configuration used when none is set
+	ConfigSourceDefault ConfigSource = "default"
+)
+
+// IntendedConfig is the config that a machine, or every machine, gets on its next preflight
+type IntendedConfig struct {
+	Source ConfigSource `json:"source"`
+	Config Config       `json:"config"`
+}
+
+// UpdateConfig is the body that updates some fields of a config; fields that are left out are not changed
+type UpdateConfig struct {
+	ClientMode               *string `json:"client_mode,omitempty"`
+	BlockedPathRegex         *string `json:"blocked_path_regex,omitempty"`
+	AllowedPathRegex         *string `json:"allowed_path_regex,omitempty"`
+	BatchSize                *int    `json:"batch_size,omitempty"`
+	EnableBundles            *bool   `json:"enable_bundles,omitempty"`
+	EnableTransitiveRules    *bool   `json:"enable_transitive_rules,omitempty"`
+	CleanSync                *bool   `json:"clean_sync,omitempty"`
+	FullSyncInterval         *int    `json:"full_sync_interval,omitempty"`
+	UploadLogsURL            *string `json:"upload_logs_url,omitempty"`
+	BlockUsbMount            *bool   `json:"block_usb_mount,omitempty"`
+	RemountUsbMode           *string `json:"remount_usb_mode,omitempty"`
+	OverrideFileAccessAction *string `json:"override_file_access_action,omitempty"`
+}
+
+// NewUpdateConfig converts an update request
+func NewUpdateConfig(request machineconfiguration.MachineConfigurationUpdateRequest) UpdateConfig {
+	update := UpdateConfig{
+		BlockedPathRegex:         request.BlockedPathRegex,
+		AllowedPathRegex:         request.AllowedPathRegex,
+		BatchSize:                request.BatchSize,
+		EnableBundles:            request.EnableBundles,
+		EnableTransitiveRules:    request.EnableTransitiveRules,
+		CleanSync:                request.CleanSync,
+		FullSyncInterval:         request.FullSyncInterval,
+		UploadLogsURL:            request.UploadLogsURL,
+		BlockUsbMount:            request.BlockUsbMount,
+		RemountUsbMode:           request.RemountUsbMode,
+		OverrideFileAccessAction: request.OverrideFileAccessAction,
+	}
+	if request.ClientMode != nil {
+		clientMode, _ := request.ClientMode.MarshalText()
+		text := string(clientMode)
+		update.ClientMode = &text
+	}
+	return update
+}
+
+// UpdateRequest converts the update back, validating it
+func (u UpdateConfig) UpdateRequest() (request machineconfiguration.MachineConfigurationUpdateRequest, err error) {
+	if u.BatchSize != nil && *u.BatchSize <= 0 {
+		err = fmt.Errorf("batch_size must be positive, not %d", *u.BatchSize)
+		return
+	}
+	if u.FullSyncInterval != nil && *u.FullSyncInterval < 0 {
+		err = fmt.Errorf("full_sync_interval cannot be negative, not %d", *u.FullSyncInterval)
+		return
+	}
+	request = machineconfiguration.MachineConfigurationUpdateRequest{
+		BlockedPathRegex:         u.BlockedPathRegex,
+		AllowedPathRegex:         u.AllowedPathRegex,
+		BatchSize:                u.BatchSize,
+		EnableBundles:            u.EnableBundles,
+		EnableTransitiveRules:    u.EnableTransitiveRules,
+		CleanSync:                u.CleanSync,
+		FullSyncInterval:         u.FullSyncInterval,
+		UploadLogsURL:            u.UploadLogsURL,
+		BlockUsbMount:            u.BlockUsbMount,
+		RemountUsbMode:           u.RemountUsbMode,
+		OverrideFileAccessAction: u.OverrideFileAccessAction,
+	}
+	if u.ClientMode != nil {
+		var clientMode types.ClientMode
+		if err = clientMode.UnmarshalText([]byte(strings.ToUpper(*u.ClientMode))); err != nil {
+			return
+		}
+		request.ClientMode = &clientMode
+	}
+	return
+}
+
+// MachineIDs is the result of a machine lookup
+type MachineIDs struct {
+	MachineIDs []string `json:"machine_ids"`
+}
+
+// Caller is who the admin API takes the caller to be, and the roles of the caller
+type Caller struct {
+	Principal string   `json:"principal"`
+	Roles     []string `json:"roles"`
+}
+
+// ErrorResponse is the body of every error
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,47 @@
+package adminapi
+
+import (
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/model/catalog"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/model/unblockrequests"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+)
+
+// Block is what Rudolph knows about a binary that Santa blocked on a machine, for the page that Santa's block dialog
+// links to
+type Block struct {
+	MachineID string `json:"machine_id"`
+	// Subject holds the identifiers of the binary, from its events or else the catalog
+	Subject ruleset.Subject `json:"subject"`
+	// Events are the binary's recent executions on the machine, newest first
+	Events []eventlog.Event `json:"events"`
+	// Catalog is the fleet-wide catalog entry of the binary; it is nil when no machine uploaded an event for it
+	Catalog *catalog.CatalogEntry `json:"catalog,omitempty"`
+	// Rules are the rules that decide whether the binary runs on the machine now
+	Rules ruleset.Explanation `json:"rules"`
+	// UnblockRequest is the latest request to unblock the binary on the machine, if any
+	UnblockRequest *unblockrequests.UnblockRequest `json:"unblock_request,omitempty"`
+}
+
+// UnblockRequestList is the review queue of unblock requests
+type UnblockRequestList struct {
+	Requests []unblockrequests.UnblockRequest `json:"requests"`
+}
+
+// CreateUnblockRequest is the body that asks for a binary to be unblocked on a machine
+type CreateUnblockRequest struct {
+	MachineID     string `json:"machine_id"`
+	FileSHA256    string `json:"file_sha256"`
+	Justification string `json:"justification"`
+}
+
+// DecideUnblockRequest is the body that approves or denies an unblock request. Approving it allowlists the binary on
+// the machine until ExpiresAt.
+type DecideUnblockRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	// ExpiresAt defaults to DefaultMachineRuleExpiration from now
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,419 @@
+package adminapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/inventory"
+	"github.com/airbnb/rudolph/pkg/machineview"
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/model/unblockrequests"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+const (
+	clientTimeout = 30 * time.Second
+	// IdempotencyKeyHeader lets a retried global rule removal be applied only once
+	IdempotencyKeyHeader = "Idempotency-Key"
+)
+
+// Error is a response from the admin API with an error status code
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("admin API returned %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the admin API
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+var (
+	_ machineconfiguration.MachineConfigurationService = &Client{}
+	_ sensordata.SensorDataFinder                      = &Client{}
+	_ globalrules.RuleRemovalService                   = &Client{}
+	_ machinerules.RuleRemovalService                  = &Client{}
+)
+
+// Client calls the admin API. It implements the same services as the DynamoDB backed models, so that the CLI can use
+// either one.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient returns a client for the admin API at baseURL, such as https://rudolph.example.com/prod, that
+// authenticates with the given bearer token
+func NewClient(baseURL string, token string) (*Client, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid admin API URL %q", baseURL)
+	}
+	if token == "" {
+		return nil, errors.New("a token is required to call the admin API")
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: clientTimeout},
+	}, nil
+}
+
+// path joins escaped path segments under /admin
+func path(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	return "/admin/" + strings.Join(escaped, "/")
+}
+
+func (c *Client) do(method string, path string, query url.Values, headers map[string]string, body interface{}, out interface{}) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	}
+
+	request, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+c.token)
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to call the admin API: %w", err)
+	}
+	defer response.Body.Close()
+
+	raw, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode >= 300 {
+		var errorResponse ErrorResponse
+		if json.Unmarshal(raw, &errorResponse) != nil || errorResponse.Error == "" {
+			// API Gateway's own errors, such as a denied authorizer, use "message" instead of "error"
+			var gatewayResponse struct {
+				Message string `json:"message"`
+			}
+			_ = json.Unmarshal(raw, &gatewayResponse)
+			errorResponse.Error = gatewayResponse.Message
+		}
+		return &Error{StatusCode: response.StatusCode, Message: errorResponse.Error}
+	}
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+//
+// Global rules
+//
+
+// ListGlobalRules returns a page of global rules, starting after the given cursor
+func (c *Client) ListGlobalRules(limit int, after string) (list RuleList, err error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if after != "" {
+		query.Set("after", after)
+	}
+	err = c.do(http.MethodGet, path("rules"), query, nil, nil, &list)
+	return
+}
+
+// GetGlobalRule returns a global rule, or nil if there is none
+func (c *Client) GetGlobalRule(identifier string, ruleType types.RuleType) (*Rule, error) {
+	var rule Rule
+	err := c.do(http.MethodGet, path("rules", ruleTypeText(ruleType), identifier), nil, nil, nil, &rule)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// AddGlobalRule creates or replaces a global rule
+func (c *Client) AddGlobalRule(identifier string, ruleType types.RuleType, policy types.Policy, description string) error {
+	return c.do(http.MethodPost, path("rules"), nil, nil, CreateRule{
+		RuleType:    ruleTypeText(ruleType),
+		Policy:      policyText(policy),
+		Identifier:  identifier,
+		Description: description,
+	}, nil)
+}
+
+// UpdateGlobalRule changes the policy of a global rule
+func (c *Client) UpdateGlobalRule(identifier string, ruleType types.RuleType, rulePolicy types.Policy) error {
+	return c.do(http.MethodPut, path("rules", ruleTypeText(ruleType), identifier), nil, nil, UpdateRule{
+		Policy: policyText(rulePolicy),
+	}, nil)
+}
+
+// RemoveGlobalRule removes a global rule by its name, such as TeamID#EQHXZ8M8AV
+func (c *Client) RemoveGlobalRule(ruleSortKey string, idempotencyKey string) error {
+	identifier, ruleType, err := rules.ParseRuleSortKey(ruleSortKey)
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{}
+	if idempotencyKey != "" {
+		headers[IdempotencyKeyHeader] = idempotencyKey
+	}
+	return c.do(http.MethodDelete, path("rules", ruleTypeText(ruleType), identifier), nil, headers, nil, nil)
+}
+
+//
+// Machine rules
+//
+
+// ListMachineRules returns every rule of a machine
+func (c *Client) ListMachineRules(machineID string) (list MachineRuleList, err error) {
+	err = c.do(http.MethodGet, path("machines", machineID, "rules"), nil, nil, nil, &list)
+	return
+}
+
+// AddMachineRule creates or replaces a machine rule
+func (c *Client) AddMachineRule(machineID string, identifier string, ruleType types.RuleType, policy types.Policy, description string, expires time.Time) error {
+	return c.do(http.MethodPost, path("machines", machineID, "rules"), nil, nil, CreateMachineRule{
+		RuleType:    ruleTypeText(ruleType),
+		Policy:      policyText(policy),
+		Identifier:  identifier,
+		Description: description,
+		ExpiresAt:   &expires,
+	}, nil)
+}
+
+// UpdateMachineRulePolicy changes the policy of a machine rule, which then expires after the default expiration
+func (c *Client) UpdateMachineRulePolicy(machineID string, sha256 string, ruleType types.RuleType, rulePolicy types.Policy) error {
+	return c.do(http.MethodPut, path("machines", machineID, "rules", ruleTypeText(ruleType), sha256), nil, nil, UpdateRule{
+		Policy: policyText(rulePolicy),
+	}, nil)
+}
+
+// RemoveMachineRule removes a machine rule by its name, such as Binary#<sha256>
+func (c *Client) RemoveMachineRule(machineID string, ruleSortKey string) error {
+	identifier, ruleType, err := rules.ParseRuleSortKey(ruleSortKey)
+	if err != nil {
+		return err
+	}
+	return c.do(http.MethodDelete, path("machines", machineID, "rules", ruleTypeText(ruleType), identifier), nil, nil, nil, nil)
+}
+
+//
+// Configs
+//
+
+func (c *Client) getConfig(path string) (config machineconfiguration.MachineConfiguration, source ConfigSource, err error) {
+	var intended IntendedConfig
+	if err = c.do(http.MethodGet, path, nil, nil, nil, &intended); err != nil {
+		return
+	}
+	config, err = intended.Config.MachineConfiguration()
+	return config, intended.Source, err
+}
+
+func (c *Client) updateConfig(path string, configRequest machineconfiguration.MachineConfigurationUpdateRequest) (*machineconfiguration.MachineConfiguration, error) {
+	var updated IntendedConfig
+	if err := c.do(http.MethodPatch, path, nil, nil, NewUpdateConfig(configRequest), &updated); err != nil {
+		return nil, err
+	}
+	config, err := updated.Config.MachineConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// GetIntendedConfig returns the config that a machine gets on its next preflight
+func (c *Client) GetIntendedConfig(machineID string) (machineconfiguration.MachineConfiguration, error) {
+	config, _, err := c.getConfig(path("machines", machineID, "config"))
+	return config, err
+}
+
+// GetIntendedGlobalConfig returns the global config, and whether it is the hardcoded default
+func (c *Client) GetIntendedGlobalConfig() (machineconfiguration.MachineConfiguration, bool, error) {
+	config, source, err := c.getConfig(path("config"))
+	return config, source == ConfigSourceDefault, err
+}
+
+func (c *Client) SetGlobalConfig(config machineconfiguration.MachineConfiguration) error {
+	return c.do(http.MethodPut, path("config"), nil, nil, NewConfig(config), nil)
+}
+
+func (c *Client) SetMachineConfig(machineID string, config machineconfiguration.MachineConfiguration) error {
+	return c.do(http.MethodPut, path("machines", machineID, "config"), nil, nil, NewConfig(config), nil)
+}
+
+func (c *Client) UpdateGlobalConfig(configRequest machineconfiguration.MachineConfigurationUpdateRequest) (*machineconfiguration.MachineConfiguration, error) {
+	return c.updateConfig(path("config"), configRequest)
+}
+
+func (c *Client) UpdateMachineConfig(machineID string, configRequest machineconfiguration.MachineConfigurationUpdateRequest) (*machineconfiguration.MachineConfiguration, error) {
+	return c.updateConfig(path("machines", machineID, "config"), configRequest)
+}
+
+func (c *Client) DeleteGlobalConfig() error {
+	return c.do(http.MethodDelete, path("config"), nil, nil, nil, nil)
+}
+
+func (c *Client) DeleteMachineConfig(machineID string) error {
+	return c.do(http.MethodDelete, path("machines", machineID, "config"), nil, nil, nil, nil)
+}
+
+//
+// Callers
+//
+
+// WhoAmI returns who the admin API takes the caller to be, so that the CLI can check the caller's roles before asking
+// for confirmation of a change that would be denied
+func (c *Client) WhoAmI() (caller Caller, err error) {
+	err = c.do(http.MethodGet, path("whoami"), nil, nil, nil, &caller)
+	return
+}
+
+//
+// Machines
+//
+
+func (c *Client) lookup(parameter string, value string, limit int32) ([]string, error) {
+	query := url.Values{parameter: {value}, "limit": {strconv.Itoa(int(limit))}}
+	var result MachineIDs
+	err := c.do(http.MethodGet, path("machines", "lookup"), query, nil, nil, &result)
+	return result.MachineIDs, err
+}
+
+func (c *Client) GetMachineIDsStartingWith(prefix string, limit int32) ([]string, error) {
+	return c.lookup("prefix", prefix, limit)
+}
+
+func (c *Client) GetMachineIDsFromSerialNumber(serialNumber string, limit int32) ([]string, error) {
+	return c.lookup("serial_num", serialNumber, limit)
+}
+
+func (c *Client) GetMachineIDsFromPrimaryUser(primaryUser string, limit int32) ([]string, error) {
+	return c.lookup("primary_user", primaryUser, limit)
+}
+
+// GetMachine returns everything Rudolph knows about a machine
+func (c *Client) GetMachine(machineID string, options machineview.Options) (machine machineview.Machine, err error) {
+	query := url.Values{}
+	if options.StaleAfter > 0 {
+		query.Set("stale_after", options.StaleAfter.String())
+	}
+	query.Set("events", strconv.Itoa(options.EventLimit))
+	if !options.EventsSince.IsZero() {
+		query.Set("events_since", options.EventsSince.UTC().Format(time.RFC3339))
+	}
+	err = c.do(http.MethodGet, path("machines", machineID), query, nil, nil, &machine)
+	return
+}
+
+// ListMachines lists the machines that match the options; see inventory.List. Without a limit, listings sorted by
+// ascending machine ID are read a page at a time until the end, while any other order returns at most MaxPageSize
+// machines.
+func (c *Client) ListMachines(options inventory.Options) (page inventory.Page, err error) {
+	followPages := options.Limit == 0
+	if followPages {
+		options.Limit = MaxPageSize
+	}
+	page.Machines = []inventory.Machine{}
+	for {
+		query, inerr := MachineListQuery(options)
+		if inerr != nil {
+			err = inerr
+			return
+		}
+		var next inventory.Page
+		if err = c.do(http.MethodGet, path("machines"), query, nil, nil, &next); err != nil {
+			return
+		}
+		page.Machines = append(page.Machines, next.Machines...)
+		page.Scanned += next.Scanned
+		page.Next = next.Next
+		if !followPages || next.Next == "" {
+			return
+		}
+		options.After = next.Next
+	}
+}
+
+//
+// Blocks and unblock requests
+//
+
+// GetBlock returns what Rudolph knows about a binary that was blocked on a machine
+func (c *Client) GetBlock(machineID string, fileSHA256 string) (block Block, err error) {
+	err = c.do(http.MethodGet, path("blocks", machineID, fileSHA256), nil, nil, nil, &block)
+	return
+}
+
+// ListUnblockRequests returns the unblock requests with the given status, or all of them if it is empty
+func (c *Client) ListUnblockRequests(status unblockrequests.Status) (list UnblockRequestList, err error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", string(status))
+	}
+	err = c.do(http.MethodGet, path("unblock-requests"), query, nil, nil, &list)
+	return
+}
+
+// RequestUnblock asks for a binary to be unblocked on a machine
+func (c *Client) RequestUnblock(machineID string, fileSHA256 string, justification string) (request unblockrequests.UnblockRequest, err error) {
+	err = c.do(http.MethodPost, path("unblock-requests"), nil, nil, CreateUnblockRequest{
+		MachineID:     machineID,
+		FileSHA256:    fileSHA256,
+		Justification: justification,
+	}, &request)
+	return
+}
+
+// DecideUnblockRequest approves or denies an unblock request; approving it allowlists the binary on the machine
+// until expires, or for DefaultMachineRuleExpiration if it is zero
+func (c *Client) DecideUnblockRequest(machineID string, fileSHA256 string, status unblockrequests.Status, reason string, expires time.Time) (request unblockrequests.UnblockRequest, err error) {
+	body := DecideUnblockRequest{Status: string(status), Reason: reason}
+	if !expires.IsZero() {
+		body.ExpiresAt = &expires
+	}
+	err = c.do(http.MethodPut, path("unblock-requests", machineID, fileSHA256), nil, nil, body, &request)
+	return
+}
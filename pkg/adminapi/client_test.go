@@ -0,0 +1,150 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/inventory"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := NewClient(server.URL+"/prod/", "token")
+	require.NoError(t, err)
+	return client
+}
+
+func Test_Client_RemoveGlobalRule(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/prod/admin/rules/SIGNINGID/EQHXZ8M8AV:com.google.Chrome", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "retry-1", r.Header.Get(IdempotencyKeyHeader))
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	err := client.RemoveGlobalRule("SigningID#EQHXZ8M8AV:com.google.Chrome", "retry-1")
+
+	assert.NoError(t, err)
+	assert.Error(t, client.RemoveGlobalRule("Nope#1", ""))
+}
+
+func Test_Client_WhoAmI(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/prod/admin/whoami", r.URL.Path)
+		_, _ = w.Write([]byte(`{"principal":"alice@example.com","roles":["viewer","responder"]}`))
+	})
+
+	caller, err := client.WhoAmI()
+
+	require.NoError(t, err)
+	assert.Equal(t, Caller{Principal: "alice@example.com", Roles: []string{"viewer", "responder"}}, caller)
+}
+
+func Test_Client_Errors(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/prod/admin/rules/TEAMID/EQHXZ8M8AV":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"rule TeamID#EQHXZ8M8AV not found"}`))
+		default:
+			// API Gateway's own errors use "message"
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"Unauthorized"}`))
+		}
+	})
+
+	rule, err := client.GetGlobalRule("EQHXZ8M8AV", types.RuleTypeTeamID)
+	assert.NoError(t, err)
+	assert.Nil(t, rule)
+
+	err = client.AddGlobalRule("EQHXZ8M8AV", types.RuleTypeTeamID, types.RulePolicyAllowlist, "")
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, &Error{StatusCode: http.StatusUnauthorized, Message: "Unauthorized"}, apiErr)
+}
+
+func Test_Client_ListMachinesFollowsPages(t *testing.T) {
+	var afters []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		options, err := ParseMachineListQuery(map[string]string{
+			"limit":      r.URL.Query().Get("limit"),
+			"after":      r.URL.Query().Get("after"),
+			"os_version": r.URL.Query().Get("os_version"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, MaxPageSize, options.Limit)
+		assert.Equal(t, "14.4", options.Filter.OSVersion)
+		afters = append(afters, options.After)
+
+		page := inventory.Page{Machines: []inventory.Machine{{MachineID: "A"}}, Next: "A", Scanned: 2}
+		if options.After == "A" {
+			page = inventory.Page{Machines: []inventory.Machine{{MachineID: "B"}}, Scanned: 1}
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	})
+
+	page, err := client.ListMachines(inventory.Options{Filter: inventory.Filter{OSVersion: "14.4"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "A"}, afters)
+	assert.Equal(t, []inventory.Machine{{MachineID: "A"}, {MachineID: "B"}}, page.Machines)
+	assert.Equal(t, 3, page.Scanned)
+	assert.Empty(t, page.Next)
+}
+
+func Test_MachineListQuery_RoundTrip(t *testing.T) {
+	options := inventory.Options{
+		Filter: inventory.Filter{
+			SantaVersion: "2024.1",
+			ReportedMode: types.Monitor,
+			ModeMismatch: true,
+			SeenAfter:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		SortBy:     inventory.SortByMachineID,
+		Descending: true,
+		Limit:      50,
+	}
+
+	query, err := MachineListQuery(options)
+	require.NoError(t, err)
+	parsed := map[string]string{}
+	for name := range query {
+		parsed[name] = query.Get(name)
+	}
+	roundTripped, err := ParseMachineListQuery(parsed)
+
+	require.NoError(t, err)
+	assert.Equal(t, options, roundTripped)
+}
+
+func Test_ParseMachineListQuery_Invalid(t *testing.T) {
+	for _, query := range []map[string]string{
+		{"limit": "0"},
+		{"limit": "501"},
+		{"reported_mode": "sometimes"},
+		{"seen_after": "yesterday"},
+		{"sort": "last_seen", "after": "AAAA"},
+	} {
+		_, err := ParseMachineListQuery(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func Test_Config_Validation(t *testing.T) {
+	_, err := Config{ClientMode: "monitor", BatchSize: 50}.MachineConfiguration()
+	assert.NoError(t, err)
+
+	_, err = Config{ClientMode: "sometimes", BatchSize: 50}.MachineConfiguration()
+	assert.Error(t, err)
+
+	_, err = Config{ClientMode: "lockdown", BatchSize: 0}.MachineConfiguration()
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package adminapi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/inventory"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// MachineListQuery encodes the options of a machine listing as the query parameters of GET /admin/machines
+func MachineListQuery(options inventory.Options) (url.Values, error) {
+	query := url.Values{}
+	set := func(name string, value string) {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	setMode := func(name string, mode types.ClientMode) error {
+		if mode == 0 {
+			return nil
+		}
+		text, err := mode.MarshalText()
+		if err != nil {
+			return err
+		}
+		query.Set(name, string(text))
+		return nil
+	}
+	setTime := func(name string, t time.Time) {
+		if !t.IsZero() {
+			query.Set(name, t.UTC().Format(time.RFC3339))
+		}
+	}
+
+	filter := options.Filter
+	set("os_version", filter.OSVersion)
+	set("os_build", filter.OSBuild)
+	set("santa_version", filter.SantaVersion)
+	set("primary_user", filter.PrimaryUser)
+	set("model", filter.ModelIdentifier)
+	if err := setMode("reported_mode", filter.ReportedMode); err != nil {
+		return nil, err
+	}
+	if err := setMode("intended_mode", filter.IntendedMode); err != nil {
+		return nil, err
+	}
+	if filter.ModeMismatch {
+		query.Set("mode_mismatch", "true")
+	}
+	setTime("seen_after", filter.SeenAfter)
+	setTime("seen_before", filter.SeenBefore)
+
+	set("sort", string(options.SortBy))
+	if options.Descending {
+		query.Set("desc", "true")
+	}
+	if options.Limit > 0 {
+		query.Set("limit", strconv.Itoa(options.Limit))
+	}
+	set("after", options.After)
+	return query, nil
+}
+
+// ParseMachineListQuery is the reverse of MachineListQuery. Listings are limited to DefaultPageSize machines unless a
+// limit of up to MaxPageSize is given.
+func ParseMachineListQuery(query map[string]string) (options inventory.Options, err error) {
+	get := func(name string) string {
+		return query[name]
+	}
+	parseMode := func(name string) (mode types.ClientMode, err error) {
+		if get(name) == "" {
+			return
+		}
+		if err = mode.UnmarshalText([]byte(strings.ToUpper(get(name)))); err != nil {
+			err = fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return
+	}
+	parseTime := func(name string) (t time.Time, err error) {
+		if get(name) == "" {
+			return
+		}
+		if t, err = time.Parse(time.RFC3339, get(name)); err != nil {
+			err = fmt.Errorf("invalid %s: must be an RFC3339 time", name)
+		}
+		return
+	}
+	parseBool := func(name string) (b bool, err error) {
+		if get(name) == "" {
+			return
+		}
+		if b, err = strconv.ParseBool(get(name)); err != nil {
+			err = fmt.Errorf("invalid %s: must be true or false", name)
+		}
+		return
+	}
+
+	options.Filter = inventory.Filter{
+		OSVersion:       get("os_version"),
+		OSBuild:         get("os_build"),
+		SantaVersion:    get("santa_version"),
+		PrimaryUser:     get("primary_user"),
+		ModelIdentifier: get("model"),
+	}
+	if options.Filter.ReportedMode, err = parseMode("reported_mode"); err != nil {
+		return
+	}
+	if options.Filter.IntendedMode, err = parseMode("intended_mode"); err != nil {
+		return
+	}
+	if options.Filter.ModeMismatch, err = parseBool("mode_mismatch"); err != nil {
+		return
+	}
+	if options.Filter.SeenAfter, err = parseTime("seen_after"); err != nil {
+		return
+	}
+	if options.Filter.SeenBefore, err = parseTime("seen_before"); err != nil {
+		return
+	}
+
+	options.SortBy = inventory.SortByMachineID
+	if get("sort") != "" {
+		if options.SortBy, err = inventory.ParseSortBy(get("sort")); err != nil {
+			return
+		}
+	}
+	if options.Descending, err = parseBool("desc"); err != nil {
+		return
+	}
+	if options.Limit, err = ParseLimit(get("limit")); err != nil {
+		return
+	}
+	options.After = get("after")
+	if options.After != "" && (options.SortBy != inventory.SortByMachineID || options.Descending) {
+		err = fmt.Errorf("after is only supported when sorting by ascending %s", inventory.SortByMachineID)
+	}
+	return
+}
+
+// ParseLimit reads the limit of a listing, which defaults to DefaultPageSize and cannot exceed MaxPageSize
+func ParseLimit(text string) (int, error) {
+	if text == "" {
+		return DefaultPageSize, nil
+	}
+	limit, err := strconv.Atoi(text)
+	if err != nil || limit <= 0 || limit > MaxPageSize {
+		return 0, fmt.Errorf("invalid limit: must be between 1 and %d", MaxPageSize)
+	}
+	return limit, nil
+}
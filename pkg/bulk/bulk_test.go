@@ -0,0 +1,284 @@
+package bulk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	awsdynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	machineA = "AAAAAAAA-A00A-1234-1234-000000000001"
+	machineB = "AAAAAAAA-A00A-1234-1234-000000000002"
+	machineC = "AAAAAAAA-A00A-1234-1234-000000000003"
+)
+
+type mockFinder struct {
+	serials map[string][]string
+	users   map[string][]string
+}
+
+func (m mockFinder) GetMachineIDsStartingWith(prefix string, limit int32) ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m mockFinder) GetMachineIDsFromSerialNumber(serialNumber string, limit int32) ([]string, error) {
+	return m.serials[serialNumber], nil
+}
+
+func (m mockFinder) GetMachineIDsFromPrimaryUser(primaryUser string, limit int32) ([]string, error) {
+	return m.users[primaryUser], nil
+}
+
+type mockBatchWriter struct {
+	dynamodb.BatchWriteItemAPI
+	batches [][]awsdynamodbtypes.WriteRequest
+	err     error
+}
+
+func (m *mockBatchWriter) CreatePutRequest(item interface{}) (*awsdynamodbtypes.WriteRequest, error) {
+	putItem, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return nil, err
+	}
+	return &awsdynamodbtypes.WriteRequest{PutRequest: &awsdynamodbtypes.PutRequest{Item: putItem}}, nil
+}
+
+func (m *mockBatchWriter) BatchWriteItems(requests []awsdynamodbtypes.WriteRequest) error {
+	m.batches = append(m.batches, append([]awsdynamodbtypes.WriteRequest(nil), requests...))
+	return m.err
+}
+
+func Test_ReadTargets_CSV(t *testing.T) {
+	targets, err := ReadTargets(FormatCSV, strings.NewReader(
+		"serial,primary_user,notes\n"+
+			"C02ABC,,laptop\n"+
+			",alice,\n"+
+			",,nothing\n"+
+			"C02DEF,bob,both\n",
+	))
+
+	assert.Empty(t, err)
+	assert.Len(t, targets, 4)
+	assert.Equal(t, Target{Position: "line 2", Key: KeySerialNumber, Value: "C02ABC"}, targets[0])
+	assert.Equal(t, Target{Position: "line 3", Key: KeyPrimaryUser, Value: "alice"}, targets[1])
+	assert.EqualError(t, targets[2].Err, "sets none of machine_id, serial and primary_user")
+	assert.EqualError(t, targets[3].Err, "sets both serial and primary_user; set exactly one")
+}
+
+func Test_ReadTargets_CSVWithoutKeyColumn(t *testing.T) {
+	_, err := ReadTargets(FormatCSV, strings.NewReader("hostname\nlaptop-1\nlaptop-2\n"))
+
+	assert.EqualError(t, err, "the header row must have a machine_id, serial or primary_user column")
+}
+
+func Test_ReadTargets_JSON(t *testing.T) {
+	targets, err := ReadTargets(FormatJSON, strings.NewReader(`[{"machine_id": "`+machineA+`"}, {"serial": "C02ABC", "owner": "alice"}]`))
+
+	assert.Empty(t, err)
+	assert.Equal(t, []Target{
+		{Position: "entry #1", Key: KeyMachineID, Value: machineA},
+		{Position: "entry #2", Key: KeySerialNumber, Value: "C02ABC"},
+	}, targets)
+}
+
+func Test_Runner_MachineRule(t *testing.T) {
+	client := &mockBatchWriter{}
+	var progress []int
+	runner := Runner{
+		Finder: mockFinder{
+			serials: map[string][]string{"C02ABC": {machineB}},
+			users:   map[string][]string{"alice": {machineA, machineC}},
+		},
+		Client: client,
+		Operation: MachineRuleOperation{
+			RuleType:   types.RuleTypeBinary,
+			Policy:     types.RulePolicyAllowlist,
+			Identifier: "ed0a9ba83449b5966363e0c20fe7755defcb2d7136657d3880bb462a8d7a7025",
+			Expires:    time.Date(2022, 1, 8, 0, 0, 0, 0, time.UTC),
+		},
+		Progress: func(done int, total int) { progress = append(progress, done) },
+	}
+
+	results, err := runner.Run([]Target{
+		{Position: "line 2", Key: KeyMachineID, Value: machineA},
+		{Position: "line 3", Key: KeySerialNumber, Value: "C02ABC"},
+		{Position: "line 4", Key: KeySerialNumber, Value: "C02XYZ"},
+		{Position: "line 5", Key: KeyPrimaryUser, Value: "alice"},
+		{Position: "line 6", Err: errors.New("sets none of machine_id, serial and primary_user")},
+	})
+
+	assert.Empty(t, err)
+	assert.Equal(t, []Result{
+		{Position: "line 2", Key: KeyMachineID, Value: machineA, MachineID: machineA, Status: StatusApplied},
+		{Position: "line 3", Key: KeySerialNumber, Value: "C02ABC", MachineID: machineB, Status: StatusApplied},
+		{Position: "line 4", Key: KeySerialNumber, Value: "C02XYZ", Status: StatusUnresolved, Error: `no machine with serial "C02XYZ" has checked in`},
+		{Position: "line 5", Key: KeyPrimaryUser, Value: "alice", MachineID: machineA, Status: StatusDuplicate},
+		{Position: "line 5", Key: KeyPrimaryUser, Value: "alice", MachineID: machineC, Status: StatusApplied},
+		{Position: "line 6", Status: StatusInvalid, Error: "sets none of machine_id, serial and primary_user"},
+	}, results)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, progress)
+
+	assert.Len(t, client.batches, 1)
+	assert.Len(t, client.batches[0], 3)
+	var row machinerules.MachineRuleRow
+	assert.Empty(t, attributevalue.UnmarshalMap(client.batches[0][1].PutRequest.Item, &row))
+	assert.Equal(t, "MachineRules#"+machineB, row.PartitionKey)
+	assert.Equal(t, types.RulePolicyAllowlist, row.Policy)
+	assert.Equal(t, int64(1641600000), row.ExpiresAfter)
+}
+
+func Test_Runner_Batches(t *testing.T) {
+	client := &mockBatchWriter{err: errors.New("access denied")}
+	targets := make([]Target, 30)
+	for i := range targets {
+		targets[i] = Target{Position: "entry", Key: KeySerialNumber, Value: string(rune('A' + i))}
+	}
+	serials := make(map[string][]string)
+	for i, target := range targets {
+		serials[target.Value] = []string{fmt.Sprintf("AAAAAAAA-A00A-1234-1234-%012d", i)}
+	}
+
+	results, err := Runner{
+		Finder: mockFinder{serials: serials},
+		Client: client,
+		Operation: MachineRuleOperation{
+			RuleType:   types.RuleTypeBinary,
+			Policy:     types.RulePolicyAllowlist,
+			Identifier: "ed0a9ba83449b5966363e0c20fe7755defcb2d7136657d3880bb462a8d7a7025",
+			Expires:    time.Date(2022, 1, 8, 0, 0, 0, 0, time.UTC),
+		},
+	}.Run(targets)
+
+	assert.Empty(t, err)
+	assert.Len(t, results, 30)
+	assert.Len(t, client.batches, 2)
+	assert.Len(t, client.batches[0], 25)
+	assert.Len(t, client.batches[1], 5)
+	assert.Equal(t, Summary{StatusFailed: 30}, Summarize(results))
+	assert.Equal(t, "access denied", results[29].Error)
+}
+
+// mockClientModeStore keeps client modes in memory and records every write
+type mockClientModeStore struct {
+	clientModes map[string]types.ClientMode
+	setErr      error
+	transitions []string
+}
+
+func (m *mockClientModeStore) GetClientMode(machineID string) (types.ClientMode, error) {
+	if clientMode, ok := m.clientModes[machineID]; ok {
+		return clientMode, nil
+	}
+	return types.Monitor, nil
+}
+
+func (m *mockClientModeStore) SetClientMode(machineID string, clientMode types.ClientMode) error {
+	if m.setErr != nil {
+		return m.setErr
+	}
+	m.clientModes[machineID] = clientMode
+	return nil
+}
+
+func (m *mockClientModeStore) RecordTransition(machineID string, fromMode types.ClientMode, toMode types.ClientMode) error {
+	m.transitions = append(m.transitions, fmt.Sprintf("%s %d->%d", machineID, fromMode, toMode))
+	return nil
+}
+
+func Test_Runner_ClientMode(t *testing.T) {
+	store := &mockClientModeStore{clientModes: map[string]types.ClientMode{machineB: types.Lockdown}}
+	client := &mockBatchWriter{}
+
+	results, err := Runner{
+		Finder:    mockFinder{users: map[string][]string{"alice": {machineA, machineB}}},
+		Client:    client,
+		Operation: ClientModeOperation{ClientMode: types.Lockdown, Store: store},
+	}.Run([]Target{{Position: "line 2", Key: KeyPrimaryUser, Value: "alice"}})
+
+	assert.Empty(t, err)
+	assert.Equal(t, Summary{StatusApplied: 2}, Summarize(results))
+	assert.Empty(t, client.batches)
+	assert.Equal(t, map[string]types.ClientMode{machineA: types.Lockdown, machineB: types.Lockdown}, store.clientModes)
+	// Only the machine whose mode changed gets a transition
+	assert.Equal(t, []string{fmt.Sprintf("%s %d->%d", machineA, types.Monitor, types.Lockdown)}, store.transitions)
+}
+
+func Test_Runner_ClientModeFailure(t *testing.T) {
+	store := &mockClientModeStore{clientModes: map[string]types.ClientMode{}, setErr: errors.New("throttled")}
+
+	results, err := Runner{
+		Finder:    mockFinder{},
+		Operation: ClientModeOperation{ClientMode: types.Lockdown, Store: store},
+	}.Run([]Target{{Position: "line 2", Key: KeyMachineID, Value: machineA}})
+
+	assert.Empty(t, err)
+	assert.Equal(t, StatusFailed, results[0].Status)
+	assert.Equal(t, "throttled", results[0].Error)
+	assert.Empty(t, store.transitions)
+}
+
+func Test_Runner_DryRun(t *testing.T) {
+	store := &mockClientModeStore{clientModes: map[string]types.ClientMode{}}
+
+	results, err := Runner{
+		Finder:    mockFinder{},
+		Operation: ClientModeOperation{ClientMode: types.Lockdown, Store: store},
+		DryRun:    true,
+	}.Run([]Target{{Position: "line 2", Key: KeyMachineID, Value: machineA}})
+
+	assert.Empty(t, err)
+	assert.Equal(t, StatusDryRun, results[0].Status)
+	assert.Empty(t, store.clientModes)
+	assert.Empty(t, store.transitions)
+}
+
+func Test_Runner_InvalidOperation(t *testing.T) {
+	_, err := Runner{
+		Operation: MachineRuleOperation{
+			RuleType:   types.RuleTypeBinary,
+			Policy:     types.RulePolicyRemove,
+			Identifier: "ed0a9ba83449b5966363e0c20fe7755defcb2d7136657d3880bb462a8d7a7025",
+			Expires:    time.Date(2022, 1, 8, 0, 0, 0, 0, time.UTC),
+		},
+	}.Run(nil)
+
+	assert.Error(t, err)
+
+	_, err = Runner{
+		Operation: MachineRuleOperation{
+			RuleType:   types.RuleTypeBinary,
+			Policy:     types.RulePolicyAllowlist,
+			Identifier: "nope",
+			Expires:    time.Date(2022, 1, 8, 0, 0, 0, 0, time.UTC),
+		},
+	}.Run(nil)
+
+	assert.EqualError(t, err, `"nope" is not a valid BINARY identifier`)
+}
+
+func Test_WriteResults(t *testing.T) {
+	results := []Result{
+		{Position: "line 2", Key: KeySerialNumber, Value: "C02ABC", MachineID: machineA, Status: StatusApplied},
+		{Position: "line 3", Status: StatusInvalid, Error: "sets none of machine_id, serial and primary_user"},
+	}
+
+	var buf bytes.Buffer
+	assert.Empty(t, WriteResults(FormatCSV, &buf, results))
+	assert.Equal(t, "position,key,value,machine_id,status,error\n"+
+		"line 2,serial,C02ABC,"+machineA+",applied,\n"+
+		"line 3,,,,invalid,\"sets none of machine_id, serial and primary_user\"\n", buf.String())
+
+	buf.Reset()
+	assert.Empty(t, WriteResults(FormatJSON, &buf, results[1:]))
+	assert.JSONEq(t, `[{"position": "line 3", "status": "invalid", "error": "sets none of machine_id, serial and primary_user"}]`, buf.String())
+}
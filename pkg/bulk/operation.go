@@ -0,0 +1,157 @@
+package bulk
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/model/modetransitions"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/types"
+	awsdynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Operation is what a bulk run does to every machine. It is either a WriteOperation or a MachineOperation.
+type Operation interface {
+	// Validate checks the operation once, before any machine is resolved
+	Validate() error
+}
+
+// WriteOperation is an operation that is a single write per machine, so that machines are written in batches
+type WriteOperation interface {
+	Operation
+	// WriteRequest returns the write that applies the operation to a machine
+	WriteRequest(client dynamodb.BatchWriteItemAPI, machineID string) (*awsdynamodbtypes.WriteRequest, error)
+}
+
+// MachineOperation is an operation that has to read a machine's state before changing it, so that it is applied to one
+// machine at a time
+type MachineOperation interface {
+	Operation
+	// Check checks the operation against a machine without changing anything, for dry runs
+	Check(machineID string) error
+	// Apply applies the operation to a machine
+	Apply(machineID string) error
+}
+
+// MachineRuleOperation puts the same machine rule on every machine, replacing any machine rule they have for the same
+// identifier
+type MachineRuleOperation struct {
+	RuleType    types.RuleType
+	Policy      types.Policy
+	Identifier  string
+	Description string
+	Expires     time.Time
+}
+
+func (o MachineRuleOperation) Validate() error {
+	if o.Policy == types.RulePolicyRemove {
+		// Removing a machine rule depends on the global rule it overrides; see machinerules.RemoveMachineRule
+		return errors.New("machine rules cannot be removed in bulk; let them expire, or use rule remove on each machine")
+	}
+	err := globalrules.ValidateRule(rules.SantaRule{RuleType: o.RuleType, Policy: o.Policy, Identifier: o.Identifier})
+	if err != nil {
+		return err
+	}
+	if o.Expires.IsZero() {
+		return errors.New("machine rules must have an expiry")
+	}
+	return nil
+}
+
+func (o MachineRuleOperation) WriteRequest(client dynamodb.BatchWriteItemAPI, machineID string) (*awsdynamodbtypes.WriteRequest, error) {
+	row, err := machinerules.NewMachineRuleRow(machineID, o.Identifier, o.RuleType, o.Policy, o.Description, o.Expires)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreatePutRequest(row)
+}
+
+// ClientModeStore is what ClientModeOperation reads and writes; see GetClientModeStore for the DynamoDB implementation
+type ClientModeStore interface {
+	GetClientMode(machineID string) (types.ClientMode, error)
+	// SetClientMode only changes the ClientMode of the machine, like config update does
+	SetClientMode(machineID string, clientMode types.ClientMode) error
+	RecordTransition(machineID string, fromMode types.ClientMode, toMode types.ClientMode) error
+}
+
+// GetClientModeStore returns a ClientModeStore backed by the DynamoDB table. Transitions are recorded as made by the
+// CLI.
+func GetClientModeStore(client dynamodb.DynamoDBClient, timeProvider clock.TimeProvider) ClientModeStore {
+	return concreteClientModeStore{
+		client:       client,
+		timeProvider: timeProvider,
+		service:      machineconfiguration.GetUncachedMachineConfigurationService(client, timeProvider),
+	}
+}
+
+type concreteClientModeStore struct {
+	client       dynamodb.DynamoDBClient
+	timeProvider clock.TimeProvider
+	service      machineconfiguration.MachineConfigurationService
+}
+
+func (s concreteClientModeStore) GetClientMode(machineID string) (types.ClientMode, error) {
+	config, err := s.service.GetIntendedConfig(machineID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the intended configuration: %w", err)
+	}
+	return config.ClientMode, nil
+}
+
+func (s concreteClientModeStore) SetClientMode(machineID string, clientMode types.ClientMode) error {
+	_, err := s.service.UpdateMachineConfig(machineID, machineconfiguration.MachineConfigurationUpdateRequest{
+		ClientMode: &clientMode,
+	})
+	return err
+}
+
+func (s concreteClientModeStore) RecordTransition(machineID string, fromMode types.ClientMode, toMode types.ClientMode) error {
+	_, err := modetransitions.RecordTransition(s.client, s.timeProvider, machineID, fromMode, toMode, modetransitions.ActorCLI, "rudolph bulk config")
+	return err
+}
+
+// ClientModeOperation sets the same client mode on every machine, like config update does for a single machine. Every
+// other setting of the machine is left as it is, and every change of mode is recorded as a transition.
+type ClientModeOperation struct {
+	ClientMode types.ClientMode
+	Store      ClientModeStore
+}
+
+func (o ClientModeOperation) Validate() error {
+	_, err := o.ClientMode.MarshalText()
+	return err
+}
+
+func (o ClientModeOperation) Check(machineID string) error {
+	return types.ValidateMachineID(machineID)
+}
+
+func (o ClientModeOperation) Apply(machineID string) error {
+	err := types.ValidateMachineID(machineID)
+	if err != nil {
+		return err
+	}
+
+	previous, err := o.Store.GetClientMode(machineID)
+	if err != nil {
+		return err
+	}
+	err = o.Store.SetClientMode(machineID, o.ClientMode)
+	if err != nil {
+		return err
+	}
+	if previous == o.ClientMode {
+		return nil
+	}
+	err = o.Store.RecordTransition(machineID, previous, o.ClientMode)
+	if err != nil {
+		return fmt.Errorf("client mode was set, but the transition could not be recorded: %w", err)
+	}
+	return nil
+}
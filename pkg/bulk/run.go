@@ -0,0 +1,232 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/types"
+	awsdynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// maxMachinesPerTarget bounds how many machines a single serial number or primary user resolves to. A serial
+	// number has more than one machine ID when a machine has been reinstalled.
+	maxMachinesPerTarget = 25
+	// writeBatchSize is how many machines are written at once; a failed write fails the results of its whole batch
+	writeBatchSize = 25
+)
+
+// Status is the outcome of a bulk operation for a machine
+type Status string
+
+const (
+	StatusApplied Status = "applied"
+	// StatusDryRun is a machine that the operation would be applied to
+	StatusDryRun Status = "dry_run"
+	StatusFailed Status = "failed"
+	// StatusInvalid is an entry of the targets file that does not identify machines
+	StatusInvalid Status = "invalid"
+	// StatusUnresolved is an entry that identifies no machine that has checked in
+	StatusUnresolved Status = "unresolved"
+	// StatusDuplicate is a machine that an earlier entry of the targets file already resolved to
+	StatusDuplicate Status = "duplicate"
+)
+
+// Result is the outcome for a machine that an entry of the targets file resolved to, or for the entry itself if it
+// resolved to no machine
+type Result struct {
+	Position  string  `json:"position"`
+	Key       KeyType `json:"key,omitempty"`
+	Value     string  `json:"value,omitempty"`
+	MachineID string  `json:"machine_id,omitempty"`
+	Status    Status  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Runner applies an operation to the machines of a targets file
+type Runner struct {
+	Finder sensordata.SensorDataFinder
+	// Client writes the batches of a WriteOperation
+	Client    dynamodb.BatchWriteItemAPI
+	Operation Operation
+	// DryRun resolves the machines and checks the operation against every one of them, without writing anything
+	DryRun bool
+	// Progress, if set, is called as entries of the targets file are processed
+	Progress func(done int, total int)
+}
+
+// Run resolves every target and applies the operation to the machines they resolve to, in batches for a WriteOperation
+// and one machine at a time for a MachineOperation. It returns a result per machine, in the order of the targets file;
+// an error is only returned if the operation itself is invalid.
+func (r Runner) Run(targets []Target) (results []Result, err error) {
+	switch r.Operation.(type) {
+	case WriteOperation, MachineOperation:
+	default:
+		err = fmt.Errorf("unsupported bulk operation %T", r.Operation)
+		return
+	}
+	err = r.Operation.Validate()
+	if err != nil {
+		return
+	}
+
+	seen := make(map[string]bool)
+	var pending []int
+	var requests []awsdynamodbtypes.WriteRequest
+
+	flush := func() {
+		if len(requests) > 0 {
+			status, message := StatusApplied, ""
+			if inerr := r.Client.BatchWriteItems(requests); inerr != nil {
+				status, message = StatusFailed, inerr.Error()
+			}
+			for _, index := range pending {
+				results[index].Status = status
+				results[index].Error = message
+			}
+		}
+		pending = pending[:0]
+		requests = requests[:0]
+	}
+
+	add := func(target Target) {
+		if target.Err != nil {
+			results = append(results, Result{Position: target.Position, Status: StatusInvalid, Error: target.Err.Error()})
+			return
+		}
+
+		base := Result{Position: target.Position, Key: target.Key, Value: target.Value}
+		machineIDs, inerr := Resolve(r.Finder, target)
+		if inerr != nil {
+			base.Status = StatusUnresolved
+			base.Error = inerr.Error()
+			results = append(results, base)
+			return
+		}
+
+		for _, machineID := range machineIDs {
+			result := base
+			result.MachineID = machineID
+			if seen[machineID] {
+				result.Status = StatusDuplicate
+				results = append(results, result)
+				continue
+			}
+			seen[machineID] = true
+
+			switch operation := r.Operation.(type) {
+			case WriteOperation:
+				request, inerr := operation.WriteRequest(r.Client, machineID)
+				switch {
+				case inerr != nil:
+					result.Status = StatusFailed
+					result.Error = inerr.Error()
+				case r.DryRun:
+					result.Status = StatusDryRun
+				default:
+					pending = append(pending, len(results))
+					requests = append(requests, *request)
+				}
+			case MachineOperation:
+				var inerr error
+				if r.DryRun {
+					result.Status = StatusDryRun
+					inerr = operation.Check(machineID)
+				} else {
+					result.Status = StatusApplied
+					inerr = operation.Apply(machineID)
+				}
+				if inerr != nil {
+					result.Status = StatusFailed
+					result.Error = inerr.Error()
+				}
+			}
+			results = append(results, result)
+		}
+	}
+
+	for done, target := range targets {
+		add(target)
+		if len(requests) >= writeBatchSize {
+			flush()
+		}
+		if r.Progress != nil {
+			r.Progress(done+1, len(targets))
+		}
+	}
+	flush()
+	return
+}
+
+// Resolve returns the machines that a target identifies. Serial numbers and primary users are looked up in the sensor
+// data that machines upload on every preflight, so only machines that have checked in can be found.
+func Resolve(finder sensordata.SensorDataFinder, target Target) (machineIDs []string, err error) {
+	switch target.Key {
+	case KeyMachineID:
+		err = types.ValidateMachineID(target.Value)
+		if err != nil {
+			return
+		}
+		return []string{target.Value}, nil
+	case KeySerialNumber:
+		machineIDs, err = finder.GetMachineIDsFromSerialNumber(target.Value, maxMachinesPerTarget)
+	case KeyPrimaryUser:
+		machineIDs, err = finder.GetMachineIDsFromPrimaryUser(target.Value, maxMachinesPerTarget)
+	default:
+		return nil, fmt.Errorf("unknown key %q", target.Key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %s %q: %w", target.Key, target.Value, err)
+	}
+	if len(machineIDs) == 0 {
+		return nil, fmt.Errorf("no machine with %s %q has checked in", target.Key, target.Value)
+	}
+	return
+}
+
+// Summary counts the results of a bulk run by status
+type Summary map[Status]int
+
+// Summarize counts the results of a bulk run by status
+func Summarize(results []Result) Summary {
+	summary := make(Summary)
+	for _, result := range results {
+		summary[result.Status]++
+	}
+	return summary
+}
+
+var resultsHeader = []string{"position", "key", "value", "machine_id", "status", "error"}
+
+// WriteResults writes the results of a bulk run, one per line of a csv file or as a json list
+func WriteResults(format Format, w io.Writer, results []Result) error {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		_ = writer.Write(resultsHeader)
+		for _, result := range results {
+			_ = writer.Write([]string{
+				result.Position,
+				string(result.Key),
+				result.Value,
+				result.MachineID,
+				string(result.Status),
+				result.Error,
+			})
+		}
+		writer.Flush()
+		return writer.Error()
+	case FormatJSON:
+		if results == nil {
+			results = []Result{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
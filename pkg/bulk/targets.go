@@ -0,0 +1,144 @@
+// Package bulk applies a machine rule or a machine configuration to many machines at once. The machines are listed in
+// a csv or json file, by machine ID, serial number or primary user, and every entry of the file gets a result.
+package bulk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/airbnb/rudolph/internal/csv"
+)
+
+// Format is the format of a targets or results file
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// DetectFormat guesses the format of a targets or results file from its extension
+func DetectFormat(filename string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), ".")) {
+	case "csv":
+		return FormatCSV, nil
+	case "json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("cannot tell the format of %q from its extension; must be .csv or .json", filename)
+}
+
+// KeyType is how an entry of a targets file identifies machines
+type KeyType string
+
+const (
+	KeyMachineID    KeyType = "machine_id"
+	KeySerialNumber KeyType = "serial"
+	KeyPrimaryUser  KeyType = "primary_user"
+)
+
+var keyTypes = []KeyType{KeyMachineID, KeySerialNumber, KeyPrimaryUser}
+
+// Target is an entry of a targets file
+type Target struct {
+	// Position locates the entry in the targets file, e.g. "line 12", or "entry #3" for json files
+	Position string
+	Key      KeyType
+	Value    string
+	// Err is set instead of Key and Value when the entry does not identify machines
+	Err error
+}
+
+// ReadTargets reads a targets file. A csv file has a header row with any of the machine_id, serial and primary_user
+// columns, and a json file is a list of objects with any of these keys; either way every entry must set exactly one of
+// them. Other columns and keys are ignored, so that an inventory export can be used as is.
+//
+// Entries that do not identify machines are returned with an error, so that they are reported alongside the others.
+func ReadTargets(format Format, r io.Reader) ([]Target, error) {
+	switch format {
+	case FormatCSV:
+		return readCSVTargets(r)
+	case FormatJSON:
+		return readJSONTargets(r)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+func readCSVTargets(r io.Reader) (targets []Target, err error) {
+	records, err := csv.ParseCsv(r)
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the targets file is empty")
+	} else if err != nil {
+		return nil, err
+	}
+
+	for record := range records {
+		position := fmt.Sprintf("line %d", record.Line)
+		if record.Err != nil {
+			targets = append(targets, Target{Position: position, Err: record.Err})
+			continue
+		}
+		if len(targets) == 0 && !hasKeyColumn(record.Data) {
+			// Drain the remaining lines so that the reader goroutine exits
+			for range records {
+			}
+			return nil, errors.New("the header row must have a machine_id, serial or primary_user column")
+		}
+		targets = append(targets, newTarget(position, record.Data))
+	}
+	return
+}
+
+func readJSONTargets(r io.Reader) (targets []Target, err error) {
+	var entries []map[string]interface{}
+	err = json.NewDecoder(r).Decode(&entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse json: %w", err)
+	}
+
+	for index, entry := range entries {
+		position := fmt.Sprintf("entry #%d", index+1)
+		fields := make(map[string]string, len(entry))
+		for key, value := range entry {
+			if s, ok := value.(string); ok {
+				fields[key] = s
+			} else if value != nil {
+				fields[key] = fmt.Sprint(value)
+			}
+		}
+		targets = append(targets, newTarget(position, fields))
+	}
+	return
+}
+
+func hasKeyColumn(record map[string]string) bool {
+	for _, key := range keyTypes {
+		if _, ok := record[string(key)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func newTarget(position string, fields map[string]string) Target {
+	target := Target{Position: position}
+	for _, key := range keyTypes {
+		value := strings.TrimSpace(fields[string(key)])
+		if value == "" {
+			continue
+		}
+		if target.Value != "" {
+			return Target{Position: position, Err: fmt.Errorf("sets both %s and %s; set exactly one", target.Key, key)}
+		}
+		target.Key = key
+		target.Value = value
+	}
+	if target.Value == "" {
+		target.Err = errors.New("sets none of machine_id, serial and primary_user")
+	}
+	return target
+}
@@ -0,0 +1,281 @@
+// Package compliance compares what each sensor last reported in preflight with what Rudolph intends for it, and
+// reports the machines that drifted from their intended configuration.
+package compliance
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// Check is a way in which a machine can drift from its intended configuration
+type Check string
+
+const (
+	// CheckClientMode is a machine reporting MONITOR while it should be in LOCKDOWN
+	CheckClientMode Check = "client_mode"
+	// CheckRuleCount is a machine whose reported rule counts deviate from its effective ruleset beyond the tolerance
+	CheckRuleCount Check = "rule_count"
+	// CheckTransitiveRules is a machine holding transitive rules while they are disabled for it
+	CheckTransitiveRules Check = "transitive_rules"
+	// CheckSantaVersion is a machine running a Santa version below the minimum
+	CheckSantaVersion Check = "santa_version"
+)
+
+// Checks lists every check, in the order they are reported
+var Checks = []Check{CheckClientMode, CheckRuleCount, CheckTransitiveRules, CheckSantaVersion}
+
+// Policy decides when a machine is out of compliance
+type Policy struct {
+	// RuleCountTolerance is how many rules a reported count can be off by before it is flagged
+	RuleCountTolerance int `json:"rule_count_tolerance"`
+	// RuleCountTolerancePercent allows larger rulesets to be off by a share of their expected count instead
+	RuleCountTolerancePercent float64 `json:"rule_count_tolerance_percent"`
+	// MinimumSantaVersion is the oldest Santa version allowed; Santa versions are not checked when it is empty
+	MinimumSantaVersion string `json:"minimum_santa_version,omitempty"`
+}
+
+// DefaultPolicy returns the policy used when none is configured
+func DefaultPolicy() Policy {
+	return Policy{
+		RuleCountTolerance:        5,
+		RuleCountTolerancePercent: 1,
+	}
+}
+
+// Validate ensures the tolerances make sense
+func (p Policy) Validate() error {
+	if p.RuleCountTolerance < 0 {
+		return errors.New("the rule count tolerance cannot be negative")
+	}
+	if p.RuleCountTolerancePercent < 0 || p.RuleCountTolerancePercent > 100 {
+		return errors.New("the rule count tolerance percent must be between 0 and 100")
+	}
+	return nil
+}
+
+// tolerance is how far a count can be off when the ruleset expects the given number of rules
+func (p Policy) tolerance(expected int) int {
+	percent := int(math.Floor(float64(expected) * p.RuleCountTolerancePercent / 100))
+	return max(p.RuleCountTolerance, percent)
+}
+
+// Finding is a single failed check
+type Finding struct {
+	Check  Check  `json:"check"`
+	Detail string `json:"detail"`
+}
+
+// Machine is the compliance detail of a single machine
+type Machine struct {
+	MachineID    string `json:"machine_id"`
+	SerialNum    string `json:"serial_num,omitempty"`
+	PrimaryUser  string `json:"primary_user,omitempty"`
+	SantaVersion string `json:"santa_version"`
+	ReportedMode string `json:"reported_mode"`
+	IntendedMode string `json:"intended_mode"`
+	// LastSeen is when the sensor last completed a preflight, which is when everything it reported was collected
+	LastSeen string `json:"last_seen"`
+	// ExpectedRuleCounts come from the machine's effective ruleset; ReportedRuleCounts exclude transitive rules
+	ExpectedRuleCounts     ruleset.RuleCounts `json:"expected_rule_counts"`
+	ReportedRuleCounts     ruleset.RuleCounts `json:"reported_rule_counts"`
+	TransitiveRuleCount    int                `json:"transitive_rule_count"`
+	TransitiveRulesEnabled bool               `json:"transitive_rules_enabled"`
+	Compliant              bool               `json:"compliant"`
+	Findings               []Finding          `json:"findings"`
+}
+
+// Failed reports whether the machine failed the given check
+func (m Machine) Failed(check Check) bool {
+	for _, finding := range m.Findings {
+		if finding.Check == check {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary counts the machines that failed each check
+type Summary struct {
+	MachinesChecked int           `json:"machines_checked"`
+	Compliant       int           `json:"compliant"`
+	NonCompliant    int           `json:"non_compliant"`
+	ByCheck         map[Check]int `json:"by_check"`
+}
+
+// Report is the compliance of the whole fleet
+type Report struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Policy      Policy    `json:"policy"`
+	Summary     Summary   `json:"summary"`
+	Machines    []Machine `json:"machines"`
+}
+
+// Store is what Run needs from DynamoDB
+type Store interface {
+	ListSensorData() ([]sensordata.SensorData, error)
+	GetIntendedConfig(machineID string) (machineconfiguration.MachineConfiguration, error)
+	GetRuleset(machineID string) (ruleset.Ruleset, error)
+}
+
+// GetStore returns a Store backed by the DynamoDB table
+func GetStore(client dynamodb.DynamoDBClient, timeProvider clock.TimeProvider) Store {
+	return &concreteStore{
+		client:        client,
+		configService: machineconfiguration.GetMachineConfigurationService(client, timeProvider),
+	}
+}
+
+type concreteStore struct {
+	client        dynamodb.DynamoDBClient
+	configService machineconfiguration.MachineConfigurationService
+
+	// Global rules are shared by every machine, so they are only loaded once per report
+	globalRules       []rules.SantaRule
+	globalRulesLoaded bool
+}
+
+func (s *concreteStore) ListSensorData() ([]sensordata.SensorData, error) {
+	return sensordata.ListSensorData(s.client)
+}
+
+func (s *concreteStore) GetIntendedConfig(machineID string) (machineconfiguration.MachineConfiguration, error) {
+	return s.configService.GetIntendedConfig(machineID)
+}
+
+func (s *concreteStore) GetRuleset(machineID string) (ruleset.Ruleset, error) {
+	if !s.globalRulesLoaded {
+		globalRules, err := ruleset.LoadGlobalRules(s.client)
+		if err != nil {
+			return ruleset.Ruleset{}, err
+		}
+		s.globalRules = globalRules
+		s.globalRulesLoaded = true
+	}
+	return ruleset.ForMachine(s.client, s.globalRules, machineID)
+}
+
+// Run checks every machine that has reported sensor data against its intended configuration and effective ruleset
+func Run(store Store, timeProvider clock.TimeProvider, policy Policy) (report Report, err error) {
+	if err = policy.Validate(); err != nil {
+		return
+	}
+
+	sensorData, err := store.ListSensorData()
+	if err != nil {
+		return
+	}
+	sort.Slice(sensorData, func(i, j int) bool {
+		return sensorData[i].MachineID < sensorData[j].MachineID
+	})
+
+	report = Report{
+		GeneratedAt: timeProvider.Now().UTC(),
+		Policy:      policy,
+		Summary:     Summary{ByCheck: map[Check]int{}},
+		Machines:    make([]Machine, 0, len(sensorData)),
+	}
+	for _, item := range sensorData {
+		config, err := store.GetIntendedConfig(item.MachineID)
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to get the intended configuration of machine %q: %w", item.MachineID, err)
+		}
+		rs, err := store.GetRuleset(item.MachineID)
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to get the ruleset of machine %q: %w", item.MachineID, err)
+		}
+
+		machine := Assess(policy, item, config, rs.Counts())
+		report.Summary.MachinesChecked++
+		if machine.Compliant {
+			report.Summary.Compliant++
+		} else {
+			report.Summary.NonCompliant++
+		}
+		for _, check := range Checks {
+			if machine.Failed(check) {
+				report.Summary.ByCheck[check]++
+			}
+		}
+		report.Machines = append(report.Machines, machine)
+	}
+	return
+}
+
+// Assess runs every check against a single machine
+func Assess(policy Policy, item sensordata.SensorData, config machineconfiguration.MachineConfiguration, expected ruleset.RuleCounts) Machine {
+	reportedModeText, _ := item.ClientMode.MarshalText()
+	intendedModeText, _ := config.ClientMode.MarshalText()
+
+	// The sensor counts the transitive rules it created locally as binary rules, so they are taken out before
+	// comparing with the ruleset, which only knows about the rules the server sent
+	reported, transitive := ruleset.ReportedCounts(item)
+	reported.Total -= transitive
+	reported.Binary -= transitive
+
+	machine := Machine{
+		MachineID:              item.MachineID,
+		SerialNum:              item.SerialNum,
+		PrimaryUser:            item.PrimaryUser,
+		SantaVersion:           item.SantaVersion,
+		ReportedMode:           string(reportedModeText),
+		IntendedMode:           string(intendedModeText),
+		LastSeen:               item.Time,
+		ExpectedRuleCounts:     expected,
+		ReportedRuleCounts:     reported,
+		TransitiveRuleCount:    transitive,
+		TransitiveRulesEnabled: config.EnabledTransitiveRules,
+		Findings:               []Finding{},
+	}
+
+	if config.ClientMode == types.Lockdown && item.ClientMode != types.Lockdown {
+		machine.Findings = append(machine.Findings, Finding{
+			Check:  CheckClientMode,
+			Detail: fmt.Sprintf("reports %s but should be in %s", machine.ReportedMode, machine.IntendedMode),
+		})
+	}
+
+	for _, difference := range expected.Compare(reported) {
+		delta := difference.Delta()
+		if delta < 0 {
+			delta = -delta
+		}
+		if delta > policy.tolerance(difference.Expected) {
+			machine.Findings = append(machine.Findings, Finding{
+				Check:  CheckRuleCount,
+				Detail: fmt.Sprintf("%s rules: expected %d, reported %d (%+d)", difference.Name, difference.Expected, difference.Reported, difference.Delta()),
+			})
+		}
+	}
+
+	if transitive > 0 && !config.EnabledTransitiveRules {
+		machine.Findings = append(machine.Findings, Finding{
+			Check:  CheckTransitiveRules,
+			Detail: fmt.Sprintf("holds %d transitive rules but transitive rules are disabled", transitive),
+		})
+	}
+
+	if policy.MinimumSantaVersion != "" && types.CompareVersions(item.SantaVersion, policy.MinimumSantaVersion) < 0 {
+		santaVersion := item.SantaVersion
+		if santaVersion == "" {
+			santaVersion = "an unknown version"
+		}
+		machine.Findings = append(machine.Findings, Finding{
+			Check:  CheckSantaVersion,
+			Detail: fmt.Sprintf("runs Santa %s, below the minimum of %s", santaVersion, policy.MinimumSantaVersion),
+		})
+	}
+
+	machine.Compliant = len(machine.Findings) == 0
+	return machine
+}
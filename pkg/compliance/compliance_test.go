@@ -0,0 +1,181 @@
+package compliance
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStore struct {
+	sensorData []sensordata.SensorData
+	configs    map[string]machineconfiguration.MachineConfiguration
+	rulesets   map[string]ruleset.Ruleset
+	err        error
+}
+
+func (m mockStore) ListSensorData() ([]sensordata.SensorData, error) {
+	return m.sensorData, m.err
+}
+
+func (m mockStore) GetIntendedConfig(machineID string) (machineconfiguration.MachineConfiguration, error) {
+	config, ok := m.configs[machineID]
+	if !ok {
+		return machineconfiguration.GetUniversalDefaultConfig(), nil
+	}
+	return config, nil
+}
+
+func (m mockStore) GetRuleset(machineID string) (ruleset.Ruleset, error) {
+	return m.rulesets[machineID], nil
+}
+
+func machineID(i int) string {
+	return fmt.Sprintf("AAAAAAAA-A00A-1234-1234-%012d", i)
+}
+
+func binaryRules(n int) []rules.SantaRule {
+	santaRules := make([]rules.SantaRule, n)
+	for i := range santaRules {
+		santaRules[i] = rules.SantaRule{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist, Identifier: fmt.Sprintf("%064d", i)}
+	}
+	return santaRules
+}
+
+// reportedSensorData builds sensor data the way preflight stores it, from the count of binary rules and the compiler
+// and transitive rules among them
+func reportedSensorData(machineID string, clientMode types.ClientMode, santaVersion string, binary int, compiler int, transitive int) sensordata.SensorData {
+	return sensordata.NewSensorData(clock.FrozenTimeProvider{}, machineID, "", "", "", santaVersion, clientMode, false, "", "", 0, binary, 0, 0, 0, compiler, transitive)
+}
+
+func Test_Assess(t *testing.T) {
+	lockdown := machineconfiguration.MachineConfiguration{ClientMode: types.Lockdown}
+	monitor := machineconfiguration.MachineConfiguration{ClientMode: types.Monitor}
+	expected := ruleset.RuleCounts{Total: 10, Binary: 10}
+	policy := Policy{RuleCountTolerance: 2, MinimumSantaVersion: "2023.5"}
+
+	cases := []struct {
+		name     string
+		item     sensordata.SensorData
+		config   machineconfiguration.MachineConfiguration
+		expected []Check
+	}{
+		{
+			"compliant",
+			reportedSensorData("", types.Lockdown, "2023.10", 11, 0, 0),
+			lockdown,
+			nil,
+		},
+		{
+			"monitor when it should be in lockdown",
+			reportedSensorData("", types.Monitor, "2023.10", 10, 0, 0),
+			lockdown,
+			[]Check{CheckClientMode},
+		},
+		{
+			"lockdown when it should be in monitor is stricter, so it is not flagged",
+			reportedSensorData("", types.Lockdown, "2023.10", 10, 0, 0),
+			monitor,
+			nil,
+		},
+		{
+			"rule counts beyond the tolerance",
+			reportedSensorData("", types.Monitor, "2023.10", 3, 0, 0),
+			monitor,
+			[]Check{CheckRuleCount, CheckRuleCount},
+		},
+		{
+			"transitive rules are not counted against the ruleset but are flagged when disabled",
+			reportedSensorData("", types.Monitor, "2023.10", 40, 0, 30),
+			monitor,
+			[]Check{CheckTransitiveRules},
+		},
+		{
+			"transitive rules are fine when enabled",
+			reportedSensorData("", types.Monitor, "2023.10", 40, 0, 30),
+			machineconfiguration.MachineConfiguration{ClientMode: types.Monitor, EnabledTransitiveRules: true},
+			nil,
+		},
+		{
+			"compiler and transitive rules are only counted once in the total",
+			reportedSensorData("", types.Monitor, "2023.10", 40, 2, 30),
+			machineconfiguration.MachineConfiguration{ClientMode: types.Monitor, EnabledTransitiveRules: true},
+			nil,
+		},
+		{
+			"santa version below the minimum",
+			reportedSensorData("", types.Monitor, "2023.4", 10, 0, 0),
+			monitor,
+			[]Check{CheckSantaVersion},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			machine := Assess(policy, test.item, test.config, expected)
+
+			var checks []Check
+			for _, finding := range machine.Findings {
+				checks = append(checks, finding.Check)
+			}
+			assert.Equal(t, test.expected, checks)
+			assert.Equal(t, len(test.expected) == 0, machine.Compliant)
+		})
+	}
+}
+
+func Test_Policy_TolerancePercent(t *testing.T) {
+	policy := Policy{RuleCountTolerance: 5, RuleCountTolerancePercent: 1}
+	assert.Equal(t, 5, policy.tolerance(100))
+	assert.Equal(t, 20, policy.tolerance(2000))
+
+	assert.Error(t, Policy{RuleCountTolerance: -1}.Validate())
+	assert.Error(t, Policy{RuleCountTolerancePercent: 101}.Validate())
+	assert.Empty(t, DefaultPolicy().Validate())
+}
+
+func Test_Run(t *testing.T) {
+	store := mockStore{
+		sensorData: []sensordata.SensorData{
+			reportedSensorData(machineID(2), types.Monitor, "2022.1", 1, 0, 0),
+			reportedSensorData(machineID(1), types.Lockdown, "2023.10", 1, 0, 0),
+		},
+		configs: map[string]machineconfiguration.MachineConfiguration{
+			machineID(2): {ClientMode: types.Lockdown},
+		},
+		rulesets: map[string]ruleset.Ruleset{
+			machineID(1): ruleset.New(binaryRules(1), nil),
+			machineID(2): ruleset.New(binaryRules(1), binaryRules(20)),
+		},
+	}
+	timeProvider := clock.FrozenTimeProvider{Current: time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)}
+
+	report, err := Run(store, timeProvider, Policy{RuleCountTolerance: 5, MinimumSantaVersion: "2023.1"})
+
+	assert.Empty(t, err)
+	assert.Equal(t, timeProvider.Current, report.GeneratedAt)
+	assert.Equal(t, 2, report.Summary.MachinesChecked)
+	assert.Equal(t, 1, report.Summary.Compliant)
+	assert.Equal(t, 1, report.Summary.NonCompliant)
+	assert.Equal(t, map[Check]int{CheckClientMode: 1, CheckRuleCount: 1, CheckSantaVersion: 1}, report.Summary.ByCheck)
+	assert.Equal(t, machineID(1), report.Machines[0].MachineID)
+	assert.True(t, report.Machines[0].Compliant)
+	assert.Equal(t, "LOCKDOWN", report.Machines[1].IntendedMode)
+	assert.Equal(t, 20, report.Machines[1].ExpectedRuleCounts.Total)
+}
+
+func Test_Run_Error(t *testing.T) {
+	_, err := Run(mockStore{err: errors.New("boom")}, clock.FrozenTimeProvider{}, DefaultPolicy())
+	assert.Error(t, err)
+
+	_, err = Run(mockStore{}, clock.FrozenTimeProvider{}, Policy{RuleCountTolerance: -1})
+	assert.Error(t, err)
+}
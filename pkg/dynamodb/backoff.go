@@ -0,0 +1,101 @@
+package dynamodb
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	defaultBackoffBase        = 50 * time.Millisecond
+	defaultBackoffMax         = 20 * time.Second
+	defaultBackoffMaxAttempts = 10
+)
+
+// ErrThrottled is returned by the batch helpers when items could still not be written after every retry
+var ErrThrottled = errors.New("requests are being throttled")
+
+// adaptiveBackoff paces the requests of the batch helpers. Its delay doubles, with jitter, every time a request is
+// throttled and halves every time one succeeds, so that concurrent writers sharing a client settle on a rate the
+// table can sustain instead of retrying in lockstep.
+type adaptiveBackoff struct {
+	base        time.Duration
+	max         time.Duration
+	maxAttempts int
+	sleep       func(time.Duration)
+
+	mu    sync.Mutex
+	delay time.Duration
+}
+
+func newAdaptiveBackoff() *adaptiveBackoff {
+	return &adaptiveBackoff{
+		base:        defaultBackoffBase,
+		max:         defaultBackoffMax,
+		maxAttempts: defaultBackoffMaxAttempts,
+		sleep:       time.Sleep,
+	}
+}
+
+// throttled grows the delay and waits for it
+func (b *adaptiveBackoff) throttled() {
+	b.mu.Lock()
+	if b.delay < b.base {
+		b.delay = b.base
+	} else {
+		b.delay *= 2
+	}
+	if b.delay > b.max {
+		b.delay = b.max
+	}
+	delay := b.delay
+	b.mu.Unlock()
+
+	// Full jitter, but never less than half of the delay
+	b.sleep(delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1)))
+}
+
+// succeeded shrinks the delay, and waits for what is left of it so that the rate only recovers gradually
+func (b *adaptiveBackoff) succeeded() {
+	b.mu.Lock()
+	b.delay /= 2
+	if b.delay < b.base {
+		b.delay = 0
+	}
+	delay := b.delay
+	b.mu.Unlock()
+
+	if delay > 0 {
+		b.sleep(delay)
+	}
+}
+
+// isThrottlingError reports whether a request failed only because the table is busy, and can be retried as is
+func isThrottlingError(err error) bool {
+	var canceled *types.TransactionCanceledException
+	if errors.As(err, &canceled) {
+		for _, reason := range canceled.CancellationReasons {
+			if reason.Code == nil {
+				continue
+			}
+			switch *reason.Code {
+			case "ThrottlingError", "ProvisionedThroughputExceeded", "TransactionConflict":
+				return true
+			}
+		}
+		return false
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "ProvisionedThroughputExceededException", "ThrottlingException", "RequestLimitExceeded", "TransactionInProgressException":
+			return true
+		}
+	}
+	return false
+}
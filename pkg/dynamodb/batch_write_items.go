@@ -0,0 +1,141 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// maxBatchWriteItems and maxTransactWriteItems are the most items DynamoDB accepts in a single request
+	maxBatchWriteItems    = 25
+	maxTransactWriteItems = 100
+)
+
+// BatchWriteItemAPI writes many items at once, without the atomicity of TransactWriteItems but at half its cost
+type BatchWriteItemAPI interface {
+	// BatchWriteItems writes any number of put and delete requests, 25 at a time, retrying throttled requests and
+	// unprocessed items with backoff
+	BatchWriteItems(requests []types.WriteRequest) error
+	CreatePutRequest(item interface{}) (*types.WriteRequest, error)
+	CreateDeleteRequest(key PrimaryKey) (*types.WriteRequest, error)
+}
+
+// TransactWriteItemGroupsAPI writes many small transactions at once
+type TransactWriteItemGroupsAPI interface {
+	// TransactWriteItemGroups packs groups of items that must be written atomically, such as a rule and its feed
+	// entry, into as few transactions as possible, retrying throttled transactions with backoff. A group is never split
+	// across transactions. Groups are written in order, and written counts the groups that were written before an
+	// error occurred.
+	TransactWriteItemGroups(groups [][]types.TransactWriteItem) (written int, err error)
+}
+
+func (dbc concreteDynamoDBClient) BatchWriteItems(requests []types.WriteRequest) error {
+	return batchWriteItems(dbc.tableName, &dbc.awsclient, requests, dbc.backoff, dbc.timeout)
+}
+
+func (dbc concreteDynamoDBClient) TransactWriteItemGroups(groups [][]types.TransactWriteItem) (int, error) {
+	return transactWriteItemGroups(&dbc.awsclient, groups, dbc.backoff, dbc.timeout)
+}
+
+func (dbc concreteDynamoDBClient) CreatePutRequest(item interface{}) (*types.WriteRequest, error) {
+	putItem, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return nil, err
+	}
+	return &types.WriteRequest{PutRequest: &types.PutRequest{Item: putItem}}, nil
+}
+
+func (dbc concreteDynamoDBClient) CreateDeleteRequest(key PrimaryKey) (*types.WriteRequest, error) {
+	keyInput, err := attributevalue.MarshalMap(key)
+	if err != nil {
+		return nil, err
+	}
+	return &types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: keyInput}}, nil
+}
+
+type dynamodbBatchWriteItemAPI interface {
+	BatchWriteItem(ctx context.Context, in *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+func batchWriteItems(tableName string, api dynamodbBatchWriteItemAPI, requests []types.WriteRequest, backoff *adaptiveBackoff, timeout time.Duration) error {
+	for start := 0; start < len(requests); start += maxBatchWriteItems {
+		pending := requests[start:min(start+maxBatchWriteItems, len(requests))]
+
+		for attempt := 1; len(pending) > 0; attempt++ {
+			if attempt > backoff.maxAttempts {
+				return fmt.Errorf("%d items were not written after %d attempts: %w", len(pending), backoff.maxAttempts, ErrThrottled)
+			}
+
+			output, err := batchWriteItem(tableName, api, pending, timeout)
+			if isThrottlingError(err) {
+				backoff.throttled()
+				continue
+			} else if err != nil {
+				return err
+			}
+
+			// Items that DynamoDB did not get to are returned, and must be sent again
+			pending = output.UnprocessedItems[tableName]
+			if len(pending) > 0 {
+				backoff.throttled()
+			} else {
+				backoff.succeeded()
+			}
+		}
+	}
+	return nil
+}
+
+func batchWriteItem(tableName string, api dynamodbBatchWriteItemAPI, requests []types.WriteRequest, timeout time.Duration) (*dynamodb.BatchWriteItemOutput, error) {
+	ctx := context.TODO()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return api.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{
+			tableName: requests,
+		},
+	})
+}
+
+func transactWriteItemGroups(api dynamodbTransactWriteItemsAPI, groups [][]types.TransactWriteItem, backoff *adaptiveBackoff, timeout time.Duration) (written int, err error) {
+	for written < len(groups) {
+		// Pack as many whole groups as fit in a transaction
+		var items []types.TransactWriteItem
+		packed := 0
+		for _, group := range groups[written:] {
+			if len(group) > maxTransactWriteItems {
+				err = fmt.Errorf("a group of %d items does not fit in a single transaction", len(group))
+				return
+			}
+			if len(items)+len(group) > maxTransactWriteItems {
+				break
+			}
+			items = append(items, group...)
+			packed++
+		}
+
+		for attempt := 1; ; attempt++ {
+			_, err = transactWriteItems(api, items, nil, timeout)
+			if err == nil {
+				backoff.succeeded()
+				break
+			}
+			if !isThrottlingError(err) {
+				return
+			}
+			if attempt >= backoff.maxAttempts {
+				err = fmt.Errorf("transaction of %d items was not written after %d attempts: %w", len(items), attempt, ErrThrottled)
+				return
+			}
+			backoff.throttled()
+		}
+		written += packed
+	}
+	return
+}
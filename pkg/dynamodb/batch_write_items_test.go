@@ -0,0 +1,168 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBatchWriteItemAPI func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+
+func (m mockBatchWriteItemAPI) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+type mockTransactWriteItemsAPI func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+
+func (m mockTransactWriteItemsAPI) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+// testBackoff records the delays instead of sleeping
+func testBackoff(sleeps *[]time.Duration) *adaptiveBackoff {
+	backoff := newAdaptiveBackoff()
+	backoff.sleep = func(d time.Duration) {
+		*sleeps = append(*sleeps, d)
+	}
+	return backoff
+}
+
+func putRequests(n int) []types.WriteRequest {
+	requests := make([]types.WriteRequest, n)
+	for i := range requests {
+		requests[i] = types.WriteRequest{PutRequest: &types.PutRequest{}}
+	}
+	return requests
+}
+
+func Test_batchWriteItems_ChunksAndRetriesUnprocessedItems(t *testing.T) {
+	var sleeps []time.Duration
+	var sizes []int
+	calls := 0
+
+	err := batchWriteItems(
+		"test_table",
+		mockBatchWriteItemAPI(func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+			calls++
+			requests := params.RequestItems["test_table"]
+			sizes = append(sizes, len(requests))
+			if calls == 1 {
+				// DynamoDB only got to the first 20 items
+				return &dynamodb.BatchWriteItemOutput{
+					UnprocessedItems: map[string][]types.WriteRequest{"test_table": requests[20:]},
+				}, nil
+			}
+			return &dynamodb.BatchWriteItemOutput{}, nil
+		}),
+		putRequests(30),
+		testBackoff(&sleeps),
+		time.Second,
+	)
+
+	assert.Empty(t, err)
+	assert.Equal(t, []int{25, 5, 5}, sizes)
+	assert.Len(t, sleeps, 1)
+}
+
+func Test_batchWriteItems_GivesUpWhenThrottled(t *testing.T) {
+	var sleeps []time.Duration
+
+	err := batchWriteItems(
+		"test_table",
+		mockBatchWriteItemAPI(func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+			return nil, &types.ProvisionedThroughputExceededException{Message: aws.String("slow down")}
+		}),
+		putRequests(3),
+		testBackoff(&sleeps),
+		time.Second,
+	)
+
+	assert.ErrorIs(t, err, ErrThrottled)
+	assert.Len(t, sleeps, defaultBackoffMaxAttempts)
+	// The delay grows while requests are throttled
+	assert.Greater(t, sleeps[len(sleeps)-1], sleeps[0])
+}
+
+func Test_batchWriteItems_OtherErrors(t *testing.T) {
+	var sleeps []time.Duration
+
+	err := batchWriteItems(
+		"test_table",
+		mockBatchWriteItemAPI(func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+			return nil, errors.New("access denied")
+		}),
+		putRequests(3),
+		testBackoff(&sleeps),
+		time.Second,
+	)
+
+	assert.EqualError(t, err, "access denied")
+	assert.Empty(t, sleeps)
+}
+
+func Test_transactWriteItemGroups(t *testing.T) {
+	var sleeps []time.Duration
+	var sizes []int
+	calls := 0
+
+	groups := make([][]types.TransactWriteItem, 60)
+	for i := range groups {
+		groups[i] = make([]types.TransactWriteItem, 2)
+	}
+
+	written, err := transactWriteItemGroups(
+		mockTransactWriteItemsAPI(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+			calls++
+			if calls == 1 {
+				return nil, &types.TransactionCanceledException{
+					CancellationReasons: []types.CancellationReason{{Code: aws.String("None")}, {Code: aws.String("ThrottlingError")}},
+				}
+			}
+			sizes = append(sizes, len(params.TransactItems))
+			return &dynamodb.TransactWriteItemsOutput{}, nil
+		}),
+		groups,
+		testBackoff(&sleeps),
+		time.Second,
+	)
+
+	assert.Empty(t, err)
+	assert.Equal(t, 60, written)
+	assert.Equal(t, []int{100, 20}, sizes)
+	assert.Len(t, sleeps, 1)
+}
+
+func Test_transactWriteItemGroups_ConditionFailure(t *testing.T) {
+	var sleeps []time.Duration
+	calls := 0
+
+	groups := make([][]types.TransactWriteItem, 75)
+	for i := range groups {
+		groups[i] = make([]types.TransactWriteItem, 2)
+	}
+
+	written, err := transactWriteItemGroups(
+		mockTransactWriteItemsAPI(func(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+			calls++
+			if calls == 2 {
+				return nil, &types.TransactionCanceledException{
+					CancellationReasons: []types.CancellationReason{{Code: aws.String("ConditionalCheckFailed")}},
+				}
+			}
+			return &dynamodb.TransactWriteItemsOutput{}, nil
+		}),
+		groups,
+		testBackoff(&sleeps),
+		time.Second,
+	)
+
+	assert.Error(t, err)
+	assert.Equal(t, 50, written)
+	assert.Empty(t, sleeps)
+}
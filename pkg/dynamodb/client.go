@@ -16,8 +16,11 @@ type DynamoDBClient interface {
 	GetItemAPI
 	PutItemAPI
 	UpdateItemAPI
+	ConditionalUpsertItemAPI
 	QueryAPI
 	TransactWriteItemsAPI
+	TransactWriteItemGroupsAPI
+	BatchWriteItemAPI
 	ScanAPI
 }
 
@@ -25,6 +28,8 @@ type concreteDynamoDBClient struct {
 	awsclient dynamodb.Client
 	tableName string
 	timeout   time.Duration
+	// backoff is shared by every copy of the client, so that all of its batch writes slow down together
+	backoff *adaptiveBackoff
 }
 
 func GetClient(inputTableName string, region string) DynamoDBClient {
@@ -38,12 +43,15 @@ func GetClientWithTimeout(inputTableName string, region string, timeout time.Dur
 		log.Fatalf("unable to load SDK config, %v", err)
 	}
 
-	client := dynamodb.NewFromConfig(cfg)
+	client := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, addMetricsMiddleware)
+	})
 
 	return concreteDynamoDBClient{
 		awsclient: *client,
 		tableName: inputTableName,
 		timeout:   defaultTimeout,
+		backoff:   newAdaptiveBackoff(),
 	}
 }
 
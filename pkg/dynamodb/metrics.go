@@ -0,0 +1,67 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/airbnb/rudolph/pkg/metrics"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// addMetricsMiddleware counts the requests that failed, once their retries are exhausted, and every attempt that
+// was throttled, including the ones that succeeded when retried. Failed conditions are counted on their own: callers
+// expect them, so they are not errors of the table.
+func addMetricsMiddleware(stack *middleware.Stack) error {
+	err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc("RudolphErrorMetrics", func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (out middleware.InitializeOutput, metadata middleware.Metadata, err error) {
+		out, metadata, err = next.HandleInitialize(ctx, in)
+		switch {
+		case err == nil:
+		case isConditionFailure(err):
+			metrics.Count("DynamoDBConditionalCheckFailures", 1, metrics.Dim("Operation", awsmiddleware.GetOperationName(ctx)))
+		default:
+			metrics.Count("DynamoDBErrors", 1, metrics.Dim("Operation", awsmiddleware.GetOperationName(ctx)))
+		}
+		return
+	}), middleware.After)
+	if err != nil {
+		return err
+	}
+
+	// Added after the retry middleware, so that it sees every attempt
+	return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("RudolphThrottleMetrics", func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (out middleware.FinalizeOutput, metadata middleware.Metadata, err error) {
+		out, metadata, err = next.HandleFinalize(ctx, in)
+		if err != nil && isThrottlingError(err) {
+			metrics.Count("DynamoDBThrottles", 1, metrics.Dim("Operation", awsmiddleware.GetOperationName(ctx)))
+		}
+		return
+	}), middleware.After)
+}
+
+// isConditionFailure reports whether a request failed only because a condition did not hold, either of a single write
+// or of a transaction whose cancellation reasons are all failed conditions
+func isConditionFailure(err error) bool {
+	if IsConditionalCheckFailed(err) {
+		return true
+	}
+
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return false
+	}
+	conditionFailed := false
+	for _, reason := range canceled.CancellationReasons {
+		if reason.Code == nil {
+			continue
+		}
+		switch *reason.Code {
+		case "None":
+		case "ConditionalCheckFailed":
+			conditionFailed = true
+		default:
+			return false
+		}
+	}
+	return conditionFailed
+}
@@ -0,0 +1,104 @@
+package dynamodb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/metrics"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// errorHTTPClient answers every request with the same DynamoDB error
+type errorHTTPClient struct {
+	errorType string
+	requests  int
+}
+
+func (c *errorHTTPClient) Do(*http.Request) (*http.Response, error) {
+	c.requests++
+	return &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(strings.NewReader(`{"__type":"com.amazonaws.dynamodb.v20120810#` + c.errorType + `","message":"no"}`)),
+	}, nil
+}
+
+func newMetricsTestClient(httpClient *errorHTTPClient) *dynamodb.Client {
+	return dynamodb.New(dynamodb.Options{
+		Region:      "us-east-1",
+		Credentials: aws.AnonymousCredentials{},
+		HTTPClient:  httpClient,
+		Retryer: retry.NewStandard(func(o *retry.StandardOptions) {
+			o.MaxAttempts = 2
+			o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+		}),
+		APIOptions: []func(*middleware.Stack) error{addMetricsMiddleware},
+	})
+}
+
+func Test_addMetricsMiddleware(t *testing.T) {
+	collector := &metrics.Collector{}
+	metrics.SetRecorder(collector)
+	defer metrics.SetRecorder(metrics.NopRecorder{})
+
+	httpClient := &errorHTTPClient{errorType: "ProvisionedThroughputExceededException"}
+	client := newMetricsTestClient(httpClient)
+
+	_, err := client.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String("test_table"),
+		Key:       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "pk"}},
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 2, httpClient.requests)
+
+	operation := metrics.Dim("Operation", "GetItem")
+	assert.Equal(t, float64(2), collector.Sum("DynamoDBThrottles", operation))
+	assert.Equal(t, float64(1), collector.Sum("DynamoDBErrors", operation))
+}
+
+func Test_addMetricsMiddleware_ConditionalCheckFailed(t *testing.T) {
+	collector := &metrics.Collector{}
+	metrics.SetRecorder(collector)
+	defer metrics.SetRecorder(metrics.NopRecorder{})
+
+	httpClient := &errorHTTPClient{errorType: "ConditionalCheckFailedException"}
+	client := newMetricsTestClient(httpClient)
+
+	_, err := client.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName: aws.String("test_table"),
+		Key:       map[string]types.AttributeValue{"PK": &types.AttributeValueMemberS{Value: "pk"}},
+	})
+
+	assert.True(t, IsConditionalCheckFailed(err))
+	assert.Equal(t, 1, httpClient.requests)
+
+	operation := metrics.Dim("Operation", "UpdateItem")
+	assert.Equal(t, float64(1), collector.Sum("DynamoDBConditionalCheckFailures", operation))
+	assert.Equal(t, float64(0), collector.Sum("DynamoDBErrors", operation))
+}
+
+func Test_isConditionFailure(t *testing.T) {
+	canceled := func(codes ...string) error {
+		exception := &types.TransactionCanceledException{}
+		for _, code := range codes {
+			exception.CancellationReasons = append(exception.CancellationReasons, types.CancellationReason{Code: aws.String(code)})
+		}
+		return exception
+	}
+
+	assert.True(t, isConditionFailure(&types.ConditionalCheckFailedException{}))
+	assert.True(t, isConditionFailure(canceled("None", "ConditionalCheckFailed")))
+	assert.False(t, isConditionFailure(canceled("None", "ThrottlingError")))
+	assert.False(t, isConditionFailure(canceled("ConditionalCheckFailed", "ValidationError")))
+	assert.False(t, isConditionFailure(&types.ResourceNotFoundException{}))
+}
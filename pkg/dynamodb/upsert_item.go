@@ -0,0 +1,86 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// UpsertItemAPI applies an arbitrary update expression to the item at the given key, creating the item if it does not
+// yet exist. Unlike UpdateItemAPI, this supports ADD and REMOVE clauses, which makes it suitable for counters and sets.
+type UpsertItemAPI interface {
+	UpsertItem(key PrimaryKey, update expression.UpdateBuilder) (*dynamodb.UpdateItemOutput, error)
+}
+
+// UpsertOptions change how UpsertItemWithOptions applies its update
+type UpsertOptions struct {
+	// Condition makes the update fail with a ConditionalCheckFailedException unless it holds; see
+	// IsConditionalCheckFailed
+	Condition *expression.ConditionBuilder
+	// ReturnUpdatedOld returns the values that the updated attributes had before the update, which are absent when
+	// the update created the item
+	ReturnUpdatedOld bool
+}
+
+// ConditionalUpsertItemAPI is UpsertItemAPI with a condition and the previous values of the item
+type ConditionalUpsertItemAPI interface {
+	UpsertItemAPI
+	UpsertItemWithOptions(key PrimaryKey, update expression.UpdateBuilder, options UpsertOptions) (*dynamodb.UpdateItemOutput, error)
+}
+
+func (dbc concreteDynamoDBClient) UpsertItem(key PrimaryKey, update expression.UpdateBuilder) (*dynamodb.UpdateItemOutput, error) {
+	return upsertItemToDynamoDB(dbc.tableName, &dbc.awsclient, key, update, UpsertOptions{}, dbc.timeout)
+}
+
+func (dbc concreteDynamoDBClient) UpsertItemWithOptions(key PrimaryKey, update expression.UpdateBuilder, options UpsertOptions) (*dynamodb.UpdateItemOutput, error) {
+	return upsertItemToDynamoDB(dbc.tableName, &dbc.awsclient, key, update, options, dbc.timeout)
+}
+
+// IsConditionalCheckFailed reports whether a write was refused because its condition did not hold
+func IsConditionalCheckFailed(err error) bool {
+	var conditionalCheckFailed *types.ConditionalCheckFailedException
+	return errors.As(err, &conditionalCheckFailed)
+}
+
+func upsertItemToDynamoDB(tableName string, api dynamodbUpdateItemAPI, upsertKey PrimaryKey, update expression.UpdateBuilder, options UpsertOptions, timeout time.Duration) (*dynamodb.UpdateItemOutput, error) {
+	ctx := context.TODO()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	key, err := attributevalue.MarshalMap(upsertKey)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := expression.NewBuilder().WithUpdate(update)
+	if options.Condition != nil {
+		builder = builder.WithCondition(*options.Condition)
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	returnValues := types.ReturnValueNone
+	if options.ReturnUpdatedOld {
+		returnValues = types.ReturnValueUpdatedOld
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		Key:                       key,
+		TableName:                 aws.String(tableName),
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ConditionExpression:       expr.Condition(),
+		ReturnValues:              returnValues,
+	}
+
+	return api.UpdateItem(ctx, input)
+}
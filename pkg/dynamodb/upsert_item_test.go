@@ -0,0 +1,83 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UpsertItem(t *testing.T) {
+	dbbPKSK := PrimaryKey{
+		PartitionKey: "AA",
+		SortKey:      "BB",
+	}
+
+	output, err := upsertItemToDynamoDB(
+		"test_table",
+		mockUpdateItemApi(func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+			expectKey, err := attributevalue.MarshalMap(dbbPKSK)
+			if err != nil {
+				return nil, err
+			}
+
+			assert.Equal(t, "test_table", *params.TableName)
+			assert.Equal(t, expectKey, params.Key)
+			assert.Nil(t, params.ConditionExpression)
+			assert.Contains(t, *params.UpdateExpression, "ADD")
+
+			return &dynamodb.UpdateItemOutput{}, nil
+		}),
+		dbbPKSK,
+		expression.Add(expression.Name("Counter"), expression.Value(1)),
+		UpsertOptions{},
+		1*time.Second,
+	)
+
+	assert.Empty(t, err)
+	assert.Empty(t, output)
+}
+
+func Test_UpsertItem_Error(t *testing.T) {
+	_, err := upsertItemToDynamoDB(
+		"test_table",
+		mockUpdateItemApi(func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+			return nil, errors.New("throttled")
+		}),
+		PrimaryKey{
+			PartitionKey: "AA",
+			SortKey:      "BB",
+		},
+		expression.Set(expression.Name("DataState"), expression.Value("Updated")),
+		UpsertOptions{},
+		1*time.Second,
+	)
+
+	assert.Error(t, err)
+}
+
+func Test_UpsertItem_WithOptions(t *testing.T) {
+	condition := expression.Name("Version").LessThan(expression.Value(2))
+
+	_, err := upsertItemToDynamoDB(
+		"test_table",
+		mockUpdateItemApi(func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+			assert.NotNil(t, params.ConditionExpression)
+			assert.Equal(t, types.ReturnValueUpdatedOld, params.ReturnValues)
+			return nil, &types.ConditionalCheckFailedException{}
+		}),
+		PrimaryKey{PartitionKey: "AA", SortKey: "BB"},
+		expression.Set(expression.Name("Version"), expression.Value(2)),
+		UpsertOptions{Condition: &condition, ReturnUpdatedOld: true},
+		1*time.Second,
+	)
+
+	assert.True(t, IsConditionalCheckFailed(err))
+	assert.False(t, IsConditionalCheckFailed(errors.New("throttled")))
+}
@@ -0,0 +1,339 @@
+// Package inventory lists the machines that have checked in to Rudolph, for fleet reviews.
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// DefaultPageSize is how many machines are read from DynamoDB per query
+const DefaultPageSize int32 = 500
+
+// SortBy is a column that the inventory can be sorted by
+type SortBy string
+
+const (
+	SortByMachineID       SortBy = "machine_id"
+	SortBySerialNum       SortBy = "serial_num"
+	SortByPrimaryUser     SortBy = "primary_user"
+	SortByModelIdentifier SortBy = "model_identifier"
+	SortByOSVersion       SortBy = "os_version"
+	SortBySantaVersion    SortBy = "santa_version"
+	SortByLastSeen        SortBy = "last_seen"
+)
+
+var sortBys = []SortBy{
+	SortByMachineID,
+	SortBySerialNum,
+	SortByPrimaryUser,
+	SortByModelIdentifier,
+	SortByOSVersion,
+	SortBySantaVersion,
+	SortByLastSeen,
+}
+
+// ParseSortBy validates the name of a sort column
+func ParseSortBy(name string) (SortBy, error) {
+	for _, sortBy := range sortBys {
+		if string(sortBy) == name {
+			return sortBy, nil
+		}
+	}
+	names := make([]string, len(sortBys))
+	for i, sortBy := range sortBys {
+		names[i] = string(sortBy)
+	}
+	return "", fmt.Errorf("unknown sort column %q; must be one of: %s", name, strings.Join(names, ", "))
+}
+
+// Machine is a single row of the inventory
+type Machine struct {
+	MachineID       string `json:"machine_id"`
+	SerialNum       string `json:"serial_num"`
+	PrimaryUser     string `json:"primary_user"`
+	ModelIdentifier string `json:"model_identifier"`
+	OSVersion       string `json:"os_version"`
+	OSBuild         string `json:"os_build"`
+	SantaVersion    string `json:"santa_version"`
+	ReportedMode    string `json:"reported_mode"`
+	IntendedMode    string `json:"intended_mode"`
+	RuleCount       int    `json:"rule_count"`
+	LastSeen        string `json:"last_seen"`
+}
+
+// Filter selects machines. Zero-valued fields match every machine.
+type Filter struct {
+	// OSVersion and SantaVersion match whole version components, so "14" matches "14.2.1" but not "140.1"
+	OSVersion    string
+	SantaVersion string
+	OSBuild      string
+	PrimaryUser  string
+	// ModelIdentifier matches by prefix, so "MacBookPro" matches every MacBook Pro
+	ModelIdentifier string
+	ReportedMode    types.ClientMode
+	IntendedMode    types.ClientMode
+	// ModeMismatch only matches machines whose sensor reports a different mode than the one intended for them
+	ModeMismatch bool
+	// SeenAfter and SeenBefore bound when the machine last completed a preflight
+	SeenAfter  time.Time
+	SeenBefore time.Time
+}
+
+// Options control what List returns
+type Options struct {
+	Filter     Filter
+	SortBy     SortBy
+	Descending bool
+	// Limit caps the number of machines returned; zero returns every match
+	Limit int
+	// After resumes a listing sorted by ascending machine ID after the given machine ID, as returned in Page.Next
+	After    string
+	PageSize int32
+}
+
+// Page is a list of machines, plus where to resume the listing if it was cut short by the limit
+type Page struct {
+	Machines []Machine `json:"machines"`
+	// Next is the machine ID to pass as Options.After to get the next page; it is empty on the last page
+	Next string `json:"next,omitempty"`
+	// Scanned is how many machines were read to fill this page
+	Scanned int `json:"scanned"`
+}
+
+// Store is what List needs from DynamoDB
+type Store interface {
+	ListSensorDataPage(afterMachineID string, pageSize int32) ([]sensordata.SensorData, string, error)
+	GetGlobalConfig() (*machineconfiguration.MachineConfiguration, error)
+	GetMachineSpecificConfig(machineID string) (*machineconfiguration.MachineConfiguration, error)
+}
+
+// GetStore returns a Store backed by the DynamoDB table
+func GetStore(client dynamodb.DynamoDBClient, timeProvider clock.TimeProvider) Store {
+	return concreteStore{
+		client:        client,
+		globalFetcher: machineconfiguration.GetUncachedGlobalConfigurationFetcher(client, timeProvider),
+		configFetcher: machineconfiguration.GetMachineConfigurationFetcher(client),
+	}
+}
+
+type concreteStore struct {
+	client        dynamodb.QueryAPI
+	globalFetcher machineconfiguration.GlobalConfigurationFetcher
+	configFetcher machineconfiguration.MachineConfigurationFetcher
+}
+
+func (s concreteStore) ListSensorDataPage(afterMachineID string, pageSize int32) ([]sensordata.SensorData, string, error) {
+	return sensordata.ListSensorDataPage(s.client, afterMachineID, pageSize)
+}
+
+func (s concreteStore) GetGlobalConfig() (*machineconfiguration.MachineConfiguration, error) {
+	return s.globalFetcher.GetGlobalConfig()
+}
+
+func (s concreteStore) GetMachineSpecificConfig(machineID string) (*machineconfiguration.MachineConfiguration, error) {
+	return s.configFetcher.GetMachineSpecificConfig(machineID)
+}
+
+// List pages through the sensor data of every machine that checked in within the last 90 days, and returns the
+// machines that match the filter.
+//
+// Listings sorted by ascending machine ID follow the order of the DataType_MachineID index, so they stop reading as
+// soon as the limit is reached and can be resumed with Options.After. Any other order reads the whole fleet first.
+// Filters are applied after reading; DynamoDB charges for every item read either way.
+func List(store Store, options Options) (page Page, err error) {
+	if options.SortBy == "" {
+		options.SortBy = SortByMachineID
+	}
+	if options.PageSize <= 0 {
+		options.PageSize = DefaultPageSize
+	}
+	indexOrder := options.SortBy == SortByMachineID && !options.Descending
+	if options.After != "" && !indexOrder {
+		err = fmt.Errorf("resuming after a machine ID is only supported when sorting by ascending %s", SortByMachineID)
+		return
+	}
+
+	resolver := intendedModeResolver{store: store}
+	after := options.After
+	page.Machines = []Machine{}
+	for {
+		var items []sensordata.SensorData
+		var lastMachineID string
+		items, lastMachineID, err = store.ListSensorDataPage(after, options.PageSize)
+		if err != nil {
+			return
+		}
+
+		for i, item := range items {
+			page.Scanned++
+			if !options.Filter.matchesSensorData(item) {
+				continue
+			}
+
+			var intendedMode types.ClientMode
+			intendedMode, err = resolver.intendedMode(item.MachineID)
+			if err != nil {
+				return
+			}
+			if !options.Filter.matchesModes(item.ClientMode, intendedMode) {
+				continue
+			}
+
+			page.Machines = append(page.Machines, newMachine(item, intendedMode))
+			if indexOrder && options.Limit > 0 && len(page.Machines) == options.Limit {
+				if i < len(items)-1 || lastMachineID != "" {
+					page.Next = item.MachineID
+				}
+				return
+			}
+		}
+
+		if lastMachineID == "" {
+			break
+		}
+		after = lastMachineID
+	}
+
+	if !indexOrder {
+		sortMachines(page.Machines, options.SortBy, options.Descending)
+	}
+	if options.Limit > 0 && len(page.Machines) > options.Limit {
+		page.Machines = page.Machines[:options.Limit]
+	}
+	return
+}
+
+func newMachine(item sensordata.SensorData, intendedMode types.ClientMode) Machine {
+	reportedModeText, _ := item.ClientMode.MarshalText()
+	intendedModeText, _ := intendedMode.MarshalText()
+	return Machine{
+		MachineID:       item.MachineID,
+		SerialNum:       item.SerialNum,
+		PrimaryUser:     item.PrimaryUser,
+		ModelIdentifier: item.ModelIdentifier,
+		OSVersion:       item.OSVersion,
+		OSBuild:         item.OSBuild,
+		SantaVersion:    item.SantaVersion,
+		ReportedMode:    string(reportedModeText),
+		IntendedMode:    string(intendedModeText),
+		RuleCount:       item.RuleCount,
+		LastSeen:        item.Time,
+	}
+}
+
+// intendedModeResolver looks up the mode intended for each machine, reading the global config at most once
+type intendedModeResolver struct {
+	store        Store
+	globalMode   types.ClientMode
+	globalLoaded bool
+}
+
+func (r *intendedModeResolver) intendedMode(machineID string) (types.ClientMode, error) {
+	config, err := r.store.GetMachineSpecificConfig(machineID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the config of machine %q: %w", machineID, err)
+	}
+	if config != nil {
+		return config.ClientMode, nil
+	}
+
+	if !r.globalLoaded {
+		globalConfig, err := r.store.GetGlobalConfig()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get the global config: %w", err)
+		}
+		if globalConfig != nil {
+			r.globalMode = globalConfig.ClientMode
+		} else {
+			r.globalMode = machineconfiguration.GetUniversalDefaultConfig().ClientMode
+		}
+		r.globalLoaded = true
+	}
+	return r.globalMode, nil
+}
+
+func (f Filter) matchesSensorData(item sensordata.SensorData) bool {
+	if f.OSVersion != "" && !versionHasPrefix(item.OSVersion, f.OSVersion) {
+		return false
+	}
+	if f.SantaVersion != "" && !versionHasPrefix(item.SantaVersion, f.SantaVersion) {
+		return false
+	}
+	if f.OSBuild != "" && !strings.EqualFold(item.OSBuild, f.OSBuild) {
+		return false
+	}
+	if f.PrimaryUser != "" && !strings.EqualFold(item.PrimaryUser, f.PrimaryUser) {
+		return false
+	}
+	if f.ModelIdentifier != "" && !strings.HasPrefix(strings.ToLower(item.ModelIdentifier), strings.ToLower(f.ModelIdentifier)) {
+		return false
+	}
+	if f.ReportedMode != 0 && item.ClientMode != f.ReportedMode {
+		return false
+	}
+
+	if !f.SeenAfter.IsZero() || !f.SeenBefore.IsZero() {
+		lastSeen, err := clock.ParseRFC3339(item.Time)
+		if err != nil {
+			return false
+		}
+		if !f.SeenAfter.IsZero() && lastSeen.Before(f.SeenAfter) {
+			return false
+		}
+		if !f.SeenBefore.IsZero() && !lastSeen.Before(f.SeenBefore) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f Filter) matchesModes(reportedMode types.ClientMode, intendedMode types.ClientMode) bool {
+	if f.IntendedMode != 0 && intendedMode != f.IntendedMode {
+		return false
+	}
+	if f.ModeMismatch && reportedMode == intendedMode {
+		return false
+	}
+	return true
+}
+
+func versionHasPrefix(version string, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, ".")
+	return version == prefix || strings.HasPrefix(version, prefix+".")
+}
+
+func sortMachines(machines []Machine, sortBy SortBy, descending bool) {
+	sort.SliceStable(machines, func(i, j int) bool {
+		a, b := machines[i], machines[j]
+		var c int
+		switch sortBy {
+		case SortBySerialNum:
+			c = strings.Compare(a.SerialNum, b.SerialNum)
+		case SortByPrimaryUser:
+			c = strings.Compare(a.PrimaryUser, b.PrimaryUser)
+		case SortByModelIdentifier:
+			c = strings.Compare(a.ModelIdentifier, b.ModelIdentifier)
+		case SortByOSVersion:
+			c = types.CompareVersions(a.OSVersion, b.OSVersion)
+		case SortBySantaVersion:
+			c = types.CompareVersions(a.SantaVersion, b.SantaVersion)
+		case SortByLastSeen:
+			c = strings.Compare(a.LastSeen, b.LastSeen)
+		}
+		if c == 0 {
+			c = strings.Compare(a.MachineID, b.MachineID)
+		}
+		if descending {
+			return c > 0
+		}
+		return c < 0
+	})
+}
@@ -0,0 +1,152 @@
+package inventory
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockStore serves the fleet in pages of pageSize, and the machine configs by machine ID
+type mockStore struct {
+	fleet          []sensordata.SensorData
+	pageSize       int
+	machineConfigs map[string]types.ClientMode
+	globalMode     types.ClientMode
+
+	pagesRead   int
+	globalReads int
+}
+
+func (m *mockStore) ListSensorDataPage(afterMachineID string, pageSize int32) ([]sensordata.SensorData, string, error) {
+	m.pagesRead++
+	start := 0
+	for i, item := range m.fleet {
+		if item.MachineID == afterMachineID {
+			start = i + 1
+		}
+	}
+	end := min(start+m.pageSize, len(m.fleet))
+	page := m.fleet[start:end]
+	if end == len(m.fleet) {
+		return page, "", nil
+	}
+	return page, page[len(page)-1].MachineID, nil
+}
+
+func (m *mockStore) GetGlobalConfig() (*machineconfiguration.MachineConfiguration, error) {
+	m.globalReads++
+	if m.globalMode == 0 {
+		return nil, nil
+	}
+	return &machineconfiguration.MachineConfiguration{ClientMode: m.globalMode}, nil
+}
+
+func (m *mockStore) GetMachineSpecificConfig(machineID string) (*machineconfiguration.MachineConfiguration, error) {
+	mode, ok := m.machineConfigs[machineID]
+	if !ok {
+		return nil, nil
+	}
+	return &machineconfiguration.MachineConfiguration{ClientMode: mode}, nil
+}
+
+func machineID(i int) string {
+	return fmt.Sprintf("AAAAAAAA-A00A-1234-1234-%012d", i)
+}
+
+func fleet() []sensordata.SensorData {
+	return []sensordata.SensorData{
+		{MachineID: machineID(1), PrimaryUser: "alice", ModelIdentifier: "MacBookPro18,3", OSVersion: "14.2.1", SantaVersion: "2023.10", ClientMode: types.Monitor, Time: "2022-01-09T00:00:00Z"},
+		{MachineID: machineID(2), PrimaryUser: "bob", ModelIdentifier: "MacBookAir10,1", OSVersion: "13.6", SantaVersion: "2023.9", ClientMode: types.Lockdown, Time: "2022-01-01T00:00:00Z"},
+		{MachineID: machineID(3), PrimaryUser: "carol", ModelIdentifier: "MacBookPro18,1", OSVersion: "14.1", SantaVersion: "2023.10", ClientMode: types.Monitor, Time: "2022-01-08T00:00:00Z"},
+		{MachineID: machineID(4), PrimaryUser: "dave", ModelIdentifier: "Mac14,3", OSVersion: "140.1", SantaVersion: "2022.1", ClientMode: types.Lockdown, Time: "2022-01-07T00:00:00Z"},
+	}
+}
+
+func Test_List_Filters(t *testing.T) {
+	cases := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{"os version matches whole components", Filter{OSVersion: "14"}, []string{machineID(1), machineID(3)}},
+		{"santa version", Filter{SantaVersion: "2023.10"}, []string{machineID(1), machineID(3)}},
+		{"model identifier prefix", Filter{ModelIdentifier: "macbookpro"}, []string{machineID(1), machineID(3)}},
+		{"primary user", Filter{PrimaryUser: "Bob"}, []string{machineID(2)}},
+		{"reported mode", Filter{ReportedMode: types.Lockdown}, []string{machineID(2), machineID(4)}},
+		{"intended mode", Filter{IntendedMode: types.Lockdown}, []string{machineID(3), machineID(4)}},
+		{"mode mismatch", Filter{ModeMismatch: true}, []string{machineID(2), machineID(3)}},
+		{"last seen window", Filter{SeenAfter: time.Date(2022, 1, 7, 0, 0, 0, 0, time.UTC), SeenBefore: time.Date(2022, 1, 9, 0, 0, 0, 0, time.UTC)}, []string{machineID(3), machineID(4)}},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			store := &mockStore{
+				fleet:          fleet(),
+				pageSize:       2,
+				machineConfigs: map[string]types.ClientMode{machineID(3): types.Lockdown, machineID(4): types.Lockdown},
+				globalMode:     types.Monitor,
+			}
+
+			page, err := List(store, Options{Filter: test.filter})
+
+			assert.Empty(t, err)
+			var machineIDs []string
+			for _, machine := range page.Machines {
+				machineIDs = append(machineIDs, machine.MachineID)
+			}
+			assert.Equal(t, test.expected, machineIDs)
+			assert.Equal(t, 4, page.Scanned)
+			assert.Empty(t, page.Next)
+			assert.LessOrEqual(t, store.globalReads, 1)
+		})
+	}
+}
+
+func Test_List_IntendedModeFallsBackToDefault(t *testing.T) {
+	store := &mockStore{fleet: fleet()[:1], pageSize: 10}
+
+	page, err := List(store, Options{})
+
+	assert.Empty(t, err)
+	assert.Equal(t, "MONITOR", page.Machines[0].IntendedMode)
+	assert.Equal(t, "MONITOR", page.Machines[0].ReportedMode)
+}
+
+func Test_List_LimitStopsReadingAndResumes(t *testing.T) {
+	store := &mockStore{fleet: fleet(), pageSize: 1, globalMode: types.Monitor}
+
+	page, err := List(store, Options{Limit: 2})
+
+	assert.Empty(t, err)
+	assert.Len(t, page.Machines, 2)
+	assert.Equal(t, machineID(2), page.Next)
+	assert.Equal(t, 2, store.pagesRead)
+
+	page, err = List(store, Options{Limit: 2, After: page.Next})
+
+	assert.Empty(t, err)
+	assert.Equal(t, machineID(3), page.Machines[0].MachineID)
+	assert.Equal(t, machineID(4), page.Machines[1].MachineID)
+	assert.Empty(t, page.Next)
+}
+
+func Test_List_SortsByVersion(t *testing.T) {
+	store := &mockStore{fleet: fleet(), pageSize: 3, globalMode: types.Monitor}
+
+	page, err := List(store, Options{SortBy: SortByOSVersion, Descending: true, Limit: 3})
+
+	assert.Empty(t, err)
+	assert.Equal(t, []string{"140.1", "14.2.1", "14.1"}, []string{page.Machines[0].OSVersion, page.Machines[1].OSVersion, page.Machines[2].OSVersion})
+	assert.Empty(t, page.Next)
+}
+
+func Test_List_AfterRequiresIndexOrder(t *testing.T) {
+	_, err := List(&mockStore{}, Options{SortBy: SortByLastSeen, After: machineID(1)})
+
+	assert.Error(t, err)
+}
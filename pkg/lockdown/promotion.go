@@ -0,0 +1,363 @@
+package lockdown
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/model/modetransitions"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// MaxEventsPerMachine bounds how much of a machine's event history a readiness report replays. Promotion does not
+// use it: a machine is only promoted once every event of its monitoring window has been replayed.
+const MaxEventsPerMachine = 5000
+
+// lockdownBlockDecision is the decision Santa reports for binaries that are blocked only because the machine is in
+// LOCKDOWN. Explicitly blocklisted binaries are blocked in MONITOR too, so they do not indicate a bad promotion.
+const lockdownBlockDecision = "BLOCK_UNKNOWN"
+
+// PromotionPolicy holds the guardrails that govern automatic MONITOR -> LOCKDOWN promotion
+type PromotionPolicy struct {
+	// MonitorDays is how many days of uploaded events a machine needs, without a single would-be-blocked execution,
+	// before it is promoted. Machines whose mode changed within this many days are left alone.
+	MonitorDays int
+	// DailyPromotionCap is the maximum number of machines that are promoted per UTC day
+	DailyPromotionCap int
+	// CandidateGroups restricts promotion to the members of these groups; when empty, every machine is a candidate
+	CandidateGroups []string
+	// ExcludedGroups are groups whose members are never promoted
+	ExcludedGroups []string
+	// DemotionWindow is how long after an automatic promotion the machine is watched for blocks
+	DemotionWindow time.Duration
+	// DemotionBlockThreshold is the number of LOCKDOWN blocks within the DemotionWindow that sends the machine
+	// back to MONITOR
+	DemotionBlockThreshold int
+	// DryRun reports what would happen without writing any configuration or transitions
+	DryRun bool
+}
+
+// DefaultPromotionPolicy returns a conservative policy: two clean weeks, a small daily cap and a one day watch
+func DefaultPromotionPolicy() PromotionPolicy {
+	return PromotionPolicy{
+		MonitorDays:            14,
+		DailyPromotionCap:      25,
+		DemotionWindow:         24 * time.Hour,
+		DemotionBlockThreshold: 5,
+	}
+}
+
+func (p PromotionPolicy) Validate() error {
+	if p.MonitorDays <= 0 {
+		return errors.New("monitor days must be positive")
+	}
+	if p.DailyPromotionCap < 0 {
+		return errors.New("daily promotion cap must not be negative")
+	}
+	if p.DemotionWindow < 0 {
+		return errors.New("demotion window must not be negative")
+	}
+	if p.DemotionBlockThreshold <= 0 {
+		return errors.New("demotion block threshold must be positive")
+	}
+	return nil
+}
+
+// PromotionStore is everything the promoter reads and writes; see GetPromotionStore for the DynamoDB implementation
+type PromotionStore interface {
+	ListMachineIDs() ([]string, error)
+	GetGroupMachineIDs(groupName string) ([]string, error)
+	GetTransitionsSince(since time.Time) ([]modetransitions.ModeTransition, error)
+	GetClientMode(machineID string) (types.ClientMode, error)
+	// GetEvents returns every event the machine executed at or after since, newest first
+	GetEvents(machineID string, since time.Time) ([]eventlog.EventRow, error)
+	GetRuleset(machineID string) (ruleset.Ruleset, error)
+	SetClientMode(machineID string, clientMode types.ClientMode) error
+	RecordTransition(machineID string, fromMode types.ClientMode, toMode types.ClientMode, actor string, reason string) error
+}
+
+// PromotionOutcome explains what happened to a single machine during a promotion run
+type PromotionOutcome struct {
+	MachineID string `json:"machine_id"`
+	Reason    string `json:"reason"`
+}
+
+// PromotionResult is the summary of a single promotion run
+type PromotionResult struct {
+	DryRun   bool               `json:"dry_run"`
+	Demoted  []PromotionOutcome `json:"demoted"`
+	Promoted []PromotionOutcome `json:"promoted"`
+	Skipped  []PromotionOutcome `json:"skipped"`
+	// CapReached is set when the daily promotion cap stopped the run before every candidate was assessed
+	CapReached bool `json:"cap_reached"`
+}
+
+// RunPromotion demotes recently promoted machines that are being blocked, then promotes MONITOR machines that have
+// gone the policy's number of days without a would-be-blocked execution, up to the daily cap.
+//
+// Demotion runs first so that a bad promotion is rolled back even on days where the cap has been reached. Every
+// change of mode is recorded as a transition. Machines are processed in order of machineID, so runs are repeatable.
+func RunPromotion(store PromotionStore, timeProvider clock.TimeProvider, policy PromotionPolicy) (result PromotionResult, err error) {
+	if err = policy.Validate(); err != nil {
+		return
+	}
+	result.DryRun = policy.DryRun
+
+	now := timeProvider.Now().UTC()
+	lookback := time.Duration(policy.MonitorDays) * 24 * time.Hour
+	if policy.DemotionWindow > lookback {
+		lookback = policy.DemotionWindow
+	}
+
+	transitions, err := store.GetTransitionsSince(now.Add(-lookback))
+	if err != nil {
+		return
+	}
+	latest := latestTransitions(transitions)
+
+	if err = runDemotions(store, now, policy, latest, &result); err != nil {
+		return
+	}
+	err = runPromotions(store, now, policy, transitions, latest, &result)
+	return
+}
+
+func runDemotions(
+	store PromotionStore,
+	now time.Time,
+	policy PromotionPolicy,
+	latest map[string]modetransitions.ModeTransition,
+	result *PromotionResult,
+) error {
+	for _, machineID := range sortedMachineIDs(latest) {
+		transition := latest[machineID]
+		if transition.Actor != modetransitions.ActorAutoPromotion || !transition.IsPromotion() {
+			continue
+		}
+		promotedAt, err := clock.ParseRFC3339(transition.TransitionedAt)
+		if err != nil {
+			return fmt.Errorf("invalid transition time for machine %q: %w", machineID, err)
+		}
+		if now.Sub(promotedAt) > policy.DemotionWindow {
+			continue
+		}
+
+		events, err := store.GetEvents(machineID, promotedAt)
+		if err != nil {
+			return err
+		}
+		blocks := 0
+		for _, row := range events {
+			if row.Decision == lockdownBlockDecision {
+				blocks++
+			}
+		}
+		if blocks < policy.DemotionBlockThreshold {
+			continue
+		}
+
+		// Someone may have already changed the mode by hand; only undo our own change
+		clientMode, err := store.GetClientMode(machineID)
+		if err != nil {
+			return err
+		}
+		if clientMode != types.Lockdown {
+			continue
+		}
+
+		reason := fmt.Sprintf("%d executions blocked within %s of promotion", blocks, now.Sub(promotedAt).Round(time.Minute))
+		if !policy.DryRun {
+			if err = store.SetClientMode(machineID, types.Monitor); err != nil {
+				return err
+			}
+			if err = store.RecordTransition(machineID, types.Lockdown, types.Monitor, modetransitions.ActorAutoDemotion, reason); err != nil {
+				return err
+			}
+		}
+		result.Demoted = append(result.Demoted, PromotionOutcome{MachineID: machineID, Reason: reason})
+
+		// A demotion counts as a fresh change of mode, so the machine must go another MonitorDays before it is retried
+		latest[machineID] = modetransitions.ModeTransition{
+			MachineID:      machineID,
+			FromMode:       types.Lockdown,
+			ToMode:         types.Monitor,
+			Actor:          modetransitions.ActorAutoDemotion,
+			TransitionedAt: clock.RFC3339(now),
+		}
+	}
+	return nil
+}
+
+func runPromotions(
+	store PromotionStore,
+	now time.Time,
+	policy PromotionPolicy,
+	transitions []modetransitions.ModeTransition,
+	latest map[string]modetransitions.ModeTransition,
+	result *PromotionResult,
+) error {
+	today := now.Format("2006-01-02")
+	remaining := policy.DailyPromotionCap
+	for _, transition := range transitions {
+		if transition.Actor == modetransitions.ActorAutoPromotion && transition.IsPromotion() && strings.HasPrefix(transition.TransitionedAt, today) {
+			remaining--
+		}
+	}
+
+	candidates, err := candidateMachineIDs(store, policy.CandidateGroups)
+	if err != nil {
+		return err
+	}
+	excluded, err := excludedMachineIDs(store, policy.ExcludedGroups)
+	if err != nil {
+		return err
+	}
+
+	since := now.Add(-time.Duration(policy.MonitorDays) * 24 * time.Hour)
+	for _, machineID := range candidates {
+		if groupName, ok := excluded[machineID]; ok {
+			result.Skipped = append(result.Skipped, PromotionOutcome{MachineID: machineID, Reason: fmt.Sprintf("excluded by group %q", groupName)})
+			continue
+		}
+		if transition, ok := latest[machineID]; ok && transition.TransitionedAt >= clock.RFC3339(since) {
+			result.Skipped = append(result.Skipped, PromotionOutcome{MachineID: machineID, Reason: fmt.Sprintf("mode last changed at %s by %s", transition.TransitionedAt, transition.Actor)})
+			continue
+		}
+
+		clientMode, err := store.GetClientMode(machineID)
+		if err != nil {
+			return err
+		}
+		if clientMode != types.Monitor {
+			continue
+		}
+
+		if remaining <= 0 {
+			result.CapReached = true
+			return nil
+		}
+
+		reason, ready, err := assessPromotion(store, machineID, since, policy.MonitorDays)
+		if err != nil {
+			return err
+		}
+		if !ready {
+			result.Skipped = append(result.Skipped, PromotionOutcome{MachineID: machineID, Reason: reason})
+			continue
+		}
+
+		if !policy.DryRun {
+			if err = store.SetClientMode(machineID, types.Lockdown); err != nil {
+				return err
+			}
+			if err = store.RecordTransition(machineID, types.Monitor, types.Lockdown, modetransitions.ActorAutoPromotion, reason); err != nil {
+				return err
+			}
+		}
+		result.Promoted = append(result.Promoted, PromotionOutcome{MachineID: machineID, Reason: reason})
+		remaining--
+	}
+	return nil
+}
+
+// assessPromotion decides whether a MONITOR machine is ready, explaining the decision either way
+func assessPromotion(store PromotionStore, machineID string, since time.Time, monitorDays int) (reason string, ready bool, err error) {
+	events, err := store.GetEvents(machineID, since)
+	if err != nil {
+		return
+	}
+	if len(events) == 0 {
+		reason = fmt.Sprintf("no uploaded events in the last %d days", monitorDays)
+		return
+	}
+
+	// Events are newest first. Requiring one from the first day of the window makes sure the machine was
+	// actually observed for the whole period, rather than e.g. being enrolled yesterday.
+	oldest := events[len(events)-1].ExecutedAt
+	if oldest > clock.RFC3339(since.Add(24*time.Hour)) {
+		reason = fmt.Sprintf("uploaded events only go back to %s", oldest)
+		return
+	}
+
+	rs, err := store.GetRuleset(machineID)
+	if err != nil {
+		return
+	}
+	readiness := AssessMachine(machineID, rs, events)
+	if !readiness.Ready() {
+		reason = fmt.Sprintf("%d of %d binaries would be blocked", len(readiness.WouldBlock), readiness.DistinctBinaries)
+		return
+	}
+
+	reason = fmt.Sprintf("no would-be-blocked executions across %d binaries in %d days", readiness.DistinctBinaries, monitorDays)
+	ready = true
+	return
+}
+
+func candidateMachineIDs(store PromotionStore, groupNames []string) ([]string, error) {
+	if len(groupNames) == 0 {
+		machineIDs, err := store.ListMachineIDs()
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(machineIDs)
+		return machineIDs, nil
+	}
+
+	members, err := groupMembers(store, groupNames)
+	if err != nil {
+		return nil, err
+	}
+	machineIDs := make([]string, 0, len(members))
+	for machineID := range members {
+		machineIDs = append(machineIDs, machineID)
+	}
+	sort.Strings(machineIDs)
+	return machineIDs, nil
+}
+
+func excludedMachineIDs(store PromotionStore, groupNames []string) (map[string]string, error) {
+	return groupMembers(store, groupNames)
+}
+
+// groupMembers maps every member of the given groups to the first group it was found in
+func groupMembers(store PromotionStore, groupNames []string) (map[string]string, error) {
+	members := make(map[string]string)
+	for _, groupName := range groupNames {
+		machineIDs, err := store.GetGroupMachineIDs(groupName)
+		if err != nil {
+			return nil, err
+		}
+		for _, machineID := range machineIDs {
+			if _, ok := members[machineID]; !ok {
+				members[machineID] = groupName
+			}
+		}
+	}
+	return members, nil
+}
+
+// latestTransitions returns the most recent transition of each machine
+func latestTransitions(transitions []modetransitions.ModeTransition) map[string]modetransitions.ModeTransition {
+	latest := make(map[string]modetransitions.ModeTransition)
+	for _, transition := range transitions {
+		if current, ok := latest[transition.MachineID]; !ok || transition.TransitionedAt >= current.TransitionedAt {
+			latest[transition.MachineID] = transition
+		}
+	}
+	return latest
+}
+
+func sortedMachineIDs(transitions map[string]modetransitions.ModeTransition) []string {
+	machineIDs := make([]string, 0, len(transitions))
+	for machineID := range transitions {
+		machineIDs = append(machineIDs, machineID)
+	}
+	sort.Strings(machineIDs)
+	return machineIDs
+}
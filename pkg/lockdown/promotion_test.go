@@ -0,0 +1,273 @@
+package lockdown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/model/modetransitions"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	readyMachineID    = "AAAAAAAA-A00A-1234-1234-000000000001"
+	blockedMachineID  = "AAAAAAAA-A00A-1234-1234-000000000002"
+	newMachineID      = "AAAAAAAA-A00A-1234-1234-000000000003"
+	excludedMachineID = "AAAAAAAA-A00A-1234-1234-000000000004"
+)
+
+type transitionRecord struct {
+	machineID string
+	toMode    types.ClientMode
+	actor     string
+}
+
+// mockPromotionStore keeps machine state in memory and records every write
+type mockPromotionStore struct {
+	machineIDs   []string
+	groups       map[string][]string
+	transitions  []modetransitions.ModeTransition
+	clientModes  map[string]types.ClientMode
+	events       map[string][]eventlog.EventRow
+	ruleset      ruleset.Ruleset
+	setModes     map[string]types.ClientMode
+	recorded     []transitionRecord
+	eventsSinces map[string]time.Time
+}
+
+func (m *mockPromotionStore) ListMachineIDs() ([]string, error) {
+	return m.machineIDs, nil
+}
+
+func (m *mockPromotionStore) GetGroupMachineIDs(groupName string) ([]string, error) {
+	return m.groups[groupName], nil
+}
+
+func (m *mockPromotionStore) GetTransitionsSince(since time.Time) ([]modetransitions.ModeTransition, error) {
+	return m.transitions, nil
+}
+
+func (m *mockPromotionStore) GetClientMode(machineID string) (types.ClientMode, error) {
+	if clientMode, ok := m.clientModes[machineID]; ok {
+		return clientMode, nil
+	}
+	return types.Monitor, nil
+}
+
+func (m *mockPromotionStore) GetEvents(machineID string, since time.Time) ([]eventlog.EventRow, error) {
+	if m.eventsSinces == nil {
+		m.eventsSinces = make(map[string]time.Time)
+	}
+	m.eventsSinces[machineID] = since
+	return m.events[machineID], nil
+}
+
+func (m *mockPromotionStore) GetRuleset(machineID string) (ruleset.Ruleset, error) {
+	return m.ruleset, nil
+}
+
+func (m *mockPromotionStore) SetClientMode(machineID string, clientMode types.ClientMode) error {
+	if m.setModes == nil {
+		m.setModes = make(map[string]types.ClientMode)
+	}
+	m.setModes[machineID] = clientMode
+	return nil
+}
+
+func (m *mockPromotionStore) RecordTransition(machineID string, fromMode types.ClientMode, toMode types.ClientMode, actor string, reason string) error {
+	m.recorded = append(m.recorded, transitionRecord{machineID: machineID, toMode: toMode, actor: actor})
+	return nil
+}
+
+// now is 2000-01-20T12:00:00Z
+var promotionTime = clock.FrozenTimeProvider{Current: clock.Y2KTime().Add(19*24*time.Hour + 12*time.Hour)}
+
+func decisionEvent(decision string, sha string, teamID string, executedAt string) eventlog.EventRow {
+	row := event(sha, teamID, "john_doe", executedAt)
+	row.Decision = decision
+	return row
+}
+
+func newPromotionStore() *mockPromotionStore {
+	return &mockPromotionStore{
+		machineIDs: []string{newMachineID, blockedMachineID, readyMachineID, excludedMachineID},
+		groups: map[string][]string{
+			"executives": {excludedMachineID},
+		},
+		ruleset: ruleset.New(
+			[]rules.SantaRule{{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV"}},
+			nil,
+		),
+		events: map[string][]eventlog.EventRow{
+			readyMachineID: {
+				decisionEvent("ALLOW_TEAMID", "aaaa", "EQHXZ8M8AV", "2000-01-19T00:00:00Z"),
+				decisionEvent("ALLOW_TEAMID", "aaaa", "EQHXZ8M8AV", "2000-01-06T13:00:00Z"),
+			},
+			blockedMachineID: {
+				decisionEvent("ALLOW_UNKNOWN", "bbbb", "FNN8Z5JMFP", "2000-01-19T00:00:00Z"),
+				decisionEvent("ALLOW_TEAMID", "aaaa", "EQHXZ8M8AV", "2000-01-06T13:00:00Z"),
+			},
+			newMachineID: {
+				decisionEvent("ALLOW_TEAMID", "aaaa", "EQHXZ8M8AV", "2000-01-19T00:00:00Z"),
+			},
+			excludedMachineID: {
+				decisionEvent("ALLOW_TEAMID", "aaaa", "EQHXZ8M8AV", "2000-01-19T00:00:00Z"),
+				decisionEvent("ALLOW_TEAMID", "aaaa", "EQHXZ8M8AV", "2000-01-06T13:00:00Z"),
+			},
+		},
+	}
+}
+
+func testPolicy() PromotionPolicy {
+	policy := DefaultPromotionPolicy()
+	policy.ExcludedGroups = []string{"executives"}
+	return policy
+}
+
+func Test_RunPromotion_PromotesReadyMachines(t *testing.T) {
+	store := newPromotionStore()
+
+	result, err := RunPromotion(store, promotionTime, testPolicy())
+
+	assert.Empty(t, err)
+	assert.Len(t, result.Promoted, 1)
+	assert.Equal(t, readyMachineID, result.Promoted[0].MachineID)
+	assert.Equal(t, map[string]types.ClientMode{readyMachineID: types.Lockdown}, store.setModes)
+	assert.Equal(t, []transitionRecord{{readyMachineID, types.Lockdown, modetransitions.ActorAutoPromotion}}, store.recorded)
+
+	skipped := make(map[string]string)
+	for _, outcome := range result.Skipped {
+		skipped[outcome.MachineID] = outcome.Reason
+	}
+	assert.Equal(t, "1 of 2 binaries would be blocked", skipped[blockedMachineID])
+	assert.Equal(t, "uploaded events only go back to 2000-01-19T00:00:00Z", skipped[newMachineID])
+	assert.Equal(t, `excluded by group "executives"`, skipped[excludedMachineID])
+	assert.False(t, result.CapReached)
+}
+
+func Test_RunPromotion_DryRun(t *testing.T) {
+	store := newPromotionStore()
+	policy := testPolicy()
+	policy.DryRun = true
+
+	result, err := RunPromotion(store, promotionTime, policy)
+
+	assert.Empty(t, err)
+	assert.True(t, result.DryRun)
+	assert.Len(t, result.Promoted, 1)
+	assert.Empty(t, store.setModes)
+	assert.Empty(t, store.recorded)
+}
+
+func Test_RunPromotion_DailyCap(t *testing.T) {
+	store := newPromotionStore()
+	store.transitions = []modetransitions.ModeTransition{
+		{MachineID: "AAAAAAAA-A00A-1234-1234-000000000009", FromMode: types.Monitor, ToMode: types.Lockdown, Actor: modetransitions.ActorAutoPromotion, TransitionedAt: "2000-01-20T01:00:00Z"},
+	}
+	store.clientModes = map[string]types.ClientMode{"AAAAAAAA-A00A-1234-1234-000000000009": types.Lockdown}
+	policy := testPolicy()
+	policy.DailyPromotionCap = 1
+
+	result, err := RunPromotion(store, promotionTime, policy)
+
+	assert.Empty(t, err)
+	assert.Empty(t, result.Promoted)
+	assert.True(t, result.CapReached)
+	assert.Empty(t, store.setModes)
+}
+
+func Test_RunPromotion_SkipsRecentlyChangedAndLockdownMachines(t *testing.T) {
+	store := newPromotionStore()
+	store.transitions = []modetransitions.ModeTransition{
+		{MachineID: readyMachineID, FromMode: types.Lockdown, ToMode: types.Monitor, Actor: modetransitions.ActorCLI, TransitionedAt: "2000-01-10T00:00:00Z"},
+	}
+	store.clientModes = map[string]types.ClientMode{blockedMachineID: types.Lockdown}
+
+	result, err := RunPromotion(store, promotionTime, testPolicy())
+
+	assert.Empty(t, err)
+	assert.Empty(t, result.Promoted)
+	for _, outcome := range result.Skipped {
+		assert.NotEqual(t, blockedMachineID, outcome.MachineID)
+		if outcome.MachineID == readyMachineID {
+			assert.Equal(t, "mode last changed at 2000-01-10T00:00:00Z by cli", outcome.Reason)
+		}
+	}
+}
+
+func Test_RunPromotion_CandidateGroups(t *testing.T) {
+	store := newPromotionStore()
+	store.groups["wave-1"] = []string{blockedMachineID}
+	policy := testPolicy()
+	policy.CandidateGroups = []string{"wave-1"}
+
+	result, err := RunPromotion(store, promotionTime, policy)
+
+	assert.Empty(t, err)
+	assert.Empty(t, result.Promoted)
+	assert.Len(t, result.Skipped, 1)
+	assert.Equal(t, blockedMachineID, result.Skipped[0].MachineID)
+}
+
+func Test_RunPromotion_DemotesOnBlockSpike(t *testing.T) {
+	store := newPromotionStore()
+	store.machineIDs = []string{readyMachineID}
+	store.transitions = []modetransitions.ModeTransition{
+		{MachineID: readyMachineID, FromMode: types.Monitor, ToMode: types.Lockdown, Actor: modetransitions.ActorAutoPromotion, TransitionedAt: "2000-01-20T10:00:00Z"},
+	}
+	store.clientModes = map[string]types.ClientMode{readyMachineID: types.Lockdown}
+	store.events[readyMachineID] = []eventlog.EventRow{
+		decisionEvent("BLOCK_UNKNOWN", "bbbb", "", "2000-01-20T11:05:00Z"),
+		decisionEvent("BLOCK_UNKNOWN", "bbbb", "", "2000-01-20T11:04:00Z"),
+		decisionEvent("BLOCK_BINARY", "cccc", "", "2000-01-20T11:03:00Z"),
+		decisionEvent("BLOCK_UNKNOWN", "bbbb", "", "2000-01-20T11:02:00Z"),
+		decisionEvent("BLOCK_UNKNOWN", "bbbb", "", "2000-01-20T11:01:00Z"),
+		decisionEvent("BLOCK_UNKNOWN", "bbbb", "", "2000-01-20T11:00:00Z"),
+	}
+
+	result, err := RunPromotion(store, promotionTime, testPolicy())
+
+	assert.Empty(t, err)
+	assert.Len(t, result.Demoted, 1)
+	assert.Equal(t, "5 executions blocked within 2h0m0s of promotion", result.Demoted[0].Reason)
+	assert.Equal(t, "2000-01-20T10:00:00Z", clock.RFC3339(store.eventsSinces[readyMachineID]))
+	assert.Equal(t, map[string]types.ClientMode{readyMachineID: types.Monitor}, store.setModes)
+	assert.Equal(t, []transitionRecord{{readyMachineID, types.Monitor, modetransitions.ActorAutoDemotion}}, store.recorded)
+	assert.Empty(t, result.Promoted)
+}
+
+func Test_RunPromotion_NoDemotionOutsideWindow(t *testing.T) {
+	store := newPromotionStore()
+	store.machineIDs = nil
+	store.transitions = []modetransitions.ModeTransition{
+		{MachineID: readyMachineID, FromMode: types.Monitor, ToMode: types.Lockdown, Actor: modetransitions.ActorAutoPromotion, TransitionedAt: "2000-01-18T10:00:00Z"},
+	}
+	store.clientModes = map[string]types.ClientMode{readyMachineID: types.Lockdown}
+	store.events[readyMachineID] = []eventlog.EventRow{
+		decisionEvent("BLOCK_UNKNOWN", "bbbb", "", "2000-01-20T11:00:00Z"),
+	}
+	policy := testPolicy()
+	policy.DemotionBlockThreshold = 1
+
+	result, err := RunPromotion(store, promotionTime, policy)
+
+	assert.Empty(t, err)
+	assert.Empty(t, result.Demoted)
+	assert.Empty(t, store.setModes)
+}
+
+func Test_PromotionPolicy_Validate(t *testing.T) {
+	assert.Empty(t, DefaultPromotionPolicy().Validate())
+
+	policy := DefaultPromotionPolicy()
+	policy.MonitorDays = 0
+	assert.Error(t, policy.Validate())
+
+	policy = DefaultPromotionPolicy()
+	policy.DemotionBlockThreshold = 0
+	assert.Error(t, policy.Validate())
+}
@@ -0,0 +1,182 @@
+// Package lockdown contains the logic that helps move machines from MONITOR to LOCKDOWN mode safely.
+package lockdown
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// BlockedBinary is a binary that executed on a machine and that would have been blocked had the machine been in
+// LOCKDOWN, given the machine's current effective ruleset
+type BlockedBinary struct {
+	FileSHA256        string   `json:"file_sha256"`
+	FileName          string   `json:"file_name"`
+	FilePath          string   `json:"file_path"`
+	SigningID         string   `json:"signing_id,omitempty"`
+	TeamID            string   `json:"team_id,omitempty"`
+	CertificateSHA256 string   `json:"certificate_sha256,omitempty"`
+	CertificateCN     string   `json:"certificate_cn,omitempty"`
+	Executions        int      `json:"executions"`
+	Users             []string `json:"users"`
+	LastSeen          string   `json:"last_seen"`
+}
+
+// MachineReadiness summarizes how disruptive it would be to move a single machine to LOCKDOWN
+type MachineReadiness struct {
+	MachineID string `json:"machine_id"`
+	// DistinctBinaries is the number of distinct binaries in the machine's uploaded events
+	DistinctBinaries int `json:"distinct_binaries"`
+	// WouldBlock lists the binaries that the machine's current ruleset does not allow, most executed first
+	WouldBlock []BlockedBinary `json:"would_block"`
+	// Score is the percentage of distinct binaries that would still run in LOCKDOWN; 100 means nothing would break.
+	// Machines without any uploaded events score 100, but HasEvents is false so they can be told apart.
+	Score     float64 `json:"score"`
+	HasEvents bool    `json:"has_events"`
+}
+
+// Ready reports whether the machine can be moved to LOCKDOWN without blocking anything it has recently executed
+func (r MachineReadiness) Ready() bool {
+	return r.HasEvents && len(r.WouldBlock) == 0
+}
+
+// SubjectFromEvent extracts the rule-matchable identifiers of an uploaded event
+func SubjectFromEvent(event eventlog.Event) ruleset.Subject {
+	subject := ruleset.Subject{
+		CDHash:     event.CDHash,
+		FileSHA256: event.FileSHA256,
+		SigningID:  event.SigningID,
+		TeamID:     event.TeamID,
+	}
+	if len(event.SigningChain) > 0 {
+		subject.CertificateSHA256 = event.SigningChain[0].SHA256
+	}
+	return subject
+}
+
+// AssessMachine replays a machine's uploaded events against its effective ruleset in LOCKDOWN mode.
+//
+// Only executions that LOCKDOWN itself would newly block are reported; binaries that are explicitly blocklisted are
+// already blocked in MONITOR mode and do not make a machine any less ready.
+func AssessMachine(machineID string, rs ruleset.Ruleset, events []eventlog.EventRow) MachineReadiness {
+	readiness := MachineReadiness{
+		MachineID: machineID,
+		HasEvents: len(events) > 0,
+	}
+
+	seen := make(map[string]bool)
+	blocked := make(map[string]*BlockedBinary)
+	users := make(map[string]map[string]bool)
+
+	for _, row := range events {
+		event := row.Event
+		if event.FileSHA256 == "" {
+			continue
+		}
+		seen[event.FileSHA256] = true
+
+		// Any matching rule, allow or block, makes the decision independent of the client mode
+		subject := SubjectFromEvent(event)
+		if _, ok := rs.Match(subject); ok {
+			continue
+		}
+
+		binary, ok := blocked[event.FileSHA256]
+		if !ok {
+			binary = &BlockedBinary{
+				FileSHA256:        event.FileSHA256,
+				FileName:          event.FileName,
+				FilePath:          event.FilePath,
+				SigningID:         event.SigningID,
+				TeamID:            event.TeamID,
+				CertificateSHA256: subject.CertificateSHA256,
+			}
+			if len(event.SigningChain) > 0 {
+				binary.CertificateCN = event.SigningChain[0].CommonName
+			}
+			blocked[event.FileSHA256] = binary
+			users[event.FileSHA256] = make(map[string]bool)
+		}
+		binary.Executions++
+		if event.ExecutedAt > binary.LastSeen {
+			binary.LastSeen = event.ExecutedAt
+		}
+		if event.ExecutingUser != "" {
+			users[event.FileSHA256][event.ExecutingUser] = true
+		}
+	}
+
+	for sha, binary := range blocked {
+		for user := range users[sha] {
+			binary.Users = append(binary.Users, user)
+		}
+		sort.Strings(binary.Users)
+		readiness.WouldBlock = append(readiness.WouldBlock, *binary)
+	}
+	sort.Slice(readiness.WouldBlock, func(i, j int) bool {
+		if readiness.WouldBlock[i].Executions != readiness.WouldBlock[j].Executions {
+			return readiness.WouldBlock[i].Executions > readiness.WouldBlock[j].Executions
+		}
+		return readiness.WouldBlock[i].FileSHA256 < readiness.WouldBlock[j].FileSHA256
+	})
+
+	readiness.DistinctBinaries = len(seen)
+	readiness.Score = 100
+	if len(seen) > 0 {
+		readiness.Score = 100 * float64(len(seen)-len(blocked)) / float64(len(seen))
+	}
+
+	return readiness
+}
+
+// SuggestRules returns a minimal set of ALLOWLIST rules that would let every given binary run.
+//
+// Binaries are covered by the broadest identifier available that is no broader than [granularity]: with TeamID
+// granularity, all binaries of a developer collapse into a single rule, while unsigned binaries always fall back
+// to a Binary rule.
+func SuggestRules(blocked []BlockedBinary, granularity types.RuleType) []rules.SantaRule {
+	// Uploaded events do not reliably carry a CDHash, so Binary rules are the most specific that can be suggested
+	if granularity == types.RuleTypeCDHash {
+		granularity = types.RuleTypeBinary
+	}
+
+	suggested := make(map[string]rules.SantaRule)
+
+	for _, binary := range blocked {
+		rule := rules.SantaRule{
+			RuleType:   types.RuleTypeBinary,
+			Policy:     types.RulePolicyAllowlist,
+			Identifier: binary.FileSHA256,
+		}
+
+		switch {
+		case granularity == types.RuleTypeTeamID && binary.TeamID != "":
+			rule.RuleType = types.RuleTypeTeamID
+			rule.Identifier = binary.TeamID
+		case (granularity == types.RuleTypeTeamID || granularity == types.RuleTypeCertificate) && binary.CertificateSHA256 != "":
+			rule.RuleType = types.RuleTypeCertificate
+			rule.Identifier = binary.CertificateSHA256
+		case granularity != types.RuleTypeBinary && binary.SigningID != "" && !strings.HasPrefix(binary.SigningID, "platform:"):
+			rule.RuleType = types.RuleTypeSigningID
+			rule.Identifier = binary.SigningID
+		}
+
+		suggested[rules.RuleSortKeyFromTypeIdentifier(rule.Identifier, rule.RuleType)] = rule
+	}
+
+	keys := make([]string, 0, len(suggested))
+	for key := range suggested {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make([]rules.SantaRule, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, suggested[key])
+	}
+	return out
+}
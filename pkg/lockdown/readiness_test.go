@@ -0,0 +1,86 @@
+package lockdown
+
+import (
+	"testing"
+
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const machineID = "AAAAAAAA-A00A-1234-1234-5864377B4831"
+
+func event(sha string, teamID string, user string, executedAt string) eventlog.EventRow {
+	return eventlog.EventRow{
+		Event: eventlog.Event{
+			MachineID:     machineID,
+			Decision:      "ALLOW_UNKNOWN",
+			FileSHA256:    sha,
+			FileName:      sha[:4],
+			TeamID:        teamID,
+			ExecutingUser: user,
+			ExecutedAt:    executedAt,
+		},
+	}
+}
+
+func Test_AssessMachine(t *testing.T) {
+	rs := ruleset.New(
+		[]rules.SantaRule{
+			{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV"},
+			{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyBlocklist, Identifier: "dddd"},
+		},
+		nil,
+	)
+
+	readiness := AssessMachine(machineID, rs, []eventlog.EventRow{
+		event("aaaa", "EQHXZ8M8AV", "john_doe", "2021-01-01T00:00:00Z"),
+		event("bbbb", "FNN8Z5JMFP", "john_doe", "2021-01-01T00:00:00Z"),
+		event("bbbb", "FNN8Z5JMFP", "jane_doe", "2021-01-02T00:00:00Z"),
+		event("cccc", "", "john_doe", "2021-01-01T00:00:00Z"),
+		event("dddd", "", "john_doe", "2021-01-01T00:00:00Z"),
+	})
+
+	assert.True(t, readiness.HasEvents)
+	assert.False(t, readiness.Ready())
+	assert.Equal(t, 4, readiness.DistinctBinaries)
+	assert.Len(t, readiness.WouldBlock, 2)
+	assert.Equal(t, "bbbb", readiness.WouldBlock[0].FileSHA256)
+	assert.Equal(t, 2, readiness.WouldBlock[0].Executions)
+	assert.Equal(t, []string{"jane_doe", "john_doe"}, readiness.WouldBlock[0].Users)
+	assert.Equal(t, "2021-01-02T00:00:00Z", readiness.WouldBlock[0].LastSeen)
+	assert.Equal(t, float64(50), readiness.Score)
+}
+
+func Test_AssessMachine_NoEvents(t *testing.T) {
+	readiness := AssessMachine(machineID, ruleset.New(nil, nil), nil)
+
+	assert.False(t, readiness.HasEvents)
+	assert.False(t, readiness.Ready())
+	assert.Equal(t, float64(100), readiness.Score)
+}
+
+func Test_SuggestRules(t *testing.T) {
+	blocked := []BlockedBinary{
+		{FileSHA256: "aaaa", TeamID: "FNN8Z5JMFP", SigningID: "FNN8Z5JMFP:com.example.a", CertificateSHA256: "cert"},
+		{FileSHA256: "bbbb", TeamID: "FNN8Z5JMFP", SigningID: "FNN8Z5JMFP:com.example.b", CertificateSHA256: "cert"},
+		{FileSHA256: "cccc"},
+	}
+
+	suggested := SuggestRules(blocked, types.RuleTypeTeamID)
+	assert.Len(t, suggested, 2)
+	assert.Equal(t, types.RuleTypeBinary, suggested[0].RuleType)
+	assert.Equal(t, "cccc", suggested[0].Identifier)
+	assert.Equal(t, types.RuleTypeTeamID, suggested[1].RuleType)
+	assert.Equal(t, types.RulePolicyAllowlist, suggested[1].Policy)
+
+	suggested = SuggestRules(blocked, types.RuleTypeSigningID)
+	assert.Len(t, suggested, 3)
+
+	suggested = SuggestRules(blocked, types.RuleTypeBinary)
+	for _, rule := range suggested {
+		assert.Equal(t, types.RuleTypeBinary, rule.RuleType)
+	}
+}
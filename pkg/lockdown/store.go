@@ -0,0 +1,93 @@
+package lockdown
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/machinegroups"
+	"github.com/airbnb/rudolph/pkg/model/modetransitions"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// GetPromotionStore returns a PromotionStore backed by the DynamoDB table
+func GetPromotionStore(client dynamodb.DynamoDBClient, timeProvider clock.TimeProvider) PromotionStore {
+	return &concretePromotionStore{
+		client:        client,
+		timeProvider:  timeProvider,
+		configService: machineconfiguration.GetUncachedMachineConfigurationService(client, timeProvider),
+	}
+}
+
+type concretePromotionStore struct {
+	client        dynamodb.DynamoDBClient
+	timeProvider  clock.TimeProvider
+	configService machineconfiguration.MachineConfigurationService
+
+	// Global rules are shared by every machine, so they are only loaded once per run
+	globalRules       []rules.SantaRule
+	globalRulesLoaded bool
+}
+
+func (s *concretePromotionStore) ListMachineIDs() ([]string, error) {
+	return sensordata.ListMachineIDs(s.client)
+}
+
+func (s *concretePromotionStore) GetGroupMachineIDs(groupName string) ([]string, error) {
+	return machinegroups.GetGroupMachineIDs(s.client, groupName)
+}
+
+func (s *concretePromotionStore) GetTransitionsSince(since time.Time) (transitions []modetransitions.ModeTransition, err error) {
+	rows, err := modetransitions.GetTransitionsSince(s.client, s.timeProvider, since)
+	if err != nil {
+		return
+	}
+	for _, row := range rows {
+		transitions = append(transitions, row.ModeTransition)
+	}
+	return
+}
+
+func (s *concretePromotionStore) GetClientMode(machineID string) (types.ClientMode, error) {
+	config, err := s.configService.GetIntendedConfig(machineID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the intended configuration of machine %q: %w", machineID, err)
+	}
+	return config.ClientMode, nil
+}
+
+func (s *concretePromotionStore) GetEvents(machineID string, since time.Time) ([]eventlog.EventRow, error) {
+	return eventlog.GetAllEventsByMachineID(s.client, machineID, since)
+}
+
+func (s *concretePromotionStore) GetRuleset(machineID string) (ruleset.Ruleset, error) {
+	if !s.globalRulesLoaded {
+		globalRules, err := ruleset.LoadGlobalRules(s.client)
+		if err != nil {
+			return ruleset.Ruleset{}, err
+		}
+		s.globalRules = globalRules
+		s.globalRulesLoaded = true
+	}
+	return ruleset.ForMachine(s.client, s.globalRules, machineID)
+}
+
+// SetClientMode only touches the ClientMode of a machine. Machines that follow the global configuration get a machine
+// configuration of their own, copied from the global one.
+func (s *concretePromotionStore) SetClientMode(machineID string, clientMode types.ClientMode) error {
+	_, err := s.configService.UpdateMachineConfig(machineID, machineconfiguration.MachineConfigurationUpdateRequest{
+		ClientMode: &clientMode,
+	})
+	return err
+}
+
+func (s *concretePromotionStore) RecordTransition(machineID string, fromMode types.ClientMode, toMode types.ClientMode, actor string, reason string) error {
+	_, err := modetransitions.RecordTransition(s.client, s.timeProvider, machineID, fromMode, toMode, actor, reason)
+	return err
+}
@@ -0,0 +1,145 @@
+// Package logging builds the structured JSON loggers used by the Lambda functions.
+//
+// Every request gets its own logger, tagged with the API Gateway request ID and the machine ID, so that all the lines
+// of a single sync can be found together. Request and response bodies and personal data (users, hostnames, serial
+// numbers, file paths, signing chains) are redacted, except for the machines listed for debugging.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the value of every sensitive attribute
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are the attributes that may hold request bodies or personal data
+var sensitiveKeys = map[string]bool{
+	"body":             true,
+	"headers":          true,
+	"primary_user":     true,
+	"executing_user":   true,
+	"logged_in_users":  true,
+	"current_sessions": true,
+	"hostname":         true,
+	"serial_num":       true,
+	"file_path":        true,
+	"signing_chain":    true,
+	"principal":        true,
+	"groups":           true,
+}
+
+// Config controls what gets logged
+type Config struct {
+	Level slog.Level
+	// Redact replaces sensitive attributes with Redacted; it is on unless explicitly turned off
+	Redact bool
+	// DebugMachineIDs are machines whose requests are logged at debug level and without redaction
+	DebugMachineIDs map[string]bool
+}
+
+// DefaultConfig logs at info level, with redaction
+func DefaultConfig() Config {
+	return Config{
+		Level:  slog.LevelInfo,
+		Redact: true,
+	}
+}
+
+// ConfigFromEnv reads LOG_LEVEL (debug, info, warn or error), LOG_REDACT (true or false) and LOG_DEBUG_MACHINE_IDS
+// (a comma-separated list of machine IDs), starting from the default config
+func ConfigFromEnv(getenv func(string) string) (config Config, err error) {
+	config = DefaultConfig()
+
+	if level := getenv("LOG_LEVEL"); level != "" {
+		if err = config.Level.UnmarshalText([]byte(level)); err != nil {
+			return config, fmt.Errorf("invalid value for LOG_LEVEL: %w", err)
+		}
+	}
+
+	switch strings.ToLower(getenv("LOG_REDACT")) {
+	case "", "true":
+	case "false":
+		config.Redact = false
+	default:
+		return config, fmt.Errorf("invalid value for LOG_REDACT: %q must be true or false", getenv("LOG_REDACT"))
+	}
+
+	for _, machineID := range strings.Split(getenv("LOG_DEBUG_MACHINE_IDS"), ",") {
+		machineID = strings.TrimSpace(machineID)
+		if machineID == "" {
+			continue
+		}
+		if config.DebugMachineIDs == nil {
+			config.DebugMachineIDs = map[string]bool{}
+		}
+		config.DebugMachineIDs[strings.ToUpper(machineID)] = true
+	}
+	return
+}
+
+// Debugging reports whether the requests of a machine are logged in debug mode
+func (c Config) Debugging(machineID string) bool {
+	return machineID != "" && c.DebugMachineIDs[strings.ToUpper(machineID)]
+}
+
+// New returns a JSON logger for a single request. The request ID and machine ID are left out when empty.
+func New(out io.Writer, config Config, requestID string, machineID string) *slog.Logger {
+	level := config.Level
+	redact := config.Redact
+	if config.Debugging(machineID) {
+		level = slog.LevelDebug
+		redact = false
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	if redact {
+		options.ReplaceAttr = redactSensitive
+	}
+
+	logger := slog.New(slog.NewJSONHandler(out, options))
+	if requestID != "" {
+		logger = logger.With("request_id", requestID)
+	}
+	if machineID != "" {
+		logger = logger.With("machine_id", machineID)
+	}
+	return logger
+}
+
+func redactSensitive(groups []string, attr slog.Attr) slog.Attr {
+	if sensitiveKeys[attr.Key] {
+		return slog.String(attr.Key, Redacted)
+	}
+	return attr
+}
+
+var (
+	mu     sync.RWMutex
+	config           = DefaultConfig()
+	output io.Writer = os.Stderr
+)
+
+// Configure sets the config used by ForRequest; it is called once when a function starts
+func Configure(c Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	config = c
+}
+
+// ForRequest returns the logger for a single request, using the configured config, and makes it the default slog
+// logger. Lines logged with the log package go through it too, so that they carry the request ID and machine ID.
+//
+// Lambda only ever handles one request at a time per instance, so replacing the default logger is safe.
+func ForRequest(requestID string, machineID string) *slog.Logger {
+	mu.RLock()
+	logger := New(output, config, requestID, machineID)
+	mu.RUnlock()
+
+	slog.SetDefault(logger)
+	return logger
+}
@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const machineID = "AAAAAAAA-A00A-1234-1234-5864377B4831"
+
+func envOf(values map[string]string) func(string) string {
+	return func(name string) string {
+		return values[name]
+	}
+}
+
+func Test_ConfigFromEnv(t *testing.T) {
+	config, err := ConfigFromEnv(envOf(nil))
+	assert.Empty(t, err)
+	assert.Equal(t, DefaultConfig(), config)
+
+	config, err = ConfigFromEnv(envOf(map[string]string{
+		"LOG_LEVEL":             "warn",
+		"LOG_REDACT":            "false",
+		"LOG_DEBUG_MACHINE_IDS": " aaaaaaaa-a00a-1234-1234-5864377b4831, ",
+	}))
+	assert.Empty(t, err)
+	assert.Equal(t, slog.LevelWarn, config.Level)
+	assert.False(t, config.Redact)
+	assert.True(t, config.Debugging(machineID))
+	assert.False(t, config.Debugging(""))
+
+	_, err = ConfigFromEnv(envOf(map[string]string{"LOG_LEVEL": "loud"}))
+	assert.Error(t, err)
+
+	_, err = ConfigFromEnv(envOf(map[string]string{"LOG_REDACT": "maybe"}))
+	assert.Error(t, err)
+}
+
+func logLines(t *testing.T, out *bytes.Buffer) (lines []map[string]interface{}) {
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		var line map[string]interface{}
+		assert.Empty(t, decoder.Decode(&line))
+		lines = append(lines, line)
+	}
+	return
+}
+
+func Test_New_RedactsByDefault(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, DefaultConfig(), "request-1", machineID)
+
+	logger.Info("Api Request", "body", `{"primary_user":"alice"}`, "primary_user", "alice", "principal", "alice@example.com", "status", 200)
+	logger.Debug("Api Request body", "body", "dropped")
+
+	lines := logLines(t, &out)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "request-1", lines[0]["request_id"])
+	assert.Equal(t, machineID, lines[0]["machine_id"])
+	assert.Equal(t, Redacted, lines[0]["body"])
+	assert.Equal(t, Redacted, lines[0]["primary_user"])
+	assert.Equal(t, Redacted, lines[0]["principal"])
+	assert.Equal(t, float64(200), lines[0]["status"])
+}
+
+func Test_New_DebugMachine(t *testing.T) {
+	var out bytes.Buffer
+	config := DefaultConfig()
+	config.DebugMachineIDs = map[string]bool{machineID: true}
+
+	New(&out, config, "request-1", machineID).Debug("Api Request body", "body", "{}")
+	New(&out, config, "request-2", "BBBBBBBB-A00A-1234-1234-5864377B4831").Debug("Api Request body", "body", "{}")
+
+	lines := logLines(t, &out)
+	assert.Len(t, lines, 1)
+	assert.Equal(t, "{}", lines[0]["body"])
+}
@@ -0,0 +1,254 @@
+// Package machineview gathers everything Rudolph knows about a single machine, for debugging an endpoint without
+// reading each of its DynamoDB items by hand.
+package machineview
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/eventlog"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/model/syncstate"
+)
+
+// DefaultStaleAfter is how long a machine can go without syncing before it is reported as stale
+const DefaultStaleAfter = 3 * 24 * time.Hour
+
+// ConfigSource is where the configuration intended for a machine comes from
+type ConfigSource string
+
+const (
+	ConfigSourceMachine ConfigSource = "machine"
+	ConfigSourceGlobal  ConfigSource = "global"
+	// ConfigSourceDefault is the hardcoded configuration used when there is neither a machine nor a global one
+	ConfigSourceDefault ConfigSource = "default"
+)
+
+// ClientAPI is what Load needs from the DynamoDB client
+type ClientAPI interface {
+	dynamodb.GetItemAPI
+	dynamodb.QueryAPI
+}
+
+// Options control what Load reports
+type Options struct {
+	// StaleAfter is how long since the last sync before the machine gets a warning
+	StaleAfter time.Duration
+	// EventLimit is how many of the most recent stored events are loaded; none are loaded if zero
+	EventLimit  int
+	EventsSince time.Time
+}
+
+// Machine is everything Rudolph knows about a machine
+type Machine struct {
+	MachineID    string           `json:"machine_id"`
+	Sensor       *Sensor          `json:"sensor"`
+	Sync         *Sync            `json:"sync"`
+	Config       Config           `json:"config"`
+	MachineRules []MachineRule    `json:"machine_rules"`
+	Events       []eventlog.Event `json:"recent_events"`
+	Warnings     []string         `json:"warnings"`
+}
+
+// Sensor is what the sensor reported in its last preflight
+type Sensor struct {
+	SerialNum    string `json:"serial_num"`
+	PrimaryUser  string `json:"primary_user"`
+	OSVersion    string `json:"os_version"`
+	OSBuild      string `json:"os_build"`
+	SantaVersion string `json:"santa_version"`
+	ClientMode   string `json:"client_mode"`
+	RuleCount    int    `json:"rule_count"`
+	LastSeen     string `json:"last_seen"`
+}
+
+// Sync is the state of the machine's last sync
+type Sync struct {
+	Status        syncstate.SyncStatus    `json:"status"`
+	CleanSync     bool                    `json:"clean_sync"`
+	LastCleanSync string                  `json:"last_clean_sync,omitempty"`
+	BatchSize     int                     `json:"batch_size"`
+	Stages        []syncstate.StageTiming `json:"stages"`
+}
+
+// Config is the configuration intended for the machine, and where it comes from
+type Config struct {
+	Source        ConfigSource                              `json:"source"`
+	ClientMode    string                                    `json:"client_mode"`
+	Configuration machineconfiguration.MachineConfiguration `json:"configuration"`
+}
+
+// MachineRule is a rule that only applies to this machine
+type MachineRule struct {
+	RuleType         string    `json:"rule_type"`
+	Policy           string    `json:"policy"`
+	Identifier       string    `json:"identifier"`
+	Description      string    `json:"description,omitempty"`
+	ExpiresAt        time.Time `json:"expires_at,omitempty"`
+	Expired          bool      `json:"expired"`
+	DeleteOnNextSync bool      `json:"delete_on_next_sync"`
+}
+
+// Load reads everything Rudolph knows about a machine. A machine that never checked in is not an error; its sensor
+// and sync are nil, and it gets a warning instead.
+func Load(client ClientAPI, timeProvider clock.TimeProvider, machineID string, options Options) (machine Machine, err error) {
+	now := timeProvider.Now().UTC()
+	machine.MachineID = machineID
+
+	sensorData, err := sensordata.GetSensorData(client, machineID)
+	if err != nil {
+		err = fmt.Errorf("failed to get sensor data: %w", err)
+		return
+	}
+	if sensorData != nil {
+		clientMode, _ := sensorData.ClientMode.MarshalText()
+		machine.Sensor = &Sensor{
+			SerialNum:    sensorData.SerialNum,
+			PrimaryUser:  sensorData.PrimaryUser,
+			OSVersion:    sensorData.OSVersion,
+			OSBuild:      sensorData.OSBuild,
+			SantaVersion: sensorData.SantaVersion,
+			ClientMode:   string(clientMode),
+			RuleCount:    sensorData.RuleCount,
+			LastSeen:     sensorData.Time,
+		}
+	}
+
+	syncState, err := syncstate.GetByMachineID(client, machineID)
+	if err != nil {
+		err = fmt.Errorf("failed to get sync state: %w", err)
+		return
+	}
+	if syncState != nil {
+		machine.Sync = &Sync{
+			Status:        syncState.Status(now),
+			CleanSync:     syncState.CleanSync,
+			LastCleanSync: syncState.LastCleanSync,
+			BatchSize:     syncState.BatchSize,
+			Stages:        syncState.StageTimings(),
+		}
+	}
+
+	machine.Config, err = loadConfig(client, timeProvider, machineID)
+	if err != nil {
+		return
+	}
+
+	rows, err := machinerules.GetMachineRules(client, machineID)
+	if err != nil {
+		err = fmt.Errorf("failed to get machine rules: %w", err)
+		return
+	}
+	machine.MachineRules = machineRules(rows, now)
+
+	if options.EventLimit > 0 {
+		var events []eventlog.EventRow
+		events, err = eventlog.GetEventsByMachineID(client, machineID, options.EventsSince, options.EventLimit)
+		if err != nil {
+			return
+		}
+		for _, event := range events {
+			machine.Events = append(machine.Events, event.Event)
+		}
+	}
+
+	machine.Warnings = warnings(machine, syncState, now, options.StaleAfter)
+	return
+}
+
+func loadConfig(client dynamodb.GetItemAPI, timeProvider clock.TimeProvider, machineID string) (config Config, err error) {
+	machineConfig, err := machineconfiguration.GetMachineConfigurationFetcher(client).GetMachineSpecificConfig(machineID)
+	if err != nil {
+		err = fmt.Errorf("failed to get machine config: %w", err)
+		return
+	}
+	if machineConfig != nil {
+		config.Source = ConfigSourceMachine
+		config.Configuration = *machineConfig
+	} else {
+		var globalConfig *machineconfiguration.MachineConfiguration
+		globalConfig, err = machineconfiguration.GetUncachedGlobalConfigurationFetcher(client, timeProvider).GetGlobalConfig()
+		if err != nil {
+			return
+		}
+		if globalConfig != nil {
+			config.Source = ConfigSourceGlobal
+			config.Configuration = *globalConfig
+		} else {
+			config.Source = ConfigSourceDefault
+			config.Configuration = machineconfiguration.GetUniversalDefaultConfig()
+		}
+	}
+
+	clientMode, _ := config.Configuration.ClientMode.MarshalText()
+	config.ClientMode = string(clientMode)
+	return
+}
+
+func machineRules(rows *[]machinerules.MachineRuleRow, now time.Time) []MachineRule {
+	if rows == nil {
+		return nil
+	}
+
+	machineRules := make([]MachineRule, 0, len(*rows))
+	for _, row := range *rows {
+		ruleType, _ := row.RuleType.MarshalText()
+		policy, _ := row.Policy.MarshalText()
+		rule := MachineRule{
+			RuleType:         string(ruleType),
+			Policy:           string(policy),
+			Identifier:       row.Identifier,
+			Description:      row.Description,
+			DeleteOnNextSync: row.DeleteOnNextSync,
+		}
+		if row.ExpiresAfter > 0 {
+			rule.ExpiresAt = time.Unix(row.ExpiresAfter, 0).UTC()
+			// DynamoDB deletes expired items within a few days, not right away
+			rule.Expired = rule.ExpiresAt.Before(now)
+		}
+		machineRules = append(machineRules, rule)
+	}
+
+	sort.Slice(machineRules, func(i, j int) bool {
+		if machineRules[i].RuleType != machineRules[j].RuleType {
+			return machineRules[i].RuleType < machineRules[j].RuleType
+		}
+		return machineRules[i].Identifier < machineRules[j].Identifier
+	})
+	return machineRules
+}
+
+func warnings(machine Machine, syncState *syncstate.SyncStateRow, now time.Time, staleAfter time.Duration) (warnings []string) {
+	if staleAfter <= 0 {
+		staleAfter = DefaultStaleAfter
+	}
+
+	if machine.Sensor == nil {
+		warnings = append(warnings, "no sensor data: the machine has not checked in within the last 90 days")
+	}
+
+	if syncState == nil {
+		warnings = append(warnings, "no sync state: the machine has not synced within the last 90 days")
+	} else {
+		if lastSync, ok := syncState.LastSyncAt(); ok && now.Sub(lastSync) > staleAfter {
+			warnings = append(warnings, fmt.Sprintf("stale: the last sync started %s ago", now.Sub(lastSync).Truncate(time.Minute)))
+		}
+		if machine.Sync.Status.IsStalled() {
+			warnings = append(warnings, fmt.Sprintf("the last sync did not complete: %s", machine.Sync.Status))
+		}
+	}
+
+	if machine.Sensor != nil && machine.Sensor.ClientMode != machine.Config.ClientMode {
+		warnings = append(warnings, fmt.Sprintf(
+			"the sensor reports %s but %s is intended; it gets the intended mode on its next sync",
+			machine.Sensor.ClientMode,
+			machine.Config.ClientMode,
+		))
+	}
+	return
+}
@@ -0,0 +1,122 @@
+package machineview
+
+import (
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/machineconfiguration"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/model/syncstate"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const machineID = "AAAAAAAA-A00A-1234-1234-5864377B4831"
+
+// mockClient serves items by primary key, and machine rules to every query
+type mockClient struct {
+	items        map[dynamodb.PrimaryKey]interface{}
+	machineRules []machinerules.MachineRuleRow
+}
+
+func (m mockClient) GetItem(key dynamodb.PrimaryKey, consistentRead bool) (*awsdynamodb.GetItemOutput, error) {
+	item, ok := m.items[key]
+	if !ok {
+		return &awsdynamodb.GetItemOutput{}, nil
+	}
+	av, err := attributevalue.MarshalMap(item)
+	return &awsdynamodb.GetItemOutput{Item: av}, err
+}
+
+func (m mockClient) Query(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+	items, err := attributevalue.MarshalList(m.machineRules)
+	if err != nil {
+		return nil, err
+	}
+	output := &awsdynamodb.QueryOutput{}
+	for _, item := range items {
+		output.Items = append(output.Items, item.(*awstypes.AttributeValueMemberM).Value)
+	}
+	return output, nil
+}
+
+func Test_Load(t *testing.T) {
+	timeProvider := clock.FrozenTimeProvider{Current: time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)}
+
+	pk, sk := sensordata.MachineIDSensorDataPKSK(machineID)
+	client := mockClient{
+		items: map[dynamodb.PrimaryKey]interface{}{
+			{PartitionKey: pk, SortKey: sk}: sensordata.SensorData{
+				MachineID:   machineID,
+				SerialNum:   "C02ABC123DEF",
+				PrimaryUser: "alice",
+				ClientMode:  types.Monitor,
+				Time:        "2022-01-01T00:00:00Z",
+				DataType:    sensordata.GetDataType(),
+			},
+			{PartitionKey: pk, SortKey: "SyncState"}: syncstate.SyncState{
+				MachineID:             machineID,
+				PreflightAt:           "2022-01-01T00:00:00Z",
+				RuledownloadStartedAt: "2022-01-01T00:00:01Z",
+				DataType:              syncstate.GetDataType(),
+			},
+			{PartitionKey: "GlobalConfig", SortKey: "Config"}: machineconfiguration.MachineConfiguration{
+				ClientMode: types.Lockdown,
+				DataType:   types.DataTypeGlobalConfig,
+			},
+		},
+		machineRules: []machinerules.MachineRuleRow{
+			{
+				SantaRule:    rules.SantaRule{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV"},
+				ExpiresAfter: time.Date(2022, 1, 11, 0, 0, 0, 0, time.UTC).Unix(),
+			},
+			{
+				SantaRule:        rules.SantaRule{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyRemove, Identifier: "ed0a9ba83449b5966363e0c20fe7755defcb2d7136657d3880bb462a8d7a7025"},
+				ExpiresAfter:     time.Date(2022, 1, 9, 0, 0, 0, 0, time.UTC).Unix(),
+				DeleteOnNextSync: true,
+			},
+		},
+	}
+
+	machine, err := Load(client, timeProvider, machineID, Options{})
+
+	assert.Empty(t, err)
+	assert.Equal(t, "C02ABC123DEF", machine.Sensor.SerialNum)
+	assert.Equal(t, "MONITOR", machine.Sensor.ClientMode)
+
+	assert.Equal(t, syncstate.SyncStatusStalledInRuledownload, machine.Sync.Status)
+	assert.Len(t, machine.Sync.Stages, 2)
+
+	assert.Equal(t, ConfigSourceGlobal, machine.Config.Source)
+	assert.Equal(t, "LOCKDOWN", machine.Config.ClientMode)
+
+	assert.Len(t, machine.MachineRules, 2)
+	assert.Equal(t, "BINARY", machine.MachineRules[0].RuleType)
+	assert.True(t, machine.MachineRules[0].Expired)
+	assert.True(t, machine.MachineRules[0].DeleteOnNextSync)
+	assert.False(t, machine.MachineRules[1].Expired)
+
+	assert.Empty(t, machine.Events)
+	assert.Equal(t, []string{
+		"stale: the last sync started 216h0m0s ago",
+		"the last sync did not complete: stalled_in_ruledownload",
+		"the sensor reports MONITOR but LOCKDOWN is intended; it gets the intended mode on its next sync",
+	}, machine.Warnings)
+}
+
+func Test_Load_NeverCheckedIn(t *testing.T) {
+	machine, err := Load(mockClient{}, clock.ConcreteTimeProvider{}, machineID, Options{})
+
+	assert.Empty(t, err)
+	assert.Nil(t, machine.Sensor)
+	assert.Nil(t, machine.Sync)
+	assert.Equal(t, ConfigSourceDefault, machine.Config.Source)
+	assert.Len(t, machine.Warnings, 2)
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import "sync"
+
+// Collector keeps every metric in memory, for tests
+type Collector struct {
+	mu      sync.Mutex
+	metrics []Metric
+}
+
+func (c *Collector) Record(metric Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics = append(c.metrics, metric)
+}
+
+// Metrics returns the metrics recorded so far, in order
+func (c *Collector) Metrics() []Metric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Metric{}, c.metrics...)
+}
+
+// Sum adds up the values of every metric with the given name and dimensions, in any order
+func (c *Collector) Sum(name string, dimensions ...Dimension) (sum float64) {
+	for _, metric := range c.Metrics() {
+		if metric.Name == name && sameDimensions(metric.Dimensions, dimensions) {
+			sum += metric.Value
+		}
+	}
+	return
+}
+
+func sameDimensions(a []Dimension, b []Dimension) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, dimension := range a {
+		found := false
+		for _, other := range b {
+			if dimension == other {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+)
+
+// DefaultNamespace is the CloudWatch namespace used when none is configured
+const DefaultNamespace = "Rudolph"
+
+// EMFRecorder writes every metric as a single line in the CloudWatch Embedded Metric Format.
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+//
+// Metrics are written as soon as they are recorded rather than batched, so that nothing is lost when a Lambda
+// invocation ends.
+type EMFRecorder struct {
+	out          io.Writer
+	namespace    string
+	timeProvider clock.TimeProvider
+
+	mu sync.Mutex
+}
+
+// NewEMFRecorder returns an EMFRecorder that writes to out, which should be stdout in Lambda
+func NewEMFRecorder(out io.Writer, namespace string, timeProvider clock.TimeProvider) *EMFRecorder {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	return &EMFRecorder{
+		out:          out,
+		namespace:    namespace,
+		timeProvider: timeProvider,
+	}
+}
+
+type emfMetricDefinition struct {
+	Name string `json:"Name"`
+	Unit Unit   `json:"Unit"`
+}
+
+type emfDirective struct {
+	Namespace  string                `json:"Namespace"`
+	Dimensions [][]string            `json:"Dimensions"`
+	Metrics    []emfMetricDefinition `json:"Metrics"`
+}
+
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+func (r *EMFRecorder) Record(metric Metric) {
+	line, err := r.format(metric)
+	if err != nil {
+		log.Printf("failed to format metric %q: %s", metric.Name, err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, _ = r.out.Write(line)
+}
+
+func (r *EMFRecorder) format(metric Metric) ([]byte, error) {
+	// The dimension values and the metric value are top level members, referenced by name from the metadata
+	document := make(map[string]interface{}, len(metric.Dimensions)+2)
+	dimensionNames := make([]string, 0, len(metric.Dimensions))
+	for _, dimension := range metric.Dimensions {
+		dimensionNames = append(dimensionNames, dimension.Name)
+		document[dimension.Name] = dimension.Value
+	}
+	document[metric.Name] = metric.Value
+	document["_aws"] = emfMetadata{
+		Timestamp: r.timeProvider.Now().UnixMilli(),
+		CloudWatchMetrics: []emfDirective{
+			{
+				Namespace:  r.namespace,
+				Dimensions: [][]string{dimensionNames},
+				Metrics:    []emfMetricDefinition{{Name: metric.Name, Unit: metric.Unit}},
+			},
+		},
+	}
+
+	line, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EMFRecorder(t *testing.T) {
+	var out bytes.Buffer
+	timeProvider := clock.FrozenTimeProvider{Current: time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)}
+	SetRecorder(NewEMFRecorder(&out, "", timeProvider))
+	defer SetRecorder(NopRecorder{})
+
+	Duration("Latency", 1500*time.Microsecond, Dim("Endpoint", "/preflight/{machine_id}"))
+	Count("Requests", 1)
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+
+	expected := `{
+		"_aws": {
+			"Timestamp": 1641772800000,
+			"CloudWatchMetrics": [{
+				"Namespace": "Rudolph",
+				"Dimensions": [["Endpoint"]],
+				"Metrics": [{"Name": "Latency", "Unit": "Milliseconds"}]
+			}]
+		},
+		"Endpoint": "/preflight/{machine_id}",
+		"Latency": 1.5
+	}`
+	assert.JSONEq(t, expected, string(lines[0]))
+
+	var document map[string]interface{}
+	assert.Empty(t, json.Unmarshal(lines[1], &document))
+	assert.Equal(t, float64(1), document["Requests"])
+}
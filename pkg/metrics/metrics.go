@@ -0,0 +1,81 @@
+// Package metrics records operational metrics from the handlers, such as request counts, latencies and sync
+// outcomes.
+//
+// Metrics are sent to a pluggable Recorder. The Lambda functions use the EMFRecorder, which writes every metric as a
+// CloudWatch Embedded Metric Format log line, so that CloudWatch extracts them from the logs without any extra AWS
+// calls. Other backends, such as a Prometheus exporter, only need to implement Recorder and be passed to SetRecorder.
+//
+// Dimensions must never identify a single machine (no machine IDs, serial numbers or users), as every distinct set
+// of dimension values is billed as a separate CloudWatch metric.
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Unit is the unit of a metric, named as CloudWatch names them
+type Unit string
+
+const (
+	UnitCount        Unit = "Count"
+	UnitMilliseconds Unit = "Milliseconds"
+)
+
+// Dimension is a name and value that a metric can be broken down by
+type Dimension struct {
+	Name  string
+	Value string
+}
+
+// Dim is shorthand for a Dimension
+func Dim(name string, value string) Dimension {
+	return Dimension{Name: name, Value: value}
+}
+
+// Metric is a single observation
+type Metric struct {
+	Name       string
+	Value      float64
+	Unit       Unit
+	Dimensions []Dimension
+}
+
+// Recorder receives every metric. Implementations must be safe for concurrent use.
+type Recorder interface {
+	Record(metric Metric)
+}
+
+// NopRecorder drops every metric
+type NopRecorder struct{}
+
+func (NopRecorder) Record(Metric) {}
+
+var (
+	mu       sync.RWMutex
+	recorder Recorder = NopRecorder{}
+)
+
+// SetRecorder replaces the recorder that metrics are sent to. Until it is called, metrics are dropped.
+func SetRecorder(r Recorder) {
+	mu.Lock()
+	defer mu.Unlock()
+	recorder = r
+}
+
+func getRecorder() Recorder {
+	mu.RLock()
+	defer mu.RUnlock()
+	return recorder
+}
+
+// Count records a number of things that happened, such as requests served or rules downloaded
+func Count(name string, value int, dimensions ...Dimension) {
+	getRecorder().Record(Metric{Name: name, Value: float64(value), Unit: UnitCount, Dimensions: dimensions})
+}
+
+// Duration records how long something took, in milliseconds
+func Duration(name string, duration time.Duration, dimensions ...Dimension) {
+	milliseconds := float64(duration) / float64(time.Millisecond)
+	getRecorder().Record(Metric{Name: name, Value: milliseconds, Unit: UnitMilliseconds, Dimensions: dimensions})
+}
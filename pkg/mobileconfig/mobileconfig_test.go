@@ -0,0 +1,150 @@
+package mobileconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSHA = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func Test_MarshalPlist(t *testing.T) {
+	contents, err := MarshalPlist(Dict{
+		"b":     Array{true, 3, "<&>"},
+		"a":     Dict{},
+		"empty": Array{},
+	})
+
+	assert.Empty(t, err)
+	assert.Equal(t, plistHeader+`<dict>
+	<key>a</key>
+	<dict/>
+	<key>b</key>
+	<array>
+		<true/>
+		<integer>3</integer>
+		<string>&lt;&amp;&gt;</string>
+	</array>
+	<key>empty</key>
+	<array/>
+</dict>
+</plist>
+`, string(contents))
+
+	_, err = MarshalPlist(Dict{"float": 1.5})
+	assert.EqualError(t, err, `key "float": unsupported property list value type float64`)
+}
+
+func Test_StaticRules(t *testing.T) {
+	staticRules, skipped, err := StaticRules([]rules.SantaRule{
+		{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV"},
+		{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyBlocklist, Identifier: testSHA, CustomMessage: "No"},
+		{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyRemove, Identifier: testSHA},
+		{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlistTransitive, Identifier: testSHA},
+	})
+
+	assert.Empty(t, err)
+	assert.Equal(t, 2, skipped)
+	assert.Equal(t, Array{
+		Dict{"identifier": testSHA, "policy": "BLOCKLIST", "rule_type": "BINARY", "custom_msg": "No"},
+		Dict{"identifier": "EQHXZ8M8AV", "policy": "ALLOWLIST", "rule_type": "TEAMID"},
+	}, staticRules)
+}
+
+func Test_StaticRulesProfile_Deterministic(t *testing.T) {
+	first := []rules.SantaRule{
+		{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV"},
+		{RuleType: types.RuleTypeSigningID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV:com.google.Chrome"},
+	}
+	reordered := []rules.SantaRule{first[1], first[0]}
+	changed := []rules.SantaRule{first[0]}
+
+	marshal := func(santaRules []rules.SantaRule) string {
+		profile, _, err := StaticRulesProfile("com.example.santa.staticrules", santaRules)
+		assert.Empty(t, err)
+		contents, err := profile.Marshal()
+		assert.Empty(t, err)
+		return string(contents)
+	}
+
+	assert.Equal(t, marshal(first), marshal(reordered))
+	assert.NotEqual(t, payloadUUIDs(marshal(first)), payloadUUIDs(marshal(changed)))
+	assert.Contains(t, marshal(first), "<key>com.google.santa</key>")
+	assert.Contains(t, marshal(first), "<string>EQHXZ8M8AV:com.google.Chrome</string>")
+}
+
+func Test_Profile_Marshal_Invalid(t *testing.T) {
+	_, err := Profile{Identifier: "com.example"}.Marshal()
+	assert.Error(t, err)
+
+	_, err = Profile{Payloads: []Payload{{Type: "com.example", Identifier: "com.example"}}}.Marshal()
+	assert.Error(t, err)
+}
+
+// payloadUUIDs returns the lines of a profile that follow a PayloadUUID key
+func payloadUUIDs(contents string) (uuids []string) {
+	lines := strings.Split(contents, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "<key>PayloadUUID</key>" && i+1 < len(lines) {
+			uuids = append(uuids, strings.TrimSpace(lines[i+1]))
+		}
+	}
+	return
+}
+
+func Test_SantaConfiguration_Validate(t *testing.T) {
+	valid := SantaConfiguration{
+		SyncBaseURL:         "https://prefix-rudolph.example.com/",
+		ClientMode:          types.Monitor,
+		MachineMappingPlist: DefaultMachineMappingPlist,
+		MachineIDKey:        DefaultMachineIDKey,
+		MachineOwnerKey:     DefaultMachineOwnerKey,
+		SyncExtraHeaders:    map[string]string{"X-Api-Key": "secret"},
+		EventDetailURL:      "https://rudolph.example.com/event/%machine_id%/%file_sha%",
+	}
+	assert.Empty(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(c *SantaConfiguration)
+		error  string
+	}{
+		{"no sync url", func(c *SantaConfiguration) { c.SyncBaseURL = "" }, "SyncBaseURL is required"},
+		{"http sync url", func(c *SantaConfiguration) { c.SyncBaseURL = "http://example.com/" }, `SyncBaseURL "http://example.com/" must be an https URL`},
+		{"no trailing slash", func(c *SantaConfiguration) { c.SyncBaseURL = "https://example.com" }, `SyncBaseURL "https://example.com" must end with a /`},
+		{"no client mode", func(c *SantaConfiguration) { c.ClientMode = 0 }, "unknown client_mode 0"},
+		{"no owner key", func(c *SantaConfiguration) { c.MachineOwnerKey = "" }, "the machine-mapping plist needs both a machine ID key and a machine owner key"},
+		{"bad header", func(c *SantaConfiguration) { c.SyncExtraHeaders = map[string]string{"X Api": "secret"} }, `"X Api" is not a valid header name`},
+		{"bad event url", func(c *SantaConfiguration) { c.EventDetailURL = "/event/%file_sha%" }, `EventDetailURL "/event/%file_sha%" must be an http or https URL`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configuration := valid
+			test.modify(&configuration)
+			assert.EqualError(t, configuration.Validate(), test.error)
+		})
+	}
+}
+
+func Test_SantaConfigurationProfile(t *testing.T) {
+	profile, err := SantaConfigurationProfile("com.google.santa", "Acme", SantaConfiguration{
+		SyncBaseURL:      "https://prefix-rudolph.example.com/",
+		ClientMode:       types.Lockdown,
+		SyncExtraHeaders: map[string]string{"X-Api-Key": "secret"},
+	})
+	assert.Empty(t, err)
+
+	contents, err := profile.Marshal()
+	assert.Empty(t, err)
+	assert.Contains(t, string(contents), "<key>ClientMode</key>\n\t\t\t\t\t\t\t\t<integer>2</integer>")
+	assert.Contains(t, string(contents), "<key>X-Api-Key</key>")
+	assert.NotContains(t, string(contents), "MachineIDPlist")
+
+	for _, profile := range []Profile{SystemExtensionProfile("com.example.sysext", "Acme"), TCCProfile("com.example.tcc", "Acme")} {
+		_, err = profile.Marshal()
+		assert.Empty(t, err)
+	}
+}
@@ -0,0 +1,93 @@
+package mobileconfig
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const plistHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+`
+
+// Dict is a property list dictionary. Its keys are always written in sorted order, so that the same dictionary is
+// always encoded to the same bytes.
+type Dict map[string]interface{}
+
+// Array is a property list array
+type Array []interface{}
+
+// MarshalPlist encodes a value as an XML property list.
+//
+// Supported values are Dict, Array, string, bool and the integer types; anything else is an error.
+func MarshalPlist(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString(plistHeader)
+	if err := encodeValue(&buffer, value, 0); err != nil {
+		return nil, err
+	}
+	buffer.WriteString("</plist>\n")
+	return buffer.Bytes(), nil
+}
+
+func encodeValue(buffer *bytes.Buffer, value interface{}, depth int) error {
+	indent := strings.Repeat("\t", depth)
+	switch v := value.(type) {
+	case Dict:
+		if len(v) == 0 {
+			buffer.WriteString(indent + "<dict/>\n")
+			return nil
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buffer.WriteString(indent + "<dict>\n")
+		for _, key := range keys {
+			buffer.WriteString(indent + "\t<key>")
+			writeEscaped(buffer, key)
+			buffer.WriteString("</key>\n")
+			if err := encodeValue(buffer, v[key], depth+1); err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+		}
+		buffer.WriteString(indent + "</dict>\n")
+	case Array:
+		if len(v) == 0 {
+			buffer.WriteString(indent + "<array/>\n")
+			return nil
+		}
+		buffer.WriteString(indent + "<array>\n")
+		for i, item := range v {
+			if err := encodeValue(buffer, item, depth+1); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		buffer.WriteString(indent + "</array>\n")
+	case string:
+		buffer.WriteString(indent + "<string>")
+		writeEscaped(buffer, v)
+		buffer.WriteString("</string>\n")
+	case bool:
+		if v {
+			buffer.WriteString(indent + "<true/>\n")
+		} else {
+			buffer.WriteString(indent + "<false/>\n")
+		}
+	case int, int32, int64, uint, uint32, uint64:
+		fmt.Fprintf(buffer, "%s<integer>%d</integer>\n", indent, v)
+	default:
+		return fmt.Errorf("unsupported property list value type %T", value)
+	}
+	return nil
+}
+
+func writeEscaped(buffer *bytes.Buffer, s string) {
+	// EscapeText only fails when the writer does; bytes.Buffer never does
+	_ = xml.EscapeText(buffer, []byte(s))
+}
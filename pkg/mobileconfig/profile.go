@@ -0,0 +1,132 @@
+// Package mobileconfig generates macOS configuration profiles (.mobileconfig) for Santa.
+//
+// Profiles are written as unsigned XML property lists, ready to be signed or uploaded to an MDM. The output is
+// deterministic: the same profile always produces the same bytes, including the PayloadUUIDs, which are derived from
+// the contents of the payloads instead of being random. Regenerating an unchanged profile therefore does not show up
+// as a change in MDM or in git, and changing it always produces a new PayloadUUID.
+package mobileconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	payloadTypeConfiguration      = "Configuration"
+	payloadTypeManagedPreferences = "com.apple.ManagedClient.preferences"
+)
+
+// uuidNamespace is the UUIDv5 namespace of every PayloadUUID generated by Rudolph
+var uuidNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/airbnb/rudolph/mobileconfig"))
+
+// Profile is a configuration profile holding one or more payloads
+type Profile struct {
+	Identifier        string
+	DisplayName       string
+	Description       string
+	Organization      string
+	RemovalDisallowed bool
+	Payloads          []Payload
+}
+
+// Payload is a single payload of a configuration profile
+type Payload struct {
+	Type        string
+	Identifier  string
+	DisplayName string
+	// Content holds the payload-specific keys; the common Payload* keys are added when the profile is marshalled
+	Content Dict
+}
+
+// ManagedPreferencesPayload forces preference settings of the given domain, e.g. com.google.santa, the same way as
+// the sample Santa configuration profile does
+func ManagedPreferencesPayload(identifier string, domain string, settings Dict) Payload {
+	return Payload{
+		Type:       payloadTypeManagedPreferences,
+		Identifier: identifier,
+		Content: Dict{
+			"PayloadContent": Dict{
+				domain: Dict{
+					"Forced": Array{
+						Dict{"mcx_preference_settings": settings},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Marshal encodes the profile as an unsigned .mobileconfig
+func (p Profile) Marshal() ([]byte, error) {
+	if p.Identifier == "" {
+		return nil, errors.New("a profile needs an identifier")
+	}
+	if len(p.Payloads) == 0 {
+		return nil, errors.New("a profile needs at least one payload")
+	}
+
+	payloads := make(Array, 0, len(p.Payloads))
+	payloadUUIDs := make([]string, 0, len(p.Payloads))
+	for _, payload := range p.Payloads {
+		dict, err := payload.dict()
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, dict)
+		payloadUUIDs = append(payloadUUIDs, dict["PayloadUUID"].(string))
+	}
+
+	profile := Dict{
+		"PayloadContent":           payloads,
+		"PayloadDisplayName":       p.DisplayName,
+		"PayloadIdentifier":        p.Identifier,
+		"PayloadOrganization":      p.Organization,
+		"PayloadRemovalDisallowed": p.RemovalDisallowed,
+		"PayloadScope":             "System",
+		"PayloadType":              payloadTypeConfiguration,
+		"PayloadUUID":              deterministicUUID(p.Identifier, []byte(strings.Join(payloadUUIDs, "\n"))),
+		"PayloadVersion":           1,
+	}
+	if p.Description != "" {
+		profile["PayloadDescription"] = p.Description
+	}
+	return MarshalPlist(profile)
+}
+
+func (p Payload) dict() (Dict, error) {
+	if p.Type == "" || p.Identifier == "" {
+		return nil, errors.New("a payload needs a type and an identifier")
+	}
+
+	dict := make(Dict, len(p.Content)+5)
+	for key, value := range p.Content {
+		dict[key] = value
+	}
+
+	// The UUID is derived from everything but itself
+	contents, err := MarshalPlist(dict)
+	if err != nil {
+		return nil, fmt.Errorf("payload %q: %w", p.Identifier, err)
+	}
+
+	dict["PayloadEnabled"] = true
+	dict["PayloadIdentifier"] = p.Identifier
+	dict["PayloadType"] = p.Type
+	dict["PayloadUUID"] = deterministicUUID(p.Type+"\n"+p.Identifier, contents)
+	dict["PayloadVersion"] = 1
+	if p.DisplayName != "" {
+		dict["PayloadDisplayName"] = p.DisplayName
+	}
+	return dict, nil
+}
+
+func deterministicUUID(name string, contents []byte) string {
+	data := make([]byte, 0, len(name)+1+len(contents))
+	data = append(data, name...)
+	data = append(data, '\n')
+	data = append(data, contents...)
+	return strings.ToUpper(uuid.NewSHA1(uuidNamespace, data).String())
+}
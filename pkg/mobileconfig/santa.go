@@ -0,0 +1,192 @@
+package mobileconfig
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+const (
+	// DefaultMachineMappingPlist is where the machine-mapping plist is installed; see
+	// configs/com.google.santa.machine-mapping.plist
+	DefaultMachineMappingPlist = "/Library/Preferences/com.google.santa.machine-mapping.plist"
+	// DefaultMachineIDKey and DefaultMachineOwnerKey are the keys of the machine-mapping plist
+	DefaultMachineIDKey    = "MachineUUID"
+	DefaultMachineOwnerKey = "Owner"
+
+	santaTeamID           = "EQHXZ8M8AV"
+	santaSystemExtension  = "com.google.santa.daemon"
+	santaCodeRequirementf = `identifier "%s" and anchor apple generic and certificate 1[field.1.2.840.113635.100.6.2.6] /* exists */ and certificate leaf[field.1.2.840.113635.100.6.1.13] /* exists */ and certificate leaf[subject.OU] = ` + santaTeamID
+)
+
+// SantaConfiguration holds the settings of the Santa configuration profile that Rudolph cares about
+type SantaConfiguration struct {
+	// SyncBaseURL is the URL of the Rudolph API, e.g. https://prefix-rudolph.example.com/
+	SyncBaseURL string
+	ClientMode  types.ClientMode
+	// MachineMappingPlist is the plist that maps the machine to its Rudolph machine ID and owner. When it is empty,
+	// Santa uses the hardware UUID as the machine ID and reports no owner.
+	MachineMappingPlist string
+	MachineIDKey        string
+	MachineOwnerKey     string
+	// SyncExtraHeaders are sent with every sync request, e.g. to authenticate sensors to the API
+	SyncExtraHeaders map[string]string
+	// EventDetailURL is opened from block notifications; Santa replaces placeholders such as %file_sha% and
+	// %machine_id%
+	EventDetailURL  string
+	EventDetailText string
+}
+
+// Validate reports settings that would produce a profile Santa cannot sync with
+func (c SantaConfiguration) Validate() error {
+	if c.SyncBaseURL == "" {
+		return errors.New("SyncBaseURL is required")
+	}
+	syncBaseURL, err := url.Parse(c.SyncBaseURL)
+	if err != nil || syncBaseURL.Scheme != "https" || syncBaseURL.Host == "" {
+		return fmt.Errorf("SyncBaseURL %q must be an https URL", c.SyncBaseURL)
+	}
+	if !strings.HasSuffix(syncBaseURL.Path, "/") {
+		return fmt.Errorf("SyncBaseURL %q must end with a /", c.SyncBaseURL)
+	}
+
+	if _, err = c.ClientMode.MarshalText(); err != nil {
+		return err
+	}
+
+	if c.MachineMappingPlist != "" && (c.MachineIDKey == "" || c.MachineOwnerKey == "") {
+		return errors.New("the machine-mapping plist needs both a machine ID key and a machine owner key")
+	}
+
+	for name, value := range c.SyncExtraHeaders {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			return fmt.Errorf("%q is not a valid header name", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("the value of header %q must not contain line breaks", name)
+		}
+	}
+
+	if c.EventDetailURL != "" {
+		// Santa's placeholders are not valid URL escapes, so they are replaced before parsing
+		eventDetailURL, err := url.Parse(strings.NewReplacer("%", "").Replace(c.EventDetailURL))
+		if err != nil || (eventDetailURL.Scheme != "https" && eventDetailURL.Scheme != "http") || eventDetailURL.Host == "" {
+			return fmt.Errorf("EventDetailURL %q must be an http or https URL", c.EventDetailURL)
+		}
+	}
+	return nil
+}
+
+func (c SantaConfiguration) settings() Dict {
+	settings := Dict{
+		"ClientMode":  int(c.ClientMode),
+		"SyncBaseURL": c.SyncBaseURL,
+	}
+	if c.MachineMappingPlist != "" {
+		settings["MachineIDPlist"] = c.MachineMappingPlist
+		settings["MachineIDKey"] = c.MachineIDKey
+		settings["MachineOwnerPlist"] = c.MachineMappingPlist
+		settings["MachineOwnerKey"] = c.MachineOwnerKey
+	}
+	if len(c.SyncExtraHeaders) > 0 {
+		headers := make(Dict, len(c.SyncExtraHeaders))
+		for name, value := range c.SyncExtraHeaders {
+			headers[name] = value
+		}
+		settings["SyncExtraHeaders"] = headers
+	}
+	if c.EventDetailURL != "" {
+		settings["EventDetailURL"] = c.EventDetailURL
+	}
+	if c.EventDetailText != "" {
+		settings["EventDetailText"] = c.EventDetailText
+	}
+	return settings
+}
+
+// SantaConfigurationProfile builds the profile that configures Santa to sync with Rudolph
+func SantaConfigurationProfile(identifier string, organization string, configuration SantaConfiguration) (profile Profile, err error) {
+	if err = configuration.Validate(); err != nil {
+		return
+	}
+
+	profile = Profile{
+		Identifier:        identifier,
+		DisplayName:       "Santa Configuration",
+		Description:       "Santa Configuration",
+		Organization:      organization,
+		RemovalDisallowed: true,
+		Payloads: []Payload{
+			ManagedPreferencesPayload(identifier+".preferences", SantaPreferencesDomain, configuration.settings()),
+		},
+	}
+	return
+}
+
+// SystemExtensionProfile builds the profile that allows Santa's system extension to load without user approval
+func SystemExtensionProfile(identifier string, organization string) Profile {
+	return Profile{
+		Identifier:   identifier,
+		DisplayName:  "Santa System Extension Policy",
+		Description:  "Allows the Santa system extension",
+		Organization: organization,
+		Payloads: []Payload{
+			{
+				Type:        "com.apple.system-extension-policy",
+				Identifier:  identifier + ".system-extension-policy",
+				DisplayName: "Santa System Extension Policy",
+				Content: Dict{
+					"AllowUserOverrides": true,
+					"AllowedSystemExtensions": Dict{
+						santaTeamID: Array{santaSystemExtension},
+					},
+					"AllowedSystemExtensionTypes": Dict{
+						santaTeamID: Array{"EndpointSecurityExtension"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// TCCProfile builds the profile that grants Santa's components Full Disk Access, which Santa needs to inspect every
+// executable
+func TCCProfile(identifier string, organization string) Profile {
+	bundleIDs := []string{"com.google.santa", "com.google.santa.bundleservice", santaSystemExtension}
+	sort.Strings(bundleIDs)
+
+	allFiles := make(Array, 0, len(bundleIDs))
+	for _, bundleID := range bundleIDs {
+		allFiles = append(allFiles, Dict{
+			"Allowed":         true,
+			"CodeRequirement": fmt.Sprintf(santaCodeRequirementf, bundleID),
+			"Comment":         fmt.Sprintf("Allow SystemPolicyAllFiles control for %s", bundleID),
+			"Identifier":      bundleID,
+			"IdentifierType":  "bundleID",
+			"StaticCode":      false,
+		})
+	}
+
+	return Profile{
+		Identifier:   identifier,
+		DisplayName:  "Santa Privacy Preferences Policy Control",
+		Description:  "Grants Full Disk Access to Santa",
+		Organization: organization,
+		Payloads: []Payload{
+			{
+				Type:        "com.apple.TCC.configuration-profile-policy",
+				Identifier:  identifier + ".configuration-profile-policy",
+				DisplayName: "Santa Privacy Preferences Policy Control",
+				Content: Dict{
+					"Services": Dict{
+						"SystemPolicyAllFiles": allFiles,
+					},
+				},
+			},
+		},
+	}
+}
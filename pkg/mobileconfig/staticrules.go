@@ -0,0 +1,82 @@
+package mobileconfig
+
+import (
+	"sort"
+
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// SantaPreferencesDomain is the preference domain read by Santa
+const SantaPreferencesDomain = "com.google.santa"
+
+// StaticRules converts rules into the entries of Santa's StaticRules setting, sorted by rule type and identifier.
+//
+// Static rules are not synced, so REMOVE rules, which only make sense as instructions to a sensor, and transitive
+// rules, which are created by the sensor itself, are skipped; skipped counts them.
+func StaticRules(santaRules []rules.SantaRule) (staticRules Array, skipped int, err error) {
+	sorted := make([]rules.SantaRule, 0, len(santaRules))
+	for _, rule := range santaRules {
+		switch rule.Policy {
+		case types.RulePolicyRemove, types.RulePolicyAllowlistTransitive:
+			skipped++
+			continue
+		}
+		sorted = append(sorted, rule)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].RuleType != sorted[j].RuleType {
+			return sorted[i].RuleType < sorted[j].RuleType
+		}
+		return sorted[i].Identifier < sorted[j].Identifier
+	})
+
+	staticRules = make(Array, 0, len(sorted))
+	for _, rule := range sorted {
+		ruleType, inerr := rule.RuleType.MarshalText()
+		if inerr != nil {
+			err = inerr
+			return
+		}
+		policy, inerr := rule.Policy.MarshalText()
+		if inerr != nil {
+			err = inerr
+			return
+		}
+
+		staticRule := Dict{
+			"identifier": rule.Identifier,
+			"policy":     string(policy),
+			"rule_type":  string(ruleType),
+		}
+		if rule.CustomMessage != "" {
+			staticRule["custom_msg"] = rule.CustomMessage
+		}
+		staticRules = append(staticRules, staticRule)
+	}
+	return
+}
+
+// StaticRulesProfile builds a profile that forces Santa's StaticRules setting to the given rules. It only holds the
+// static rules, so that it can be deployed next to the profile holding the rest of the Santa configuration.
+func StaticRulesProfile(identifier string, santaRules []rules.SantaRule) (profile Profile, skipped int, err error) {
+	staticRules, skipped, err := StaticRules(santaRules)
+	if err != nil {
+		return
+	}
+
+	profile = Profile{
+		Identifier:        identifier,
+		DisplayName:       "Santa Static Rules",
+		Description:       "Santa rules exported from Rudolph",
+		RemovalDisallowed: true,
+		Payloads: []Payload{
+			ManagedPreferencesPayload(
+				identifier+".preferences",
+				SantaPreferencesDomain,
+				Dict{"StaticRules": staticRules},
+			),
+		},
+	}
+	return
+}
@@ -0,0 +1,137 @@
+package catalog
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+const (
+	catalogPKPrefix               = "Catalog#"
+	catalogMemberPKPrefix         = "CatalogMember#"
+	decisionAttributePrefix       = "Decision#"
+	catalogExpiresAfterInDays int = 90
+	catalogQueryPageLimit         = 100
+	// catalogShards spreads the entries of each type over this many partitions, so that the writes of the whole fleet
+	// do not land on a single partition per type
+	catalogShards = 16
+)
+
+// EntryType is the dimension that a catalog entry aggregates executions over
+type EntryType string
+
+const (
+	EntryTypeBinary      EntryType = "Binary"
+	EntryTypeSigningID   EntryType = "SigningID"
+	EntryTypeTeamID      EntryType = "TeamID"
+	EntryTypeCertificate EntryType = "Certificate"
+)
+
+// ParseEntryType accepts the case-insensitive name of an EntryType
+func ParseEntryType(s string) (EntryType, error) {
+	switch strings.ToLower(s) {
+	case "binary", "sha256":
+		return EntryTypeBinary, nil
+	case "signingid", "signing_id":
+		return EntryTypeSigningID, nil
+	case "teamid", "team_id":
+		return EntryTypeTeamID, nil
+	case "certificate", "cert":
+		return EntryTypeCertificate, nil
+	}
+	return "", fmt.Errorf("unknown catalog entry type %q", s)
+}
+
+// CatalogEntryRow is a single aggregated entry of the fleet-wide binary catalog.
+//
+// Entries of the same EntryType are spread over catalogShards partitions by their identifier, so that the whole
+// catalog of a given type can be listed with one paginated query per shard.
+type CatalogEntryRow struct {
+	dynamodb.PrimaryKey
+	CatalogEntry
+	ExpiresAfter int64          `dynamodbav:"ExpiresAfter,omitempty"`
+	DataType     types.DataType `dynamodbav:"DataType"`
+}
+
+// CatalogEntry holds the aggregated statistics of all uploaded executions that share an identifier
+type CatalogEntry struct {
+	EntryType  EntryType `dynamodbav:"EntryType" json:"type"`
+	Identifier string    `dynamodbav:"Identifier" json:"identifier"`
+	FirstSeen  string    `dynamodbav:"FirstSeen" json:"first_seen"`
+	LastSeen   string    `dynamodbav:"LastSeen" json:"last_seen"`
+	Executions int64     `dynamodbav:"Executions" json:"executions"`
+	// DistinctMachines and DistinctUsers count the machines and users that executed the entry. Each of them is
+	// recorded in a catalog member row of its own, so that popular entries stay small; see recordMembers.
+	DistinctMachines int64 `dynamodbav:"DistinctMachines" json:"machines"`
+	DistinctUsers    int64 `dynamodbav:"DistinctUsers" json:"users"`
+
+	// DecisionCounts are stored as one top-level attribute per decision, so that they can be incremented atomically
+	// without first initializing a map attribute. See unmarshalCatalogEntryRow.
+	DecisionCounts map[string]int64 `dynamodbav:"-" json:"decisions"`
+
+	// Descriptive details of the most recent execution
+	FileName          string `dynamodbav:"FileName,omitempty" json:"file_name,omitempty"`
+	FilePath          string `dynamodbav:"FilePath,omitempty" json:"file_path,omitempty"`
+	SigningID         string `dynamodbav:"SigningID,omitempty" json:"signing_id,omitempty"`
+	TeamID            string `dynamodbav:"TeamID,omitempty" json:"team_id,omitempty"`
+	CertificateSHA256 string `dynamodbav:"CertificateSHA256,omitempty" json:"certificate_sha256,omitempty"`
+	CertificateCN     string `dynamodbav:"CertificateCN,omitempty" json:"certificate_cn,omitempty"`
+	BundleID          string `dynamodbav:"BundleID,omitempty" json:"bundle_id,omitempty"`
+	BundleName        string `dynamodbav:"BundleName,omitempty" json:"bundle_name,omitempty"`
+	BundleVersion     string `dynamodbav:"BundleVersion,omitempty" json:"bundle_version,omitempty"`
+}
+
+// MachineCount is the number of distinct machines that have executed this entry
+func (e CatalogEntry) MachineCount() int {
+	return int(e.DistinctMachines)
+}
+
+// UserCount is the number of distinct users that have executed this entry
+func (e CatalogEntry) UserCount() int {
+	return int(e.DistinctUsers)
+}
+
+// BlockCount is the number of executions that Santa blocked, or would have blocked when in MONITOR mode
+func (e CatalogEntry) BlockCount() (count int64) {
+	for decision, n := range e.DecisionCounts {
+		if strings.HasPrefix(decision, "BLOCK_") {
+			count += n
+		}
+	}
+	return
+}
+
+func catalogPK(entryType EntryType, sortKey string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(sortKey))
+	return catalogShardPK(entryType, int(hash.Sum32()%catalogShards))
+}
+
+func catalogShardPK(entryType EntryType, shard int) string {
+	return fmt.Sprintf("%s%s#%02d", catalogPKPrefix, entryType, shard)
+}
+
+// catalogMemberPK is the partition of the machines and users that executed an entry
+func catalogMemberPK(entryType EntryType, sortKey string) string {
+	return fmt.Sprintf("%s%s#%s", catalogMemberPKPrefix, entryType, sortKey)
+}
+
+func catalogSK(entryType EntryType, identifier string) string {
+	switch entryType {
+	case EntryTypeBinary, EntryTypeCertificate:
+		return strings.ToLower(identifier)
+	}
+	return identifier
+}
+
+func GetCatalogExpiresAfter(timeProvider clock.TimeProvider) int64 {
+	return clock.Unixtimestamp(timeProvider.Now().UTC().AddDate(0, 0, catalogExpiresAfterInDays))
+}
+
+func GetDataType() types.DataType {
+	return types.DataTypeCatalogEntry
+}
@@ -0,0 +1,125 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// GetCatalogEntry returns a single catalog entry, or nil if the identifier has never been seen
+func GetCatalogEntry(client dynamodb.GetItemAPI, entryType EntryType, identifier string) (entry *CatalogEntryRow, err error) {
+	sortKey := catalogSK(entryType, identifier)
+	output, err := client.GetItem(
+		dynamodb.PrimaryKey{
+			PartitionKey: catalogPK(entryType, sortKey),
+			SortKey:      sortKey,
+		},
+		false,
+	)
+	if err != nil {
+		err = fmt.Errorf("failed to get catalog entry: %w", err)
+		return
+	}
+	if len(output.Item) == 0 {
+		return
+	}
+
+	row, err := unmarshalCatalogEntryRow(output.Item)
+	if err != nil {
+		return
+	}
+	entry = &row
+	return
+}
+
+// GetCatalogEntries invokes the callback for every catalog entry of the given type, one shard after the other
+func GetCatalogEntries(client dynamodb.QueryAPI, entryType EntryType, callback func(CatalogEntryRow) error) (err error) {
+	for shard := 0; shard < catalogShards; shard++ {
+		if err = getCatalogShardEntries(client, entryType, shard, callback); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func getCatalogShardEntries(client dynamodb.QueryAPI, entryType EntryType, shard int, callback func(CatalogEntryRow) error) (err error) {
+	keyCond := expression.Key("PK").Equal(expression.Value(catalogShardPK(entryType, shard)))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		return
+	}
+
+	var exclusiveStartKey map[string]awstypes.AttributeValue
+	for {
+		input := &awsdynamodb.QueryInput{
+			ConsistentRead:            aws.Bool(false),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			KeyConditionExpression:    expr.KeyCondition(),
+			ExclusiveStartKey:         exclusiveStartKey,
+			Limit:                     aws.Int32(catalogQueryPageLimit),
+		}
+
+		var result *awsdynamodb.QueryOutput
+		result, err = client.Query(input)
+		if err != nil {
+			err = fmt.Errorf("failed to query catalog entries of type %q: %w", entryType, err)
+			return
+		}
+
+		for _, item := range result.Items {
+			var row CatalogEntryRow
+			row, err = unmarshalCatalogEntryRow(item)
+			if err != nil {
+				return
+			}
+			err = callback(row)
+			if err != nil {
+				return
+			}
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return
+		}
+		exclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// ListCatalogEntries returns every catalog entry of the given type
+func ListCatalogEntries(client dynamodb.QueryAPI, entryType EntryType) (entries []CatalogEntryRow, err error) {
+	err = GetCatalogEntries(client, entryType, func(row CatalogEntryRow) error {
+		entries = append(entries, row)
+		return nil
+	})
+	return
+}
+
+func unmarshalCatalogEntryRow(item map[string]awstypes.AttributeValue) (row CatalogEntryRow, err error) {
+	err = attributevalue.UnmarshalMap(item, &row)
+	if err != nil {
+		err = fmt.Errorf("failed to unmarshal catalog entry: %w", err)
+		return
+	}
+
+	row.DecisionCounts = make(map[string]int64)
+	for name, value := range item {
+		if !strings.HasPrefix(name, decisionAttributePrefix) {
+			continue
+		}
+		var count int64
+		err = attributevalue.Unmarshal(value, &count)
+		if err != nil {
+			err = fmt.Errorf("failed to unmarshal decision count %q: %w", name, err)
+			return
+		}
+		row.DecisionCounts[strings.TrimPrefix(name, decisionAttributePrefix)] = count
+	}
+	return
+}
@@ -0,0 +1,235 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Observation is a single execution of a binary on a machine, as reported by an uploaded event
+type Observation struct {
+	MachineID         string
+	ExecutingUser     string
+	Decision          string
+	ExecutedAt        string
+	FileSHA256        string
+	FileName          string
+	FilePath          string
+	SigningID         string
+	TeamID            string
+	CertificateSHA256 string
+	CertificateCN     string
+	BundleID          string
+	BundleName        string
+	BundleVersion     string
+}
+
+type aggregate struct {
+	entry     CatalogEntry
+	machines  map[string]bool
+	users     map[string]bool
+	decisions map[string]int64
+}
+
+// RecordObservations folds the given observations into the catalog.
+//
+// Observations are first aggregated in memory, so that a batch of uploaded events results in one write per distinct
+// catalog entry, plus one per machine and user of it, regardless of how many times the same binary appears in the
+// batch.
+func RecordObservations(client dynamodb.ConditionalUpsertItemAPI, timeProvider clock.TimeProvider, observations []Observation) error {
+	aggregates := aggregateObservations(observations)
+
+	keys := make([]dynamodb.PrimaryKey, 0, len(aggregates))
+	for key := range aggregates {
+		keys = append(keys, key)
+	}
+	// Deterministic write ordering makes failures reproducible
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].PartitionKey != keys[j].PartitionKey {
+			return keys[i].PartitionKey < keys[j].PartitionKey
+		}
+		return keys[i].SortKey < keys[j].SortKey
+	})
+
+	for _, key := range keys {
+		err := recordEntry(client, timeProvider, key, aggregates[key])
+		if err != nil {
+			return fmt.Errorf("failed to record catalog entry %s%s: %w", key.PartitionKey, key.SortKey, err)
+		}
+	}
+	return nil
+}
+
+// recordEntry counts the new machines and users of the entry, and then updates the entry. The details of the most
+// recent execution only move forward: uploads that arrive late still count, but do not replace newer details.
+func recordEntry(client dynamodb.ConditionalUpsertItemAPI, timeProvider clock.TimeProvider, key dynamodb.PrimaryKey, agg *aggregate) error {
+	newMachines, err := recordMembers(client, timeProvider, agg.entry, machineMemberPrefix, agg.machines)
+	if err != nil {
+		return err
+	}
+	newUsers, err := recordMembers(client, timeProvider, agg.entry, userMemberPrefix, agg.users)
+	if err != nil {
+		return err
+	}
+
+	notNewer := expression.Or(
+		expression.AttributeNotExists(expression.Name("LastSeen")),
+		expression.Name("LastSeen").LessThanEqual(expression.Value(agg.entry.LastSeen)),
+	)
+	_, err = client.UpsertItemWithOptions(key, buildUpdate(timeProvider, agg, newMachines, newUsers, true), dynamodb.UpsertOptions{Condition: &notNewer})
+	if dynamodb.IsConditionalCheckFailed(err) {
+		_, err = client.UpsertItem(key, buildUpdate(timeProvider, agg, newMachines, newUsers, false))
+	}
+	return err
+}
+
+const (
+	machineMemberPrefix = "Machine#"
+	userMemberPrefix    = "User#"
+)
+
+// recordMembers records each of the machines or users of an entry in a row of its own, and returns how many of them
+// the entry has not seen before. Member rows expire like entries do, so a machine that comes back after not executing
+// the entry for catalogExpiresAfterInDays counts again.
+func recordMembers(client dynamodb.ConditionalUpsertItemAPI, timeProvider clock.TimeProvider, entry CatalogEntry, prefix string, members map[string]bool) (added int64, err error) {
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := dynamodb.PrimaryKey{
+			PartitionKey: catalogMemberPK(entry.EntryType, entry.Identifier),
+			SortKey:      prefix + name,
+		}
+		update := expression.
+			Set(expression.Name("DataType"), expression.Value(types.DataTypeCatalogMember)).
+			Set(expression.Name("ExpiresAfter"), expression.Value(GetCatalogExpiresAfter(timeProvider)))
+
+		var output *awsdynamodb.UpdateItemOutput
+		output, err = client.UpsertItemWithOptions(key, update, dynamodb.UpsertOptions{ReturnUpdatedOld: true})
+		if err != nil {
+			err = fmt.Errorf("failed to record catalog member %s: %w", key.SortKey, err)
+			return
+		}
+		if _, existed := output.Attributes["ExpiresAfter"]; !existed {
+			added++
+		}
+	}
+	return
+}
+
+func aggregateObservations(observations []Observation) map[dynamodb.PrimaryKey]*aggregate {
+	aggregates := make(map[dynamodb.PrimaryKey]*aggregate)
+
+	add := func(entryType EntryType, identifier string, observation Observation) {
+		if identifier == "" {
+			return
+		}
+		sortKey := catalogSK(entryType, identifier)
+		key := dynamodb.PrimaryKey{
+			PartitionKey: catalogPK(entryType, sortKey),
+			SortKey:      sortKey,
+		}
+		agg, ok := aggregates[key]
+		if !ok {
+			agg = &aggregate{
+				entry: CatalogEntry{
+					EntryType:  entryType,
+					Identifier: key.SortKey,
+				},
+				machines:  make(map[string]bool),
+				users:     make(map[string]bool),
+				decisions: make(map[string]int64),
+			}
+			aggregates[key] = agg
+		}
+
+		agg.entry.Executions++
+		if observation.MachineID != "" {
+			agg.machines[observation.MachineID] = true
+		}
+		if observation.ExecutingUser != "" {
+			agg.users[observation.ExecutingUser] = true
+		}
+		if observation.Decision != "" {
+			agg.decisions[observation.Decision]++
+		}
+
+		if agg.entry.FirstSeen == "" || observation.ExecutedAt < agg.entry.FirstSeen {
+			agg.entry.FirstSeen = observation.ExecutedAt
+		}
+		if observation.ExecutedAt >= agg.entry.LastSeen {
+			agg.entry.LastSeen = observation.ExecutedAt
+			agg.entry.FileName = observation.FileName
+			agg.entry.FilePath = observation.FilePath
+			agg.entry.SigningID = observation.SigningID
+			agg.entry.TeamID = observation.TeamID
+			agg.entry.CertificateSHA256 = observation.CertificateSHA256
+			agg.entry.CertificateCN = observation.CertificateCN
+			agg.entry.BundleID = observation.BundleID
+			agg.entry.BundleName = observation.BundleName
+			agg.entry.BundleVersion = observation.BundleVersion
+		}
+	}
+
+	for _, observation := range observations {
+		add(EntryTypeBinary, observation.FileSHA256, observation)
+		add(EntryTypeSigningID, observation.SigningID, observation)
+		add(EntryTypeTeamID, observation.TeamID, observation)
+		add(EntryTypeCertificate, observation.CertificateSHA256, observation)
+	}
+
+	return aggregates
+}
+
+// buildUpdate adds the aggregate to the entry. withLatest also sets LastSeen and the details of the most recent
+// execution, which must only be done when the aggregate is not older than the entry.
+func buildUpdate(timeProvider clock.TimeProvider, agg *aggregate, newMachines int64, newUsers int64, withLatest bool) expression.UpdateBuilder {
+	entry := agg.entry
+
+	update := expression.
+		Set(expression.Name("EntryType"), expression.Value(entry.EntryType)).
+		Set(expression.Name("Identifier"), expression.Value(entry.Identifier)).
+		Set(expression.Name("DataType"), expression.Value(GetDataType())).
+		Set(expression.Name("ExpiresAfter"), expression.Value(GetCatalogExpiresAfter(timeProvider))).
+		Set(expression.Name("FirstSeen"), expression.IfNotExists(expression.Name("FirstSeen"), expression.Value(entry.FirstSeen))).
+		Add(expression.Name("Executions"), expression.Value(entry.Executions)).
+		Add(expression.Name("DistinctMachines"), expression.Value(newMachines)).
+		Add(expression.Name("DistinctUsers"), expression.Value(newUsers))
+
+	for decision, count := range agg.decisions {
+		update = update.Add(expression.Name(decisionAttributePrefix+decision), expression.Value(count))
+	}
+
+	if !withLatest {
+		return update
+	}
+
+	update = update.Set(expression.Name("LastSeen"), expression.Value(entry.LastSeen))
+
+	details := map[string]string{
+		"FileName":          entry.FileName,
+		"FilePath":          entry.FilePath,
+		"SigningID":         entry.SigningID,
+		"TeamID":            entry.TeamID,
+		"CertificateSHA256": entry.CertificateSHA256,
+		"CertificateCN":     entry.CertificateCN,
+		"BundleID":          entry.BundleID,
+		"BundleName":        entry.BundleName,
+		"BundleVersion":     entry.BundleVersion,
+	}
+	for name, value := range details {
+		if value != "" {
+			update = update.Set(expression.Name(name), expression.Value(value))
+		}
+	}
+
+	return update
+}
@@ -0,0 +1,243 @@
+package catalog
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockUpsertItem func(key dynamodb.PrimaryKey, update expression.UpdateBuilder, options dynamodb.UpsertOptions) (*awsdynamodb.UpdateItemOutput, error)
+
+func (m mockUpsertItem) UpsertItem(key dynamodb.PrimaryKey, update expression.UpdateBuilder) (*awsdynamodb.UpdateItemOutput, error) {
+	return m(key, update, dynamodb.UpsertOptions{})
+}
+
+func (m mockUpsertItem) UpsertItemWithOptions(key dynamodb.PrimaryKey, update expression.UpdateBuilder, options dynamodb.UpsertOptions) (*awsdynamodb.UpdateItemOutput, error) {
+	return m(key, update, options)
+}
+
+func entryKey(entryType EntryType, identifier string) dynamodb.PrimaryKey {
+	return dynamodb.PrimaryKey{PartitionKey: catalogPK(entryType, identifier), SortKey: identifier}
+}
+
+var testObservations = []Observation{
+	{
+		MachineID:         "AAAAAAAA-A00A-1234-1234-5864377B4831",
+		ExecutingUser:     "john_doe",
+		Decision:          "ALLOW_UNKNOWN",
+		ExecutedAt:        "2021-04-29T20:49:00Z",
+		FileSHA256:        "35DE834C7F280DF703F57FF75B3486B9A04D73C0DF96F9F6968DB15FA86B8962",
+		FileName:          "LauncherApplication",
+		TeamID:            "FNN8Z5JMFP",
+		CertificateSHA256: "0000000b28b738354c43a11486651ca33266e2b7454477d6b351df09c2e97faf",
+	},
+	{
+		MachineID:     "BBBBBBBB-A00A-1234-1234-5864377B4831",
+		ExecutingUser: "jane_doe",
+		Decision:      "BLOCK_UNKNOWN",
+		ExecutedAt:    "2021-04-28T20:49:00Z",
+		FileSHA256:    "35de834c7f280df703f57ff75b3486b9a04d73c0df96f9f6968db15fa86b8962",
+		FileName:      "LauncherApplication",
+		TeamID:        "FNN8Z5JMFP",
+	},
+}
+
+func Test_AggregateObservations(t *testing.T) {
+	aggregates := aggregateObservations(testObservations)
+
+	// One binary, one team ID, one certificate
+	assert.Len(t, aggregates, 3)
+
+	binary := aggregates[entryKey(EntryTypeBinary, "35de834c7f280df703f57ff75b3486b9a04d73c0df96f9f6968db15fa86b8962")]
+	assert.NotNil(t, binary)
+	assert.Equal(t, int64(2), binary.entry.Executions)
+	assert.Len(t, binary.machines, 2)
+	assert.Len(t, binary.users, 2)
+	assert.Equal(t, int64(1), binary.decisions["BLOCK_UNKNOWN"])
+	assert.Equal(t, "2021-04-28T20:49:00Z", binary.entry.FirstSeen)
+	assert.Equal(t, "2021-04-29T20:49:00Z", binary.entry.LastSeen)
+
+	cert := aggregates[entryKey(EntryTypeCertificate, "0000000b28b738354c43a11486651ca33266e2b7454477d6b351df09c2e97faf")]
+	assert.NotNil(t, cert)
+	assert.Equal(t, int64(1), cert.entry.Executions)
+}
+
+func Test_RecordObservations(t *testing.T) {
+	var keys []dynamodb.PrimaryKey
+	members := map[dynamodb.PrimaryKey]bool{}
+	client := mockUpsertItem(func(key dynamodb.PrimaryKey, update expression.UpdateBuilder, options dynamodb.UpsertOptions) (*awsdynamodb.UpdateItemOutput, error) {
+		expr, err := expression.NewBuilder().WithUpdate(update).Build()
+		assert.Empty(t, err)
+
+		if strings.HasPrefix(key.PartitionKey, catalogMemberPKPrefix) {
+			assert.True(t, options.ReturnUpdatedOld)
+			output := &awsdynamodb.UpdateItemOutput{}
+			if members[key] {
+				output.Attributes = map[string]awstypes.AttributeValue{"ExpiresAfter": &awstypes.AttributeValueMemberN{Value: "1"}}
+			}
+			members[key] = true
+			return output, nil
+		}
+
+		keys = append(keys, key)
+		assert.NotNil(t, options.Condition)
+		assert.Contains(t, *expr.Update(), "ADD")
+		assert.Contains(t, expr.Values(), ":0")
+
+		return &awsdynamodb.UpdateItemOutput{}, nil
+	})
+
+	err := RecordObservations(client, clock.Y2K{}, testObservations)
+
+	assert.Empty(t, err)
+	assert.Equal(t, []dynamodb.PrimaryKey{
+		entryKey(EntryTypeBinary, "35de834c7f280df703f57ff75b3486b9a04d73c0df96f9f6968db15fa86b8962"),
+		entryKey(EntryTypeCertificate, "0000000b28b738354c43a11486651ca33266e2b7454477d6b351df09c2e97faf"),
+		entryKey(EntryTypeTeamID, "FNN8Z5JMFP"),
+	}, keys)
+	// Two machines and two users of the binary and team ID, one of each for the certificate
+	assert.Len(t, members, 10)
+	assert.Contains(t, members, dynamodb.PrimaryKey{
+		PartitionKey: "CatalogMember#TeamID#FNN8Z5JMFP",
+		SortKey:      "Machine#BBBBBBBB-A00A-1234-1234-5864377B4831",
+	})
+}
+
+func Test_RecordEntry_CountsNewMembers(t *testing.T) {
+	agg := aggregateObservations(testObservations)[entryKey(EntryTypeTeamID, "FNN8Z5JMFP")]
+
+	var counts []map[string]string
+	client := mockUpsertItem(func(key dynamodb.PrimaryKey, update expression.UpdateBuilder, options dynamodb.UpsertOptions) (*awsdynamodb.UpdateItemOutput, error) {
+		if strings.HasPrefix(key.PartitionKey, catalogMemberPKPrefix) {
+			// Only jane_doe's machine has executed the entry before
+			if key.SortKey == "Machine#BBBBBBBB-A00A-1234-1234-5864377B4831" {
+				return &awsdynamodb.UpdateItemOutput{Attributes: map[string]awstypes.AttributeValue{
+					"ExpiresAfter": &awstypes.AttributeValueMemberN{Value: "1"},
+				}}, nil
+			}
+			return &awsdynamodb.UpdateItemOutput{}, nil
+		}
+
+		expr, err := expression.NewBuilder().WithUpdate(update).Build()
+		assert.Empty(t, err)
+		counts = append(counts, addedCounts(expr))
+		return &awsdynamodb.UpdateItemOutput{}, nil
+	})
+
+	err := recordEntry(client, clock.Y2K{}, entryKey(EntryTypeTeamID, "FNN8Z5JMFP"), agg)
+
+	assert.Empty(t, err)
+	assert.Equal(t, []map[string]string{{"DistinctMachines": "1", "DistinctUsers": "2"}}, counts)
+}
+
+func Test_RecordEntry_OlderThanEntry(t *testing.T) {
+	agg := aggregateObservations(testObservations)[entryKey(EntryTypeTeamID, "FNN8Z5JMFP")]
+
+	var updates []string
+	client := mockUpsertItem(func(key dynamodb.PrimaryKey, update expression.UpdateBuilder, options dynamodb.UpsertOptions) (*awsdynamodb.UpdateItemOutput, error) {
+		if strings.HasPrefix(key.PartitionKey, catalogMemberPKPrefix) {
+			return &awsdynamodb.UpdateItemOutput{}, nil
+		}
+
+		expr, err := expression.NewBuilder().WithUpdate(update).Build()
+		assert.Empty(t, err)
+		updates = append(updates, strings.Join(sortedNames(expr), ","))
+
+		// The entry has already seen a newer execution
+		if options.Condition != nil {
+			return nil, &awstypes.ConditionalCheckFailedException{}
+		}
+		return &awsdynamodb.UpdateItemOutput{}, nil
+	})
+
+	err := recordEntry(client, clock.Y2K{}, entryKey(EntryTypeTeamID, "FNN8Z5JMFP"), agg)
+
+	assert.Empty(t, err)
+	assert.Len(t, updates, 2)
+	assert.Contains(t, updates[0], "LastSeen")
+	assert.Contains(t, updates[0], "FileName")
+	assert.NotContains(t, updates[1], "LastSeen")
+	assert.NotContains(t, updates[1], "FileName")
+	assert.Contains(t, updates[1], "Executions")
+}
+
+func Test_RecordObservations_Error(t *testing.T) {
+	client := mockUpsertItem(func(key dynamodb.PrimaryKey, update expression.UpdateBuilder, options dynamodb.UpsertOptions) (*awsdynamodb.UpdateItemOutput, error) {
+		return nil, errors.New("throttled")
+	})
+
+	err := RecordObservations(client, clock.Y2K{}, testObservations)
+
+	assert.Error(t, err)
+}
+
+// addedCounts returns the values that an update adds to the distinct counts
+func addedCounts(expr expression.Expression) map[string]string {
+	counts := map[string]string{}
+	// ADD clauses pair a name placeholder with a value placeholder, without the "=" of SET clauses
+	for _, match := range addClausePattern.FindAllStringSubmatch(*expr.Update(), -1) {
+		name := expr.Names()[match[1]]
+		if name != "DistinctMachines" && name != "DistinctUsers" {
+			continue
+		}
+		counts[name] = expr.Values()[match[2]].(*awstypes.AttributeValueMemberN).Value
+	}
+	return counts
+}
+
+var addClausePattern = regexp.MustCompile(`(#\d+) (:\d+)`)
+
+func sortedNames(expr expression.Expression) []string {
+	names := make([]string, 0, len(expr.Names()))
+	for _, name := range expr.Names() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func Test_UnmarshalCatalogEntryRow(t *testing.T) {
+	row, err := unmarshalCatalogEntryRow(map[string]awstypes.AttributeValue{
+		"PK":                     &awstypes.AttributeValueMemberS{Value: "Catalog#TeamID#03"},
+		"SK":                     &awstypes.AttributeValueMemberS{Value: "FNN8Z5JMFP"},
+		"EntryType":              &awstypes.AttributeValueMemberS{Value: "TeamID"},
+		"Identifier":             &awstypes.AttributeValueMemberS{Value: "FNN8Z5JMFP"},
+		"Executions":             &awstypes.AttributeValueMemberN{Value: "7"},
+		"DistinctMachines":       &awstypes.AttributeValueMemberN{Value: "2"},
+		"Decision#ALLOW_UNKNOWN": &awstypes.AttributeValueMemberN{Value: "4"},
+		"Decision#BLOCK_TEAMID":  &awstypes.AttributeValueMemberN{Value: "3"},
+		"DataType":               &awstypes.AttributeValueMemberS{Value: "CatalogEntry"},
+	})
+
+	assert.Empty(t, err)
+	assert.Equal(t, EntryTypeTeamID, row.EntryType)
+	assert.Equal(t, int64(7), row.Executions)
+	assert.Equal(t, 2, row.MachineCount())
+	assert.Equal(t, map[string]int64{"ALLOW_UNKNOWN": 4, "BLOCK_TEAMID": 3}, row.DecisionCounts)
+	assert.Equal(t, int64(3), row.BlockCount())
+}
+
+func Test_TopEntries(t *testing.T) {
+	entries := []CatalogEntryRow{
+		{CatalogEntry: CatalogEntry{Identifier: "a", Executions: 10, DistinctMachines: 1}},
+		{CatalogEntry: CatalogEntry{Identifier: "b", Executions: 1, DistinctMachines: 3}},
+		{CatalogEntry: CatalogEntry{Identifier: "c", Executions: 5, DistinctMachines: 2}},
+	}
+
+	top := TopEntries(entries, OrderByMachines, 2)
+	assert.Equal(t, "b", top[0].Identifier)
+	assert.Equal(t, "c", top[1].Identifier)
+
+	top = TopEntries(entries, OrderByExecutions, 0)
+	assert.Len(t, top, 3)
+	assert.Equal(t, "a", top[0].Identifier)
+}
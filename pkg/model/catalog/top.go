@@ -0,0 +1,57 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+)
+
+// OrderBy is the statistic used to rank catalog entries
+type OrderBy string
+
+const (
+	OrderByMachines   OrderBy = "machines"
+	OrderByExecutions OrderBy = "executions"
+	OrderByBlocks     OrderBy = "blocks"
+	OrderByLastSeen   OrderBy = "last_seen"
+)
+
+func ParseOrderBy(s string) (OrderBy, error) {
+	switch o := OrderBy(s); o {
+	case OrderByMachines, OrderByExecutions, OrderByBlocks, OrderByLastSeen:
+		return o, nil
+	}
+	return "", fmt.Errorf("unknown order %q (one of: machines, executions, blocks, last_seen)", s)
+}
+
+// TopEntries sorts entries in descending order of the given statistic, and returns at most [limit] of them.
+// Ties are broken by identifier to keep output stable.
+func TopEntries(entries []CatalogEntryRow, orderBy OrderBy, limit int) []CatalogEntryRow {
+	sorted := make([]CatalogEntryRow, len(entries))
+	copy(sorted, entries)
+
+	value := func(e CatalogEntryRow) int64 {
+		switch orderBy {
+		case OrderByExecutions:
+			return e.Executions
+		case OrderByBlocks:
+			return e.BlockCount()
+		}
+		return int64(e.MachineCount())
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if orderBy == OrderByLastSeen {
+			if sorted[i].LastSeen != sorted[j].LastSeen {
+				return sorted[i].LastSeen > sorted[j].LastSeen
+			}
+		} else if vi, vj := value(sorted[i]), value(sorted[j]); vi != vj {
+			return vi > vj
+		}
+		return sorted[i].Identifier < sorted[j].Identifier
+	})
+
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
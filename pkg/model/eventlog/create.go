@@ -0,0 +1,39 @@
+package eventlog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+)
+
+func CreateEventRow(timeProvider clock.TimeProvider, machineID string, event Event) EventRow {
+	event.MachineID = machineID
+	event.FileSHA256 = strings.ToLower(event.FileSHA256)
+	if event.ExecutedAt == "" {
+		event.ExecutedAt = clock.RFC3339(timeProvider.Now())
+	}
+
+	return EventRow{
+		PrimaryKey: dynamodb.PrimaryKey{
+			PartitionKey: eventsPK(machineID),
+			SortKey:      eventSK(event.ExecutedAt, event.ExecutionTime, event.FileSHA256),
+		},
+		Event:        event,
+		ExpiresAfter: GetEventExpiresAfter(timeProvider),
+		DataType:     GetDataType(),
+	}
+}
+
+// AddEvents persists every given event for the machine. It stops at the first failure.
+func AddEvents(client dynamodb.PutItemAPI, timeProvider clock.TimeProvider, machineID string, events []Event) error {
+	for _, event := range events {
+		row := CreateEventRow(timeProvider, machineID, event)
+		_, err := client.PutItem(row)
+		if err != nil {
+			return fmt.Errorf("failed to store event %q for machine %q: %w", row.SortKey, machineID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package eventlog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+var frozenTime, _ = clock.ParseRFC3339("2000-01-01T00:00:00Z")
+var timeProvider = clock.FrozenTimeProvider{
+	Current: frozenTime,
+}
+
+type mockPutItem func(item interface{}) (*awsdynamodb.PutItemOutput, error)
+
+func (m mockPutItem) PutItem(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+	return m(item)
+}
+
+func Test_CreateEventRow(t *testing.T) {
+	machineID := "AAAAAAAA-A00A-1234-1234-5864377B4831"
+	row := CreateEventRow(timeProvider, machineID, Event{
+		Decision:      "BLOCK_BINARY",
+		FileSHA256:    "2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824",
+		ExecutedAt:    ExecutionTimeToRFC3339(1577836800.123),
+		ExecutionTime: 1577836800.123,
+	})
+
+	assert.Equal(t, "MachineEvents#AAAAAAAA-A00A-1234-1234-5864377B4831", row.PartitionKey)
+	assert.Equal(t, "Event#2020-01-01T00:00:00Z#2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824#123000", row.SortKey)
+	assert.Equal(t, machineID, row.MachineID)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", row.FileSHA256)
+	assert.Equal(t, GetEventExpiresAfter(timeProvider), row.ExpiresAfter)
+	assert.Equal(t, GetDataType(), row.DataType)
+}
+
+func Test_CreateEventRow_MissingExecutionTime(t *testing.T) {
+	row := CreateEventRow(timeProvider, "AAAAAAAA-A00A-1234-1234-5864377B4831", Event{})
+
+	assert.Equal(t, "2000-01-01T00:00:00Z", row.ExecutedAt)
+}
+
+func Test_AddEvents(t *testing.T) {
+	var stored []EventRow
+	client := mockPutItem(func(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+		stored = append(stored, item.(EventRow))
+		return &awsdynamodb.PutItemOutput{}, nil
+	})
+
+	err := AddEvents(client, timeProvider, "AAAAAAAA-A00A-1234-1234-5864377B4831", []Event{
+		{FileSHA256: "a", ExecutedAt: "2020-01-01T00:00:00Z"},
+		{FileSHA256: "b", ExecutedAt: "2020-01-01T00:00:01Z"},
+	})
+
+	assert.Empty(t, err)
+	assert.Len(t, stored, 2)
+	assert.Equal(t, "Event#2020-01-01T00:00:01Z#b#000000", stored[1].SortKey)
+}
+
+func Test_CreateEventRow_SameSecond(t *testing.T) {
+	machineID := "AAAAAAAA-A00A-1234-1234-5864377B4831"
+	first := CreateEventRow(timeProvider, machineID, Event{FileSHA256: "a", ExecutedAt: ExecutionTimeToRFC3339(1577836800.25), ExecutionTime: 1577836800.25})
+	second := CreateEventRow(timeProvider, machineID, Event{FileSHA256: "a", ExecutedAt: ExecutionTimeToRFC3339(1577836800.75), ExecutionTime: 1577836800.75})
+	again := CreateEventRow(timeProvider, machineID, Event{FileSHA256: "a", ExecutedAt: ExecutionTimeToRFC3339(1577836800.25), ExecutionTime: 1577836800.25})
+
+	assert.Equal(t, first.ExecutedAt, second.ExecutedAt)
+	assert.NotEqual(t, first.SortKey, second.SortKey)
+	// Uploading the same event again overwrites it
+	assert.Equal(t, first.SortKey, again.SortKey)
+}
+
+func Test_EventRow_OmitsEmptyFileSHA256(t *testing.T) {
+	row := CreateEventRow(timeProvider, "AAAAAAAA-A00A-1234-1234-5864377B4831", Event{Decision: "BLOCK_UNKNOWN"})
+
+	item, err := attributevalue.MarshalMap(row)
+
+	assert.Empty(t, err)
+	// An empty key of the FileSHA256_ExecutedAt index would be rejected; leaving it out keeps the index sparse
+	assert.NotContains(t, item, "FileSHA256")
+}
+
+func Test_AddEvents_Error(t *testing.T) {
+	client := mockPutItem(func(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+		return nil, errors.New("boom")
+	})
+
+	err := AddEvents(client, timeProvider, "AAAAAAAA-A00A-1234-1234-5864377B4831", []Event{{FileSHA256: "a"}})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package eventlog
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+const (
+	eventsPKPrefix              = "MachineEvents#"
+	eventSKPrefix               = "Event#"
+	eventsExpiresAfterInDays    = 30
+	FileSHA256_ExecutedAt_GSI   = "FileSHA256_ExecutedAt"
+	defaultEventsQueryPageLimit = 100
+)
+
+// EventRow is a single event uploaded by a Santa sensor, as persisted to the database.
+//
+// Events are partitioned by machine, and sorted by the time that the binary was executed. The
+// FileSHA256_ExecutedAt GSI allows the same events to be looked up by the hash of the binary instead.
+type EventRow struct {
+	dynamodb.PrimaryKey
+	Event
+	ExpiresAfter int64          `dynamodbav:"ExpiresAfter,omitempty"`
+	DataType     types.DataType `dynamodbav:"DataType"`
+}
+
+// Event is the normalized subset of an uploaded event that Rudolph keeps around for investigations.
+type Event struct {
+	MachineID     string               `dynamodbav:"MachineID" json:"machine_id"`
+	Decision      string               `dynamodbav:"Decision" json:"decision"`
+	FileSHA256    string               `dynamodbav:"FileSHA256,omitempty" json:"file_sha256"`
+	FilePath      string               `dynamodbav:"FilePath" json:"file_path"`
+	FileName      string               `dynamodbav:"FileName" json:"file_name"`
+	ExecutingUser string               `dynamodbav:"ExecutingUser" json:"executing_user"`
+	ExecutedAt    string               `dynamodbav:"ExecutedAt" json:"executed_at"`
+	SigningID     string               `dynamodbav:"SigningID,omitempty" json:"signing_id,omitempty"`
+	TeamID        string               `dynamodbav:"TeamID,omitempty" json:"team_id,omitempty"`
+	CDHash        string               `dynamodbav:"CDHash,omitempty" json:"cdhash,omitempty"`
+	BundleID      string               `dynamodbav:"BundleID,omitempty" json:"bundle_id,omitempty"`
+	ParentName    string               `dynamodbav:"ParentName,omitempty" json:"parent_name,omitempty"`
+	SigningChain  []SigningCertificate `dynamodbav:"SigningChain,omitempty" json:"signing_chain,omitempty"`
+
+	// ExecutionTime is the fractional unix timestamp that Santa reported, if any; ExecutedAt is truncated to seconds
+	ExecutionTime float64 `dynamodbav:"ExecutionTime,omitempty" json:"execution_time,omitempty"`
+}
+
+// SigningCertificate is a single entry in the signing chain of an event's binary
+type SigningCertificate struct {
+	SHA256             string `dynamodbav:"SHA256" json:"sha256"`
+	CommonName         string `dynamodbav:"CommonName" json:"cn"`
+	Organization       string `dynamodbav:"Organization,omitempty" json:"org,omitempty"`
+	OrganizationalUnit string `dynamodbav:"OrganizationalUnit,omitempty" json:"ou,omitempty"`
+	ValidFrom          int    `dynamodbav:"ValidFrom,omitempty" json:"valid_from,omitempty"`
+	ValidUntil         int    `dynamodbav:"ValidUntil,omitempty" json:"valid_until,omitempty"`
+}
+
+func eventsPK(machineID string) string {
+	return fmt.Sprintf("%s%s", eventsPKPrefix, machineID)
+}
+
+// eventSK orders events chronologically within a machine's partition. The hash and the microseconds of the execution
+// time disambiguate events that execute within the same second, while an event that is uploaded again keeps its key.
+func eventSK(executedAt string, executionTime float64, fileSHA256 string) string {
+	micros := int64(math.Min(math.Round((executionTime-math.Floor(executionTime))*1e6), 999999))
+	return fmt.Sprintf("%s%s#%s#%06d", eventSKPrefix, executedAt, strings.ToLower(fileSHA256), micros)
+}
+
+// ExecutionTimeToRFC3339 converts the fractional unix timestamp reported by Santa into the format stored in ExecutedAt
+func ExecutionTimeToRFC3339(executionTime float64) string {
+	seconds := int64(executionTime)
+	return clock.RFC3339(time.Unix(seconds, 0))
+}
+
+func GetEventExpiresAfter(timeProvider clock.TimeProvider) int64 {
+	return clock.Unixtimestamp(timeProvider.Now().UTC().AddDate(0, 0, eventsExpiresAfterInDays))
+}
+
+func GetDataType() types.DataType {
+	return types.DataTypeEvent
+}
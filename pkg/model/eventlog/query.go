@@ -0,0 +1,103 @@
+package eventlog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// GetEventsByMachineID returns up to [limit] events uploaded by the given machine that executed at or after [since],
+// newest first.
+func GetEventsByMachineID(client dynamodb.QueryAPI, machineID string, since time.Time, limit int) (items []EventRow, err error) {
+	keyCond := expression.KeyAnd(
+		expression.Key("PK").Equal(expression.Value(eventsPK(machineID))),
+		expression.Key("SK").GreaterThanEqual(expression.Value(fmt.Sprintf("%s%s", eventSKPrefix, clock.RFC3339(since)))),
+	)
+
+	items, err = queryEvents(client, keyCond, nil, limit)
+	if err != nil {
+		err = fmt.Errorf("failed to query events for machine %q: %w", machineID, err)
+	}
+	return
+}
+
+// GetAllEventsByMachineID returns every event uploaded by the given machine that executed at or after [since], newest
+// first, paging through as many queries as that takes.
+func GetAllEventsByMachineID(client dynamodb.QueryAPI, machineID string, since time.Time) ([]EventRow, error) {
+	return GetEventsByMachineID(client, machineID, since, math.MaxInt)
+}
+
+// GetEventsByFileSHA256 returns up to [limit] events, across all machines, for the binary with the given hash that
+// executed at or after [since], newest first.
+func GetEventsByFileSHA256(client dynamodb.QueryAPI, fileSHA256 string, since time.Time, limit int) (items []EventRow, err error) {
+	keyCond := expression.KeyAnd(
+		expression.Key("FileSHA256").Equal(expression.Value(strings.ToLower(fileSHA256))),
+		expression.Key("ExecutedAt").GreaterThanEqual(expression.Value(clock.RFC3339(since))),
+	)
+
+	items, err = queryEvents(client, keyCond, aws.String(FileSHA256_ExecutedAt_GSI), limit)
+	if err != nil {
+		err = fmt.Errorf("failed to query events for sha256 %q: %w", fileSHA256, err)
+	}
+	return
+}
+
+func queryEvents(client dynamodb.QueryAPI, keyCond expression.KeyConditionBuilder, indexName *string, limit int) (items []EventRow, err error) {
+	if limit <= 0 {
+		err = errors.New("invalid limit specified")
+		return
+	}
+
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		return
+	}
+
+	var exclusiveStartKey map[string]types.AttributeValue
+	for {
+		pageLimit := limit - len(items)
+		if pageLimit > defaultEventsQueryPageLimit {
+			pageLimit = defaultEventsQueryPageLimit
+		}
+
+		input := &awsdynamodb.QueryInput{
+			ConsistentRead:            aws.Bool(false),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			KeyConditionExpression:    expr.KeyCondition(),
+			IndexName:                 indexName,
+			ExclusiveStartKey:         exclusiveStartKey,
+			Limit:                     aws.Int32(int32(pageLimit)),
+			ScanIndexForward:          aws.Bool(false),
+		}
+
+		var result *awsdynamodb.QueryOutput
+		result, err = client.Query(input)
+		if err != nil {
+			return
+		}
+
+		var page []EventRow
+		err = attributevalue.UnmarshalListOfMaps(result.Items, &page)
+		if err != nil {
+			err = fmt.Errorf("failed to unmarshal result from DynamoDB: %w", err)
+			return
+		}
+		items = append(items, page...)
+
+		if len(result.LastEvaluatedKey) == 0 || len(items) >= limit {
+			return
+		}
+		exclusiveStartKey = result.LastEvaluatedKey
+	}
+}
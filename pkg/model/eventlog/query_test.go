@@ -0,0 +1,102 @@
+package eventlog
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockQuery func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error)
+
+func (m mockQuery) Query(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+	return m(input)
+}
+
+func Test_GetEventsByMachineID_Paginates(t *testing.T) {
+	machineID := "AAAAAAAA-A00A-1234-1234-5864377B4831"
+	calls := 0
+
+	client := mockQuery(func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+		calls++
+		assert.Nil(t, input.IndexName)
+		assert.False(t, *input.ScanIndexForward)
+
+		row := CreateEventRow(timeProvider, machineID, Event{FileSHA256: "a"})
+		item, err := attributevalue.MarshalMap(row)
+		if err != nil {
+			return nil, err
+		}
+
+		output := &awsdynamodb.QueryOutput{Items: []map[string]awstypes.AttributeValue{item}}
+		if calls == 1 {
+			output.LastEvaluatedKey = map[string]awstypes.AttributeValue{
+				"PK": &awstypes.AttributeValueMemberS{Value: row.PartitionKey},
+				"SK": &awstypes.AttributeValueMemberS{Value: row.SortKey},
+			}
+		} else {
+			assert.NotNil(t, input.ExclusiveStartKey)
+		}
+		return output, nil
+	})
+
+	items, err := GetEventsByMachineID(client, machineID, frozenTime, 10)
+
+	assert.Empty(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Len(t, items, 2)
+	assert.Equal(t, machineID, items[0].MachineID)
+}
+
+func Test_GetEventsByFileSHA256_UsesIndex(t *testing.T) {
+	client := mockQuery(func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+		assert.Equal(t, FileSHA256_ExecutedAt_GSI, *input.IndexName)
+		assert.Equal(t, int32(5), *input.Limit)
+		return &awsdynamodb.QueryOutput{}, nil
+	})
+
+	items, err := GetEventsByFileSHA256(client, "ABC", frozenTime, 5)
+
+	assert.Empty(t, err)
+	assert.Empty(t, items)
+}
+
+func Test_GetEventsByMachineID_InvalidLimit(t *testing.T) {
+	_, err := GetEventsByMachineID(mockQuery(nil), "AAAAAAAA-A00A-1234-1234-5864377B4831", frozenTime, 0)
+
+	assert.Error(t, err)
+}
+
+func Test_GetAllEventsByMachineID_ReadsEveryPage(t *testing.T) {
+	machineID := "AAAAAAAA-A00A-1234-1234-5864377B4831"
+	calls := 0
+
+	client := mockQuery(func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+		calls++
+		assert.Equal(t, int32(defaultEventsQueryPageLimit), *input.Limit)
+
+		row := CreateEventRow(timeProvider, machineID, Event{FileSHA256: "a"})
+		item, err := attributevalue.MarshalMap(row)
+		if err != nil {
+			return nil, err
+		}
+
+		// Keep returning pages well past any fixed cap
+		output := &awsdynamodb.QueryOutput{Items: []map[string]awstypes.AttributeValue{item}}
+		if calls < 20 {
+			output.LastEvaluatedKey = map[string]awstypes.AttributeValue{
+				"PK": &awstypes.AttributeValueMemberS{Value: row.PartitionKey},
+				"SK": &awstypes.AttributeValueMemberS{Value: row.SortKey},
+			}
+		}
+		return output, nil
+	})
+
+	items, err := GetAllEventsByMachineID(client, machineID, frozenTime)
+
+	assert.Empty(t, err)
+	assert.Equal(t, 20, calls)
+	assert.Len(t, items, 20)
+}
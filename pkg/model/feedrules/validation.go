@@ -28,6 +28,8 @@ func (f *FeedRuleRow) feedRuleRowValidation() (bool, error) {
 		validRuleIdentifier = rules.ValidTeamID(f.Identifier)
 	case types.RuleTypeSigningID:
 		validRuleIdentifier = rules.ValidSigningID(f.Identifier)
+	case types.RuleTypeCDHash:
+		validRuleIdentifier = rules.ValidCDHash(f.Identifier)
 	}
 
 	if !validRuleIdentifier {
@@ -18,18 +18,37 @@ func AddNewGlobalRule(
 	ruleType types.RuleType,
 	policy types.Policy,
 	description string,
+) error {
+	return PutManagedGlobalRule(
+		time,
+		client,
+		rules.SantaRule{
+			RuleType:   ruleType,
+			Policy:     policy,
+			Identifier: identifier,
+		},
+		description,
+		"",
+	)
+}
+
+// PutManagedGlobalRule creates or replaces a global rule, along with its custom message, and tags it with the source
+// that owns it. The rule is also added to the feed, so that clients pick it up without a clean sync.
+func PutManagedGlobalRule(
+	time clock.TimeProvider,
+	client dynamodb.TransactWriteItemsAPI,
+	santaRule rules.SantaRule,
+	description string,
+	managedBy string,
 ) error {
 	rule := &GlobalRuleRow{
 		PrimaryKey: dynamodb.PrimaryKey{
 			PartitionKey: globalRulesPK,
-			SortKey:      globalRulesSK(identifier, ruleType),
+			SortKey:      globalRulesSK(santaRule.Identifier, santaRule.RuleType),
 		},
 		Description: description,
-		SantaRule: rules.SantaRule{
-			RuleType:   ruleType,
-			Policy:     policy,
-			Identifier: identifier,
-		},
+		SantaRule:   santaRule,
+		ManagedBy:   managedBy,
 	}
 
 	// Input Validation
@@ -14,6 +14,9 @@ type GlobalRuleRow struct {
 	dynamodb.PrimaryKey
 	rules.SantaRule
 	Description string `dynamodbav:"Description,omitempty"`
+	// ManagedBy tags rules that are owned by a declarative rules file; see PutManagedGlobalRule.
+	// It is empty for rules that were created by hand.
+	ManagedBy string `dynamodbav:"ManagedBy,omitempty"`
 }
 
 type updateRulePolicyRequest struct {
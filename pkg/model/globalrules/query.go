@@ -90,3 +90,44 @@ func GetPaginatedGlobalRules(
 	}
 	return
 }
+
+// ListGlobalRules pages through and returns every global rule
+func ListGlobalRules(client dynamodb.QueryAPI) (items []GlobalRuleRow, err error) {
+	var key *dynamodb.PrimaryKey
+	for {
+		page, nextKey, inerr := GetPaginatedGlobalRules(client, 100, key)
+		if inerr != nil {
+			err = inerr
+			return
+		}
+
+		for _, item := range page {
+			items = append(items, *item)
+		}
+
+		if nextKey == nil || len(page) == 0 {
+			return
+		}
+		key = nextKey
+	}
+}
+
+// ListGlobalRulesPage returns up to limit global rules in rule name order, starting after the rule named
+// afterSortKey, along with the name to pass as afterSortKey for the next page. The next name is empty on the last page.
+func ListGlobalRulesPage(client dynamodb.QueryAPI, limit int, afterSortKey string) (items []GlobalRuleRow, nextSortKey string, err error) {
+	var startKey *dynamodb.PrimaryKey
+	if afterSortKey != "" {
+		startKey = &dynamodb.PrimaryKey{PartitionKey: globalRulesPK, SortKey: afterSortKey}
+	}
+	page, lastKey, err := GetPaginatedGlobalRules(client, limit, startKey)
+	if err != nil {
+		return
+	}
+	for _, item := range page {
+		items = append(items, *item)
+	}
+	if lastKey != nil && len(page) > 0 {
+		nextSortKey = lastKey.SortKey
+	}
+	return
+}
@@ -117,3 +117,32 @@ func TestGetPaginatedGlobalRules(t *testing.T) {
 		assert.Equal(t, rules[i].Policy, globalRule.SantaRule.Policy)
 	}
 }
+
+func TestListGlobalRulesPage(t *testing.T) {
+	mockQueryClient := mockQuery(
+		func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+			assert.Equal(t, int32(1), *input.Limit)
+			assert.Equal(t, &awstypes.AttributeValueMemberS{Value: "TeamID#AAAAAAAAAA"}, input.ExclusiveStartKey["SK"])
+			return &awsdynamodb.QueryOutput{
+				Items: []map[string]awstypes.AttributeValue{
+					{
+						"PK":         &awstypes.AttributeValueMemberS{Value: globalRulesPK},
+						"SK":         &awstypes.AttributeValueMemberS{Value: "TeamID#EQHXZ8M8AV"},
+						"Identifier": &awstypes.AttributeValueMemberS{Value: "EQHXZ8M8AV"},
+					},
+				},
+				LastEvaluatedKey: map[string]awstypes.AttributeValue{
+					"PK": &awstypes.AttributeValueMemberS{Value: globalRulesPK},
+					"SK": &awstypes.AttributeValueMemberS{Value: "TeamID#EQHXZ8M8AV"},
+				},
+			}, nil
+		},
+	)
+
+	items, next, err := ListGlobalRulesPage(mockQueryClient, 1, "TeamID#AAAAAAAAAA")
+
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "EQHXZ8M8AV", items[0].Identifier)
+	assert.Equal(t, "TeamID#EQHXZ8M8AV", next)
+}
@@ -1,6 +1,8 @@
 package globalrules
 
 import (
+	"fmt"
+
 	"github.com/airbnb/rudolph/pkg/model/rules"
 	"github.com/airbnb/rudolph/pkg/types"
 )
@@ -28,6 +30,8 @@ func (g *GlobalRuleRow) globalRuleValidation() (bool, error) {
 		validRuleIdentifier = rules.ValidTeamID(g.Identifier)
 	case types.RuleTypeSigningID:
 		validRuleIdentifier = rules.ValidSigningID(g.Identifier)
+	case types.RuleTypeCDHash:
+		validRuleIdentifier = rules.ValidCDHash(g.Identifier)
 	}
 
 	if !validRuleIdentifier {
@@ -37,3 +41,17 @@ func (g *GlobalRuleRow) globalRuleValidation() (bool, error) {
 	// All validations have passed
 	return true, nil
 }
+
+// ValidateRule checks that a rule could be stored as a global rule
+func ValidateRule(rule rules.SantaRule) error {
+	row := GlobalRuleRow{SantaRule: rule}
+	isValid, err := row.globalRuleValidation()
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		ruleType, _ := rule.RuleType.MarshalText()
+		return fmt.Errorf("%q is not a valid %s identifier", rule.Identifier, ruleType)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package globalrules
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/feedrules"
+	"github.com/airbnb/rudolph/pkg/types"
+	awsdynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// BatchWriteAPI is what WriteGlobalRules needs from the DynamoDB client
+type BatchWriteAPI interface {
+	dynamodb.TransactWriteItemsAPI
+	dynamodb.TransactWriteItemGroupsAPI
+}
+
+// WriteGlobalRules creates, replaces or removes many global rules at once. Rules with the REMOVE policy delete the
+// existing rule; every other rule is put like PutManagedGlobalRule does, with its Description and ManagedBy.
+//
+// Each rule is written atomically with its feed entry, and the rules are packed into as few transactions as possible.
+// Rules are written in order, and written counts the rules that were written before an error occurred. A single call
+// must not hold the same rule twice.
+func WriteGlobalRules(time clock.TimeProvider, client BatchWriteAPI, rows []GlobalRuleRow) (written int, err error) {
+	groups := make([][]awsdynamodbtypes.TransactWriteItem, 0, len(rows))
+	for _, row := range rows {
+		row.PrimaryKey = dynamodb.PrimaryKey{
+			PartitionKey: globalRulesPK,
+			SortKey:      globalRulesSK(row.Identifier, row.RuleType),
+		}
+
+		group, inerr := globalRuleWriteItems(time, client, row)
+		if inerr != nil {
+			err = fmt.Errorf("rule %s: %w", row.SortKey, inerr)
+			return
+		}
+		groups = append(groups, group)
+	}
+
+	return client.TransactWriteItemGroups(groups)
+}
+
+func globalRuleWriteItems(time clock.TimeProvider, client dynamodb.TransactWriteItemsAPI, row GlobalRuleRow) ([]awsdynamodbtypes.TransactWriteItem, error) {
+	if row.Policy == types.RulePolicyRemove {
+		deleteItem, err := client.CreateTransactDeleteItem(row.PrimaryKey)
+		if err != nil {
+			return nil, err
+		}
+
+		// Like RemoveGlobalRule, tell non-clean syncs to remove the rule through the feed
+		feedRule := feedrules.ConstructFeedRuleFromBaseRule(time, row.SantaRule)
+		if feedRule == nil {
+			return nil, fmt.Errorf("%q is not a valid rule identifier", row.Identifier)
+		}
+		putFeedItem, err := client.CreateTransactPutItem(feedRule)
+		if err != nil {
+			return nil, err
+		}
+		return []awsdynamodbtypes.TransactWriteItem{*deleteItem, *putFeedItem}, nil
+	}
+
+	if err := ValidateRule(row.SantaRule); err != nil {
+		return nil, err
+	}
+
+	putItem, err := client.CreateTransactPutItem(row)
+	if err != nil {
+		return nil, err
+	}
+	putFeedItem, err := client.CreateTransactPutItem(feedrules.ConstructFeedRuleFromBaseRule(time, row.SantaRule))
+	if err != nil {
+		return nil, err
+	}
+	return []awsdynamodbtypes.TransactWriteItem{*putItem, *putFeedItem}, nil
+}
@@ -0,0 +1,84 @@
+package globalrules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	awsdynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBatchWriter struct {
+	dynamodb.TransactWriteItemsAPI
+	groups [][]awsdynamodbtypes.TransactWriteItem
+}
+
+func (m *mockBatchWriter) CreateTransactPutItem(item interface{}) (*awsdynamodbtypes.TransactWriteItem, error) {
+	putItem, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return nil, err
+	}
+	return &awsdynamodbtypes.TransactWriteItem{Put: &awsdynamodbtypes.Put{Item: putItem}}, nil
+}
+
+func (m *mockBatchWriter) CreateTransactDeleteItem(key dynamodb.PrimaryKey) (*awsdynamodbtypes.TransactWriteItem, error) {
+	keyInput, err := attributevalue.MarshalMap(key)
+	if err != nil {
+		return nil, err
+	}
+	return &awsdynamodbtypes.TransactWriteItem{Delete: &awsdynamodbtypes.Delete{Key: keyInput}}, nil
+}
+
+func (m *mockBatchWriter) TransactWriteItemGroups(groups [][]awsdynamodbtypes.TransactWriteItem) (int, error) {
+	m.groups = groups
+	return len(groups), nil
+}
+
+func Test_WriteGlobalRules(t *testing.T) {
+	timeProvider := clock.FrozenTimeProvider{Current: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+	client := &mockBatchWriter{}
+
+	written, err := WriteGlobalRules(timeProvider, client, []GlobalRuleRow{
+		{
+			SantaRule:   rules.SantaRule{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV"},
+			Description: "Google",
+			ManagedBy:   "git:santa-rules",
+		},
+		{
+			SantaRule: rules.SantaRule{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyRemove, Identifier: "BJ4HAAB9B3"},
+		},
+	})
+
+	assert.Empty(t, err)
+	assert.Equal(t, 2, written)
+	assert.Len(t, client.groups, 2)
+
+	var row GlobalRuleRow
+	assert.Empty(t, attributevalue.UnmarshalMap(client.groups[0][0].Put.Item, &row))
+	assert.Equal(t, "GlobalRules", row.PartitionKey)
+	assert.Equal(t, "TeamID#EQHXZ8M8AV", row.SortKey)
+	assert.Equal(t, "git:santa-rules", row.ManagedBy)
+	assert.NotNil(t, client.groups[0][1].Put)
+
+	var key dynamodb.PrimaryKey
+	assert.Empty(t, attributevalue.UnmarshalMap(client.groups[1][0].Delete.Key, &key))
+	assert.Equal(t, "TeamID#BJ4HAAB9B3", key.SortKey)
+	assert.Equal(t, &awsdynamodbtypes.AttributeValueMemberN{Value: "4"}, client.groups[1][1].Put.Item["Policy"])
+}
+
+func Test_WriteGlobalRules_Invalid(t *testing.T) {
+	client := &mockBatchWriter{}
+
+	written, err := WriteGlobalRules(clock.ConcreteTimeProvider{}, client, []GlobalRuleRow{
+		{SantaRule: rules.SantaRule{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist, Identifier: "nope"}},
+	})
+
+	assert.EqualError(t, err, `rule Binary#nope: "nope" is not a valid BINARY identifier`)
+	assert.Equal(t, 0, written)
+	assert.Nil(t, client.groups)
+}
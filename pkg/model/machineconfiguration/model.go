@@ -1,6 +1,7 @@
 package machineconfiguration
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/airbnb/rudolph/pkg/dynamodb"
@@ -19,6 +20,9 @@ const (
 	SyncTypeCleanAll             string = "clean_all"
 )
 
+// ErrGlobalLockdownDisabled is returned when the global config would put every machine into lockdown
+var ErrGlobalLockdownDisabled = errors.New("global lockdown configuration is disabled right now")
+
 // MachineConfigurationRow is an encapsulation of a DynamoDB row containing machine configuration data
 type MachineConfigurationRow struct {
 	dynamodb.PrimaryKey
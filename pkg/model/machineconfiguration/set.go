@@ -1,7 +1,6 @@
 package machineconfiguration
 
 import (
-	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -16,7 +15,7 @@ func SetGlobalConfig(client dynamodb.PutItemAPI, clientMode types.ClientMode, bl
 	// This is a safety check to prevent the accidental setting of lockdown mode globally via a config set operation
 	// Nothing is preventing this from being manually performed at the DyanmoDB table itself
 	if !allowGlobalLockdown && clientMode == types.Lockdown {
-		return errors.New("global lockdown configuration is disabled right now")
+		return ErrGlobalLockdownDisabled
 	}
 
 	// Construct a MachineConfigRow to represent a GlobalConfig
@@ -151,7 +150,7 @@ type ConcreteGlobalConfigurationSetter struct {
 
 func (c ConcreteGlobalConfigurationSetter) setGlobalConfig(config MachineConfiguration) error {
 	if !allowGlobalLockdown && config.ClientMode == types.Lockdown {
-		return errors.New("global lockdown configuration is disabled right now")
+		return ErrGlobalLockdownDisabled
 	}
 
 	// Construct a MachineConfigRow to represent a GlobalConfig
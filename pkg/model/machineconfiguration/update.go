@@ -1,7 +1,6 @@
 package machineconfiguration
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
@@ -116,7 +115,7 @@ func (c ConcreteGlobalConfigurationUpdater) updateConfig(configRequest MachineCo
 
 	if configRequest.ClientMode != nil && *configRequest.ClientMode != currentGlobalConfig.ClientMode {
 		if *configRequest.ClientMode == types.Lockdown {
-			err = errors.New("global lockdown configuration is disabled right now")
+			err = ErrGlobalLockdownDisabled
 			return
 		} else {
 			newGlobalConfig.ClientMode = *configRequest.ClientMode
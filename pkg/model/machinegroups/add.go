@@ -0,0 +1,33 @@
+package machinegroups
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+func AddMachineToGroup(client dynamodb.PutItemAPI, timeProvider clock.TimeProvider, groupName string, machineID string) (err error) {
+	if err = ValidateGroupName(groupName); err != nil {
+		return
+	}
+	if err = types.ValidateMachineID(machineID); err != nil {
+		return
+	}
+
+	_, err = client.PutItem(MachineGroupMemberRow{
+		PrimaryKey: dynamodb.PrimaryKey{
+			PartitionKey: machineGroupPK(groupName),
+			SortKey:      machineGroupSK(machineID),
+		},
+		GroupName: groupName,
+		MachineID: machineID,
+		AddedAt:   clock.RFC3339(timeProvider.Now()),
+		DataType:  GetDataType(),
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to add machine %q to group %q: %w", machineID, groupName, err)
+	}
+	return
+}
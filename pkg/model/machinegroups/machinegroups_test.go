@@ -0,0 +1,58 @@
+package machinegroups
+
+import (
+	"testing"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPutItem func(item interface{}) (*awsdynamodb.PutItemOutput, error)
+
+func (m mockPutItem) PutItem(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+	return m(item)
+}
+
+type mockQuery func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error)
+
+func (m mockQuery) Query(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+	return m(input)
+}
+
+func Test_AddMachineToGroup(t *testing.T) {
+	var stored MachineGroupMemberRow
+	client := mockPutItem(func(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+		stored = item.(MachineGroupMemberRow)
+		return &awsdynamodb.PutItemOutput{}, nil
+	})
+
+	err := AddMachineToGroup(client, clock.Y2K{}, "canary", "AAAAAAAA-A00A-1234-1234-5864377B4831")
+
+	assert.Empty(t, err)
+	assert.Equal(t, "MachineGroup#canary", stored.PartitionKey)
+	assert.Equal(t, "AAAAAAAA-A00A-1234-1234-5864377B4831", stored.SortKey)
+	assert.Equal(t, GetDataType(), stored.DataType)
+}
+
+func Test_AddMachineToGroup_Invalid(t *testing.T) {
+	assert.Error(t, AddMachineToGroup(nil, clock.Y2K{}, "has spaces", "AAAAAAAA-A00A-1234-1234-5864377B4831"))
+	assert.Error(t, AddMachineToGroup(nil, clock.Y2K{}, "canary", "not-a-machine"))
+}
+
+func Test_GetGroupMachineIDs(t *testing.T) {
+	client := mockQuery(func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+		item, err := attributevalue.MarshalMap(MachineGroupMemberRow{GroupName: "canary", MachineID: "AAAAAAAA-A00A-1234-1234-5864377B4831", DataType: GetDataType()})
+		if err != nil {
+			return nil, err
+		}
+		return &awsdynamodb.QueryOutput{Items: []map[string]awstypes.AttributeValue{item}}, nil
+	})
+
+	machineIDs, err := GetGroupMachineIDs(client, "canary")
+
+	assert.Empty(t, err)
+	assert.Equal(t, []string{"AAAAAAAA-A00A-1234-1234-5864377B4831"}, machineIDs)
+}
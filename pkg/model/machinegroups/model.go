@@ -0,0 +1,36 @@
+package machinegroups
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+const (
+	machineGroupPKPrefix = "MachineGroup#"
+)
+
+// MachineGroupMemberRow records that a machine belongs to a named group.
+//
+// Groups are an operator-defined way to address many machines at once (e.g. a team, a hardware fleet, or a
+// rollout wave); every member of a group lives in the group's partition.
+type MachineGroupMemberRow struct {
+	dynamodb.PrimaryKey
+	GroupName string         `dynamodbav:"GroupName"`
+	MachineID string         `dynamodbav:"MachineID"`
+	AddedAt   string         `dynamodbav:"AddedAt"`
+	DataType  types.DataType `dynamodbav:"DataType"`
+}
+
+func machineGroupPK(groupName string) string {
+	return fmt.Sprintf("%s%s", machineGroupPKPrefix, groupName)
+}
+
+func machineGroupSK(machineID string) string {
+	return machineID
+}
+
+func GetDataType() types.DataType {
+	return types.DataTypeMachineGroup
+}
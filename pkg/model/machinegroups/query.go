@@ -0,0 +1,58 @@
+package machinegroups
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// GetGroupMachineIDs returns the IDs of every machine in the group, sorted
+func GetGroupMachineIDs(client dynamodb.QueryAPI, groupName string) (machineIDs []string, err error) {
+	if err = ValidateGroupName(groupName); err != nil {
+		return
+	}
+
+	keyCond := expression.Key("PK").Equal(expression.Value(machineGroupPK(groupName)))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		return
+	}
+
+	var exclusiveStartKey map[string]awstypes.AttributeValue
+	for {
+		input := &awsdynamodb.QueryInput{
+			ConsistentRead:            aws.Bool(false),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			KeyConditionExpression:    expr.KeyCondition(),
+			ExclusiveStartKey:         exclusiveStartKey,
+		}
+
+		var result *awsdynamodb.QueryOutput
+		result, err = client.Query(input)
+		if err != nil {
+			err = fmt.Errorf("failed to query members of group %q: %w", groupName, err)
+			return
+		}
+
+		var rows []MachineGroupMemberRow
+		err = attributevalue.UnmarshalListOfMaps(result.Items, &rows)
+		if err != nil {
+			err = fmt.Errorf("failed to unmarshal result from DynamoDB: %w", err)
+			return
+		}
+		for _, row := range rows {
+			machineIDs = append(machineIDs, row.MachineID)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return
+		}
+		exclusiveStartKey = result.LastEvaluatedKey
+	}
+}
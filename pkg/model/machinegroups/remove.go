@@ -0,0 +1,18 @@
+package machinegroups
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+)
+
+func RemoveMachineFromGroup(client dynamodb.DeleteItemAPI, groupName string, machineID string) (err error) {
+	_, err = client.DeleteItem(dynamodb.PrimaryKey{
+		PartitionKey: machineGroupPK(groupName),
+		SortKey:      machineGroupSK(machineID),
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to remove machine %q from group %q: %w", machineID, groupName, err)
+	}
+	return
+}
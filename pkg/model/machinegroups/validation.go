@@ -0,0 +1,15 @@
+package machinegroups
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var groupNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.\-]{1,64}$`)
+
+func ValidateGroupName(groupName string) error {
+	if !groupNameRegexp.MatchString(groupName) {
+		return fmt.Errorf("invalid group name %q: must be 1-64 characters of letters, digits, '.', '_' or '-'", groupName)
+	}
+	return nil
+}
@@ -18,6 +18,27 @@ func AddNewMachineRule(
 	description string,
 	expires time.Time,
 ) error {
+	rule, err := NewMachineRuleRow(machineID, identifier, ruleType, policy, description, expires)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.PutItem(rule)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// NewMachineRuleRow validates and constructs the row of a machine rule, for callers that write many rows at once
+func NewMachineRuleRow(
+	machineID string,
+	identifier string,
+	ruleType types.RuleType,
+	policy types.Policy,
+	description string,
+	expires time.Time,
+) (rule MachineRuleRow, err error) {
 	// Input Validation
 	isValid, err := ruleValidation(
 		machineID,
@@ -28,13 +49,14 @@ func AddNewMachineRule(
 		expires,
 	)
 	if err != nil {
-		return err
+		return
 	}
 	if !isValid {
-		return errors.New("no errors occurred during the rule validation check but the provided rule is not valid")
+		err = errors.New("no errors occurred during the rule validation check but the provided rule is not valid")
+		return
 	}
 
-	rule := MachineRuleRow{
+	rule = MachineRuleRow{
 		PrimaryKey: dynamodb.PrimaryKey{
 			PartitionKey: machineRulePK(machineID),
 			SortKey:      machineRuleSK(identifier, ruleType),
@@ -47,12 +69,7 @@ func AddNewMachineRule(
 		},
 		ExpiresAfter: expires.Unix(),
 	}
-
-	_, err = client.PutItem(rule)
-	if err != nil {
-		return err
-	}
-	return nil
+	return
 }
 
 func ruleValidation(
@@ -0,0 +1,50 @@
+package modetransitions
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// RecordTransition persists a change of the machine's intended ClientMode, timestamped with the current time
+func RecordTransition(
+	client dynamodb.PutItemAPI,
+	timeProvider clock.TimeProvider,
+	machineID string,
+	fromMode types.ClientMode,
+	toMode types.ClientMode,
+	actor string,
+	reason string,
+) (row ModeTransitionRow, err error) {
+	if err = types.ValidateMachineID(machineID); err != nil {
+		return
+	}
+
+	now := timeProvider.Now().UTC()
+	transitionedAt := clock.RFC3339(now)
+
+	row = ModeTransitionRow{
+		PrimaryKey: dynamodb.PrimaryKey{
+			PartitionKey: modeTransitionsPK(now),
+			SortKey:      modeTransitionSK(transitionedAt, machineID),
+		},
+		ModeTransition: ModeTransition{
+			MachineID:      machineID,
+			FromMode:       fromMode,
+			ToMode:         toMode,
+			Actor:          actor,
+			Reason:         reason,
+			TransitionedAt: transitionedAt,
+		},
+		ExpiresAfter: GetModeTransitionExpiresAfter(timeProvider),
+		DataType:     GetDataType(),
+	}
+
+	_, err = client.PutItem(row)
+	if err != nil {
+		err = fmt.Errorf("failed to record mode transition for machine %q: %w", machineID, err)
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package modetransitions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+const (
+	modeTransitionsPKPrefix           = "ModeTransitions#"
+	modeTransitionsExpiresAfterInDays = 365
+	dayFormat                         = "2006-01-02"
+)
+
+// Actors that are recorded against a transition
+const (
+	ActorAutoPromotion = "auto-promotion"
+	ActorAutoDemotion  = "auto-demotion"
+	ActorCLI           = "cli"
+	ActorAdminAPI      = "admin-api"
+)
+
+// ModeTransitionRow records a single change of a machine's intended ClientMode.
+//
+// Transitions are partitioned by the UTC day that they happened on, so that a fleet-wide history of recent
+// transitions (e.g. "how many machines were promoted today?") is a handful of queries. Within a day, transitions
+// are sorted by time.
+type ModeTransitionRow struct {
+	dynamodb.PrimaryKey
+	ModeTransition
+	ExpiresAfter int64          `dynamodbav:"ExpiresAfter,omitempty"`
+	DataType     types.DataType `dynamodbav:"DataType"`
+}
+
+// ModeTransition is the abstract notion, sans DynamoDB magic (e.g. PK/SK)
+type ModeTransition struct {
+	MachineID      string           `dynamodbav:"MachineID" json:"machine_id"`
+	FromMode       types.ClientMode `dynamodbav:"FromMode" json:"from_mode"`
+	ToMode         types.ClientMode `dynamodbav:"ToMode" json:"to_mode"`
+	Actor          string           `dynamodbav:"Actor" json:"actor"`
+	Reason         string           `dynamodbav:"Reason,omitempty" json:"reason,omitempty"`
+	TransitionedAt string           `dynamodbav:"TransitionedAt" json:"transitioned_at"`
+}
+
+// IsPromotion reports whether the transition moved the machine into LOCKDOWN
+func (t ModeTransition) IsPromotion() bool {
+	return t.ToMode == types.Lockdown && t.FromMode != types.Lockdown
+}
+
+func modeTransitionsPK(day time.Time) string {
+	return fmt.Sprintf("%s%s", modeTransitionsPKPrefix, day.UTC().Format(dayFormat))
+}
+
+// modeTransitionSK sorts transitions chronologically within a day; the machineID disambiguates transitions that
+// happen within the same second
+func modeTransitionSK(transitionedAt string, machineID string) string {
+	return fmt.Sprintf("%s#%s", transitionedAt, machineID)
+}
+
+func GetModeTransitionExpiresAfter(timeProvider clock.TimeProvider) int64 {
+	return clock.Unixtimestamp(timeProvider.Now().UTC().AddDate(0, 0, modeTransitionsExpiresAfterInDays))
+}
+
+func GetDataType() types.DataType {
+	return types.DataTypeModeTransition
+}
@@ -0,0 +1,72 @@
+package modetransitions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPutItem func(item interface{}) (*awsdynamodb.PutItemOutput, error)
+
+func (m mockPutItem) PutItem(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+	return m(item)
+}
+
+type mockQuery func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error)
+
+func (m mockQuery) Query(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+	return m(input)
+}
+
+func Test_RecordTransition(t *testing.T) {
+	var stored ModeTransitionRow
+	client := mockPutItem(func(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+		stored = item.(ModeTransitionRow)
+		return &awsdynamodb.PutItemOutput{}, nil
+	})
+
+	row, err := RecordTransition(client, clock.Y2K{}, "AAAAAAAA-A00A-1234-1234-5864377B4831", types.Monitor, types.Lockdown, ActorAutoPromotion, "ready")
+
+	assert.Empty(t, err)
+	assert.Equal(t, row, stored)
+	assert.Equal(t, "ModeTransitions#2000-01-01", stored.PartitionKey)
+	assert.Equal(t, "2000-01-01T00:00:00Z#AAAAAAAA-A00A-1234-1234-5864377B4831", stored.SortKey)
+	assert.True(t, stored.IsPromotion())
+	assert.Equal(t, GetDataType(), stored.DataType)
+}
+
+func Test_RecordTransition_InvalidMachineID(t *testing.T) {
+	_, err := RecordTransition(nil, clock.Y2K{}, "not-a-machine", types.Monitor, types.Lockdown, ActorCLI, "")
+	assert.Error(t, err)
+}
+
+func Test_GetTransitionsSince(t *testing.T) {
+	var queriedPKs []string
+	client := mockQuery(func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+		pk := input.ExpressionAttributeValues[":0"].(*awstypes.AttributeValueMemberS).Value
+		queriedPKs = append(queriedPKs, pk)
+
+		item, err := attributevalue.MarshalMap(ModeTransitionRow{
+			ModeTransition: ModeTransition{MachineID: "AAAAAAAA-A00A-1234-1234-5864377B4831", FromMode: types.Lockdown, ToMode: types.Monitor},
+			DataType:       GetDataType(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &awsdynamodb.QueryOutput{Items: []map[string]awstypes.AttributeValue{item}}, nil
+	})
+
+	timeProvider := clock.FrozenTimeProvider{Current: clock.Y2KTime().Add(2*24*time.Hour + time.Hour)}
+	items, err := GetTransitionsSince(client, timeProvider, clock.Y2KTime().Add(12*time.Hour))
+
+	assert.Empty(t, err)
+	assert.Equal(t, []string{"ModeTransitions#2000-01-01", "ModeTransitions#2000-01-02", "ModeTransitions#2000-01-03"}, queriedPKs)
+	assert.Len(t, items, 3)
+	assert.False(t, items[0].IsPromotion())
+}
@@ -0,0 +1,74 @@
+package modetransitions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// GetTransitionsSince returns every transition, across all machines, that happened at or after [since], oldest first
+func GetTransitionsSince(client dynamodb.QueryAPI, timeProvider clock.TimeProvider, since time.Time) (items []ModeTransitionRow, err error) {
+	since = since.UTC()
+	now := timeProvider.Now().UTC()
+
+	day := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, time.UTC)
+	for !day.After(now) {
+		var rows []ModeTransitionRow
+		rows, err = getTransitionsOnDay(client, day, clock.RFC3339(since))
+		if err != nil {
+			return
+		}
+		items = append(items, rows...)
+		day = day.AddDate(0, 0, 1)
+	}
+	return
+}
+
+func getTransitionsOnDay(client dynamodb.QueryAPI, day time.Time, since string) (items []ModeTransitionRow, err error) {
+	keyCond := expression.KeyAnd(
+		expression.Key("PK").Equal(expression.Value(modeTransitionsPK(day))),
+		expression.Key("SK").GreaterThanEqual(expression.Value(since)),
+	)
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		return
+	}
+
+	var exclusiveStartKey map[string]awstypes.AttributeValue
+	for {
+		input := &awsdynamodb.QueryInput{
+			ConsistentRead:            aws.Bool(false),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			KeyConditionExpression:    expr.KeyCondition(),
+			ExclusiveStartKey:         exclusiveStartKey,
+		}
+
+		var result *awsdynamodb.QueryOutput
+		result, err = client.Query(input)
+		if err != nil {
+			err = fmt.Errorf("failed to query mode transitions for %s: %w", day.Format(dayFormat), err)
+			return
+		}
+
+		var rows []ModeTransitionRow
+		err = attributevalue.UnmarshalListOfMaps(result.Items, &rows)
+		if err != nil {
+			err = fmt.Errorf("failed to unmarshal result from DynamoDB: %w", err)
+			return
+		}
+		items = append(items, rows...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return
+		}
+		exclusiveStartKey = result.LastEvaluatedKey
+	}
+}
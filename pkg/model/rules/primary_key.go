@@ -3,6 +3,7 @@ package rules
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/airbnb/rudolph/pkg/types"
 )
@@ -22,8 +23,31 @@ func RuleSortKeyFromTypeIdentifier(identifier string, ruleType types.RuleType) s
 		return fmt.Sprintf("%s%s", teamIDRuleSKPrefix, identifier)
 	case types.RuleTypeSigningID:
 		return fmt.Sprintf("%s%s", signingIDRuleSKPrefix, identifier)
+	case types.RuleTypeCDHash:
+		return fmt.Sprintf("%s%s", cdhashRuleSKPrefix, identifier)
 	default:
 		log.Printf("error (recovered): encountered unknown ruleType: (%+v)", ruleType)
 		return ""
 	}
 }
+
+// ParseRuleSortKey is the reverse of RuleSortKeyFromTypeIdentifier, for rule names such as "TeamID#EQHXZ8M8AV"
+func ParseRuleSortKey(sortKey string) (identifier string, ruleType types.RuleType, err error) {
+	prefixes := []struct {
+		prefix   string
+		ruleType types.RuleType
+	}{
+		{binaryRuleSKPrefix, types.RuleTypeBinary},
+		{certificateRuleSKPrefix, types.RuleTypeCertificate},
+		{teamIDRuleSKPrefix, types.RuleTypeTeamID},
+		{signingIDRuleSKPrefix, types.RuleTypeSigningID},
+		{cdhashRuleSKPrefix, types.RuleTypeCDHash},
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(sortKey, p.prefix) && len(sortKey) > len(p.prefix) {
+			return strings.TrimPrefix(sortKey, p.prefix), p.ruleType, nil
+		}
+	}
+	err = fmt.Errorf("invalid rule name %q; must look like TeamID#<identifier>", sortKey)
+	return
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_RuleSortKeyFromTypeIdentifier(t *testing.T) {
@@ -75,3 +76,20 @@ func Test_RuleSortKeyFromTypeIdentifier(t *testing.T) {
 		})
 	}
 }
+
+func Test_ParseRuleSortKey(t *testing.T) {
+	identifier, ruleType, err := ParseRuleSortKey("SigningID#EQHXZ8M8AV:com.google.Chrome")
+	assert.NoError(t, err)
+	assert.Equal(t, "EQHXZ8M8AV:com.google.Chrome", identifier)
+	assert.Equal(t, types.RuleTypeSigningID, ruleType)
+
+	identifier, ruleType, err = ParseRuleSortKey(RuleSortKeyFromTypeIdentifier("EQHXZ8M8AV", types.RuleTypeTeamID))
+	assert.NoError(t, err)
+	assert.Equal(t, "EQHXZ8M8AV", identifier)
+	assert.Equal(t, types.RuleTypeTeamID, ruleType)
+
+	_, _, err = ParseRuleSortKey("TeamID#")
+	assert.Error(t, err)
+	_, _, err = ParseRuleSortKey("Folder#abc")
+	assert.Error(t, err)
+}
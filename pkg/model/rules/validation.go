@@ -6,6 +6,8 @@ var sha256Regexp = regexp.MustCompile(`^[a-f0-9]{64}$`)
 
 var teamIDRegexp = regexp.MustCompile(`^([A-Z0-9]{1,10})$`)
 
+var cdhashRegexp = regexp.MustCompile(`^[a-f0-9]{40}$`)
+
 var signingIDRegexp = regexp.MustCompile(`^([A-Z0-9]{1,10}|platform)(:[\w\-\.]+)$`)
 
 func ValidSha256(sha256 string) bool {
@@ -19,3 +21,7 @@ func ValidTeamID(teamID string) bool {
 func ValidSigningID(signingID string) bool {
 	return signingIDRegexp.MatchString(signingID)
 }
+
+func ValidCDHash(cdhash string) bool {
+	return cdhashRegexp.MatchString(cdhash)
+}
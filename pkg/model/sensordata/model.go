@@ -26,6 +26,7 @@ type SensorData struct {
 	ClientMode           types.ClientMode `dynamodbav:"ClientMode"`
 	RequestCleanSync     bool             `dynamodbav:"RequestCleanSync"`
 	PrimaryUser          string           `dynamodbav:"PrimaryUser"`
+	ModelIdentifier      string           `dynamodbav:"ModelIdentifier,omitempty"`
 	RuleCount            int              `dynamodbav:"RuleCount"`
 	CertificateRuleCount int              `dynamodbav:"CertificateRuleCount"`
 	BinaryRuleCount      int              `dynamodbav:"BinaryRuleCount"`
@@ -16,6 +16,7 @@ func NewSensorData(
 	clientMode rudolphtypes.ClientMode,
 	requestCleanSync bool,
 	primaryUser string,
+	modelIdentifier string,
 	certRuleCount int,
 	binaryRuleCount int,
 	cdHashRuleCount int,
@@ -40,6 +41,7 @@ func NewSensorData(
 		ClientMode:           clientMode,
 		RequestCleanSync:     requestCleanSync,
 		PrimaryUser:          primaryUser,
+		ModelIdentifier:      modelIdentifier,
 		RuleCount:            totalRuleCount,
 		BinaryRuleCount:      binaryRuleCount,
 		CertificateRuleCount: certRuleCount,
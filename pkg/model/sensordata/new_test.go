@@ -31,6 +31,7 @@ func Test_CreateSyncState(t *testing.T) {
 		compilerRuleCount    int
 		ruleCount            int
 		primaryUser          string
+		modelIdentifier      string
 		expectedTime         string
 		expectedExpiresAfter int64
 		expectedDataType     rudolphtypes.DataType
@@ -58,6 +59,7 @@ func Test_CreateSyncState(t *testing.T) {
 		compilerRuleCount:    1,
 		ruleCount:            7,
 		primaryUser:          "john_doe",
+		modelIdentifier:      "MacBookPro18,3",
 		expectedTime:         clock.RFC3339(timeProvider.Now()),
 		expectedExpiresAfter: clock.Unixtimestamp(timeProvider.Now().UTC().AddDate(0, 0, 90)),
 		expectedDataType:     rudolphtypes.DataTypeSensorData,
@@ -73,6 +75,7 @@ func Test_CreateSyncState(t *testing.T) {
 		expected.clientMode,
 		expected.requestCleanSync,
 		expected.primaryUser,
+		expected.modelIdentifier,
 		expected.certRuleCount,
 		expected.binaryRuleCount,
 		expected.cdHashRuleCount,
@@ -96,6 +99,7 @@ func Test_CreateSyncState(t *testing.T) {
 	assert.Equal(t, expected.transitiveRuleCount, sensorData.TransitiveRuleCount)
 	assert.Equal(t, expected.ruleCount, sensorData.RuleCount)
 	assert.Equal(t, expected.primaryUser, sensorData.PrimaryUser)
+	assert.Equal(t, expected.modelIdentifier, sensorData.ModelIdentifier)
 	assert.Equal(t, pk, sensorData.PartitionKey)
 	assert.Equal(t, sk, sensorData.SortKey)
 	assert.Equal(t, expected.expectedDataType, sensorData.DataType)
@@ -1,17 +1,21 @@
 package sensordata
 
 import (
+	"fmt"
+
 	"github.com/airbnb/rudolph/pkg/dynamodb"
 	"github.com/airbnb/rudolph/pkg/types"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 const (
 	MachineID_DataType_GSI           string = "DataType_MachineID"
 	SerialNum_DataType_MachineID_GSI string = "SerialNum_DataType_MachineID"
+	PrimaryUser_DataType_GSI         string = "PrimaryUser_DataType"
 )
 
 func GetSensorDataFinder(api dynamodb.QueryAPI) SensorDataFinder {
@@ -29,6 +33,7 @@ func GetSensorDataFinder(api dynamodb.QueryAPI) SensorDataFinder {
 type SensorDataFinder interface {
 	GetMachineIDsStartingWith(prefix string, limit int32) ([]string, error)
 	GetMachineIDsFromSerialNumber(serialNumber string, limit int32) ([]string, error)
+	GetMachineIDsFromPrimaryUser(primaryUser string, limit int32) ([]string, error)
 }
 
 type ConcreteSensorDataFinder struct {
@@ -128,6 +133,50 @@ func (f ConcreteSensorDataFinder) GetMachineIDsFromSerialNumber(serialNumber str
 	return
 }
 
+func (f ConcreteSensorDataFinder) GetMachineIDsFromPrimaryUser(primaryUser string, limit int32) (machineIDs []string, err error) {
+	// Build the key conditions
+	keyCond := expression.KeyAnd(
+		expression.Key("PrimaryUser").Equal(expression.Value(primaryUser)),
+		expression.Key("DataType").Equal(expression.Value(string(GetDataType()))),
+	)
+
+	proj := expression.NamesList(expression.Name("MachineID"))
+
+	builder := expression.NewBuilder().WithKeyCondition(keyCond).WithProjection(proj)
+	expr, err := builder.Build()
+	if err != nil {
+		return
+	}
+
+	input := awsdynamodb.QueryInput{
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeValues: expr.Values(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		IndexName:                 aws.String(PrimaryUser_DataType_GSI),
+		Limit:                     aws.Int32(limit),
+		ConsistentRead:            aws.Bool(false),
+	}
+
+	output, err := f.queryapi.Query(&input)
+	if err != nil {
+		return
+	}
+
+	var gsiItems []dataTypeMachineIDGSIItem
+	err = attributevalue.UnmarshalListOfMaps(output.Items, &gsiItems)
+	if err != nil {
+		return
+	}
+
+	machineIDs = make([]string, len(gsiItems))
+	for index, item := range gsiItems {
+		machineIDs[index] = item.MachineID
+	}
+
+	return
+}
+
 type SerialNumDataTypeMachineIdGSIItem struct {
 	PrimaryKey dynamodb.PrimaryKey
 	SerialNumb string         `dynamodbav:"SerialNum"`
@@ -140,3 +189,114 @@ type dataTypeMachineIDGSIItem struct {
 	DataType   types.DataType `dynamodbav:"DataType"`
 	MachineID  string         `dynamodbav:"MachineID"`
 }
+
+// ListMachineIDs returns the ID of every machine that has ever checked in, sorted
+func ListMachineIDs(client dynamodb.QueryAPI) (machineIDs []string, err error) {
+	keyCond := expression.Key("DataType").Equal(expression.Value(string(GetDataType())))
+	proj := expression.NamesList(expression.Name("MachineID"))
+
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).WithProjection(proj).Build()
+	if err != nil {
+		return
+	}
+
+	var exclusiveStartKey map[string]awstypes.AttributeValue
+	for {
+		input := &awsdynamodb.QueryInput{
+			KeyConditionExpression:    expr.KeyCondition(),
+			ExpressionAttributeValues: expr.Values(),
+			ProjectionExpression:      expr.Projection(),
+			ExpressionAttributeNames:  expr.Names(),
+			IndexName:                 aws.String(MachineID_DataType_GSI),
+			ConsistentRead:            aws.Bool(false),
+			ExclusiveStartKey:         exclusiveStartKey,
+		}
+
+		var output *awsdynamodb.QueryOutput
+		output, err = client.Query(input)
+		if err != nil {
+			err = fmt.Errorf("failed to list machine IDs: %w", err)
+			return
+		}
+
+		var gsiItems []dataTypeMachineIDGSIItem
+		err = attributevalue.UnmarshalListOfMaps(output.Items, &gsiItems)
+		if err != nil {
+			return
+		}
+		for _, item := range gsiItems {
+			machineIDs = append(machineIDs, item.MachineID)
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return
+		}
+		exclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+// ListSensorDataPage returns up to pageSize sensor data items, ordered by machine ID, of the machines whose ID sorts
+// after afterMachineID; pass "" to start at the first machine. lastMachineID is the ID to pass to get the next page,
+// and is empty once there are no more pages.
+func ListSensorDataPage(client dynamodb.QueryAPI, afterMachineID string, pageSize int32) (items []SensorData, lastMachineID string, err error) {
+	keyCond := expression.Key("DataType").Equal(expression.Value(string(GetDataType())))
+
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		return
+	}
+
+	input := &awsdynamodb.QueryInput{
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeValues: expr.Values(),
+		ExpressionAttributeNames:  expr.Names(),
+		IndexName:                 aws.String(MachineID_DataType_GSI),
+		ConsistentRead:            aws.Bool(false),
+	}
+	if pageSize > 0 {
+		input.Limit = aws.Int32(pageSize)
+	}
+	if afterMachineID != "" {
+		// The index key plus the table key of the last item read is all that DynamoDB needs to resume
+		pk, sk := MachineIDSensorDataPKSK(afterMachineID)
+		input.ExclusiveStartKey = map[string]awstypes.AttributeValue{
+			"PK":        &awstypes.AttributeValueMemberS{Value: pk},
+			"SK":        &awstypes.AttributeValueMemberS{Value: sk},
+			"DataType":  &awstypes.AttributeValueMemberS{Value: string(GetDataType())},
+			"MachineID": &awstypes.AttributeValueMemberS{Value: afterMachineID},
+		}
+	}
+
+	output, err := client.Query(input)
+	if err != nil {
+		err = fmt.Errorf("failed to list sensor data: %w", err)
+		return
+	}
+
+	err = attributevalue.UnmarshalListOfMaps(output.Items, &items)
+	if err != nil {
+		return
+	}
+
+	if len(output.LastEvaluatedKey) > 0 && len(items) > 0 {
+		lastMachineID = items[len(items)-1].MachineID
+	}
+	return
+}
+
+// ListSensorData returns the sensor data of every machine that checked in within the last 90 days, ordered by
+// machine ID
+func ListSensorData(client dynamodb.QueryAPI) (items []SensorData, err error) {
+	var after string
+	for {
+		var page []SensorData
+		page, after, err = ListSensorDataPage(client, after, 0)
+		if err != nil {
+			return
+		}
+		items = append(items, page...)
+		if after == "" {
+			return
+		}
+	}
+}
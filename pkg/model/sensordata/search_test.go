@@ -0,0 +1,96 @@
+package sensordata
+
+import (
+	"testing"
+
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type querySensorData func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error)
+
+func (query querySensorData) Query(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+	return query(input)
+}
+
+func Test_ListMachineIDs_Paginates(t *testing.T) {
+	pages := []*awsdynamodb.QueryOutput{
+		{
+			Items: []map[string]awstypes.AttributeValue{
+				{"MachineID": &awstypes.AttributeValueMemberS{Value: "AAAAAAAA-A00A-1234-1234-000000000001"}},
+			},
+			LastEvaluatedKey: map[string]awstypes.AttributeValue{
+				"PK": &awstypes.AttributeValueMemberS{Value: "MachineInfo#AAAAAAAA-A00A-1234-1234-000000000001"},
+			},
+		},
+		{
+			Items: []map[string]awstypes.AttributeValue{
+				{"MachineID": &awstypes.AttributeValueMemberS{Value: "AAAAAAAA-A00A-1234-1234-000000000002"}},
+			},
+		},
+	}
+
+	calls := 0
+	client := querySensorData(func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+		assert.Equal(t, MachineID_DataType_GSI, *input.IndexName)
+		assert.Equal(t, calls > 0, input.ExclusiveStartKey != nil)
+		page := pages[calls]
+		calls++
+		return page, nil
+	})
+
+	machineIDs, err := ListMachineIDs(client)
+
+	assert.Empty(t, err)
+	assert.Equal(t, []string{"AAAAAAAA-A00A-1234-1234-000000000001", "AAAAAAAA-A00A-1234-1234-000000000002"}, machineIDs)
+	assert.Equal(t, 2, calls)
+}
+
+func Test_GetMachineIDsFromPrimaryUser(t *testing.T) {
+	client := querySensorData(func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+		assert.Equal(t, PrimaryUser_DataType_GSI, *input.IndexName)
+		assert.Equal(t, int32(10), *input.Limit)
+		return &awsdynamodb.QueryOutput{
+			Items: []map[string]awstypes.AttributeValue{
+				{"MachineID": &awstypes.AttributeValueMemberS{Value: "AAAAAAAA-A00A-1234-1234-000000000001"}},
+				{"MachineID": &awstypes.AttributeValueMemberS{Value: "AAAAAAAA-A00A-1234-1234-000000000002"}},
+			},
+		}, nil
+	})
+
+	machineIDs, err := GetSensorDataFinder(client).GetMachineIDsFromPrimaryUser("alice", 10)
+
+	assert.Empty(t, err)
+	assert.Equal(t, []string{"AAAAAAAA-A00A-1234-1234-000000000001", "AAAAAAAA-A00A-1234-1234-000000000002"}, machineIDs)
+}
+
+func Test_ListSensorDataPage_ResumesAfterMachineID(t *testing.T) {
+	client := querySensorData(func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+		assert.Equal(t, MachineID_DataType_GSI, *input.IndexName)
+		assert.Equal(t, int32(2), *input.Limit)
+		assert.Equal(t, &awstypes.AttributeValueMemberS{Value: "AAAAAAAA-A00A-1234-1234-000000000001"}, input.ExclusiveStartKey["MachineID"])
+		assert.Equal(t, &awstypes.AttributeValueMemberS{Value: "Machine#AAAAAAAA-A00A-1234-1234-000000000001"}, input.ExclusiveStartKey["PK"])
+		assert.Equal(t, &awstypes.AttributeValueMemberS{Value: "Current"}, input.ExclusiveStartKey["SK"])
+		return &awsdynamodb.QueryOutput{
+			Items: []map[string]awstypes.AttributeValue{
+				{
+					"MachineID":   &awstypes.AttributeValueMemberS{Value: "AAAAAAAA-A00A-1234-1234-000000000002"},
+					"SerialNum":   &awstypes.AttributeValueMemberS{Value: "C02ABC123DEF"},
+					"PrimaryUser": &awstypes.AttributeValueMemberS{Value: "alice"},
+					"DataType":    &awstypes.AttributeValueMemberS{Value: "SensorData"},
+				},
+			},
+			LastEvaluatedKey: map[string]awstypes.AttributeValue{
+				"MachineID": &awstypes.AttributeValueMemberS{Value: "AAAAAAAA-A00A-1234-1234-000000000002"},
+			},
+		}, nil
+	})
+
+	items, lastMachineID, err := ListSensorDataPage(client, "AAAAAAAA-A00A-1234-1234-000000000001", 2)
+
+	assert.Empty(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "alice", items[0].PrimaryUser)
+	assert.Equal(t, "AAAAAAAA-A00A-1234-1234-000000000002", lastMachineID)
+}
@@ -10,6 +10,7 @@ func CreateNewSyncState(
 	machineID string,
 	requestCleanSync bool,
 	lastCleanSync string,
+	consecutiveCleanSyncs int,
 	batchSize int,
 	feedSyncCursor string,
 ) SyncStateRow {
@@ -19,14 +20,15 @@ func CreateNewSyncState(
 			SortKey:      syncStateSK,
 		},
 		SyncState: SyncState{
-			MachineID:      machineID,
-			CleanSync:      requestCleanSync,
-			BatchSize:      batchSize,
-			LastCleanSync:  lastCleanSync,
-			PreflightAt:    clock.RFC3339(timeProvider.Now()),
-			FeedSyncCursor: feedSyncCursor,
-			ExpiresAfter:   GetSyncStateExpiresAfter(timeProvider),
-			DataType:       GetDataType(),
+			MachineID:             machineID,
+			CleanSync:             requestCleanSync,
+			BatchSize:             batchSize,
+			LastCleanSync:         lastCleanSync,
+			ConsecutiveCleanSyncs: consecutiveCleanSyncs,
+			PreflightAt:           clock.RFC3339(timeProvider.Now()),
+			FeedSyncCursor:        feedSyncCursor,
+			ExpiresAfter:          GetSyncStateExpiresAfter(timeProvider),
+			DataType:              GetDataType(),
 		},
 	}
 }
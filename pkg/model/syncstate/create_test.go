@@ -41,7 +41,7 @@ func Test_CreateSyncState(t *testing.T) {
 	}
 
 	for _, test := range cases {
-		result := CreateNewSyncState(timeProvider, test.machineID, test.expectedCleanSync, test.expectedLastCleanSync, test.expectedBatchSize, test.expectedFeedSyncCursor)
+		result := CreateNewSyncState(timeProvider, test.machineID, test.expectedCleanSync, test.expectedLastCleanSync, 0, test.expectedBatchSize, test.expectedFeedSyncCursor)
 
 		if test.expectedBatchSize != 0 {
 			assert.NotEmpty(t, result)
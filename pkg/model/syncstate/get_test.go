@@ -96,3 +96,39 @@ func Test_GetIntendedConfig(t *testing.T) {
 		}
 	}
 }
+
+type querySyncStates func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error)
+
+func (query querySyncStates) Query(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+	return query(input)
+}
+
+func Test_ListSyncStates_Paginates(t *testing.T) {
+	calls := 0
+	client := querySyncStates(func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+		assert.Equal(t, "DataType_MachineID", *input.IndexName)
+		assert.NotEmpty(t, input.FilterExpression)
+		calls++
+		output := &awsdynamodb.QueryOutput{
+			Items: []map[string]awstypes.AttributeValue{
+				{
+					"MachineID": &awstypes.AttributeValueMemberS{Value: fmt.Sprintf("AAAAAAAA-A00A-1234-1234-%012d", calls)},
+					"DataType":  &awstypes.AttributeValueMemberS{Value: string(types.DataTypeSyncState)},
+				},
+			},
+		}
+		if calls == 1 {
+			output.LastEvaluatedKey = map[string]awstypes.AttributeValue{
+				"MachineID": &awstypes.AttributeValueMemberS{Value: "AAAAAAAA-A00A-1234-1234-000000000001"},
+			}
+		}
+		return output, nil
+	})
+
+	syncStates, err := ListSyncStates(client)
+
+	assert.Empty(t, err)
+	assert.Len(t, syncStates, 2)
+	assert.Equal(t, "AAAAAAAA-A00A-1234-1234-000000000002", syncStates[1].MachineID)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,57 @@
+package syncstate
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ListSyncStates returns the current sync state of every machine that synced within the last 90 days, ordered by
+// machine ID. Archived copies of sync states are skipped.
+func ListSyncStates(client dynamodb.QueryAPI) (syncStates []SyncStateRow, err error) {
+	keyCond := expression.Key("DataType").Equal(expression.Value(string(GetDataType())))
+	filter := expression.Name("SK").Equal(expression.Value(syncStateSK))
+
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).WithFilter(filter).Build()
+	if err != nil {
+		return
+	}
+
+	var exclusiveStartKey map[string]awstypes.AttributeValue
+	for {
+		input := &awsdynamodb.QueryInput{
+			KeyConditionExpression:    expr.KeyCondition(),
+			FilterExpression:          expr.Filter(),
+			ExpressionAttributeValues: expr.Values(),
+			ExpressionAttributeNames:  expr.Names(),
+			IndexName:                 aws.String(sensordata.MachineID_DataType_GSI),
+			ConsistentRead:            aws.Bool(false),
+			ExclusiveStartKey:         exclusiveStartKey,
+		}
+
+		var output *awsdynamodb.QueryOutput
+		output, err = client.Query(input)
+		if err != nil {
+			err = fmt.Errorf("failed to list sync states: %w", err)
+			return
+		}
+
+		var page []SyncStateRow
+		err = attributevalue.UnmarshalListOfMaps(output.Items, &page)
+		if err != nil {
+			return
+		}
+		syncStates = append(syncStates, page...)
+
+		if len(output.LastEvaluatedKey) == 0 {
+			return
+		}
+		exclusiveStartKey = output.LastEvaluatedKey
+	}
+}
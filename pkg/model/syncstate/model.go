@@ -26,6 +26,7 @@ type SyncState struct {
 	BatchSize              int            `dynamodbav:"BatchSize"`
 	CleanSync              bool           `dynamodbav:"CleanSync"`
 	LastCleanSync          string         `dynamodbav:"LastCleanSync"`
+	ConsecutiveCleanSyncs  int            `dynamodbav:"ConsecutiveCleanSyncs"`
 	FeedSyncCursor         string         `dynamodbav:"FeedSyncCursor"`
 	PreflightAt            string         `dynamodbav:"PreflightAt"`
 	RuledownloadStartedAt  string         `dynamodbav:"RuledownloadStartedAt"`
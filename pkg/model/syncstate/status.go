@@ -0,0 +1,98 @@
+package syncstate
+
+import (
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+)
+
+// SyncStallTimeout is how long a sync can take before it is considered stalled. Syncs take seconds, so a sync that has
+// not reached postflight by then has been abandoned by the sensor.
+const SyncStallTimeout = 15 * time.Minute
+
+// SyncStatus describes how far the last sync of a machine got
+type SyncStatus string
+
+const (
+	SyncStatusCompleted  SyncStatus = "completed"
+	SyncStatusInProgress SyncStatus = "in_progress"
+	// SyncStatusStalledAfterPreflight is a sync that never downloaded rules
+	SyncStatusStalledAfterPreflight SyncStatus = "stalled_after_preflight"
+	// SyncStatusStalledInRuledownload is a sync that started downloading rules but did not get to the last page
+	SyncStatusStalledInRuledownload SyncStatus = "stalled_in_ruledownload"
+	// SyncStatusStalledBeforePostflight is a sync that downloaded every rule but never reported back
+	SyncStatusStalledBeforePostflight SyncStatus = "stalled_before_postflight"
+)
+
+// StageTiming is when a stage of a sync happened, and how long it took to get to the next one
+type StageTiming struct {
+	Stage string    `json:"stage"`
+	At    time.Time `json:"at"`
+	// Duration is the time until the next stage, or zero for the last stage that was reached
+	Duration time.Duration `json:"duration"`
+}
+
+// StageTimings returns the stages that the sync reached, in order
+func (s SyncState) StageTimings() (timings []StageTiming) {
+	stages := []struct {
+		name string
+		at   string
+	}{
+		{"preflight", s.PreflightAt},
+		{"ruledownload_started", s.RuledownloadStartedAt},
+		{"ruledownload_finished", s.RuledownloadFinishedAt},
+		{"postflight", s.PostflightAt},
+	}
+
+	for _, stage := range stages {
+		if stage.at == "" {
+			continue
+		}
+		at, err := clock.ParseRFC3339(stage.at)
+		if err != nil {
+			continue
+		}
+		if len(timings) > 0 {
+			timings[len(timings)-1].Duration = at.Sub(timings[len(timings)-1].At)
+		}
+		timings = append(timings, StageTiming{Stage: stage.name, At: at})
+	}
+	return
+}
+
+// LastSyncAt returns when the machine last started a sync
+func (s SyncState) LastSyncAt() (time.Time, bool) {
+	at, err := clock.ParseRFC3339(s.PreflightAt)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// Status returns how far the last sync got. Every preflight replaces the sync state, so the stages that are set all
+// belong to the same sync.
+func (s SyncState) Status(now time.Time) SyncStatus {
+	if s.PostflightAt != "" {
+		return SyncStatusCompleted
+	}
+	if at, ok := s.LastSyncAt(); ok && now.Sub(at) < SyncStallTimeout {
+		return SyncStatusInProgress
+	}
+	switch {
+	case s.RuledownloadFinishedAt != "":
+		return SyncStatusStalledBeforePostflight
+	case s.RuledownloadStartedAt != "":
+		return SyncStatusStalledInRuledownload
+	default:
+		return SyncStatusStalledAfterPreflight
+	}
+}
+
+// IsStalled is whether the status is one of the stalled ones
+func (s SyncStatus) IsStalled() bool {
+	switch s {
+	case SyncStatusStalledAfterPreflight, SyncStatusStalledInRuledownload, SyncStatusStalledBeforePostflight:
+		return true
+	}
+	return false
+}
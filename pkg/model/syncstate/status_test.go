@@ -0,0 +1,47 @@
+package syncstate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SyncState_StageTimings(t *testing.T) {
+	state := SyncState{
+		PreflightAt:            "2000-01-01T00:00:00Z",
+		RuledownloadStartedAt:  "2000-01-01T00:00:02Z",
+		RuledownloadFinishedAt: "2000-01-01T00:00:12Z",
+		PostflightAt:           "2000-01-01T00:00:13Z",
+	}
+
+	timings := state.StageTimings()
+
+	assert.Len(t, timings, 4)
+	assert.Equal(t, "preflight", timings[0].Stage)
+	assert.Equal(t, 2*time.Second, timings[0].Duration)
+	assert.Equal(t, 10*time.Second, timings[1].Duration)
+	assert.Equal(t, time.Second, timings[2].Duration)
+	assert.Equal(t, time.Duration(0), timings[3].Duration)
+}
+
+func Test_SyncState_Status(t *testing.T) {
+	now := time.Date(2000, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		state    SyncState
+		expected SyncStatus
+	}{
+		{SyncState{PreflightAt: "2000-01-01T00:00:00Z", PostflightAt: "2000-01-01T00:00:13Z"}, SyncStatusCompleted},
+		{SyncState{PreflightAt: "2000-01-01T00:55:00Z"}, SyncStatusInProgress},
+		{SyncState{PreflightAt: "2000-01-01T00:00:00Z"}, SyncStatusStalledAfterPreflight},
+		{SyncState{PreflightAt: "2000-01-01T00:00:00Z", RuledownloadStartedAt: "2000-01-01T00:00:02Z"}, SyncStatusStalledInRuledownload},
+		{SyncState{PreflightAt: "2000-01-01T00:00:00Z", RuledownloadStartedAt: "2000-01-01T00:00:02Z", RuledownloadFinishedAt: "2000-01-01T00:00:12Z"}, SyncStatusStalledBeforePostflight},
+	}
+
+	for _, test := range cases {
+		status := test.state.Status(now)
+		assert.Equal(t, test.expected, status)
+		assert.Equal(t, test.expected != SyncStatusCompleted && test.expected != SyncStatusInProgress, status.IsStalled())
+	}
+}
@@ -0,0 +1,71 @@
+package unblockrequests
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// FileUnblockRequest persists a pending request, replacing any earlier request for the same binary on the machine
+func FileUnblockRequest(client dynamodb.PutItemAPI, timeProvider clock.TimeProvider, request UnblockRequest) (row UnblockRequestRow, err error) {
+	if err = types.ValidateMachineID(request.MachineID); err != nil {
+		return
+	}
+	request.FileSHA256 = strings.ToLower(request.FileSHA256)
+	if err = types.ValidateSha256(request.FileSHA256); err != nil {
+		return
+	}
+	request.Justification = strings.TrimSpace(request.Justification)
+	if request.Justification == "" {
+		err = errors.New("a justification is required")
+		return
+	}
+	if utf8.RuneCountInString(request.Justification) > MaxJustificationLength {
+		err = fmt.Errorf("the justification must be at most %d characters", MaxJustificationLength)
+		return
+	}
+
+	request.RequestedAt = clock.RFC3339(timeProvider.Now())
+	request.Status = StatusPending
+	request.DecidedBy, request.DecidedAt, request.Reason = "", "", ""
+
+	row = UnblockRequestRow{
+		PrimaryKey: dynamodb.PrimaryKey{
+			PartitionKey: unblockRequestsPK,
+			SortKey:      unblockRequestSK(request.MachineID, request.FileSHA256),
+		},
+		UnblockRequest: request,
+		ExpiresAfter:   GetUnblockRequestExpiresAfter(timeProvider),
+		DataType:       GetDataType(),
+	}
+	_, err = client.PutItem(row)
+	if err != nil {
+		err = fmt.Errorf("failed to file unblock request for machine %q: %w", request.MachineID, err)
+	}
+	return
+}
+
+// DecideUnblockRequest records that a pending request was approved or denied, and by whom. Decided requests are kept
+// until they expire, so that users can see the decision.
+func DecideUnblockRequest(client dynamodb.PutItemAPI, timeProvider clock.TimeProvider, row UnblockRequestRow, status Status, decidedBy string, reason string) (UnblockRequestRow, error) {
+	if status != StatusApproved && status != StatusDenied {
+		return row, fmt.Errorf("invalid decision %q", status)
+	}
+	if row.Status != StatusPending {
+		return row, fmt.Errorf("the request was already %s", row.Status)
+	}
+
+	row.Status = status
+	row.DecidedBy = decidedBy
+	row.DecidedAt = clock.RFC3339(timeProvider.Now())
+	row.Reason = strings.TrimSpace(reason)
+	if _, err := client.PutItem(row); err != nil {
+		return row, fmt.Errorf("failed to record decision on unblock request %q: %w", row.SortKey, err)
+	}
+	return row, nil
+}
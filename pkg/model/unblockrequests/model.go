@@ -0,0 +1,65 @@
+package unblockrequests
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+const (
+	unblockRequestsPK                 = "UnblockRequests"
+	unblockRequestsExpiresAfterInDays = 30
+	// MaxJustificationLength keeps justifications to what fits in a review queue
+	MaxJustificationLength = 1000
+)
+
+// Status is where an unblock request stands
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusDenied   Status = "denied"
+)
+
+// UnblockRequestRow is a user's request to run a binary that Santa blocked on their machine.
+//
+// All requests share a partition, so that the queue of pending requests is a single query; requests expire after
+// unblockRequestsExpiresAfterInDays, so the partition stays small. A machine has at most one request per binary, and
+// filing it again replaces it.
+type UnblockRequestRow struct {
+	dynamodb.PrimaryKey
+	UnblockRequest
+	ExpiresAfter int64          `dynamodbav:"ExpiresAfter,omitempty"`
+	DataType     types.DataType `dynamodbav:"DataType"`
+}
+
+// UnblockRequest is the abstract notion, sans DynamoDB magic (e.g. PK/SK)
+type UnblockRequest struct {
+	MachineID     string `dynamodbav:"MachineID" json:"machine_id"`
+	FileSHA256    string `dynamodbav:"FileSHA256" json:"file_sha256"`
+	FileName      string `dynamodbav:"FileName,omitempty" json:"file_name,omitempty"`
+	Requester     string `dynamodbav:"Requester" json:"requester"`
+	Justification string `dynamodbav:"Justification" json:"justification"`
+	RequestedAt   string `dynamodbav:"RequestedAt" json:"requested_at"`
+	Status        Status `dynamodbav:"Status" json:"status"`
+	DecidedBy     string `dynamodbav:"DecidedBy,omitempty" json:"decided_by,omitempty"`
+	DecidedAt     string `dynamodbav:"DecidedAt,omitempty" json:"decided_at,omitempty"`
+	// Reason is what the approver or denier said about their decision
+	Reason string `dynamodbav:"Reason,omitempty" json:"reason,omitempty"`
+}
+
+func unblockRequestSK(machineID string, fileSHA256 string) string {
+	return fmt.Sprintf("%s#%s", machineID, strings.ToLower(fileSHA256))
+}
+
+func GetUnblockRequestExpiresAfter(timeProvider clock.TimeProvider) int64 {
+	return clock.Unixtimestamp(timeProvider.Now().UTC().AddDate(0, 0, unblockRequestsExpiresAfterInDays))
+}
+
+func GetDataType() types.DataType {
+	return types.DataTypeUnblockRequest
+}
@@ -0,0 +1,89 @@
+package unblockrequests
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// GetUnblockRequest returns the request for the binary on the machine, or nil if there is none
+func GetUnblockRequest(client dynamodb.GetItemAPI, machineID string, fileSHA256 string) (row *UnblockRequestRow, err error) {
+	output, err := client.GetItem(
+		dynamodb.PrimaryKey{
+			PartitionKey: unblockRequestsPK,
+			SortKey:      unblockRequestSK(machineID, fileSHA256),
+		},
+		true,
+	)
+	if err != nil {
+		err = fmt.Errorf("failed to get unblock request: %w", err)
+		return
+	}
+	if len(output.Item) == 0 {
+		return
+	}
+
+	row = &UnblockRequestRow{}
+	if err = attributevalue.UnmarshalMap(output.Item, row); err != nil {
+		err = fmt.Errorf("failed to unmarshal unblock request: %w", err)
+		row = nil
+	}
+	return
+}
+
+// ListUnblockRequests returns every request that has not expired, with the given status or any status if it is
+// empty, oldest first so that the queue is worked in order
+func ListUnblockRequests(client dynamodb.QueryAPI, status Status) (items []UnblockRequestRow, err error) {
+	keyCond := expression.Key("PK").Equal(expression.Value(unblockRequestsPK))
+	builder := expression.NewBuilder().WithKeyCondition(keyCond)
+	if status != "" {
+		builder = builder.WithFilter(expression.Name("Status").Equal(expression.Value(status)))
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return
+	}
+
+	var exclusiveStartKey map[string]awstypes.AttributeValue
+	for {
+		input := &awsdynamodb.QueryInput{
+			ConsistentRead:            aws.Bool(false),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+			KeyConditionExpression:    expr.KeyCondition(),
+			FilterExpression:          expr.Filter(),
+			ExclusiveStartKey:         exclusiveStartKey,
+		}
+
+		var result *awsdynamodb.QueryOutput
+		result, err = client.Query(input)
+		if err != nil {
+			err = fmt.Errorf("failed to query unblock requests: %w", err)
+			return
+		}
+
+		var rows []UnblockRequestRow
+		err = attributevalue.UnmarshalListOfMaps(result.Items, &rows)
+		if err != nil {
+			err = fmt.Errorf("failed to unmarshal result from DynamoDB: %w", err)
+			return
+		}
+		items = append(items, rows...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		exclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].RequestedAt < items[j].RequestedAt
+	})
+	return
+}
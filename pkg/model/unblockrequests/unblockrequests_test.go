@@ -0,0 +1,155 @@
+package unblockrequests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	machineID  = "AAAAAAAA-A00A-1234-1234-5864377B4831"
+	fileSHA256 = "2dc104631939b4bdf5d6bccab76e166e37fe5e1605340cf68dab919df58b8eda"
+)
+
+type mockPutItem func(item interface{}) (*awsdynamodb.PutItemOutput, error)
+
+func (m mockPutItem) PutItem(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+	return m(item)
+}
+
+type mockGetItem func(key dynamodb.PrimaryKey, consistentRead bool) (*awsdynamodb.GetItemOutput, error)
+
+func (m mockGetItem) GetItem(key dynamodb.PrimaryKey, consistentRead bool) (*awsdynamodb.GetItemOutput, error) {
+	return m(key, consistentRead)
+}
+
+type mockQuery func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error)
+
+func (m mockQuery) Query(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+	return m(input)
+}
+
+func Test_FileUnblockRequest(t *testing.T) {
+	var stored UnblockRequestRow
+	client := mockPutItem(func(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+		stored = item.(UnblockRequestRow)
+		return &awsdynamodb.PutItemOutput{}, nil
+	})
+
+	row, err := FileUnblockRequest(client, clock.Y2K{}, UnblockRequest{
+		MachineID:     machineID,
+		FileSHA256:    strings.ToUpper(fileSHA256),
+		Requester:     "alice@example.com",
+		Justification: "  I need it for the build  ",
+		Status:        StatusApproved,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, row, stored)
+	assert.Equal(t, "UnblockRequests", stored.PartitionKey)
+	assert.Equal(t, machineID+"#"+fileSHA256, stored.SortKey)
+	assert.Equal(t, StatusPending, stored.Status)
+	assert.Equal(t, "I need it for the build", stored.Justification)
+	assert.Equal(t, "2000-01-01T00:00:00Z", stored.RequestedAt)
+	assert.Equal(t, GetDataType(), stored.DataType)
+}
+
+func Test_FileUnblockRequest_Invalid(t *testing.T) {
+	valid := UnblockRequest{MachineID: machineID, FileSHA256: fileSHA256, Justification: "why"}
+	tests := []struct {
+		name   string
+		modify func(*UnblockRequest)
+	}{
+		{"machine", func(r *UnblockRequest) { r.MachineID = "not-a-machine" }},
+		{"sha256", func(r *UnblockRequest) { r.FileSHA256 = "abc" }},
+		{"no justification", func(r *UnblockRequest) { r.Justification = " " }},
+		{"long justification", func(r *UnblockRequest) { r.Justification = strings.Repeat("a", MaxJustificationLength+1) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := valid
+			test.modify(&request)
+			_, err := FileUnblockRequest(nil, clock.Y2K{}, request)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_DecideUnblockRequest(t *testing.T) {
+	var stored UnblockRequestRow
+	client := mockPutItem(func(item interface{}) (*awsdynamodb.PutItemOutput, error) {
+		stored = item.(UnblockRequestRow)
+		return &awsdynamodb.PutItemOutput{}, nil
+	})
+	pending := UnblockRequestRow{UnblockRequest: UnblockRequest{MachineID: machineID, FileSHA256: fileSHA256, Status: StatusPending}}
+
+	row, err := DecideUnblockRequest(client, clock.Y2K{}, pending, StatusApproved, "bob@example.com", "ok for a week")
+	require.NoError(t, err)
+	assert.Equal(t, row, stored)
+	assert.Equal(t, StatusApproved, stored.Status)
+	assert.Equal(t, "bob@example.com", stored.DecidedBy)
+	assert.Equal(t, "2000-01-01T00:00:00Z", stored.DecidedAt)
+
+	_, err = DecideUnblockRequest(client, clock.Y2K{}, row, StatusDenied, "carol@example.com", "")
+	assert.ErrorContains(t, err, "already approved")
+	_, err = DecideUnblockRequest(client, clock.Y2K{}, pending, StatusPending, "carol@example.com", "")
+	assert.Error(t, err)
+}
+
+func Test_GetUnblockRequest(t *testing.T) {
+	client := mockGetItem(func(key dynamodb.PrimaryKey, consistentRead bool) (*awsdynamodb.GetItemOutput, error) {
+		if key.SortKey != machineID+"#"+fileSHA256 {
+			return &awsdynamodb.GetItemOutput{}, nil
+		}
+		item, err := attributevalue.MarshalMap(UnblockRequestRow{
+			PrimaryKey:     key,
+			UnblockRequest: UnblockRequest{MachineID: machineID, FileSHA256: fileSHA256, Status: StatusPending},
+			DataType:       GetDataType(),
+		})
+		return &awsdynamodb.GetItemOutput{Item: item}, err
+	})
+
+	row, err := GetUnblockRequest(client, machineID, strings.ToUpper(fileSHA256))
+	require.NoError(t, err)
+	require.NotNil(t, row)
+	assert.Equal(t, StatusPending, row.Status)
+
+	row, err = GetUnblockRequest(client, machineID, strings.Repeat("0", 64))
+	assert.NoError(t, err)
+	assert.Nil(t, row)
+}
+
+func Test_ListUnblockRequests(t *testing.T) {
+	pages := [][]UnblockRequest{
+		{{MachineID: "b", RequestedAt: "2000-01-02T00:00:00Z", Status: StatusPending}},
+		{{MachineID: "a", RequestedAt: "2000-01-01T00:00:00Z", Status: StatusPending}},
+	}
+	calls := 0
+	client := mockQuery(func(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+		assert.NotNil(t, input.FilterExpression)
+		var items []map[string]awstypes.AttributeValue
+		for _, request := range pages[calls] {
+			item, err := attributevalue.MarshalMap(UnblockRequestRow{UnblockRequest: request, DataType: GetDataType()})
+			require.NoError(t, err)
+			items = append(items, item)
+		}
+		output := &awsdynamodb.QueryOutput{Items: items}
+		calls++
+		if calls < len(pages) {
+			output.LastEvaluatedKey = map[string]awstypes.AttributeValue{"PK": &awstypes.AttributeValueMemberS{Value: "x"}}
+		}
+		return output, nil
+	})
+
+	rows, err := ListUnblockRequests(client, StatusPending)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "a", rows[0].MachineID)
+	assert.Equal(t, "b", rows[1].MachineID)
+}
@@ -0,0 +1,154 @@
+package oidc
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+)
+
+const (
+	// keysRefreshInterval is how long fetched keys are trusted before the JWKS is fetched again
+	keysRefreshInterval = time.Hour
+	// keysMinimumRefreshInterval stops tokens with unknown key IDs from making every request fetch the JWKS
+	keysMinimumRefreshInterval = time.Minute
+	fetchTimeout               = 5 * time.Second
+)
+
+// ErrUnknownKey is returned for tokens signed with a key that the identity provider does not publish
+var ErrUnknownKey = errors.New("token is signed with an unknown key")
+
+// KeySet returns the public key that signed a token
+type KeySet interface {
+	Key(keyID string) (*rsa.PublicKey, error)
+}
+
+// StaticKeySet is a fixed set of keys, by key ID
+type StaticKeySet map[string]*rsa.PublicKey
+
+func (s StaticKeySet) Key(keyID string) (*rsa.PublicKey, error) {
+	key, ok := s[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// RemoteKeySet fetches the keys of an identity provider from its JWKS, and refetches them when they are stale or a
+// token is signed with a key it has not seen yet, as happens when the provider rotates its keys.
+type RemoteKeySet struct {
+	// url is either the JWKS itself or the provider's openid-configuration, which points to the JWKS
+	url          string
+	httpClient   *http.Client
+	timeProvider clock.TimeProvider
+
+	mu        sync.Mutex
+	keys      StaticKeySet
+	fetchedAt time.Time
+}
+
+// NewRemoteKeySet returns a KeySet that fetches its keys from url, which is a JWKS or an openid-configuration
+func NewRemoteKeySet(url string, timeProvider clock.TimeProvider) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:          url,
+		httpClient:   &http.Client{Timeout: fetchTimeout},
+		timeProvider: timeProvider,
+	}
+}
+
+func (r *RemoteKeySet) Key(keyID string) (*rsa.PublicKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.timeProvider.Now()
+	age := now.Sub(r.fetchedAt)
+	if key, ok := r.keys[keyID]; ok && age < keysRefreshInterval {
+		return key, nil
+	}
+	if r.keys != nil && age < keysMinimumRefreshInterval {
+		return r.keys.Key(keyID)
+	}
+
+	keys, err := r.fetch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the signing keys from %s: %w", r.url, err)
+	}
+	r.keys = keys
+	r.fetchedAt = now
+	return r.keys.Key(keyID)
+}
+
+type jwks struct {
+	// JWKSURI is set when the url is an openid-configuration rather than the JWKS itself
+	JWKSURI string `json:"jwks_uri"`
+	Keys    []jwk  `json:"keys"`
+}
+
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+func (r *RemoteKeySet) fetch() (StaticKeySet, error) {
+	document, err := r.get(r.url)
+	if err != nil {
+		return nil, err
+	}
+	if document.JWKSURI != "" && len(document.Keys) == 0 {
+		if document, err = r.get(document.JWKSURI); err != nil {
+			return nil, err
+		}
+	}
+	return parseKeys(document.Keys)
+}
+
+func (r *RemoteKeySet) get(url string) (document jwks, err error) {
+	response, err := r.httpClient.Get(url)
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		err = fmt.Errorf("GET %s returned %s", url, response.Status)
+		return
+	}
+	err = json.NewDecoder(response.Body).Decode(&document)
+	return
+}
+
+// parseKeys keeps the RSA signing keys of a JWKS; encryption keys and other key types are skipped
+func parseKeys(keys []jwk) (StaticKeySet, error) {
+	set := StaticKeySet{}
+	for _, key := range keys {
+		if key.KeyType != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus for key %q: %w", key.KeyID, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent for key %q: %w", key.KeyID, err)
+		}
+		set[key.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(set) == 0 {
+		return nil, errors.New("no RSA signing keys found")
+	}
+	return set, nil
+}
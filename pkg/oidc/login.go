@@ -0,0 +1,133 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Login logs users in with the identity provider through the authorization code flow with PKCE, for the web console.
+// The ID token it obtains is what the admin API verifies.
+type Login struct {
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	ClientID              string
+	// ClientSecret is only needed by identity providers that treat the console as a confidential client
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+}
+
+type providerConfiguration struct {
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+// DiscoverLogin reads the endpoints of the issuer from its openid-configuration
+func DiscoverLogin(issuer string, clientID string, clientSecret string, redirectURL string) (*Login, error) {
+	if clientID == "" {
+		return nil, errors.New("a client ID is required")
+	}
+	httpClient := &http.Client{Timeout: fetchTimeout}
+	configurationURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+
+	response, err := httpClient.Get(configurationURL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %s", configurationURL, response.Status)
+	}
+	var configuration providerConfiguration
+	if err = json.NewDecoder(response.Body).Decode(&configuration); err != nil {
+		return nil, fmt.Errorf("invalid openid-configuration: %w", err)
+	}
+	if configuration.AuthorizationEndpoint == "" || configuration.TokenEndpoint == "" {
+		return nil, errors.New("the openid-configuration has no authorization or token endpoint")
+	}
+
+	return &Login{
+		AuthorizationEndpoint: configuration.AuthorizationEndpoint,
+		TokenEndpoint:         configuration.TokenEndpoint,
+		ClientID:              clientID,
+		ClientSecret:          clientSecret,
+		RedirectURL:           redirectURL,
+		Scopes:                []string{"openid", "email", "profile"},
+		HTTPClient:            httpClient,
+	}, nil
+}
+
+// NewState returns a random value that cannot be guessed, for the state and the PKCE verifier of a login
+func NewState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthCodeURL is where users are sent to log in. The identity provider sends them back to the redirect URL with the
+// same state, and a code that Exchange turns into a token with the same verifier.
+func (l *Login) AuthCodeURL(state string, verifier string) string {
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {l.ClientID},
+		"redirect_uri":          {l.RedirectURL},
+		"scope":                 {strings.Join(l.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(l.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return l.AuthorizationEndpoint + separator + query.Encode()
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange redeems the code that the identity provider sent the user back with for an ID token
+func (l *Login) Exchange(code string, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {l.RedirectURL},
+		"client_id":     {l.ClientID},
+		"code_verifier": {verifier},
+	}
+	if l.ClientSecret != "" {
+		form.Set("client_secret", l.ClientSecret)
+	}
+
+	response, err := l.HTTPClient.PostForm(l.TokenEndpoint, form)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	var token tokenResponse
+	if err = json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("invalid token response (%s): %w", response.Status, err)
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("identity provider refused the login: %s %s", token.Error, token.ErrorDescription)
+	}
+	if response.StatusCode != http.StatusOK || token.IDToken == "" {
+		return "", fmt.Errorf("identity provider returned no ID token (%s)", response.Status)
+	}
+	return token.IDToken, nil
+}
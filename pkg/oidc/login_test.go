@@ -0,0 +1,70 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Login(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"authorization_endpoint": server.URL + "/authorize",
+				"token_endpoint":         server.URL + "/token",
+			})
+		case "/token":
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "authorization_code", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "console", r.PostForm.Get("client_id"))
+			assert.Empty(t, r.PostForm.Get("client_secret"))
+			if r.PostForm.Get("code") != "good-code" || r.PostForm.Get("code_verifier") != "verifier" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"bad code"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id_token":"header.claims.signature","access_token":"opaque"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	login, err := DiscoverLogin(server.URL+"/", "console", "", "https://console.example.com/callback")
+	require.NoError(t, err)
+
+	authURL, err := url.Parse(login.AuthCodeURL("state", "verifier"))
+	require.NoError(t, err)
+	assert.Equal(t, "/authorize", authURL.Path)
+	challenge := sha256.Sum256([]byte("verifier"))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(challenge[:]), authURL.Query().Get("code_challenge"))
+	assert.Equal(t, "state", authURL.Query().Get("state"))
+	assert.Equal(t, "openid email profile", authURL.Query().Get("scope"))
+	assert.Equal(t, "https://console.example.com/callback", authURL.Query().Get("redirect_uri"))
+
+	token, err := login.Exchange("good-code", "verifier")
+	require.NoError(t, err)
+	assert.Equal(t, "header.claims.signature", token)
+
+	_, err = login.Exchange("good-code", "another-verifier")
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func Test_NewState(t *testing.T) {
+	a, err := NewState()
+	require.NoError(t, err)
+	b, err := NewState()
+	require.NoError(t, err)
+
+	assert.Len(t, a, 43)
+	assert.NotEqual(t, a, b)
+}
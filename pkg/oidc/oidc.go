@@ -0,0 +1,176 @@
+// Package oidc verifies the ID and access tokens issued by an OpenID Connect identity provider, such as Okta, Azure AD
+// or Google, so that the admin API can tell who is calling it without handing out AWS credentials. It also logs users
+// in to the web console, which gets the ID tokens that it sends to the admin API on their behalf.
+//
+// Only RS256 signed JWTs are supported, which is what every major identity provider issues by default.
+package oidc
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+)
+
+// clockSkew is how far the clocks of the identity provider and Lambda can drift before valid tokens are refused
+const clockSkew = 2 * time.Minute
+
+var (
+	ErrMalformedToken       = errors.New("malformed token")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm; only RS256 is supported")
+	ErrInvalidSignature     = errors.New("invalid token signature")
+	ErrExpiredToken         = errors.New("token is expired")
+	ErrInvalidIssuer        = errors.New("token was issued by another issuer")
+	ErrInvalidAudience      = errors.New("token was issued for another audience")
+)
+
+// Claims are the claims of a verified token
+type Claims map[string]interface{}
+
+// String returns a string claim, or an empty string if the claim is missing or not a string
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Strings returns a claim that is a list of strings, such as groups. A single string is returned as a list of one.
+func (c Claims) Strings(name string) []string {
+	switch value := c[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// time returns a NumericDate claim, and whether it was present
+func (c Claims) time(name string) (time.Time, bool) {
+	value, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(value), 0), true
+}
+
+// audiences returns the aud claim, which is either a single string or a list of strings
+func (c Claims) audiences() []string {
+	return c.Strings("aud")
+}
+
+// Verifier checks the signature, issuer, audience and lifetime of tokens
+type Verifier struct {
+	Issuer   string
+	Audience string
+	Keys     KeySet
+	Time     clock.TimeProvider
+}
+
+// NewVerifier returns a Verifier for tokens of the given issuer and audience, signed with keys from the issuer's
+// JWKS. When jwksURL is empty, it is discovered from the issuer's openid-configuration.
+func NewVerifier(issuer string, audience string, jwksURL string, timeProvider clock.TimeProvider) (*Verifier, error) {
+	if issuer == "" {
+		return nil, errors.New("an issuer is required")
+	}
+	if audience == "" {
+		return nil, errors.New("an audience is required")
+	}
+	if jwksURL == "" {
+		jwksURL = strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	}
+	return &Verifier{
+		Issuer:   issuer,
+		Audience: audience,
+		Keys:     NewRemoteKeySet(jwksURL, timeProvider),
+		Time:     timeProvider,
+	}, nil
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// Verify returns the claims of a token, if it is valid
+func (v *Verifier) Verify(token string) (claims Claims, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var h header
+	if err = decodeSegment(parts[0], &h); err != nil {
+		return nil, err
+	}
+	if h.Algorithm != "RS256" {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	key, err := v.Keys.Key(h.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err = v.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *Verifier) validate(claims Claims) error {
+	now := v.Time.Now()
+
+	expires, ok := claims.time("exp")
+	if !ok || now.After(expires.Add(clockSkew)) {
+		return ErrExpiredToken
+	}
+	if notBefore, ok := claims.time("nbf"); ok && now.Add(clockSkew).Before(notBefore) {
+		return fmt.Errorf("%w: not valid before %s", ErrExpiredToken, notBefore.UTC().Format(time.RFC3339))
+	}
+
+	if claims.String("iss") != v.Issuer {
+		return ErrInvalidIssuer
+	}
+	for _, audience := range claims.audiences() {
+		if audience == v.Audience {
+			return nil
+		}
+	}
+	return ErrInvalidAudience
+}
+
+func decodeSegment(segment string, into interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformedToken
+	}
+	if err = json.Unmarshal(raw, into); err != nil {
+		return ErrMalformedToken
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://idp.example.com"
+	testAudience = "rudolph-admin"
+)
+
+var testNow = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func sign(t *testing.T, key *rsa.PrivateKey, keyID string, algorithm string, claims map[string]interface{}) string {
+	encode := func(v interface{}) string {
+		raw, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+	signingInput := encode(map[string]string{"alg": algorithm, "kid": keyID, "typ": "JWT"}) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":    testIssuer,
+		"aud":    testAudience,
+		"sub":    "00u1abcd",
+		"email":  "alice@example.com",
+		"groups": []string{"it-helpdesk", "santa-admins"},
+		"exp":    testNow.Add(time.Hour).Unix(),
+		"iat":    testNow.Add(-time.Minute).Unix(),
+	}
+}
+
+func newTestVerifier(t *testing.T) (*Verifier, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return &Verifier{
+		Issuer:   testIssuer,
+		Audience: testAudience,
+		Keys:     StaticKeySet{"key-1": &key.PublicKey},
+		Time:     clock.FrozenTimeProvider{Current: testNow},
+	}, key
+}
+
+func Test_Verify_Valid(t *testing.T) {
+	verifier, key := newTestVerifier(t)
+
+	claims, err := verifier.Verify(sign(t, key, "key-1", "RS256", validClaims()))
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", claims.String("email"))
+	assert.Equal(t, []string{"it-helpdesk", "santa-admins"}, claims.Strings("groups"))
+	assert.Equal(t, []string{testAudience}, claims.audiences())
+}
+
+func Test_Verify_AudienceList(t *testing.T) {
+	verifier, key := newTestVerifier(t)
+	claims := validClaims()
+	claims["aud"] = []string{"something-else", testAudience}
+
+	_, err := verifier.Verify(sign(t, key, "key-1", "RS256", claims))
+
+	assert.NoError(t, err)
+}
+
+func Test_Verify_Invalid(t *testing.T) {
+	verifier, key := newTestVerifier(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	withClaim := func(name string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"malformed", "not-a-token", ErrMalformedToken},
+		{"wrong algorithm", sign(t, key, "key-1", "HS256", validClaims()), ErrUnsupportedAlgorithm},
+		{"unknown key", sign(t, key, "key-2", "RS256", validClaims()), ErrUnknownKey},
+		{"wrong key", sign(t, otherKey, "key-1", "RS256", validClaims()), ErrInvalidSignature},
+		{"expired", sign(t, key, "key-1", "RS256", withClaim("exp", testNow.Add(-time.Hour).Unix())), ErrExpiredToken},
+		{"no expiry", sign(t, key, "key-1", "RS256", withClaim("exp", nil)), ErrExpiredToken},
+		{"not yet valid", sign(t, key, "key-1", "RS256", withClaim("nbf", testNow.Add(time.Hour).Unix())), ErrExpiredToken},
+		{"wrong issuer", sign(t, key, "key-1", "RS256", withClaim("iss", "https://evil.example.com")), ErrInvalidIssuer},
+		{"wrong audience", sign(t, key, "key-1", "RS256", withClaim("aud", "another-app")), ErrInvalidAudience},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := verifier.Verify(test.token)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func Test_Verify_ExpiredWithinClockSkew(t *testing.T) {
+	verifier, key := newTestVerifier(t)
+	claims := validClaims()
+	claims["exp"] = testNow.Add(-time.Minute).Unix()
+
+	_, err := verifier.Verify(sign(t, key, "key-1", "RS256", claims))
+
+	assert.NoError(t, err)
+}
+
+func Test_RemoteKeySet_Discovery(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	fetches := 0
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": testIssuer, "jwks_uri": server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{
+				{"kty": "EC", "kid": "ec-key", "crv": "P-256"},
+				{
+					"kty": "RSA",
+					"kid": "key-1",
+					"use": "sig",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	})
+
+	timeProvider := &clock.FrozenTimeProvider{Current: testNow}
+	keys := NewRemoteKeySet(server.URL+"/.well-known/openid-configuration", timeProvider)
+
+	publicKey, err := keys.Key("key-1")
+	require.NoError(t, err)
+	assert.Equal(t, key.PublicKey.N, publicKey.N)
+	assert.Equal(t, key.PublicKey.E, publicKey.E)
+
+	// Unknown keys do not refetch the JWKS more than once a minute
+	_, err = keys.Key("key-2")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, 1, fetches)
+
+	timeProvider.Current = testNow.Add(2 * time.Minute)
+	_, err = keys.Key("key-2")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, 2, fetches)
+}
+
+func Test_NewVerifier_RequiresIssuerAndAudience(t *testing.T) {
+	_, err := NewVerifier("", testAudience, "", clock.ConcreteTimeProvider{})
+	assert.Error(t, err)
+
+	_, err = NewVerifier(testIssuer, "", "", clock.ConcreteTimeProvider{})
+	assert.Error(t, err)
+
+	verifier, err := NewVerifier(testIssuer+"/", testAudience, "", clock.ConcreteTimeProvider{})
+	require.NoError(t, err)
+	assert.Equal(t, testIssuer+"/.well-known/openid-configuration", verifier.Keys.(*RemoteKeySet).url)
+}
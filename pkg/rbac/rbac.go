@@ -0,0 +1,160 @@
+// Package rbac defines the roles of admin API callers, what each role may do, and how roles are granted by the groups
+// of the identity provider.
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Role is a set of permissions granted to admin API callers
+type Role string
+
+const (
+	// Viewer may look up, list and export machines, rules and configs
+	Viewer Role = "viewer"
+	// Responder may also unblock files on single machines, for a limited time
+	Responder Role = "responder"
+	// RuleAdmin may also manage global rules and any machine rules
+	RuleAdmin Role = "rule-admin"
+	// FleetAdmin may also manage the global config and the configs of machines, including their client mode
+	FleetAdmin Role = "fleet-admin"
+)
+
+// AllRoles lists every role, from the least to the most privileged
+var AllRoles = []Role{Viewer, Responder, RuleAdmin, FleetAdmin}
+
+// Permission is something that a role allows
+type Permission string
+
+const (
+	// View allows reading machines, rules and configs
+	View Permission = "view"
+	// Unblock allows allowlisting a file on a single machine until at most MaxUnblockDuration from now, and removing
+	// such allowlist rules
+	Unblock Permission = "unblock"
+	// ManageMachineRules allows creating, changing and removing any machine rule
+	ManageMachineRules Permission = "manage-machine-rules"
+	// ManageGlobalRules allows creating, changing and removing global rules
+	ManageGlobalRules Permission = "manage-global-rules"
+	// ManageConfigs allows changing the global config and the configs of machines
+	ManageConfigs Permission = "manage-configs"
+)
+
+// MaxUnblockDuration is the longest that an unblock by a responder may last
+const MaxUnblockDuration = 7 * 24 * time.Hour
+
+var permissions = map[Role][]Permission{
+	Viewer:     {View},
+	Responder:  {View, Unblock},
+	RuleAdmin:  {View, Unblock, ManageMachineRules, ManageGlobalRules},
+	FleetAdmin: {View, ManageConfigs},
+}
+
+// ParseRole parses the name of a role
+func ParseRole(text string) (Role, error) {
+	for _, role := range AllRoles {
+		if string(role) == text {
+			return role, nil
+		}
+	}
+	return "", fmt.Errorf("unknown role %q", text)
+}
+
+// Roles are the roles of a caller
+type Roles []Role
+
+// ParseRoles reads roles as written by Roles.String, skipping any that are unknown
+func ParseRoles(text string) (roles Roles) {
+	for _, name := range strings.Split(text, ",") {
+		if role, err := ParseRole(strings.TrimSpace(name)); err == nil {
+			roles = append(roles, role)
+		}
+	}
+	return
+}
+
+// String joins the roles with commas, since API Gateway only passes strings from the authorizer to the handlers
+func (r Roles) String() string {
+	names := make([]string, len(r))
+	for i, role := range r {
+		names[i] = string(role)
+	}
+	return strings.Join(names, ",")
+}
+
+// Can reports whether any of the roles allows the permission
+func (r Roles) Can(permission Permission) bool {
+	for _, role := range r {
+		for _, p := range permissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RolesWith returns the roles that allow a permission
+func RolesWith(permission Permission) (roles Roles) {
+	for _, role := range AllRoles {
+		if (Roles{role}).Can(permission) {
+			roles = append(roles, role)
+		}
+	}
+	return
+}
+
+// GroupMapping names, for each role, the identity provider groups that grant it
+type GroupMapping map[Role][]string
+
+// DefaultGroupMapping grants each role to the group with the same name, which also suits tokens that carry the roles
+// themselves in a claim
+func DefaultGroupMapping() GroupMapping {
+	mapping := GroupMapping{}
+	for _, role := range AllRoles {
+		mapping[role] = []string{string(role)}
+	}
+	return mapping
+}
+
+// ParseGroupMapping reads a mapping written as a JSON object from role names to lists of groups, such as
+// {"viewer": ["it-support"], "fleet-admin": ["endpoint-engineering"]}. Without one, the default mapping applies.
+func ParseGroupMapping(text string) (GroupMapping, error) {
+	if strings.TrimSpace(text) == "" {
+		return DefaultGroupMapping(), nil
+	}
+
+	var raw map[string][]string
+	if err := json.Unmarshal([]byte(text), &raw); err != nil {
+		return nil, fmt.Errorf("invalid role mapping: %w", err)
+	}
+	mapping := GroupMapping{}
+	for name, groups := range raw {
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid role mapping: %w", err)
+		}
+		mapping[role] = groups
+	}
+	return mapping, nil
+}
+
+// Roles returns the roles that the groups grant, in the order of AllRoles
+func (m GroupMapping) Roles(groups []string) (roles Roles) {
+	member := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		member[group] = true
+	}
+	for _, role := range AllRoles {
+		for _, group := range m[role] {
+			if member[group] {
+				roles = append(roles, role)
+				break
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,61 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Roles_Can(t *testing.T) {
+	tests := []struct {
+		roles      Roles
+		permission Permission
+		expected   bool
+	}{
+		{Roles{Viewer}, View, true},
+		{Roles{Viewer}, Unblock, false},
+		{Roles{Responder}, Unblock, true},
+		{Roles{Responder}, ManageMachineRules, false},
+		{Roles{RuleAdmin}, ManageGlobalRules, true},
+		{Roles{RuleAdmin}, ManageConfigs, false},
+		{Roles{FleetAdmin}, ManageConfigs, true},
+		{Roles{FleetAdmin}, Unblock, false},
+		{Roles{Viewer, FleetAdmin}, ManageConfigs, true},
+		{nil, View, false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.roles.Can(test.permission), "%v %s", test.roles, test.permission)
+	}
+}
+
+func Test_RolesWith(t *testing.T) {
+	assert.Equal(t, Roles{Responder, RuleAdmin}, RolesWith(Unblock))
+	assert.Equal(t, Roles{FleetAdmin}, RolesWith(ManageConfigs))
+}
+
+func Test_Roles_RoundTrip(t *testing.T) {
+	roles := Roles{Viewer, RuleAdmin}
+
+	assert.Equal(t, "viewer,rule-admin", roles.String())
+	assert.Equal(t, roles, ParseRoles(roles.String()))
+	assert.Equal(t, Roles{FleetAdmin}, ParseRoles("root, fleet-admin"))
+	assert.Empty(t, ParseRoles(""))
+}
+
+func Test_GroupMapping(t *testing.T) {
+	mapping, err := ParseGroupMapping(`{"viewer": ["it-support", "security"], "fleet-admin": ["endpoint-engineering"]}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, Roles{Viewer, FleetAdmin}, mapping.Roles([]string{"endpoint-engineering", "security", "everyone"}))
+	assert.Empty(t, mapping.Roles([]string{"rule-admin"}))
+
+	mapping, err = ParseGroupMapping("")
+	require.NoError(t, err)
+	assert.Equal(t, Roles{Responder, RuleAdmin}, mapping.Roles([]string{"rule-admin", "responder"}))
+
+	_, err = ParseGroupMapping(`{"superuser": ["admins"]}`)
+	assert.Error(t, err)
+	_, err = ParseGroupMapping(`["admins"]`)
+	assert.Error(t, err)
+}
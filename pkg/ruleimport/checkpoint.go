@@ -0,0 +1,117 @@
+package ruleimport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Checkpoint records how far the import of a rules file got, so that an interrupted import can be resumed without
+// writing every rule again
+type Checkpoint struct {
+	Filename string `json:"filename"`
+	// SHA256 is the hash of the rules file; a checkpoint cannot be resumed once the file has changed
+	SHA256 string `json:"sha256"`
+	// Completed is the number of converted rules, in file order, that have all been imported
+	Completed int `json:"completed"`
+}
+
+// HashFile returns the SHA256 of a rules file, for its Checkpoint
+func HashFile(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ReadCheckpoint reads a checkpoint file; found is false when the file does not exist yet
+func ReadCheckpoint(path string) (checkpoint Checkpoint, found bool, err error) {
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+		return
+	} else if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(contents, &checkpoint)
+	if err != nil {
+		err = fmt.Errorf("failed to parse checkpoint %q: %w", path, err)
+		return
+	}
+	found = true
+	return
+}
+
+// WriteCheckpoint replaces a checkpoint file atomically, so that an interrupted write never leaves it corrupt
+func WriteCheckpoint(path string, checkpoint Checkpoint) error {
+	contents, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = temp.Write(contents)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// Progress tracks the rules of an import that have completed. Rules are imported concurrently and complete in any
+// order, but an import can only be resumed after the rules that all completed, so Progress counts those.
+type Progress struct {
+	mu        sync.Mutex
+	completed int
+	done      map[int]bool
+}
+
+// NewProgress starts tracking an import that has already completed the given number of rules
+func NewProgress(completed int) *Progress {
+	return &Progress{
+		completed: completed,
+		done:      make(map[int]bool),
+	}
+}
+
+// Done marks the rule at index, counted from 0 in file order, as imported, and returns the number of rules that have
+// all been imported
+func (p *Progress) Done(index int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done[index] = true
+	for p.done[p.completed] {
+		delete(p.done, p.completed)
+		p.completed++
+	}
+	return p.completed
+}
+
+// Completed returns the number of rules that have all been imported
+func (p *Progress) Completed() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.completed
+}
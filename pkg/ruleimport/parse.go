@@ -0,0 +1,283 @@
+package ruleimport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/airbnb/rudolph/internal/csv"
+)
+
+// Parse converts the contents of a rules file in memory; see Stream
+func Parse(format Format, contents []byte) (result Result, err error) {
+	result.Unconvertible, err = Stream(format, bytes.NewReader(contents), func(rule ImportedRule) error {
+		result.Rules = append(result.Rules, rule)
+		return nil
+	})
+	return
+}
+
+// Stream reads a rules file entry by entry and calls handle with every rule, in file order, as soon as it has been
+// converted. Entries that cannot be converted are returned instead, so that all of them can be reported at once.
+//
+// csv and json files are read without loading them in memory, and their entries are located by line number. Moroz
+// TOML files are read in full and their entries are located by their index.
+//
+// Stream stops at the first error returned by handle, and at syntax errors that prevent reading the rest of the file.
+func Stream(format Format, r io.Reader, handle func(ImportedRule) error) (unconvertible []Unconvertible, err error) {
+	s := &streamer{handle: handle}
+	switch format {
+	case FormatCSV:
+		err = s.streamCSV(r)
+	case FormatJSON:
+		err = s.streamJSON(r)
+	case FormatMoroz:
+		err = s.streamMoroz(r)
+	case FormatYAML:
+		err = s.streamYAML(r)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	unconvertible = s.unconvertible
+	return
+}
+
+type streamer struct {
+	handle        func(ImportedRule) error
+	unconvertible []Unconvertible
+}
+
+func (s *streamer) add(raw rawRule, position string) error {
+	rule, err := raw.convert(position)
+	if err != nil {
+		s.skip(position, err)
+		return nil
+	}
+	return s.handle(rule)
+}
+
+func (s *streamer) skip(position string, reason error) {
+	s.unconvertible = append(s.unconvertible, Unconvertible{Position: position, Reason: reason.Error()})
+}
+
+// ParseCSVRecord converts a single line of a csv file, keyed by the header row
+func ParseCSVRecord(record map[string]string, position string) (ImportedRule, error) {
+	return csvRawRule(record).convert(position)
+}
+
+func csvRawRule(record map[string]string) rawRule {
+	return rawRule{
+		Identifier:    record["identifier"],
+		SHA256:        record["sha256"],
+		RuleType:      record["rule_type"],
+		Type:          record["type"],
+		Policy:        record["policy"],
+		CustomMessage: record["custom_msg"],
+		Description:   record["description"],
+	}
+}
+
+func (s *streamer) streamCSV(r io.Reader) (err error) {
+	records, err := csv.ParseCsv(r)
+	if err != nil {
+		return fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	for record := range records {
+		// Keep draining the records after an error, so that the reading goroutine can exit
+		if err != nil {
+			continue
+		}
+		position := fmt.Sprintf("line %d", record.Line)
+		if record.Err != nil {
+			s.skip(position, record.Err)
+			continue
+		}
+		err = s.add(csvRawRule(record.Data), position)
+	}
+	return
+}
+
+// streamJSON reads either Rudolph's json export, which is a list of rules, or santactl rule --export, which is an
+// object holding the list of rules
+func (s *streamer) streamJSON(r io.Reader) error {
+	lines := &lineCounter{reader: r}
+	decoder := json.NewDecoder(lines)
+
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return jsonError(lines, decoder, err)
+	}
+
+	switch token {
+	case json.Delim('['):
+		return s.streamJSONList(lines, decoder)
+	case json.Delim('{'):
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return jsonError(lines, decoder, err)
+			}
+			if key != "rules" {
+				var ignored json.RawMessage
+				if err = decoder.Decode(&ignored); err != nil {
+					return jsonError(lines, decoder, err)
+				}
+				continue
+			}
+
+			token, err = decoder.Token()
+			if err != nil {
+				return jsonError(lines, decoder, err)
+			}
+			if token != json.Delim('[') {
+				return fmt.Errorf("failed to parse json: rules must be a list, at line %d", lines.lineAt(decoder.InputOffset()))
+			}
+			if err = s.streamJSONList(lines, decoder); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return errors.New("failed to parse json: expected a list of rules, or an object with a list of rules")
+}
+
+func (s *streamer) streamJSONList(lines *lineCounter, decoder *json.Decoder) error {
+	for decoder.More() {
+		var entry json.RawMessage
+		if err := decoder.Decode(&entry); err != nil {
+			return jsonError(lines, decoder, err)
+		}
+		// The raw message holds the entry exactly, so it starts this many bytes before the decoder's position
+		position := fmt.Sprintf("line %d", lines.lineAt(decoder.InputOffset()-int64(len(entry))))
+
+		var raw rawRule
+		if err := json.Unmarshal(entry, &raw); err != nil {
+			s.skip(position, err)
+			continue
+		}
+		if err := s.add(raw, position); err != nil {
+			return err
+		}
+	}
+
+	// Consume the closing ]
+	if _, err := decoder.Token(); err != nil {
+		return jsonError(lines, decoder, err)
+	}
+	return nil
+}
+
+func jsonError(lines *lineCounter, decoder *json.Decoder, err error) error {
+	offset := decoder.InputOffset()
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("failed to parse json at line %d: %w", lines.lineAt(offset), err)
+}
+
+// streamMoroz reads the [[rules]] tables of a Moroz configuration; the other settings of the configuration, such as
+// client_mode, are not rules and are ignored
+func (s *streamer) streamMoroz(r io.Reader) error {
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var config struct {
+		Rules []rawRule `toml:"rules"`
+	}
+	err = toml.Unmarshal(contents, &config)
+	if err != nil {
+		return fmt.Errorf("failed to parse Moroz TOML configuration: %w", err)
+	}
+
+	for i, raw := range config.Rules {
+		if err = s.add(raw, fmt.Sprintf("rule #%d", i+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamYAML reads a list of rules, either at the top level or under a "rules" key
+func (s *streamer) streamYAML(r io.Reader) error {
+	var document yaml.Node
+	err := yaml.NewDecoder(r).Decode(&document)
+	if err == io.EOF || len(document.Content) == 0 {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to parse yaml: %w", err)
+	}
+
+	list := document.Content[0]
+	if list.Kind == yaml.MappingNode {
+		list = nil
+		for i := 0; i+1 < len(document.Content[0].Content); i += 2 {
+			if document.Content[0].Content[i].Value == "rules" {
+				list = document.Content[0].Content[i+1]
+			}
+		}
+		if list == nil {
+			return nil
+		}
+	}
+	if list.Kind != yaml.SequenceNode {
+		return fmt.Errorf("failed to parse yaml: expected a list of rules at line %d", list.Line)
+	}
+
+	for _, item := range list.Content {
+		position := fmt.Sprintf("line %d", item.Line)
+
+		var raw rawRule
+		if err = item.Decode(&raw); err != nil {
+			s.skip(position, err)
+			continue
+		}
+		if err = s.add(raw, position); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lineCounter remembers where the lines of a reader start, so that offsets can be turned into line numbers
+type lineCounter struct {
+	reader io.Reader
+	read   int64
+	// newlines holds the offsets of the newlines that follow the last offset looked up
+	newlines []int64
+	// passed counts the newlines before the last offset looked up
+	passed int
+}
+
+func (c *lineCounter) Read(p []byte) (n int, err error) {
+	n, err = c.reader.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			c.newlines = append(c.newlines, c.read+int64(i))
+		}
+	}
+	c.read += int64(n)
+	return
+}
+
+// lineAt returns the line number of the byte at offset. Offsets must be looked up in increasing order.
+func (c *lineCounter) lineAt(offset int64) int {
+	for len(c.newlines) > 0 && c.newlines[0] < offset {
+		c.newlines = c.newlines[1:]
+		c.passed++
+	}
+	return c.passed + 1
+}
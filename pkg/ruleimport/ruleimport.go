@@ -0,0 +1,176 @@
+// Package ruleimport converts rules exported from Rudolph and from other Santa sync servers into Rudolph global rules.
+//
+// Supported formats are Rudolph's own csv and json exports, the json written by santactl rule --export, Moroz TOML
+// configurations and a generic YAML list of rules. Entries that cannot be converted are reported, not dropped silently.
+package ruleimport
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// Format is the format of a rules file
+type Format string
+
+const (
+	// FormatCSV is Rudolph's csv export
+	FormatCSV Format = "csv"
+	// FormatJSON is either Rudolph's json export (a list of rules) or santactl rule --export (an object with a list of
+	// rules); the two are told apart by their shape
+	FormatJSON Format = "json"
+	// FormatMoroz is a Moroz TOML configuration
+	FormatMoroz Format = "moroz"
+	// FormatYAML is a YAML list of rules, optionally under a top-level "rules" key
+	FormatYAML Format = "yaml"
+)
+
+// ParseFormat accepts the name of a Format
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "csv":
+		return FormatCSV, nil
+	case "json", "santactl":
+		return FormatJSON, nil
+	case "moroz", "toml":
+		return FormatMoroz, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("unknown format %q; must be one of: csv, json, santactl, moroz, yaml", s)
+}
+
+// DetectFormat guesses the format of a rules file from its extension
+func DetectFormat(filename string) (Format, error) {
+	extension := strings.TrimPrefix(filepath.Ext(filename), ".")
+	if extension == "" {
+		return "", fmt.Errorf("cannot tell the format of %q from its extension; specify it explicitly", filename)
+	}
+	return ParseFormat(extension)
+}
+
+// ImportedRule is a global rule converted from a rules file
+type ImportedRule struct {
+	rules.SantaRule
+	Description string
+	// Position locates the entry in the rules file, e.g. "line 12", or "rule #3" for formats without line numbers
+	Position string
+}
+
+// Unconvertible is an entry of a rules file that cannot be converted into a global rule, and why
+type Unconvertible struct {
+	Position string `json:"position"`
+	Reason   string `json:"reason"`
+}
+
+// Result holds the outcome of parsing a rules file
+type Result struct {
+	Rules         []ImportedRule
+	Unconvertible []Unconvertible
+}
+
+// rawRule has the union of the fields used by every supported format, before any of them are interpreted
+type rawRule struct {
+	Identifier string `json:"identifier" toml:"identifier" yaml:"identifier"`
+	// SHA256 is used instead of Identifier by older exports
+	SHA256 string `json:"sha256" toml:"sha256" yaml:"sha256"`
+	// RuleType is used by Santa, Moroz and santactl; Type is used by Rudolph
+	RuleType      string `json:"rule_type" toml:"rule_type" yaml:"rule_type"`
+	Type          string `json:"type" toml:"type" yaml:"type"`
+	Policy        string `json:"policy" toml:"policy" yaml:"policy"`
+	CustomMessage string `json:"custom_msg" toml:"custom_msg" yaml:"custom_msg"`
+	Description   string `json:"description" toml:"description" yaml:"description"`
+	// Comment is santactl's name for the description
+	Comment string `json:"comment" toml:"comment" yaml:"comment"`
+}
+
+func (raw rawRule) convert(position string) (imported ImportedRule, err error) {
+	identifier := strings.TrimSpace(raw.Identifier)
+	if identifier == "" {
+		identifier = strings.TrimSpace(raw.SHA256)
+	}
+	if identifier == "" {
+		err = errors.New("no identifier")
+		return
+	}
+
+	ruleTypeName := raw.RuleType
+	if ruleTypeName == "" {
+		ruleTypeName = raw.Type
+	}
+	ruleType, err := convertRuleType(ruleTypeName)
+	if err != nil {
+		return
+	}
+	policy, err := convertPolicy(raw.Policy)
+	if err != nil {
+		return
+	}
+
+	switch ruleType {
+	case types.RuleTypeBinary, types.RuleTypeCertificate, types.RuleTypeCDHash:
+		identifier = strings.ToLower(identifier)
+	}
+
+	imported = ImportedRule{
+		SantaRule: rules.SantaRule{
+			RuleType:      ruleType,
+			Policy:        policy,
+			Identifier:    identifier,
+			CustomMessage: raw.CustomMessage,
+		},
+		Description: raw.Description,
+		Position:    position,
+	}
+	if imported.Description == "" {
+		imported.Description = raw.Comment
+	}
+
+	err = globalrules.ValidateRule(imported.SantaRule)
+	return
+}
+
+func convertRuleType(name string) (ruleType types.RuleType, err error) {
+	normalized := strings.ToUpper(strings.TrimSpace(name))
+	switch normalized {
+	case "":
+		err = errors.New("no rule type")
+		return
+	case "CERT":
+		normalized = "CERTIFICATE"
+	case "SIGNING_ID":
+		normalized = "SIGNINGID"
+	case "TEAM_ID":
+		normalized = "TEAMID"
+	}
+	err = ruleType.UnmarshalText([]byte(normalized))
+	return
+}
+
+// convertPolicy also accepts the names that Santa and Moroz used before they were renamed
+func convertPolicy(name string) (policy types.Policy, err error) {
+	normalized := strings.ToUpper(strings.TrimSpace(name))
+	switch normalized {
+	case "":
+		err = errors.New("no policy")
+		return
+	case "WHITELIST":
+		normalized = "ALLOWLIST"
+	case "BLACKLIST":
+		normalized = "BLOCKLIST"
+	case "SILENT_BLACKLIST":
+		normalized = "SILENT_BLOCKLIST"
+	case "WHITELIST_COMPILER":
+		normalized = "ALLOWLIST_COMPILER"
+	case "ALLOWLIST_TRANSITIVE", "WHITELIST_TRANSITIVE":
+		err = errors.New("transitive rules are created by the sensor itself and cannot be imported")
+		return
+	}
+	err = policy.UnmarshalText([]byte(normalized))
+	return
+}
@@ -0,0 +1,261 @@
+package ruleimport
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testSHA  = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	testCert = "0000000b28b738354c43a11486651ca33266e2b7454477d6b351df09c2e97faf"
+)
+
+func Test_Parse_Moroz(t *testing.T) {
+	contents := []byte(`
+client_mode = "MONITOR"
+blocklist_regex = "^(?:/Users)/.*"
+
+[[rules]]
+rule_type = "BINARY"
+policy = "BLACKLIST"
+sha256 = "2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824"
+custom_msg = "blocklist firefox"
+
+[[rules]]
+rule_type = "CERTIFICATE"
+policy = "ALLOWLIST"
+identifier = "0000000b28b738354c43a11486651ca33266e2b7454477d6b351df09c2e97faf"
+
+[[rules]]
+rule_type = "BINARY"
+policy = "ALLOWLIST_TRANSITIVE"
+sha256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+`)
+
+	result, err := Parse(FormatMoroz, contents)
+
+	assert.Empty(t, err)
+	assert.Equal(t, []ImportedRule{
+		{
+			SantaRule: rules.SantaRule{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyBlocklist, Identifier: testSHA, CustomMessage: "blocklist firefox"},
+			Position:  "rule #1",
+		},
+		{
+			SantaRule: rules.SantaRule{RuleType: types.RuleTypeCertificate, Policy: types.RulePolicyAllowlist, Identifier: testCert},
+			Position:  "rule #2",
+		},
+	}, result.Rules)
+	assert.Equal(t, []Unconvertible{
+		{Position: "rule #3", Reason: "transitive rules are created by the sensor itself and cannot be imported"},
+	}, result.Unconvertible)
+}
+
+func Test_Parse_SantactlExport(t *testing.T) {
+	contents := []byte(`{
+  "rules": [
+    {"identifier": "EQHXZ8M8AV:com.google.Chrome", "policy": "ALLOWLIST", "rule_type": "SIGNINGID", "comment": "Chrome"},
+    {"identifier": "EQHXZ8M8AV", "policy": "BLOCKLIST", "rule_type": "TEAMID", "custom_msg": "No Google"},
+    {"identifier": "EQHXZ8M8AV", "policy": "CEL", "rule_type": "TEAMID"}
+  ]
+}`)
+
+	result, err := Parse(FormatJSON, contents)
+
+	assert.Empty(t, err)
+	assert.Len(t, result.Rules, 2)
+	assert.Equal(t, "Chrome", result.Rules[0].Description)
+	assert.Equal(t, types.RuleTypeSigningID, result.Rules[0].RuleType)
+	assert.Equal(t, "No Google", result.Rules[1].CustomMessage)
+	assert.Equal(t, []Unconvertible{
+		{Position: "line 5", Reason: `unknown policy value "CEL"`},
+	}, result.Unconvertible)
+}
+
+func Test_Parse_RudolphJSONExport(t *testing.T) {
+	contents := []byte(`[
+  {"type": "BINARY", "policy": "ALLOWLIST", "identifier": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", "description": "hello"},
+  {"type": "BINARY", "policy": "ALLOWLIST", "identifier": "not-a-sha"}
+]`)
+
+	result, err := Parse(FormatJSON, contents)
+
+	assert.Empty(t, err)
+	assert.Equal(t, []ImportedRule{{
+		SantaRule:   rules.SantaRule{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist, Identifier: testSHA},
+		Description: "hello",
+		Position:    "line 2",
+	}}, result.Rules)
+	assert.Equal(t, `"not-a-sha" is not a valid BINARY identifier`, result.Unconvertible[0].Reason)
+}
+
+func Test_Parse_YAML(t *testing.T) {
+	list := []byte(`
+- identifier: EQHXZ8M8AV
+  type: teamid
+  policy: allowlist
+- identifier: EQHXZ8M8AV:com.google.Chrome
+  policy: BLOCKLIST
+`)
+	wrapped := []byte(`
+managed_by: git:santa-rules
+rules:
+  - identifier: EQHXZ8M8AV
+    rule_type: TEAM_ID
+    policy: ALLOWLIST
+`)
+
+	result, err := Parse(FormatYAML, list)
+	assert.Empty(t, err)
+	assert.Len(t, result.Rules, 1)
+	assert.Equal(t, types.RuleTypeTeamID, result.Rules[0].RuleType)
+	assert.Equal(t, []Unconvertible{{Position: "line 5", Reason: "no rule type"}}, result.Unconvertible)
+
+	result, err = Parse(FormatYAML, wrapped)
+	assert.Empty(t, err)
+	assert.Len(t, result.Rules, 1)
+	assert.Empty(t, result.Unconvertible)
+}
+
+func Test_Parse_Malformed(t *testing.T) {
+	_, err := Parse(FormatMoroz, []byte("[[rules]\n"))
+	assert.Error(t, err)
+
+	_, err = Parse(FormatJSON, []byte("{"))
+	assert.Error(t, err)
+
+	_, err = Parse(FormatJSON, []byte("[\n  {\"identifier\": \"EQHXZ8M8AV\"},\n  {\"identifier\": }\n]"))
+	assert.ErrorContains(t, err, "failed to parse json at line 3: ")
+}
+
+func Test_Stream_CollectsEveryError(t *testing.T) {
+	contents := `[
+  {"type": "TEAMID", "policy": 1, "identifier": "EQHXZ8M8AV"},
+  {
+    "type": "TEAMID",
+    "policy": "ALLOWLIST",
+    "identifier": "EQHXZ8M8AV"
+  },
+  {"type": "TEAMID", "policy": "ALLOWLIST", "identifier": "not a team id"}
+]`
+
+	var handled []string
+	unconvertible, err := Stream(FormatJSON, strings.NewReader(contents), func(rule ImportedRule) error {
+		handled = append(handled, rule.Position)
+		return nil
+	})
+
+	assert.Empty(t, err)
+	assert.Equal(t, []string{"line 3"}, handled)
+	assert.Len(t, unconvertible, 2)
+	assert.Equal(t, "line 2", unconvertible[0].Position)
+	assert.Equal(t, Unconvertible{Position: "line 8", Reason: `"not a team id" is not a valid TEAMID identifier`}, unconvertible[1])
+}
+
+func Test_Stream_CSV(t *testing.T) {
+	contents := "sha256,type,policy,custom_msg,description\n" +
+		testSHA + ",BINARY,ALLOWLIST,,hello\n" +
+		"EQHXZ8M8AV,TEAMID,ALLOWLIST\n" +
+		"EQHXZ8M8AV,TEAMID,WHATEVER,,\n"
+
+	var handled []ImportedRule
+	unconvertible, err := Stream(FormatCSV, strings.NewReader(contents), func(rule ImportedRule) error {
+		handled = append(handled, rule)
+		return nil
+	})
+
+	assert.Empty(t, err)
+	assert.Len(t, handled, 1)
+	assert.Equal(t, "line 2", handled[0].Position)
+	assert.Equal(t, []Unconvertible{
+		{Position: "line 3", Reason: "incorrect number of columns: expected 5, got 3"},
+		{Position: "line 4", Reason: `unknown policy value "WHATEVER"`},
+	}, unconvertible)
+}
+
+func Test_Stream_StopsOnHandlerError(t *testing.T) {
+	contents := "sha256,type,policy\n" + testSHA + ",BINARY,ALLOWLIST\n" + testSHA + ",BINARY,BLOCKLIST\n"
+
+	calls := 0
+	_, err := Stream(FormatCSV, strings.NewReader(contents), func(rule ImportedRule) error {
+		calls++
+		return errors.New("stop")
+	})
+
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, 1, calls)
+}
+
+func Test_Progress(t *testing.T) {
+	progress := NewProgress(2)
+
+	assert.Equal(t, 2, progress.Done(3))
+	assert.Equal(t, 2, progress.Done(4))
+	assert.Equal(t, 5, progress.Done(2))
+	assert.Equal(t, 5, progress.Completed())
+}
+
+func Test_Checkpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "import.checkpoint")
+
+	_, found, err := ReadCheckpoint(path)
+	assert.Empty(t, err)
+	assert.False(t, found)
+
+	checkpoint := Checkpoint{Filename: "rules.csv", SHA256: testSHA, Completed: 42}
+	assert.Empty(t, WriteCheckpoint(path, checkpoint))
+
+	read, found, err := ReadCheckpoint(path)
+	assert.Empty(t, err)
+	assert.True(t, found)
+	assert.Equal(t, checkpoint, read)
+}
+
+func Test_ParseCSVRecord(t *testing.T) {
+	// Rudolph's csv export names the identifier column sha256
+	rule, err := ParseCSVRecord(map[string]string{"sha256": testSHA, "type": "BINARY", "policy": "REMOVE"}, "line 2")
+	assert.Empty(t, err)
+	assert.Equal(t, types.RulePolicyRemove, rule.Policy)
+	assert.Equal(t, testSHA, rule.Identifier)
+
+	_, err = ParseCSVRecord(map[string]string{"identifier": testSHA, "policy": "ALLOWLIST"}, "line 3")
+	assert.EqualError(t, err, "no rule type")
+}
+
+func Test_DetectFormat(t *testing.T) {
+	format, err := DetectFormat("/tmp/moroz/global.toml")
+	assert.Empty(t, err)
+	assert.Equal(t, FormatMoroz, format)
+
+	format, err = DetectFormat("rules.yml")
+	assert.Empty(t, err)
+	assert.Equal(t, FormatYAML, format)
+
+	_, err = DetectFormat("rules")
+	assert.Error(t, err)
+}
+
+func Test_Summarize(t *testing.T) {
+	live := []globalrules.GlobalRuleRow{
+		{SantaRule: rules.SantaRule{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist, Identifier: testSHA}},
+		{SantaRule: rules.SantaRule{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV"}},
+		{SantaRule: rules.SantaRule{RuleType: types.RuleTypeCertificate, Policy: types.RulePolicyAllowlist, Identifier: testCert}},
+	}
+	imported := []ImportedRule{
+		{SantaRule: rules.SantaRule{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist, Identifier: testSHA}},
+		{SantaRule: rules.SantaRule{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV", CustomMessage: "Google"}},
+		{SantaRule: rules.SantaRule{RuleType: types.RuleTypeCertificate, Policy: types.RulePolicyRemove, Identifier: testCert}},
+		{SantaRule: rules.SantaRule{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyRemove, Identifier: "BJ4HAAB9B3"}},
+		{SantaRule: rules.SantaRule{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyBlocklist, Identifier: "FNN8Z5JMFP"}},
+	}
+
+	summary := Summarize(imported, live)
+
+	assert.Equal(t, Summary{Add: 1, Update: 1, Unchanged: 1, Remove: 1, RemoveMissing: 1}, summary)
+}
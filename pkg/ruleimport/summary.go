@@ -0,0 +1,96 @@
+package ruleimport
+
+import (
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// Change is what importing a rule does to the live global rules
+type Change int
+
+const (
+	ChangeNone Change = iota
+	ChangeAdd
+	ChangeUpdate
+	ChangeRemove
+	// ChangeRemoveMissing is a REMOVE entry for a rule that does not exist, which has nothing to remove
+	ChangeRemoveMissing
+)
+
+// Summary counts what importing a set of rules would do to the live global rules
+type Summary struct {
+	Add       int
+	Update    int
+	Unchanged int
+	Remove    int
+	// RemoveMissing counts REMOVE entries for rules that do not exist, which have nothing to remove
+	RemoveMissing int
+}
+
+type ruleKey struct {
+	ruleType   types.RuleType
+	identifier string
+}
+
+// LiveRules indexes the live global rules, so that imported rules that would not change anything can be skipped
+// instead of being written and added to the feed again
+type LiveRules map[ruleKey]globalrules.GlobalRuleRow
+
+// IndexLiveRules indexes the live global rules by rule type and identifier
+func IndexLiveRules(liveRules []globalrules.GlobalRuleRow) LiveRules {
+	live := make(LiveRules, len(liveRules))
+	for _, row := range liveRules {
+		identifier := row.Identifier
+		if identifier == "" {
+			identifier = row.SHA256
+		}
+		live[ruleKey{row.RuleType, identifier}] = row
+	}
+	return live
+}
+
+// Apply returns what importing the rule changes, and records the change, so that later entries of the same file are
+// compared with it. Only the policy, custom message and description of a rule are compared.
+func (l LiveRules) Apply(rule ImportedRule) Change {
+	key := ruleKey{rule.RuleType, rule.Identifier}
+	existing, ok := l[key]
+	switch {
+	case rule.Policy == types.RulePolicyRemove && ok:
+		delete(l, key)
+		return ChangeRemove
+	case rule.Policy == types.RulePolicyRemove:
+		return ChangeRemoveMissing
+	case !ok:
+		l[key] = globalrules.GlobalRuleRow{SantaRule: rule.SantaRule, Description: rule.Description}
+		return ChangeAdd
+	case existing.Policy != rule.Policy || existing.CustomMessage != rule.CustomMessage || existing.Description != rule.Description:
+		l[key] = globalrules.GlobalRuleRow{SantaRule: rule.SantaRule, Description: rule.Description, ManagedBy: existing.ManagedBy}
+		return ChangeUpdate
+	}
+	return ChangeNone
+}
+
+// ManagedBy returns the source that owns a live rule, which an import keeps when it updates the rule
+func (l LiveRules) ManagedBy(rule ImportedRule) string {
+	return l[ruleKey{rule.RuleType, rule.Identifier}].ManagedBy
+}
+
+// Summarize compares imported rules with the live global rules
+func Summarize(imported []ImportedRule, liveRules []globalrules.GlobalRuleRow) (summary Summary) {
+	live := IndexLiveRules(liveRules)
+	for _, rule := range imported {
+		switch live.Apply(rule) {
+		case ChangeAdd:
+			summary.Add++
+		case ChangeUpdate:
+			summary.Update++
+		case ChangeRemove:
+			summary.Remove++
+		case ChangeRemoveMissing:
+			summary.RemoveMissing++
+		default:
+			summary.Unchanged++
+		}
+	}
+	return
+}
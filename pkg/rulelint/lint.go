@@ -0,0 +1,299 @@
+// Package rulelint finds contradictions and dead weight in the global and machine rulesets: rules that conflict
+// with the rules that cover the same executables, rules that are made redundant by broader rules, and global rules
+// that machine rules override.
+package rulelint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// Severity ranks findings; errors are contradictions that almost certainly do not do what was intended
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// ParseSeverity accepts the case-insensitive name of a Severity
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(strings.ToLower(s)) {
+	case SeverityError:
+		return SeverityError, nil
+	case SeverityWarning:
+		return SeverityWarning, nil
+	case SeverityInfo:
+		return SeverityInfo, nil
+	}
+	return "", fmt.Errorf("unknown severity %q; must be one of: error, warning, info", s)
+}
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast reports whether s is as severe as, or more severe than, other
+func (s Severity) AtLeast(other Severity) bool {
+	return s.rank() >= other.rank()
+}
+
+// Kind classifies findings
+type Kind string
+
+const (
+	// KindConflict is a rule whose policy contradicts a broader rule covering the same executables
+	KindConflict Kind = "conflict"
+	// KindRedundant is a rule that has no effect because another rule already applies the same policy
+	KindRedundant Kind = "redundant"
+	// KindShadowed is a global rule that a machine rule overrides or removes on that machine
+	KindShadowed Kind = "shadowed"
+)
+
+// Finding is a single problem found by Lint
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Kind     Kind     `json:"kind"`
+	// MachineID is empty for findings that only involve global rules
+	MachineID string       `json:"machine_id,omitempty"`
+	Rule      ruleset.Rule `json:"rule"`
+	// Related is the other rule involved in the finding, e.g. the broader rule that a binary rule conflicts with
+	Related *ruleset.Rule `json:"related,omitempty"`
+	Message string        `json:"message"`
+}
+
+// Inventory is everything Lint looks at
+type Inventory struct {
+	GlobalRules []rules.SantaRule
+	// MachineRules maps machine IDs to the rules in their MachineRules partition
+	MachineRules map[string][]rules.SantaRule
+	// Binaries maps the SHA-256 of binaries seen in uploaded events to their identifiers. Without it, binary rules
+	// cannot be related to the signing ID, certificate and team ID rules that also cover them.
+	Binaries map[string]ruleset.Subject
+}
+
+// Lint reports every finding of the inventory, most severe first
+func Lint(inventory Inventory) []Finding {
+	findings := []Finding{}
+
+	global := ruleset.New(inventory.GlobalRules, nil)
+	findings = append(findings, lintHierarchy(global, inventory.Binaries, "")...)
+
+	for machineID, machineRules := range inventory.MachineRules {
+		findings = append(findings, lintMachineRules(global, machineRules, machineID)...)
+		effective := ruleset.New(inventory.GlobalRules, machineRules)
+		findings = append(findings, lintHierarchy(effective, inventory.Binaries, machineID)...)
+	}
+
+	sortFindings(findings)
+	return findings
+}
+
+// lintMachineRules compares the rules of a machine with the global rules that they replace
+func lintMachineRules(global ruleset.Ruleset, machineRules []rules.SantaRule, machineID string) (findings []Finding) {
+	for _, machineRule := range machineRules {
+		identifier := machineRule.Identifier
+		if identifier == "" {
+			identifier = machineRule.SHA256
+		}
+		globalRule, ok := global.Get(machineRule.RuleType, identifier)
+		if !ok {
+			continue
+		}
+
+		rule := ruleset.Rule{SantaRule: machineRule, Source: ruleset.SourceMachine}
+		switch {
+		case machineRule.Policy == types.RulePolicyRemove:
+			findings = append(findings, Finding{
+				Severity:  SeverityInfo,
+				Kind:      KindShadowed,
+				MachineID: machineID,
+				Rule:      globalRule,
+				Related:   &rule,
+				Message:   "global rule is removed on this machine by a machine rule",
+			})
+		case machineRule.Policy == globalRule.Policy && machineRule.CustomMessage == globalRule.CustomMessage:
+			findings = append(findings, Finding{
+				Severity:  SeverityWarning,
+				Kind:      KindRedundant,
+				MachineID: machineID,
+				Rule:      rule,
+				Related:   &globalRule,
+				Message:   "machine rule is identical to the global rule",
+			})
+		default:
+			findings = append(findings, Finding{
+				Severity:  SeverityInfo,
+				Kind:      KindShadowed,
+				MachineID: machineID,
+				Rule:      globalRule,
+				Related:   &rule,
+				Message:   fmt.Sprintf("global rule is overridden on this machine by a machine rule with policy %s", policyName(machineRule.Policy)),
+			})
+		}
+	}
+	return
+}
+
+// lintHierarchy compares every rule with the broader rules that cover the same executables. When machineID is set,
+// only pairs that involve at least one machine rule are reported, since the rest are reported for the global ruleset.
+func lintHierarchy(rs ruleset.Ruleset, binaries map[string]ruleset.Subject, machineID string) (findings []Finding) {
+	for _, rule := range rs.Rules() {
+		for _, broader := range broaderRules(rs, rule, binaries) {
+			if machineID != "" && rule.Source != ruleset.SourceMachine && broader.Source != ruleset.SourceMachine {
+				continue
+			}
+			if finding, ok := compare(rule, broader); ok {
+				finding.MachineID = machineID
+				findings = append(findings, finding)
+			}
+		}
+	}
+	return
+}
+
+// broaderRules lists the rules of lower precedence that would apply to the rule's executables if it did not exist
+func broaderRules(rs ruleset.Ruleset, rule ruleset.Rule, binaries map[string]ruleset.Subject) []ruleset.Rule {
+	var candidates []ruleset.Rule
+	add := func(ruleType types.RuleType, identifier string) {
+		if identifier == "" {
+			return
+		}
+		if candidate, ok := rs.Get(ruleType, identifier); ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	switch rule.RuleType {
+	case types.RuleTypeBinary:
+		subject, ok := binaries[strings.ToLower(rule.Identifier)]
+		if !ok {
+			return nil
+		}
+		add(types.RuleTypeSigningID, subject.SigningID)
+		add(types.RuleTypeCertificate, subject.CertificateSHA256)
+		add(types.RuleTypeTeamID, subject.TeamID)
+	case types.RuleTypeSigningID:
+		// Signing IDs are prefixed with the team ID of their developer, e.g. EQHXZ8M8AV:com.google.Chrome
+		if teamID, _, ok := strings.Cut(rule.Identifier, ":"); ok && teamID != "platform" {
+			add(types.RuleTypeTeamID, teamID)
+		}
+	}
+	return candidates
+}
+
+// compare checks a rule against a broader rule that covers the same executables
+func compare(rule ruleset.Rule, broader ruleset.Rule) (finding Finding, ok bool) {
+	if rule.Policy == types.RulePolicyRemove || broader.Policy == types.RulePolicyRemove {
+		return
+	}
+
+	finding = Finding{
+		Rule:    rule,
+		Related: &broader,
+	}
+	switch {
+	case isBlock(rule.Policy) != isBlock(broader.Policy):
+		finding.Severity = SeverityError
+		finding.Kind = KindConflict
+		finding.Message = fmt.Sprintf("%s rule contradicts the %s %s rule that covers it", policyName(rule.Policy), policyName(broader.Policy), typeName(broader.RuleType))
+	case isBlock(rule.Policy) && rule.Policy != broader.Policy:
+		finding.Severity = SeverityWarning
+		finding.Kind = KindConflict
+		finding.Message = fmt.Sprintf("%s rule is inconsistent with the %s %s rule that covers it", policyName(rule.Policy), policyName(broader.Policy), typeName(broader.RuleType))
+	case rule.Policy == broader.Policy && (rule.CustomMessage == "" || rule.CustomMessage == broader.CustomMessage):
+		finding.Severity = SeverityWarning
+		finding.Kind = KindRedundant
+		finding.Message = fmt.Sprintf("rule is fully covered by the %s %s rule", policyName(broader.Policy), typeName(broader.RuleType))
+	default:
+		return
+	}
+	ok = true
+	return
+}
+
+func isBlock(policy types.Policy) bool {
+	return policy == types.RulePolicyBlocklist || policy == types.RulePolicySilentBlocklist
+}
+
+func policyName(policy types.Policy) string {
+	text, err := policy.MarshalText()
+	if err != nil {
+		return fmt.Sprintf("%d", policy)
+	}
+	return string(text)
+}
+
+func typeName(ruleType types.RuleType) string {
+	text, err := ruleType.MarshalText()
+	if err != nil {
+		return fmt.Sprintf("%d", ruleType)
+	}
+	return string(text)
+}
+
+// sortFindings orders findings by severity, then global findings before machine findings, then by rule
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity.rank() > b.Severity.rank()
+		}
+		if a.MachineID != b.MachineID {
+			return a.MachineID < b.MachineID
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Rule.RuleType != b.Rule.RuleType {
+			return a.Rule.RuleType < b.Rule.RuleType
+		}
+		if a.Rule.Identifier != b.Rule.Identifier {
+			return a.Rule.Identifier < b.Rule.Identifier
+		}
+		return relatedKey(a) < relatedKey(b)
+	})
+}
+
+func relatedKey(finding Finding) string {
+	if finding.Related == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d#%s", finding.Related.RuleType, finding.Related.Identifier)
+}
+
+// Summary counts findings by severity
+type Summary struct {
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Infos    int `json:"infos"`
+}
+
+// Summarize counts the findings by severity
+func Summarize(findings []Finding) (summary Summary) {
+	for _, finding := range findings {
+		switch finding.Severity {
+		case SeverityError:
+			summary.Errors++
+		case SeverityWarning:
+			summary.Warnings++
+		case SeverityInfo:
+			summary.Infos++
+		}
+	}
+	return
+}
@@ -0,0 +1,147 @@
+package rulelint
+
+import (
+	"testing"
+
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testMachineID = "AAAAAAAA-A00A-1234-1234-5864377B4831"
+	chromeSHA     = "2222222222222222222222222222222222222222222222222222222222222222"
+	zoomSHA       = "3333333333333333333333333333333333333333333333333333333333333333"
+)
+
+var testBinaries = map[string]ruleset.Subject{
+	chromeSHA: {FileSHA256: chromeSHA, SigningID: "EQHXZ8M8AV:com.google.Chrome", TeamID: "EQHXZ8M8AV"},
+	zoomSHA:   {FileSHA256: zoomSHA, SigningID: "BJ4HAAB9B3:us.zoom.xos", TeamID: "BJ4HAAB9B3"},
+}
+
+func rule(ruleType types.RuleType, policy types.Policy, identifier string) rules.SantaRule {
+	return rules.SantaRule{RuleType: ruleType, Policy: policy, Identifier: identifier}
+}
+
+type findingSummary struct {
+	severity   Severity
+	kind       Kind
+	machineID  string
+	identifier string
+}
+
+func summarize(findings []Finding) (out []findingSummary) {
+	for _, finding := range findings {
+		out = append(out, findingSummary{finding.Severity, finding.Kind, finding.MachineID, finding.Rule.Identifier})
+	}
+	return
+}
+
+func Test_Lint_GlobalRules(t *testing.T) {
+	findings := Lint(Inventory{
+		GlobalRules: []rules.SantaRule{
+			rule(types.RuleTypeTeamID, types.RulePolicyAllowlist, "EQHXZ8M8AV"),
+			rule(types.RuleTypeBinary, types.RulePolicyBlocklist, chromeSHA),
+			rule(types.RuleTypeTeamID, types.RulePolicyBlocklist, "BJ4HAAB9B3"),
+			rule(types.RuleTypeSigningID, types.RulePolicySilentBlocklist, "BJ4HAAB9B3:us.zoom.xos"),
+			rule(types.RuleTypeBinary, types.RulePolicyBlocklist, zoomSHA),
+			rule(types.RuleTypeSigningID, types.RulePolicyAllowlist, "platform:com.apple.curl"),
+		},
+		Binaries: testBinaries,
+	})
+
+	assert.Equal(t, []findingSummary{
+		{SeverityError, KindConflict, "", chromeSHA},
+		{SeverityWarning, KindConflict, "", zoomSHA},
+		{SeverityWarning, KindConflict, "", "BJ4HAAB9B3:us.zoom.xos"},
+		{SeverityWarning, KindRedundant, "", zoomSHA},
+	}, summarize(findings))
+
+	assert.Equal(t, "BLOCKLIST rule contradicts the ALLOWLIST TEAMID rule that covers it", findings[0].Message)
+	assert.Equal(t, "EQHXZ8M8AV", findings[0].Related.Identifier)
+	assert.Equal(t, "SILENT_BLOCKLIST rule is inconsistent with the BLOCKLIST TEAMID rule that covers it", findings[2].Message)
+	assert.Equal(t, "rule is fully covered by the BLOCKLIST TEAMID rule", findings[3].Message)
+}
+
+func Test_Lint_UnknownBinariesAreNotRelated(t *testing.T) {
+	findings := Lint(Inventory{
+		GlobalRules: []rules.SantaRule{
+			rule(types.RuleTypeTeamID, types.RulePolicyAllowlist, "EQHXZ8M8AV"),
+			rule(types.RuleTypeBinary, types.RulePolicyBlocklist, chromeSHA),
+		},
+	})
+
+	assert.Empty(t, findings)
+}
+
+func Test_Lint_MachineRules(t *testing.T) {
+	findings := Lint(Inventory{
+		GlobalRules: []rules.SantaRule{
+			rule(types.RuleTypeTeamID, types.RulePolicyAllowlist, "EQHXZ8M8AV"),
+			rule(types.RuleTypeSigningID, types.RulePolicyAllowlist, "BJ4HAAB9B3:us.zoom.xos"),
+			rule(types.RuleTypeCertificate, types.RulePolicyBlocklist, "4444444444444444444444444444444444444444444444444444444444444444"),
+		},
+		MachineRules: map[string][]rules.SantaRule{
+			testMachineID: {
+				rule(types.RuleTypeTeamID, types.RulePolicyAllowlist, "EQHXZ8M8AV"),
+				rule(types.RuleTypeBinary, types.RulePolicyBlocklist, chromeSHA),
+				rule(types.RuleTypeSigningID, types.RulePolicyRemove, "BJ4HAAB9B3:us.zoom.xos"),
+				rule(types.RuleTypeCertificate, types.RulePolicyAllowlist, "4444444444444444444444444444444444444444444444444444444444444444"),
+			},
+		},
+		Binaries: testBinaries,
+	})
+
+	assert.Equal(t, []findingSummary{
+		{SeverityError, KindConflict, testMachineID, chromeSHA},
+		{SeverityWarning, KindRedundant, testMachineID, "EQHXZ8M8AV"},
+		{SeverityInfo, KindShadowed, testMachineID, "4444444444444444444444444444444444444444444444444444444444444444"},
+		{SeverityInfo, KindShadowed, testMachineID, "BJ4HAAB9B3:us.zoom.xos"},
+	}, summarize(findings))
+
+	assert.Equal(t, ruleset.SourceMachine, findings[0].Related.Source)
+	assert.Equal(t, "machine rule is identical to the global rule", findings[1].Message)
+	assert.Equal(t, "global rule is overridden on this machine by a machine rule with policy ALLOWLIST", findings[2].Message)
+	assert.Equal(t, "global rule is removed on this machine by a machine rule", findings[3].Message)
+}
+
+func Test_Lint_MachineFindingsDoNotRepeatGlobalFindings(t *testing.T) {
+	findings := Lint(Inventory{
+		GlobalRules: []rules.SantaRule{
+			rule(types.RuleTypeTeamID, types.RulePolicyAllowlist, "EQHXZ8M8AV"),
+			rule(types.RuleTypeBinary, types.RulePolicyBlocklist, chromeSHA),
+		},
+		MachineRules: map[string][]rules.SantaRule{
+			testMachineID: {
+				rule(types.RuleTypeBinary, types.RulePolicyAllowlist, zoomSHA),
+			},
+		},
+		Binaries: testBinaries,
+	})
+
+	assert.Equal(t, []findingSummary{
+		{SeverityError, KindConflict, "", chromeSHA},
+	}, summarize(findings))
+}
+
+func Test_Summarize(t *testing.T) {
+	summary := Summarize([]Finding{
+		{Severity: SeverityError},
+		{Severity: SeverityWarning},
+		{Severity: SeverityWarning},
+	})
+
+	assert.Equal(t, Summary{Errors: 1, Warnings: 2}, summary)
+}
+
+func Test_Severity(t *testing.T) {
+	severity, err := ParseSeverity("Warning")
+	assert.Empty(t, err)
+	assert.Equal(t, SeverityWarning, severity)
+	assert.True(t, SeverityError.AtLeast(SeverityWarning))
+	assert.False(t, SeverityInfo.AtLeast(SeverityWarning))
+
+	_, err = ParseSeverity("fatal")
+	assert.Error(t, err)
+}
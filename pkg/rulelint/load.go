@@ -0,0 +1,85 @@
+package rulelint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/catalog"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/ruleset"
+	"github.com/airbnb/rudolph/pkg/scan"
+)
+
+const (
+	// These mirror the partition keys of the globalrules and machinerules packages
+	globalRulesPK          = "GlobalRules"
+	machineRulesPKPrefix   = "MachineRules#"
+	inventoryScanPageLimit = 500
+)
+
+type ruleRow struct {
+	dynamodb.PrimaryKey
+	rules.SantaRule
+}
+
+// LoadInventory scans the table for the global rules and every machine's rules, and reads the binary catalog
+func LoadInventory(scanService scan.ScanService, client dynamodb.QueryAPI) (inventory Inventory, err error) {
+	inventory.MachineRules = make(map[string][]rules.SantaRule)
+	inventory.Binaries = make(map[string]ruleset.Subject)
+
+	filter := expression.Name("PK").Equal(expression.Value(globalRulesPK)).
+		Or(expression.Name("PK").BeginsWith(machineRulesPKPrefix))
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return
+	}
+
+	input := awsdynamodb.ScanInput{
+		ConsistentRead:            aws.Bool(false),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+		Limit:                     aws.Int32(inventoryScanPageLimit),
+	}
+	callback := func(out *awsdynamodb.ScanOutput) error {
+		var rows []ruleRow
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &rows); err != nil {
+			return fmt.Errorf("failed to unmarshal rules: %w", err)
+		}
+		for _, row := range rows {
+			if row.PartitionKey == globalRulesPK {
+				inventory.GlobalRules = append(inventory.GlobalRules, row.SantaRule)
+				continue
+			}
+			machineID := strings.TrimPrefix(row.PartitionKey, machineRulesPKPrefix)
+			inventory.MachineRules[machineID] = append(inventory.MachineRules[machineID], row.SantaRule)
+		}
+		return nil
+	}
+	stop := func(out *awsdynamodb.ScanOutput) (bool, error) {
+		return false, nil
+	}
+
+	err = scanService.ScanAll(input, callback, stop)
+	if err != nil {
+		err = fmt.Errorf("failed to scan rules: %w", err)
+		return
+	}
+
+	err = catalog.GetCatalogEntries(client, catalog.EntryTypeBinary, func(row catalog.CatalogEntryRow) error {
+		inventory.Binaries[strings.ToLower(row.Identifier)] = ruleset.Subject{
+			FileSHA256:        row.Identifier,
+			SigningID:         row.SigningID,
+			CertificateSHA256: row.CertificateSHA256,
+			TeamID:            row.TeamID,
+		}
+		return nil
+	})
+	return
+}
@@ -0,0 +1,108 @@
+package ruleplan
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// RuleStore reads and writes the global rules that plans are applied to
+type RuleStore interface {
+	// GetRule returns nil when the rule does not exist
+	GetRule(ruleType types.RuleType, identifier string) (*ManagedRule, error)
+	// PutRule creates or replaces a global rule, and adds it to the feed
+	PutRule(rule ManagedRule) error
+	// RemoveRule deletes a global rule, and adds a REMOVE entry for it to the feed
+	RemoveRule(ruleType types.RuleType, identifier string) error
+}
+
+// GetRuleStore returns a RuleStore backed by the DynamoDB table
+func GetRuleStore(client dynamodb.DynamoDBClient, timeProvider clock.TimeProvider) RuleStore {
+	return concreteRuleStore{
+		client:       client,
+		timeProvider: timeProvider,
+	}
+}
+
+type concreteRuleStore struct {
+	client       dynamodb.DynamoDBClient
+	timeProvider clock.TimeProvider
+}
+
+func (s concreteRuleStore) GetRule(ruleType types.RuleType, identifier string) (*ManagedRule, error) {
+	row, err := globalrules.GetGlobalRuleByIdentifier(s.client, identifier, ruleType)
+	if err != nil || row == nil {
+		return nil, err
+	}
+	rule := managedRuleFromRow(*row)
+	return &rule, nil
+}
+
+func (s concreteRuleStore) PutRule(rule ManagedRule) error {
+	return globalrules.PutManagedGlobalRule(s.timeProvider, s.client, rule.SantaRule, rule.Description, rule.ManagedBy)
+}
+
+func (s concreteRuleStore) RemoveRule(ruleType types.RuleType, identifier string) error {
+	return globalrules.RemoveGlobalRule(s.timeProvider, s.client, s.client, rules.RuleSortKeyFromTypeIdentifier(identifier, ruleType), "")
+}
+
+// Apply makes exactly the changes of the plan, in order.
+//
+// Before every change, the live rule is compared with the rule the plan was computed against; if anything changed the
+// table in the meantime, Apply stops and the plan has to be computed again. Changes made before that point are kept.
+func Apply(plan Plan, store RuleStore) (applied int, err error) {
+	if len(plan.Conflicts) > 0 {
+		err = fmt.Errorf("the plan has %d conflicts with rules owned by other sources or created by hand; resolve them first", len(plan.Conflicts))
+		return
+	}
+
+	for _, change := range plan.Changes {
+		rule := change.Rule()
+		if rule.ManagedBy != plan.ManagedBy {
+			err = fmt.Errorf("refusing to %s %s: it is not managed by %q", change.Action, rule.Identifier, plan.ManagedBy)
+			return
+		}
+
+		var live *ManagedRule
+		live, err = store.GetRule(rule.RuleType, rule.Identifier)
+		if err != nil {
+			err = fmt.Errorf("failed to read the live rule %s: %w", rule.Identifier, err)
+			return
+		}
+		if !matches(live, change.Before) {
+			err = fmt.Errorf("the live rule %s changed since the plan was computed; compute a new plan", rule.Identifier)
+			return
+		}
+
+		switch change.Action {
+		case ActionAdd, ActionUpdate:
+			if change.After == nil {
+				err = fmt.Errorf("the %s of %s has no declared rule", change.Action, rule.Identifier)
+				return
+			}
+			err = store.PutRule(*change.After)
+		case ActionRemove:
+			err = store.RemoveRule(rule.RuleType, rule.Identifier)
+		default:
+			err = errors.New("unknown action " + string(change.Action))
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to %s %s: %w", change.Action, rule.Identifier, err)
+			return
+		}
+		applied++
+	}
+	return
+}
+
+func matches(live *ManagedRule, before *ManagedRule) bool {
+	if live == nil || before == nil {
+		return live == nil && before == nil
+	}
+	return live.equal(*before)
+}
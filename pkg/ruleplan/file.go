@@ -0,0 +1,57 @@
+// Package ruleplan manages global rules declaratively: a rules file, usually kept in git, is the source of truth for
+// every global rule tagged with its managed-by source. Plans are computed against the live GlobalRules partition and
+// then applied, in the spirit of terraform plan and apply.
+package ruleplan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// RulesFile is the declarative description of every global rule owned by a single source
+type RulesFile struct {
+	// ManagedBy identifies the owner of the rules, e.g. "git:santa-rules". Apply only ever changes or removes rules
+	// tagged with the same source.
+	ManagedBy string         `yaml:"managed_by" json:"managed_by"`
+	Rules     []DeclaredRule `yaml:"rules" json:"rules"`
+}
+
+// DeclaredRule is a single rule of a rules file
+type DeclaredRule struct {
+	Identifier    string         `yaml:"identifier" json:"identifier"`
+	RuleType      types.RuleType `yaml:"type" json:"type"`
+	Policy        types.Policy   `yaml:"policy" json:"policy"`
+	CustomMessage string         `yaml:"custom_msg,omitempty" json:"custom_msg,omitempty"`
+	Description   string         `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// ParseRulesFile reads a .yaml, .yml or .json rules file
+func ParseRulesFile(filename string) (rulesFile RulesFile, err error) {
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		err = fmt.Errorf("failed to read rules file: %w", err)
+		return
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(contents, &rulesFile)
+	case ".json":
+		err = json.Unmarshal(contents, &rulesFile)
+	default:
+		err = errors.New("unrecognized file extension; must be one of: .yaml, .yml, .json")
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to parse rules file %q: %w", filename, err)
+	}
+	return
+}
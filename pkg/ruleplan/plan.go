@@ -0,0 +1,203 @@
+package ruleplan
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// Action is what applying a Change does to a global rule
+type Action string
+
+const (
+	ActionAdd    Action = "add"
+	ActionUpdate Action = "update"
+	ActionRemove Action = "remove"
+)
+
+// ManagedRule is a global rule as far as plans are concerned
+type ManagedRule struct {
+	rules.SantaRule
+	Description string `json:"description,omitempty"`
+	ManagedBy   string `json:"managed_by,omitempty"`
+}
+
+func (r ManagedRule) key() ruleKey {
+	return ruleKey{ruleType: r.RuleType, identifier: r.Identifier}
+}
+
+// equal compares everything that apply writes
+func (r ManagedRule) equal(other ManagedRule) bool {
+	return r.RuleType == other.RuleType &&
+		r.Identifier == other.Identifier &&
+		r.Policy == other.Policy &&
+		r.CustomMessage == other.CustomMessage &&
+		r.Description == other.Description &&
+		r.ManagedBy == other.ManagedBy
+}
+
+func managedRuleFromRow(row globalrules.GlobalRuleRow) ManagedRule {
+	rule := ManagedRule{
+		SantaRule:   row.SantaRule,
+		Description: row.Description,
+		ManagedBy:   row.ManagedBy,
+	}
+	if rule.Identifier == "" {
+		rule.Identifier = rule.SHA256
+	}
+	rule.SHA256 = ""
+	return rule
+}
+
+type ruleKey struct {
+	ruleType   types.RuleType
+	identifier string
+}
+
+// Change is a single write that applying the plan performs
+type Change struct {
+	Action Action `json:"action"`
+	// Before is the live rule that the change replaces or removes; it is nil for additions. Apply refuses to make the
+	// change if the live rule no longer matches it.
+	Before *ManagedRule `json:"before,omitempty"`
+	// After is the rule as declared; it is nil for removals
+	After *ManagedRule `json:"after,omitempty"`
+}
+
+// Rule is the rule that the change is about
+func (c Change) Rule() ManagedRule {
+	if c.After != nil {
+		return *c.After
+	}
+	return *c.Before
+}
+
+// Conflict is a declared rule that already exists, but is owned by another source or, when OwnedBy is empty, was
+// created by hand and not adopted. It is never changed.
+type Conflict struct {
+	Declared ManagedRule `json:"declared"`
+	OwnedBy  string      `json:"owned_by"`
+}
+
+// Plan is the set of changes that brings the global rules owned by a source in line with its rules file
+type Plan struct {
+	ManagedBy string     `json:"managed_by"`
+	Changes   []Change   `json:"changes"`
+	Conflicts []Conflict `json:"conflicts"`
+	Unchanged int        `json:"unchanged"`
+}
+
+// HasChanges reports whether applying the plan would write anything
+func (p Plan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// Count returns the number of changes with the given action
+func (p Plan) Count(action Action) (count int) {
+	for _, change := range p.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return
+}
+
+// ComputePlan diffs a rules file against the live global rules.
+//
+// Declared rules that do not exist are added, and declared rules that differ from the live rule are updated. Live rules
+// owned by the file's source that are no longer declared are removed. Rules owned by other sources, and rules created
+// by hand, are never touched and are reported as conflicts when declared.
+//
+// With adopt, declared rules that were created by hand are taken over by the file's source instead. From then on they
+// are owned by it, and deleting them from the file removes them.
+func ComputePlan(rulesFile RulesFile, liveRules []globalrules.GlobalRuleRow, adopt bool) (plan Plan, err error) {
+	if rulesFile.ManagedBy == "" {
+		err = errors.New("the rules file must set managed_by, so that the rules it owns can be told apart from other rules")
+		return
+	}
+
+	plan = Plan{
+		ManagedBy: rulesFile.ManagedBy,
+		Changes:   []Change{},
+		Conflicts: []Conflict{},
+	}
+
+	declared := make(map[ruleKey]ManagedRule, len(rulesFile.Rules))
+	for i, declaredRule := range rulesFile.Rules {
+		rule := ManagedRule{
+			SantaRule: rules.SantaRule{
+				RuleType:      declaredRule.RuleType,
+				Policy:        declaredRule.Policy,
+				Identifier:    declaredRule.Identifier,
+				CustomMessage: declaredRule.CustomMessage,
+			},
+			Description: declaredRule.Description,
+			ManagedBy:   rulesFile.ManagedBy,
+		}
+		if rule.Policy == types.RulePolicyRemove {
+			err = fmt.Errorf("rule #%d (%s): REMOVE is not a declarable policy; delete the rule from the file instead", i+1, rule.Identifier)
+			return
+		}
+		if err = globalrules.ValidateRule(rule.SantaRule); err != nil {
+			err = fmt.Errorf("rule #%d: %w", i+1, err)
+			return
+		}
+		if _, ok := declared[rule.key()]; ok {
+			err = fmt.Errorf("rule #%d (%s) is declared more than once", i+1, rule.Identifier)
+			return
+		}
+		declared[rule.key()] = rule
+	}
+
+	live := make(map[ruleKey]ManagedRule, len(liveRules))
+	for _, row := range liveRules {
+		rule := managedRuleFromRow(row)
+		live[rule.key()] = rule
+	}
+
+	for key, after := range declared {
+		after := after
+		before, ok := live[key]
+		switch {
+		case !ok:
+			plan.Changes = append(plan.Changes, Change{Action: ActionAdd, After: &after})
+		case before.ManagedBy == "" && !adopt:
+			plan.Conflicts = append(plan.Conflicts, Conflict{Declared: after})
+		case before.ManagedBy != "" && before.ManagedBy != rulesFile.ManagedBy:
+			plan.Conflicts = append(plan.Conflicts, Conflict{Declared: after, OwnedBy: before.ManagedBy})
+		case before.equal(after):
+			plan.Unchanged++
+		default:
+			plan.Changes = append(plan.Changes, Change{Action: ActionUpdate, Before: &before, After: &after})
+		}
+	}
+
+	for key, before := range live {
+		before := before
+		if before.ManagedBy != rulesFile.ManagedBy {
+			continue
+		}
+		if _, ok := declared[key]; !ok {
+			plan.Changes = append(plan.Changes, Change{Action: ActionRemove, Before: &before})
+		}
+	}
+
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		return lessRule(plan.Changes[i].Rule(), plan.Changes[j].Rule())
+	})
+	sort.Slice(plan.Conflicts, func(i, j int) bool {
+		return lessRule(plan.Conflicts[i].Declared, plan.Conflicts[j].Declared)
+	})
+	return
+}
+
+func lessRule(a ManagedRule, b ManagedRule) bool {
+	if a.RuleType != b.RuleType {
+		return a.RuleType < b.RuleType
+	}
+	return a.Identifier < b.Identifier
+}
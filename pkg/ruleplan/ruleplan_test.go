@@ -0,0 +1,251 @@
+package ruleplan
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testManagedBy = "git:santa-rules"
+	testSHA       = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	otherSHA      = "f2ca1bb6c7e907d06dafe4687e579fce76b37e4e93b7605022da52e6ccc26fd2"
+)
+
+func liveRule(ruleType types.RuleType, policy types.Policy, identifier string, managedBy string) globalrules.GlobalRuleRow {
+	return globalrules.GlobalRuleRow{
+		PrimaryKey: dynamodb.PrimaryKey{PartitionKey: "GlobalRules", SortKey: rules.RuleSortKeyFromTypeIdentifier(identifier, ruleType)},
+		SantaRule:  rules.SantaRule{RuleType: ruleType, Policy: policy, Identifier: identifier},
+		ManagedBy:  managedBy,
+	}
+}
+
+func Test_ParseRulesFile_YAML(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "rules.yaml")
+	err := os.WriteFile(filename, []byte(`
+managed_by: git:santa-rules
+rules:
+  - identifier: EQHXZ8M8AV
+    type: TEAMID
+    policy: ALLOWLIST
+    description: Google
+  - identifier: 2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824
+    type: BINARY
+    policy: BLOCKLIST
+    custom_msg: Not allowed
+`), 0644)
+	assert.Empty(t, err)
+
+	rulesFile, err := ParseRulesFile(filename)
+
+	assert.Empty(t, err)
+	assert.Equal(t, RulesFile{
+		ManagedBy: testManagedBy,
+		Rules: []DeclaredRule{
+			{Identifier: "EQHXZ8M8AV", RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Description: "Google"},
+			{Identifier: testSHA, RuleType: types.RuleTypeBinary, Policy: types.RulePolicyBlocklist, CustomMessage: "Not allowed"},
+		},
+	}, rulesFile)
+}
+
+func Test_ParseRulesFile_InvalidPolicy(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "rules.yml")
+	_ = os.WriteFile(filename, []byte("managed_by: x\nrules:\n  - {identifier: EQHXZ8M8AV, type: TEAMID, policy: MAYBE}\n"), 0644)
+
+	_, err := ParseRulesFile(filename)
+
+	assert.ErrorContains(t, err, `unknown policy value "MAYBE"`)
+}
+
+func Test_ComputePlan(t *testing.T) {
+	rulesFile := RulesFile{
+		ManagedBy: testManagedBy,
+		Rules: []DeclaredRule{
+			// new
+			{Identifier: "EQHXZ8M8AV", RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist},
+			// policy changed
+			{Identifier: testSHA, RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist},
+			// unchanged
+			{Identifier: "platform:com.apple.curl", RuleType: types.RuleTypeSigningID, Policy: types.RulePolicyAllowlist},
+			// created by hand; adopted, as the plan is computed with adopt
+			{Identifier: "BJ4HAAB9B3", RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist},
+			// owned by another source
+			{Identifier: "UBF8T346G9", RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist},
+		},
+	}
+	live := []globalrules.GlobalRuleRow{
+		liveRule(types.RuleTypeBinary, types.RulePolicyBlocklist, testSHA, testManagedBy),
+		liveRule(types.RuleTypeSigningID, types.RulePolicyAllowlist, "platform:com.apple.curl", testManagedBy),
+		liveRule(types.RuleTypeTeamID, types.RulePolicyAllowlist, "BJ4HAAB9B3", ""),
+		liveRule(types.RuleTypeTeamID, types.RulePolicyAllowlist, "UBF8T346G9", "okta-workflows"),
+		// deleted from the file
+		liveRule(types.RuleTypeBinary, types.RulePolicyAllowlist, otherSHA, testManagedBy),
+		// created by hand, never removed
+		liveRule(types.RuleTypeTeamID, types.RulePolicyBlocklist, "FNN8Z5JMFP", ""),
+	}
+
+	plan, err := ComputePlan(rulesFile, live, true)
+
+	assert.Empty(t, err)
+	assert.Equal(t, 1, plan.Unchanged)
+	assert.Len(t, plan.Changes, 4)
+
+	// The changes are sorted by rule type, then identifier
+	assert.Equal(t, ActionUpdate, plan.Changes[0].Action)
+	assert.Equal(t, testSHA, plan.Changes[0].Rule().Identifier)
+	assert.Equal(t, types.RulePolicyBlocklist, plan.Changes[0].Before.Policy)
+	assert.Equal(t, ActionRemove, plan.Changes[1].Action)
+	assert.Equal(t, otherSHA, plan.Changes[1].Rule().Identifier)
+	assert.Equal(t, ActionUpdate, plan.Changes[2].Action)
+	assert.Equal(t, "", plan.Changes[2].Before.ManagedBy)
+	assert.Equal(t, testManagedBy, plan.Changes[2].After.ManagedBy)
+	assert.Equal(t, ActionAdd, plan.Changes[3].Action)
+	assert.Equal(t, "EQHXZ8M8AV", plan.Changes[3].After.Identifier)
+
+	assert.Equal(t, []Conflict{{
+		Declared: ManagedRule{SantaRule: rules.SantaRule{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "UBF8T346G9"}, ManagedBy: testManagedBy},
+		OwnedBy:  "okta-workflows",
+	}}, plan.Conflicts)
+}
+
+func Test_ComputePlan_HandCreatedRulesConflictWithoutAdopt(t *testing.T) {
+	rulesFile := RulesFile{
+		ManagedBy: testManagedBy,
+		Rules: []DeclaredRule{
+			{Identifier: "BJ4HAAB9B3", RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist},
+		},
+	}
+	live := []globalrules.GlobalRuleRow{
+		liveRule(types.RuleTypeTeamID, types.RulePolicyAllowlist, "BJ4HAAB9B3", ""),
+	}
+
+	plan, err := ComputePlan(rulesFile, live, false)
+
+	assert.Empty(t, err)
+	assert.False(t, plan.HasChanges())
+	assert.Equal(t, []Conflict{{
+		Declared: ManagedRule{SantaRule: rules.SantaRule{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "BJ4HAAB9B3"}, ManagedBy: testManagedBy},
+	}}, plan.Conflicts)
+
+	_, err = Apply(plan, newMockRuleStore(live))
+	assert.ErrorContains(t, err, "conflicts")
+}
+
+func Test_ComputePlan_InvalidFiles(t *testing.T) {
+	_, err := ComputePlan(RulesFile{}, nil, false)
+	assert.ErrorContains(t, err, "managed_by")
+
+	_, err = ComputePlan(RulesFile{ManagedBy: testManagedBy, Rules: []DeclaredRule{
+		{Identifier: "not-a-sha", RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist},
+	}}, nil, false)
+	assert.EqualError(t, err, `rule #1: "not-a-sha" is not a valid BINARY identifier`)
+
+	_, err = ComputePlan(RulesFile{ManagedBy: testManagedBy, Rules: []DeclaredRule{
+		{Identifier: "EQHXZ8M8AV", RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist},
+		{Identifier: "EQHXZ8M8AV", RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyBlocklist},
+	}}, nil, false)
+	assert.EqualError(t, err, "rule #2 (EQHXZ8M8AV) is declared more than once")
+
+	_, err = ComputePlan(RulesFile{ManagedBy: testManagedBy, Rules: []DeclaredRule{
+		{Identifier: "EQHXZ8M8AV", RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyRemove},
+	}}, nil, false)
+	assert.ErrorContains(t, err, "REMOVE is not a declarable policy")
+}
+
+type mockRuleStore struct {
+	rules   map[ruleKey]ManagedRule
+	puts    []ManagedRule
+	removes []string
+	putErr  error
+}
+
+func (m *mockRuleStore) GetRule(ruleType types.RuleType, identifier string) (*ManagedRule, error) {
+	rule, ok := m.rules[ruleKey{ruleType, identifier}]
+	if !ok {
+		return nil, nil
+	}
+	return &rule, nil
+}
+
+func (m *mockRuleStore) PutRule(rule ManagedRule) error {
+	if m.putErr != nil {
+		return m.putErr
+	}
+	m.puts = append(m.puts, rule)
+	m.rules[rule.key()] = rule
+	return nil
+}
+
+func (m *mockRuleStore) RemoveRule(ruleType types.RuleType, identifier string) error {
+	m.removes = append(m.removes, identifier)
+	delete(m.rules, ruleKey{ruleType, identifier})
+	return nil
+}
+
+func newMockRuleStore(live []globalrules.GlobalRuleRow) *mockRuleStore {
+	store := &mockRuleStore{rules: make(map[ruleKey]ManagedRule)}
+	for _, row := range live {
+		rule := managedRuleFromRow(row)
+		store.rules[rule.key()] = rule
+	}
+	return store
+}
+
+func Test_Apply(t *testing.T) {
+	live := []globalrules.GlobalRuleRow{
+		liveRule(types.RuleTypeBinary, types.RulePolicyBlocklist, testSHA, testManagedBy),
+		liveRule(types.RuleTypeBinary, types.RulePolicyAllowlist, otherSHA, testManagedBy),
+	}
+	rulesFile := RulesFile{
+		ManagedBy: testManagedBy,
+		Rules: []DeclaredRule{
+			{Identifier: testSHA, RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist},
+			{Identifier: "EQHXZ8M8AV", RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist},
+		},
+	}
+	plan, err := ComputePlan(rulesFile, live, false)
+	assert.Empty(t, err)
+	store := newMockRuleStore(live)
+
+	applied, err := Apply(plan, store)
+
+	assert.Empty(t, err)
+	assert.Equal(t, 3, applied)
+	assert.Equal(t, []string{otherSHA}, store.removes)
+	assert.Len(t, store.puts, 2)
+
+	// Applying the same plan again finds that the table has changed since
+	_, err = Apply(plan, store)
+	assert.ErrorContains(t, err, "changed since the plan was computed")
+
+	// Once applied, the rules file has nothing left to change
+	plan, err = ComputePlan(rulesFile, []globalrules.GlobalRuleRow{
+		liveRule(types.RuleTypeBinary, types.RulePolicyAllowlist, testSHA, testManagedBy),
+		liveRule(types.RuleTypeTeamID, types.RulePolicyAllowlist, "EQHXZ8M8AV", testManagedBy),
+	}, false)
+	assert.Empty(t, err)
+	assert.False(t, plan.HasChanges())
+}
+
+func Test_Apply_RefusesConflictsAndStopsOnErrors(t *testing.T) {
+	_, err := Apply(Plan{ManagedBy: testManagedBy, Conflicts: []Conflict{{OwnedBy: "other"}}}, newMockRuleStore(nil))
+	assert.ErrorContains(t, err, "conflicts")
+
+	store := newMockRuleStore(nil)
+	store.putErr = errors.New("throttled")
+	plan, _ := ComputePlan(RulesFile{ManagedBy: testManagedBy, Rules: []DeclaredRule{
+		{Identifier: "EQHXZ8M8AV", RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist},
+	}}, nil, false)
+
+	applied, err := Apply(plan, store)
+
+	assert.Equal(t, 0, applied)
+	assert.EqualError(t, err, "failed to add EQHXZ8M8AV: throttled")
+}
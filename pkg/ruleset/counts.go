@@ -0,0 +1,84 @@
+package ruleset
+
+import (
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// RuleCounts are the per-type rule counts that the sensor reports in preflight
+type RuleCounts struct {
+	Total       int `json:"total"`
+	Binary      int `json:"binary"`
+	Certificate int `json:"certificate"`
+	SigningID   int `json:"signing_id"`
+	TeamID      int `json:"team_id"`
+	CDHash      int `json:"cdhash"`
+	Compiler    int `json:"compiler"`
+}
+
+// Counts returns the rule counts that a sensor holding exactly this ruleset would report
+func (rs Ruleset) Counts() (counts RuleCounts) {
+	for _, rule := range rs.rules {
+		counts.Total++
+		switch rule.RuleType {
+		case types.RuleTypeBinary:
+			counts.Binary++
+		case types.RuleTypeCertificate:
+			counts.Certificate++
+		case types.RuleTypeSigningID:
+			counts.SigningID++
+		case types.RuleTypeTeamID:
+			counts.TeamID++
+		case types.RuleTypeCDHash:
+			counts.CDHash++
+		}
+		if rule.Policy == types.RulePolicyAllowlistCompiler {
+			counts.Compiler++
+		}
+	}
+	return
+}
+
+// ReportedCounts extracts the rule counts that a sensor last reported in preflight.
+//
+// The sensor's counts also include the transitive rules it created locally, which the server knows nothing about;
+// they are reported separately as transitive. The sensor counts compiler and transitive rules as binary rules too, and
+// the stored RuleCount adds them a second time, so the total is derived from the counts per rule type instead.
+func ReportedCounts(sensorData sensordata.SensorData) (counts RuleCounts, transitive int) {
+	counts = RuleCounts{
+		Binary:      sensorData.BinaryRuleCount,
+		Certificate: sensorData.CertificateRuleCount,
+		SigningID:   sensorData.SigningIDRuleCount,
+		TeamID:      sensorData.TeamIDRuleCount,
+		CDHash:      sensorData.CDHashRuleCount,
+		Compiler:    sensorData.CompilerRuleCount,
+	}
+	counts.Total = counts.Binary + counts.Certificate + counts.SigningID + counts.TeamID + counts.CDHash
+	transitive = sensorData.TransitiveRuleCount
+	return
+}
+
+// CountDifference compares the expected and reported count of a single rule type
+type CountDifference struct {
+	Name     string `json:"name"`
+	Expected int    `json:"expected"`
+	Reported int    `json:"reported"`
+}
+
+// Delta is positive when the sensor holds more rules than expected
+func (d CountDifference) Delta() int {
+	return d.Reported - d.Expected
+}
+
+// Compare lines up expected and reported counts, type by type
+func (c RuleCounts) Compare(reported RuleCounts) []CountDifference {
+	return []CountDifference{
+		{Name: "Total", Expected: c.Total, Reported: reported.Total},
+		{Name: "Binary", Expected: c.Binary, Reported: reported.Binary},
+		{Name: "Certificate", Expected: c.Certificate, Reported: reported.Certificate},
+		{Name: "SigningID", Expected: c.SigningID, Reported: reported.SigningID},
+		{Name: "TeamID", Expected: c.TeamID, Reported: reported.TeamID},
+		{Name: "CDHash", Expected: c.CDHash, Reported: reported.CDHash},
+		{Name: "Compiler", Expected: c.Compiler, Reported: reported.Compiler},
+	}
+}
@@ -0,0 +1,57 @@
+package ruleset
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// ShadowedRule is a rule that matches a subject but does not decide its fate, and why
+type ShadowedRule struct {
+	Rule
+	Reason string `json:"reason"`
+}
+
+// Explanation details how the sensor arrives at the decision for a subject
+type Explanation struct {
+	Decision   string           `json:"decision"`
+	ClientMode types.ClientMode `json:"client_mode"`
+	// Winner is the rule that decides the execution; it is nil when no rule matches and the client mode decides
+	Winner   *Rule          `json:"winner"`
+	Shadowed []ShadowedRule `json:"shadowed"`
+}
+
+// Explain evaluates the subject like Evaluate does, and also reports every other rule that matches the subject:
+// rules of lower precedence than the winner, and global rules that the machine's own rules override or remove.
+func (rs Ruleset) Explain(subject Subject, clientMode types.ClientMode) Explanation {
+	explanation := Explanation{
+		Decision:   rs.Evaluate(subject, clientMode),
+		ClientMode: clientMode,
+		Shadowed:   []ShadowedRule{},
+	}
+
+	for _, candidate := range candidates(subject) {
+		if rule, ok := rs.rules[candidate]; ok {
+			if explanation.Winner == nil {
+				winner := rule
+				explanation.Winner = &winner
+			} else {
+				explanation.Shadowed = append(explanation.Shadowed, ShadowedRule{
+					Rule:   rule,
+					Reason: fmt.Sprintf("lower precedence than the %s rule", typeName(explanation.Winner.RuleType)),
+				})
+			}
+		}
+		explanation.Shadowed = append(explanation.Shadowed, rs.overridden[candidate]...)
+	}
+
+	return explanation
+}
+
+func typeName(ruleType types.RuleType) string {
+	text, err := ruleType.MarshalText()
+	if err != nil {
+		return fmt.Sprintf("%d", ruleType)
+	}
+	return string(text)
+}
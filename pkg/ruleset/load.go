@@ -0,0 +1,63 @@
+package ruleset
+
+import (
+	"fmt"
+
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+)
+
+// LoadGlobalRules reads all global rules, so that they can be shared across many calls to ForMachine
+func LoadGlobalRules(client dynamodb.QueryAPI) (globalRules []rules.SantaRule, err error) {
+	rows, err := globalrules.ListGlobalRules(client)
+	if err != nil {
+		err = fmt.Errorf("failed to load global rules: %w", err)
+		return
+	}
+	for _, row := range rows {
+		globalRules = append(globalRules, row.SantaRule)
+	}
+	return
+}
+
+// ForMachine builds the effective ruleset of a machine from previously loaded global rules plus the machine's own rules
+func ForMachine(client dynamodb.QueryAPI, globalRules []rules.SantaRule, machineID string) (rs Ruleset, err error) {
+	rows, err := machinerules.GetMachineRules(client, machineID)
+	if err != nil {
+		err = fmt.Errorf("failed to load machine rules: %w", err)
+		return
+	}
+
+	var machineRules []rules.SantaRule
+	if rows != nil {
+		for _, row := range *rows {
+			machineRules = append(machineRules, row.SantaRule)
+		}
+	}
+
+	rs = New(globalRules, machineRules)
+	return
+}
+
+// ForSubject builds the part of a machine's effective ruleset that can apply to the subject: the global rules for its
+// identifiers plus the machine's own rules. It reads a handful of items instead of every global rule, so it suits
+// explaining a single binary.
+func ForSubject(client interface {
+	dynamodb.GetItemAPI
+	dynamodb.QueryAPI
+}, machineID string, subject Subject) (rs Ruleset, err error) {
+	var globalRules []rules.SantaRule
+	for _, candidate := range candidates(subject) {
+		row, inerr := globalrules.GetGlobalRuleByIdentifier(client, candidate.identifier, candidate.ruleType)
+		if inerr != nil {
+			err = fmt.Errorf("failed to load global rules: %w", inerr)
+			return
+		}
+		if row != nil {
+			globalRules = append(globalRules, row.SantaRule)
+		}
+	}
+	return ForMachine(client, globalRules, machineID)
+}
@@ -0,0 +1,173 @@
+// Package ruleset models the set of rules that a single Santa sensor ends up with after a sync, and evaluates
+// executions against it the same way the sensor would.
+package ruleset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/types"
+)
+
+// Source describes where a rule in the effective ruleset was downloaded from
+type Source string
+
+const (
+	SourceGlobal  Source = "global"
+	SourceMachine Source = "machine"
+)
+
+// Subject holds the identifiers of an executable that rules can match against
+type Subject struct {
+	CDHash            string `json:"cdhash,omitempty"`
+	FileSHA256        string `json:"file_sha256"`
+	SigningID         string `json:"signing_id,omitempty"`
+	CertificateSHA256 string `json:"certificate_sha256,omitempty"`
+	TeamID            string `json:"team_id,omitempty"`
+}
+
+// Rule is a single rule of the effective ruleset, annotated with its source
+type Rule struct {
+	rules.SantaRule
+	Source Source `json:"source"`
+}
+
+type ruleKey struct {
+	ruleType   types.RuleType
+	identifier string
+}
+
+// Ruleset is the effective set of rules for a single machine.
+//
+// Machine rules are downloaded after global rules; since the sensor keys rules by (type, identifier), a machine rule
+// replaces any global rule for the same identifier, and a machine REMOVE rule deletes it.
+type Ruleset struct {
+	rules map[ruleKey]Rule
+	// overridden keeps the global rules that machine rules replaced or removed, so they can be explained
+	overridden map[ruleKey][]ShadowedRule
+}
+
+// New merges global and machine rules into the effective ruleset of a machine
+func New(globalRules []rules.SantaRule, machineRules []rules.SantaRule) Ruleset {
+	rs := Ruleset{
+		rules:      make(map[ruleKey]Rule),
+		overridden: make(map[ruleKey][]ShadowedRule),
+	}
+	rs.apply(globalRules, SourceGlobal)
+	rs.apply(machineRules, SourceMachine)
+	return rs
+}
+
+func (rs Ruleset) apply(santaRules []rules.SantaRule, source Source) {
+	for _, rule := range santaRules {
+		if rule.Identifier == "" && rule.SHA256 != "" {
+			rule.Identifier = rule.SHA256
+		}
+		key := ruleKey{ruleType: rule.RuleType, identifier: rule.Identifier}
+		if existing, ok := rs.rules[key]; ok && existing.Source != source {
+			reason := "overridden by machine rule"
+			if rule.Policy == types.RulePolicyRemove {
+				reason = "removed by machine rule"
+			}
+			rs.overridden[key] = append(rs.overridden[key], ShadowedRule{Rule: existing, Reason: reason})
+		}
+		if rule.Policy == types.RulePolicyRemove {
+			delete(rs.rules, key)
+			continue
+		}
+		rs.rules[key] = Rule{SantaRule: rule, Source: source}
+	}
+}
+
+// Rules returns every rule in the effective ruleset, in no particular order
+func (rs Ruleset) Rules() []Rule {
+	out := make([]Rule, 0, len(rs.rules))
+	for _, rule := range rs.rules {
+		out = append(out, rule)
+	}
+	return out
+}
+
+// Len is the number of rules in the effective ruleset
+func (rs Ruleset) Len() int {
+	return len(rs.rules)
+}
+
+// Get returns the effective rule with the given type and identifier, if any
+func (rs Ruleset) Get(ruleType types.RuleType, identifier string) (rule Rule, ok bool) {
+	rule, ok = rs.rules[ruleKey{ruleType: ruleType, identifier: identifier}]
+	return
+}
+
+// candidates lists the rules that could apply to the subject, following Santa's precedence from most to least
+// specific: CDHash, Binary, Signing ID, Certificate and finally Team ID.
+func candidates(subject Subject) []ruleKey {
+	keys := []ruleKey{
+		{types.RuleTypeCDHash, subject.CDHash},
+		{types.RuleTypeBinary, subject.FileSHA256},
+		{types.RuleTypeSigningID, subject.SigningID},
+		{types.RuleTypeCertificate, subject.CertificateSHA256},
+		{types.RuleTypeTeamID, subject.TeamID},
+	}
+
+	out := make([]ruleKey, 0, len(keys))
+	for _, key := range keys {
+		if key.identifier != "" {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+// Match returns the rule that the sensor would apply to the subject, following Santa's precedence from most to least
+// specific: CDHash, Binary, Signing ID, Certificate and finally Team ID.
+func (rs Ruleset) Match(subject Subject) (rule Rule, ok bool) {
+	for _, candidate := range candidates(subject) {
+		if rule, ok = rs.rules[candidate]; ok {
+			return
+		}
+	}
+	return
+}
+
+// Evaluate returns the Santa decision (e.g. ALLOW_BINARY, BLOCK_UNKNOWN) for the subject under the given client mode
+func (rs Ruleset) Evaluate(subject Subject, clientMode types.ClientMode) string {
+	rule, ok := rs.Match(subject)
+	if !ok {
+		if clientMode == types.Lockdown {
+			return "BLOCK_UNKNOWN"
+		}
+		return "ALLOW_UNKNOWN"
+	}
+	return decisionForRule(rule.SantaRule)
+}
+
+func decisionForRule(rule rules.SantaRule) string {
+	var ruleType string
+	switch rule.RuleType {
+	case types.RuleTypeCDHash:
+		ruleType = "CDHASH"
+	case types.RuleTypeBinary:
+		ruleType = "BINARY"
+	case types.RuleTypeCertificate:
+		ruleType = "CERTIFICATE"
+	case types.RuleTypeSigningID:
+		ruleType = "SIGNINGID"
+	case types.RuleTypeTeamID:
+		ruleType = "TEAMID"
+	default:
+		ruleType = fmt.Sprintf("%d", rule.RuleType)
+	}
+
+	switch rule.Policy {
+	case types.RulePolicyBlocklist, types.RulePolicySilentBlocklist:
+		return "BLOCK_" + ruleType
+	}
+	return "ALLOW_" + ruleType
+}
+
+// IsBlock reports whether a Santa decision prevents execution
+func IsBlock(decision string) bool {
+	return strings.HasPrefix(decision, "BLOCK_")
+}
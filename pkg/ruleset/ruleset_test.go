@@ -0,0 +1,197 @@
+package ruleset
+
+import (
+	"testing"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/globalrules"
+	"github.com/airbnb/rudolph/pkg/model/machinerules"
+	"github.com/airbnb/rudolph/pkg/model/rules"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/types"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testSHA    = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	testCert   = "0000000b28b738354c43a11486651ca33266e2b7454477d6b351df09c2e97faf"
+	testCDHash = "dbe8c39801e1ef2c6d7ce5fe8f4f1d5c6a1e1f1a"
+)
+
+func Test_Match_Precedence(t *testing.T) {
+	rs := New(
+		[]rules.SantaRule{
+			{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV"},
+			{RuleType: types.RuleTypeCertificate, Policy: types.RulePolicyBlocklist, Identifier: testCert},
+		},
+		nil,
+	)
+
+	subject := Subject{FileSHA256: testSHA, CertificateSHA256: testCert, TeamID: "EQHXZ8M8AV"}
+
+	// Certificate rules are more specific than team ID rules
+	assert.Equal(t, "BLOCK_CERTIFICATE", rs.Evaluate(subject, types.Monitor))
+
+	rs = New(
+		[]rules.SantaRule{
+			{RuleType: types.RuleTypeCertificate, Policy: types.RulePolicyBlocklist, Identifier: testCert},
+		},
+		[]rules.SantaRule{
+			{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist, Identifier: testSHA},
+		},
+	)
+	rule, ok := rs.Match(subject)
+	assert.True(t, ok)
+	assert.Equal(t, SourceMachine, rule.Source)
+	assert.Equal(t, "ALLOW_BINARY", rs.Evaluate(subject, types.Lockdown))
+}
+
+func Test_MachineRulesOverrideGlobalRules(t *testing.T) {
+	rs := New(
+		[]rules.SantaRule{
+			{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyBlocklist, Identifier: testSHA},
+			{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV"},
+		},
+		[]rules.SantaRule{
+			{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist, Identifier: testSHA},
+			{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyRemove, Identifier: "EQHXZ8M8AV"},
+		},
+	)
+
+	assert.Equal(t, 1, rs.Len())
+	assert.Equal(t, "ALLOW_BINARY", rs.Evaluate(Subject{FileSHA256: testSHA}, types.Lockdown))
+	assert.Equal(t, "BLOCK_UNKNOWN", rs.Evaluate(Subject{TeamID: "EQHXZ8M8AV"}, types.Lockdown))
+	assert.Equal(t, "ALLOW_UNKNOWN", rs.Evaluate(Subject{TeamID: "EQHXZ8M8AV"}, types.Monitor))
+}
+
+func Test_LegacySHA256Identifier(t *testing.T) {
+	rs := New([]rules.SantaRule{{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist, SHA256: testSHA}}, nil)
+
+	_, ok := rs.Get(types.RuleTypeBinary, testSHA)
+	assert.True(t, ok)
+}
+
+func Test_CDHashTakesPrecedence(t *testing.T) {
+	rs := New(
+		[]rules.SantaRule{
+			{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist, Identifier: testSHA},
+			{RuleType: types.RuleTypeCDHash, Policy: types.RulePolicyBlocklist, Identifier: testCDHash},
+		},
+		nil,
+	)
+
+	assert.Equal(t, "BLOCK_CDHASH", rs.Evaluate(Subject{CDHash: testCDHash, FileSHA256: testSHA}, types.Monitor))
+	assert.Equal(t, "ALLOW_BINARY", rs.Evaluate(Subject{FileSHA256: testSHA}, types.Lockdown))
+}
+
+func Test_Explain(t *testing.T) {
+	rs := New(
+		[]rules.SantaRule{
+			{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyBlocklist, Identifier: testSHA},
+			{RuleType: types.RuleTypeCertificate, Policy: types.RulePolicyAllowlist, Identifier: testCert},
+			{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV"},
+		},
+		[]rules.SantaRule{
+			{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist, Identifier: testSHA},
+			{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyRemove, Identifier: "EQHXZ8M8AV"},
+		},
+	)
+
+	explanation := rs.Explain(Subject{FileSHA256: testSHA, CertificateSHA256: testCert, TeamID: "EQHXZ8M8AV"}, types.Lockdown)
+
+	assert.Equal(t, "ALLOW_BINARY", explanation.Decision)
+	assert.Equal(t, SourceMachine, explanation.Winner.Source)
+	assert.Len(t, explanation.Shadowed, 3)
+	assert.Equal(t, "overridden by machine rule", explanation.Shadowed[0].Reason)
+	assert.Equal(t, types.RulePolicyBlocklist, explanation.Shadowed[0].Policy)
+	assert.Equal(t, "lower precedence than the BINARY rule", explanation.Shadowed[1].Reason)
+	assert.Equal(t, types.RuleTypeCertificate, explanation.Shadowed[1].RuleType)
+	assert.Equal(t, "removed by machine rule", explanation.Shadowed[2].Reason)
+}
+
+func Test_Explain_NoMatch(t *testing.T) {
+	explanation := New(nil, nil).Explain(Subject{FileSHA256: testSHA}, types.Lockdown)
+
+	assert.Equal(t, "BLOCK_UNKNOWN", explanation.Decision)
+	assert.Nil(t, explanation.Winner)
+	assert.Empty(t, explanation.Shadowed)
+}
+
+func Test_Counts(t *testing.T) {
+	rs := New(
+		[]rules.SantaRule{
+			{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyBlocklist, Identifier: testSHA},
+			{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlistCompiler, Identifier: "f2ca1bb6c7e907d06dafe4687e579fce76b37e4e93b7605022da52e6ccc26fd2"},
+			{RuleType: types.RuleTypeCertificate, Policy: types.RulePolicyAllowlist, Identifier: testCert},
+			{RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyAllowlist, Identifier: "EQHXZ8M8AV"},
+		},
+		[]rules.SantaRule{
+			{RuleType: types.RuleTypeCertificate, Policy: types.RulePolicyRemove, Identifier: testCert},
+			{RuleType: types.RuleTypeCDHash, Policy: types.RulePolicyAllowlist, Identifier: testCDHash},
+		},
+	)
+
+	counts := rs.Counts()
+	assert.Equal(t, RuleCounts{Total: 4, Binary: 2, TeamID: 1, CDHash: 1, Compiler: 1}, counts)
+
+	sensorData := sensordata.NewSensorData(clock.FrozenTimeProvider{}, "", "", "", "", "", types.Monitor, false, "", "", 0, 5, 1, 1, 0, 1, 3)
+	reported, transitive := ReportedCounts(sensorData)
+	assert.Equal(t, 3, transitive)
+
+	differences := counts.Compare(reported)
+	assert.Equal(t, CountDifference{Name: "Total", Expected: 4, Reported: 7}, differences[0])
+	assert.Equal(t, 3, differences[1].Delta())
+	assert.Equal(t, 0, differences[4].Delta())
+}
+
+type mockDynamoDB struct {
+	globalRules  map[string]rules.SantaRule
+	machineRules []rules.SantaRule
+	gets         []string
+}
+
+func (m *mockDynamoDB) GetItem(key dynamodb.PrimaryKey, consistentRead bool) (*awsdynamodb.GetItemOutput, error) {
+	m.gets = append(m.gets, key.SortKey)
+	rule, ok := m.globalRules[key.SortKey]
+	if !ok {
+		return &awsdynamodb.GetItemOutput{}, nil
+	}
+	item, err := attributevalue.MarshalMap(globalrules.GlobalRuleRow{PrimaryKey: key, SantaRule: rule})
+	return &awsdynamodb.GetItemOutput{Item: item}, err
+}
+
+func (m *mockDynamoDB) Query(input *awsdynamodb.QueryInput) (*awsdynamodb.QueryOutput, error) {
+	var items []map[string]awstypes.AttributeValue
+	for _, rule := range m.machineRules {
+		item, err := attributevalue.MarshalMap(machinerules.MachineRuleRow{SantaRule: rule})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return &awsdynamodb.QueryOutput{Items: items}, nil
+}
+
+func Test_ForSubject(t *testing.T) {
+	client := &mockDynamoDB{
+		globalRules: map[string]rules.SantaRule{
+			rules.RuleSortKeyFromTypeIdentifier("EQHXZ8M8AV", types.RuleTypeTeamID): {RuleType: types.RuleTypeTeamID, Policy: types.RulePolicyBlocklist, Identifier: "EQHXZ8M8AV"},
+		},
+		machineRules: []rules.SantaRule{
+			{RuleType: types.RuleTypeBinary, Policy: types.RulePolicyAllowlist, Identifier: testSHA},
+		},
+	}
+
+	rs, err := ForSubject(client, "machine", Subject{FileSHA256: testSHA, TeamID: "EQHXZ8M8AV"})
+	assert.NoError(t, err)
+	assert.Len(t, client.gets, 2, "only the global rules for the subject's identifiers are read")
+
+	explanation := rs.Explain(Subject{FileSHA256: testSHA, TeamID: "EQHXZ8M8AV"}, types.Lockdown)
+	assert.Equal(t, "ALLOW_BINARY", explanation.Decision)
+	assert.Equal(t, SourceMachine, explanation.Winner.Source)
+	assert.Len(t, explanation.Shadowed, 1)
+}
@@ -31,7 +31,9 @@ type ConcreteScanService struct {
 func (c ConcreteScanService) ScanAll(in awsdynamodb.ScanInput, callback func(out *awsdynamodb.ScanOutput) error, stop func(out *awsdynamodb.ScanOutput) (bool, error)) (err error) {
 	nextInput := &in
 	for {
-		shouldStop, lastEvaluatedKey, err := scanIterator(c.scanner, nextInput, callback, stop)
+		var shouldStop bool
+		var lastEvaluatedKey map[string]types.AttributeValue
+		shouldStop, lastEvaluatedKey, err = scanIterator(c.scanner, nextInput, callback, stop)
 		if err != nil {
 			break
 		}
@@ -1,6 +1,7 @@
 package scan
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/airbnb/rudolph/pkg/dynamodb"
@@ -109,3 +110,23 @@ func Test_ScanService(t *testing.T) {
 	assert.Empty(t, err)
 	assert.Equal(t, 3, numItems)
 }
+
+func Test_ScanService_ReturnsErrors(t *testing.T) {
+	scanner := scanApi(
+		func(in *awsdynamodb.ScanInput) (*awsdynamodb.ScanOutput, error) {
+			return nil, errors.New("throttled")
+		},
+	)
+
+	service := ConcreteScanService{
+		scanner: scanner,
+	}
+
+	err := service.ScanAll(
+		awsdynamodb.ScanInput{},
+		func(out *awsdynamodb.ScanOutput) error { return nil },
+		func(out *awsdynamodb.ScanOutput) (bool, error) { return false, nil },
+	)
+
+	assert.EqualError(t, err, "throttled")
+}
@@ -0,0 +1,202 @@
+// Package synchealth finds machines whose syncs silently stopped working: machines that stopped syncing, machines
+// whose last sync never completed, and machines stuck in clean syncs. Any of these lets a machine drift away from its
+// intended ruleset without anyone noticing.
+package synchealth
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/dynamodb"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/model/syncstate"
+)
+
+// Problem is a way in which a machine's syncs are not working
+type Problem string
+
+const (
+	// ProblemStale is a machine that has not started a sync within the stale threshold
+	ProblemStale Problem = "stale"
+	// ProblemIncomplete is a machine whose last sync stalled before postflight
+	ProblemIncomplete Problem = "incomplete"
+	// ProblemRepeatedCleanSyncs is a machine whose recent syncs were all clean syncs
+	ProblemRepeatedCleanSyncs Problem = "repeated_clean_syncs"
+)
+
+// Thresholds decide when a machine is reported
+type Thresholds struct {
+	// StaleDays is how many days a machine can go without starting a sync
+	StaleDays int `json:"stale_days"`
+	// RepeatedCleanSyncs is how many clean syncs in a row get a machine reported
+	RepeatedCleanSyncs int `json:"repeated_clean_syncs"`
+}
+
+// DefaultThresholds returns the thresholds used when none are configured
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		StaleDays:          7,
+		RepeatedCleanSyncs: 3,
+	}
+}
+
+// Validate ensures the thresholds can report anything at all
+func (t Thresholds) Validate() error {
+	if t.StaleDays < 1 {
+		return errors.New("the stale threshold must be at least 1 day")
+	}
+	if t.RepeatedCleanSyncs < 2 {
+		return errors.New("the repeated clean syncs threshold must be at least 2")
+	}
+	return nil
+}
+
+// Finding is a machine with at least one problem
+type Finding struct {
+	MachineID   string    `json:"machine_id"`
+	SerialNum   string    `json:"serial_num,omitempty"`
+	PrimaryUser string    `json:"primary_user,omitempty"`
+	Problems    []Problem `json:"problems"`
+	// SyncStatus is how far the last sync got; it is empty if the machine has no sync state at all
+	SyncStatus            syncstate.SyncStatus `json:"sync_status,omitempty"`
+	LastSyncAt            string               `json:"last_sync_at,omitempty"`
+	LastCleanSync         string               `json:"last_clean_sync,omitempty"`
+	ConsecutiveCleanSyncs int                  `json:"consecutive_clean_syncs"`
+	// LastSeen is when the sensor last completed a preflight
+	LastSeen string `json:"last_seen,omitempty"`
+}
+
+// Has reports whether the machine has the given problem
+func (f Finding) Has(problem Problem) bool {
+	for _, p := range f.Problems {
+		if p == problem {
+			return true
+		}
+	}
+	return false
+}
+
+// Report lists every machine with a sync problem
+type Report struct {
+	GeneratedAt     time.Time       `json:"generated_at"`
+	Thresholds      Thresholds      `json:"thresholds"`
+	MachinesChecked int             `json:"machines_checked"`
+	Counts          map[Problem]int `json:"counts"`
+	Findings        []Finding       `json:"findings"`
+}
+
+// Store is what Check needs from DynamoDB
+type Store interface {
+	ListSyncStates() ([]syncstate.SyncStateRow, error)
+	ListSensorData() ([]sensordata.SensorData, error)
+}
+
+// GetStore returns a Store backed by the DynamoDB table
+func GetStore(client dynamodb.QueryAPI) Store {
+	return concreteStore{client: client}
+}
+
+type concreteStore struct {
+	client dynamodb.QueryAPI
+}
+
+func (s concreteStore) ListSyncStates() ([]syncstate.SyncStateRow, error) {
+	return syncstate.ListSyncStates(s.client)
+}
+
+func (s concreteStore) ListSensorData() ([]sensordata.SensorData, error) {
+	return sensordata.ListSensorData(s.client)
+}
+
+// Check reads the sync state and sensor data of the whole fleet, and reports the machines with sync problems
+func Check(store Store, timeProvider clock.TimeProvider, thresholds Thresholds) (report Report, err error) {
+	if err = thresholds.Validate(); err != nil {
+		return
+	}
+
+	syncStates, err := store.ListSyncStates()
+	if err != nil {
+		return
+	}
+	sensorData, err := store.ListSensorData()
+	if err != nil {
+		return
+	}
+
+	report = Assess(timeProvider.Now().UTC(), thresholds, syncStates, sensorData)
+	return
+}
+
+// Assess finds the sync problems of every machine that has either a sync state or sensor data
+func Assess(now time.Time, thresholds Thresholds, syncStates []syncstate.SyncStateRow, sensorData []sensordata.SensorData) Report {
+	report := Report{
+		GeneratedAt: now,
+		Thresholds:  thresholds,
+		Counts:      map[Problem]int{},
+		Findings:    []Finding{},
+	}
+
+	sensorDataByMachineID := make(map[string]sensordata.SensorData, len(sensorData))
+	for _, item := range sensorData {
+		sensorDataByMachineID[item.MachineID] = item
+	}
+
+	syncStateByMachineID := make(map[string]syncstate.SyncState, len(syncStates))
+	for _, row := range syncStates {
+		syncStateByMachineID[row.MachineID] = row.SyncState
+	}
+
+	machineIDs := make([]string, 0, len(sensorDataByMachineID)+len(syncStateByMachineID))
+	for machineID := range syncStateByMachineID {
+		machineIDs = append(machineIDs, machineID)
+	}
+	for machineID := range sensorDataByMachineID {
+		if _, ok := syncStateByMachineID[machineID]; !ok {
+			machineIDs = append(machineIDs, machineID)
+		}
+	}
+	sort.Strings(machineIDs)
+
+	staleAfter := time.Duration(thresholds.StaleDays) * 24 * time.Hour
+	for _, machineID := range machineIDs {
+		finding := Finding{MachineID: machineID}
+		if item, ok := sensorDataByMachineID[machineID]; ok {
+			finding.SerialNum = item.SerialNum
+			finding.PrimaryUser = item.PrimaryUser
+			finding.LastSeen = item.Time
+		}
+
+		if syncState, ok := syncStateByMachineID[machineID]; ok {
+			finding.SyncStatus = syncState.Status(now)
+			finding.LastSyncAt = syncState.PreflightAt
+			finding.LastCleanSync = syncState.LastCleanSync
+			finding.ConsecutiveCleanSyncs = syncState.ConsecutiveCleanSyncs
+
+			if lastSync, ok := syncState.LastSyncAt(); !ok || now.Sub(lastSync) > staleAfter {
+				finding.Problems = append(finding.Problems, ProblemStale)
+			}
+			if finding.SyncStatus.IsStalled() {
+				finding.Problems = append(finding.Problems, ProblemIncomplete)
+			}
+			if syncState.ConsecutiveCleanSyncs >= thresholds.RepeatedCleanSyncs {
+				finding.Problems = append(finding.Problems, ProblemRepeatedCleanSyncs)
+			}
+		} else {
+			// Every preflight saves the sync state right after the sensor data, so a machine without one never got
+			// far enough to sync
+			finding.Problems = append(finding.Problems, ProblemStale, ProblemIncomplete)
+		}
+
+		report.MachinesChecked++
+		if len(finding.Problems) == 0 {
+			continue
+		}
+		for _, problem := range finding.Problems {
+			report.Counts[problem]++
+		}
+		report.Findings = append(report.Findings, finding)
+	}
+	return report
+}
@@ -0,0 +1,102 @@
+package synchealth
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/airbnb/rudolph/pkg/clock"
+	"github.com/airbnb/rudolph/pkg/model/sensordata"
+	"github.com/airbnb/rudolph/pkg/model/syncstate"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func machineID(i int) string {
+	return fmt.Sprintf("AAAAAAAA-A00A-1234-1234-%012d", i)
+}
+
+func syncStateRow(machineID string, state syncstate.SyncState) syncstate.SyncStateRow {
+	state.MachineID = machineID
+	return syncstate.SyncStateRow{SyncState: state}
+}
+
+func Test_Assess(t *testing.T) {
+	syncStates := []syncstate.SyncStateRow{
+		// Healthy
+		syncStateRow(machineID(1), syncstate.SyncState{PreflightAt: "2022-01-10T11:00:00Z", PostflightAt: "2022-01-10T11:00:05Z"}),
+		// Has not synced in 9 days, but the last sync completed
+		syncStateRow(machineID(2), syncstate.SyncState{PreflightAt: "2022-01-01T00:00:00Z", PostflightAt: "2022-01-01T00:00:05Z"}),
+		// Stalled mid-ruledownload an hour ago
+		syncStateRow(machineID(3), syncstate.SyncState{PreflightAt: "2022-01-10T11:00:00Z", RuledownloadStartedAt: "2022-01-10T11:00:01Z"}),
+		// Clean syncing over and over
+		syncStateRow(machineID(4), syncstate.SyncState{PreflightAt: "2022-01-10T11:00:00Z", PostflightAt: "2022-01-10T11:00:05Z", CleanSync: true, ConsecutiveCleanSyncs: 5}),
+		// Still in progress
+		syncStateRow(machineID(5), syncstate.SyncState{PreflightAt: "2022-01-10T11:55:00Z"}),
+	}
+	sensorData := []sensordata.SensorData{
+		{MachineID: machineID(3), SerialNum: "C02ABC123DEF", PrimaryUser: "alice", Time: "2022-01-10T11:00:00Z"},
+		// Checked in but never saved a sync state
+		{MachineID: machineID(6), Time: "2022-01-10T11:00:00Z"},
+	}
+
+	report := Assess(now, DefaultThresholds(), syncStates, sensorData)
+
+	assert.Equal(t, 6, report.MachinesChecked)
+	assert.Len(t, report.Findings, 4)
+
+	assert.Equal(t, machineID(2), report.Findings[0].MachineID)
+	assert.Equal(t, []Problem{ProblemStale}, report.Findings[0].Problems)
+
+	assert.Equal(t, machineID(3), report.Findings[1].MachineID)
+	assert.Equal(t, []Problem{ProblemIncomplete}, report.Findings[1].Problems)
+	assert.Equal(t, syncstate.SyncStatusStalledInRuledownload, report.Findings[1].SyncStatus)
+	assert.Equal(t, "alice", report.Findings[1].PrimaryUser)
+
+	assert.Equal(t, machineID(4), report.Findings[2].MachineID)
+	assert.Equal(t, []Problem{ProblemRepeatedCleanSyncs}, report.Findings[2].Problems)
+
+	assert.Equal(t, machineID(6), report.Findings[3].MachineID)
+	assert.True(t, report.Findings[3].Has(ProblemStale))
+	assert.True(t, report.Findings[3].Has(ProblemIncomplete))
+
+	assert.Equal(t, map[Problem]int{ProblemStale: 2, ProblemIncomplete: 2, ProblemRepeatedCleanSyncs: 1}, report.Counts)
+}
+
+func Test_Assess_Thresholds(t *testing.T) {
+	syncStates := []syncstate.SyncStateRow{
+		syncStateRow(machineID(1), syncstate.SyncState{PreflightAt: "2022-01-08T12:00:00Z", PostflightAt: "2022-01-08T12:00:05Z", ConsecutiveCleanSyncs: 2}),
+	}
+
+	report := Assess(now, DefaultThresholds(), syncStates, nil)
+	assert.Empty(t, report.Findings)
+
+	report = Assess(now, Thresholds{StaleDays: 1, RepeatedCleanSyncs: 2}, syncStates, nil)
+	assert.Equal(t, []Problem{ProblemStale, ProblemRepeatedCleanSyncs}, report.Findings[0].Problems)
+}
+
+type mockStore struct {
+	syncStates []syncstate.SyncStateRow
+	sensorData []sensordata.SensorData
+}
+
+func (m mockStore) ListSyncStates() ([]syncstate.SyncStateRow, error) {
+	return m.syncStates, nil
+}
+
+func (m mockStore) ListSensorData() ([]sensordata.SensorData, error) {
+	return m.sensorData, nil
+}
+
+func Test_Check_ValidatesThresholds(t *testing.T) {
+	_, err := Check(mockStore{}, clock.FrozenTimeProvider{Current: now}, Thresholds{StaleDays: 0, RepeatedCleanSyncs: 3})
+	assert.Error(t, err)
+
+	_, err = Check(mockStore{}, clock.FrozenTimeProvider{Current: now}, Thresholds{StaleDays: 1, RepeatedCleanSyncs: 1})
+	assert.Error(t, err)
+
+	report, err := Check(mockStore{}, clock.FrozenTimeProvider{Current: now}, DefaultThresholds())
+	assert.Empty(t, err)
+	assert.Equal(t, now, report.GeneratedAt)
+}
@@ -10,11 +10,17 @@ import (
 type DataType string
 
 const (
-	DataTypeSensorData    DataType = "SensorData"
-	DataTypeSyncState     DataType = "SyncState"
-	DataTypeGlobalConfig  DataType = "GlobalConfig"
-	DataTypeMachineConfig DataType = "MachineConfig"
-	DataTypeRulesFeed     DataType = "RulesFeed"
+	DataTypeSensorData     DataType = "SensorData"
+	DataTypeSyncState      DataType = "SyncState"
+	DataTypeGlobalConfig   DataType = "GlobalConfig"
+	DataTypeMachineConfig  DataType = "MachineConfig"
+	DataTypeRulesFeed      DataType = "RulesFeed"
+	DataTypeEvent          DataType = "Event"
+	DataTypeCatalogEntry   DataType = "CatalogEntry"
+	DataTypeMachineGroup   DataType = "MachineGroup"
+	DataTypeModeTransition DataType = "ModeTransition"
+	DataTypeUnblockRequest DataType = "UnblockRequest"
+	DataTypeCatalogMember  DataType = "CatalogMember"
 )
 
 // UnmarshalText
@@ -50,6 +56,40 @@ func (dt *DataType) UnmarshalText(text []byte) error {
 		fallthrough
 	case "GlobalConfig":
 		*dt = DataTypeGlobalConfig
+	case "EVENT":
+		fallthrough
+	case "Event":
+		*dt = DataTypeEvent
+	case "CATALOG_ENTRY":
+		fallthrough
+	case "CATALOGENTRY":
+		fallthrough
+	case "CatalogEntry":
+		*dt = DataTypeCatalogEntry
+	case "MACHINE_GROUP":
+		fallthrough
+	case "MACHINEGROUP":
+		fallthrough
+	case "MachineGroup":
+		*dt = DataTypeMachineGroup
+	case "MODE_TRANSITION":
+		fallthrough
+	case "MODETRANSITION":
+		fallthrough
+	case "ModeTransition":
+		*dt = DataTypeModeTransition
+	case "UNBLOCK_REQUEST":
+		fallthrough
+	case "UNBLOCKREQUEST":
+		fallthrough
+	case "UnblockRequest":
+		*dt = DataTypeUnblockRequest
+	case "CATALOG_MEMBER":
+		fallthrough
+	case "CATALOGMEMBER":
+		fallthrough
+	case "CatalogMember":
+		*dt = DataTypeCatalogMember
 	default:
 		return fmt.Errorf("unknown data_type value %q", mode)
 	}
@@ -69,6 +109,18 @@ func (dt DataType) MarshalText() ([]byte, error) {
 		return []byte("GlobalConfig"), nil
 	case DataTypeRulesFeed:
 		return []byte("RulesFeed"), nil
+	case DataTypeEvent:
+		return []byte("Event"), nil
+	case DataTypeCatalogEntry:
+		return []byte("CatalogEntry"), nil
+	case DataTypeMachineGroup:
+		return []byte("MachineGroup"), nil
+	case DataTypeModeTransition:
+		return []byte("ModeTransition"), nil
+	case DataTypeUnblockRequest:
+		return []byte("UnblockRequest"), nil
+	case DataTypeCatalogMember:
+		return []byte("CatalogMember"), nil
 	default:
 		return nil, fmt.Errorf("unknown data_type %s", dt)
 	}
@@ -88,6 +140,18 @@ func (dt DataType) MarshalDynamoDBAttributeValue() (awstypes.AttributeValue, err
 		s = "GlobalConfig"
 	case DataTypeRulesFeed:
 		s = "RulesFeed"
+	case DataTypeEvent:
+		s = "Event"
+	case DataTypeCatalogEntry:
+		s = "CatalogEntry"
+	case DataTypeMachineGroup:
+		s = "MachineGroup"
+	case DataTypeModeTransition:
+		s = "ModeTransition"
+	case DataTypeUnblockRequest:
+		s = "UnblockRequest"
+	case DataTypeCatalogMember:
+		s = "CatalogMember"
 	default:
 		return nil, fmt.Errorf("unknown data_type value %q", dt)
 	}
@@ -142,6 +206,52 @@ func (dt *DataType) UnmarshalDynamoDBAttributeValue(av awstypes.AttributeValue)
 		fallthrough
 	case "RulesFeed":
 		*dt = DataTypeRulesFeed
+	case "6":
+		fallthrough
+	case "EVENT":
+		fallthrough
+	case "Event":
+		*dt = DataTypeEvent
+	case "7":
+		fallthrough
+	case "CATALOG_ENTRY":
+		fallthrough
+	case "CATALOGENTRY":
+		fallthrough
+	case "CatalogEntry":
+		*dt = DataTypeCatalogEntry
+	case "8":
+		fallthrough
+	case "MACHINE_GROUP":
+		fallthrough
+	case "MACHINEGROUP":
+		fallthrough
+	case "MachineGroup":
+		*dt = DataTypeMachineGroup
+	case "9":
+		fallthrough
+	case "MODE_TRANSITION":
+		fallthrough
+	case "MODETRANSITION":
+		fallthrough
+	case "ModeTransition":
+		*dt = DataTypeModeTransition
+	case "10":
+		fallthrough
+	case "UNBLOCK_REQUEST":
+		fallthrough
+	case "UNBLOCKREQUEST":
+		fallthrough
+	case "UnblockRequest":
+		*dt = DataTypeUnblockRequest
+	case "11":
+		fallthrough
+	case "CATALOG_MEMBER":
+		fallthrough
+	case "CATALOGMEMBER":
+		fallthrough
+	case "CatalogMember":
+		*dt = DataTypeCatalogMember
 	default:
 		return fmt.Errorf("unknown data_type value %q", t)
 	}
@@ -19,6 +19,12 @@ func TestDataTypes_MarshalTest(t *testing.T) {
 		{"RulesFeed", DataTypeRulesFeed, []byte(DataTypeRulesFeed), false},
 		{"MachineConfig", DataTypeMachineConfig, []byte(DataTypeMachineConfig), false},
 		{"GlobalConfig", DataTypeGlobalConfig, []byte(DataTypeGlobalConfig), false},
+		{"Event", DataTypeEvent, []byte(DataTypeEvent), false},
+		{"CatalogEntry", DataTypeCatalogEntry, []byte(DataTypeCatalogEntry), false},
+		{"MachineGroup", DataTypeMachineGroup, []byte(DataTypeMachineGroup), false},
+		{"ModeTransition", DataTypeModeTransition, []byte(DataTypeModeTransition), false},
+		{"UnblockRequest", DataTypeUnblockRequest, []byte(DataTypeUnblockRequest), false},
+		{"CatalogMember", DataTypeCatalogMember, []byte(DataTypeCatalogMember), false},
 		{"MISSPELLED", DataType(""), []byte(nil), true},
 	}
 
@@ -46,6 +52,12 @@ func TestDataTypes_UnmarshalText(t *testing.T) {
 		{"RulesFeed", []byte(DataTypeRulesFeed), DataTypeRulesFeed, false},
 		{"MachineConfig", []byte(DataTypeMachineConfig), DataTypeMachineConfig, false},
 		{"GlobalConfig", []byte(DataTypeGlobalConfig), DataTypeGlobalConfig, false},
+		{"Event", []byte(DataTypeEvent), DataTypeEvent, false},
+		{"CatalogEntry", []byte(DataTypeCatalogEntry), DataTypeCatalogEntry, false},
+		{"MachineGroup", []byte(DataTypeMachineGroup), DataTypeMachineGroup, false},
+		{"ModeTransition", []byte(DataTypeModeTransition), DataTypeModeTransition, false},
+		{"UnblockRequest", []byte(DataTypeUnblockRequest), DataTypeUnblockRequest, false},
+		{"CatalogMember", []byte(DataTypeCatalogMember), DataTypeCatalogMember, false},
 		{"MISSPELLED", []byte(""), DataType(""), true},
 	}
 	for _, tt := range tests {
@@ -73,6 +85,12 @@ func TestDataTypes_MarshalDynamoDBAttributeValue(t *testing.T) {
 		{"RulesFeed", DataTypeRulesFeed, &awstypes.AttributeValueMemberS{Value: string(DataTypeRulesFeed)}, false},
 		{"MachineConfig", DataTypeMachineConfig, &awstypes.AttributeValueMemberS{Value: string(DataTypeMachineConfig)}, false},
 		{"GlobalConfig", DataTypeGlobalConfig, &awstypes.AttributeValueMemberS{Value: string(DataTypeGlobalConfig)}, false},
+		{"Event", DataTypeEvent, &awstypes.AttributeValueMemberS{Value: string(DataTypeEvent)}, false},
+		{"CatalogEntry", DataTypeCatalogEntry, &awstypes.AttributeValueMemberS{Value: string(DataTypeCatalogEntry)}, false},
+		{"MachineGroup", DataTypeMachineGroup, &awstypes.AttributeValueMemberS{Value: string(DataTypeMachineGroup)}, false},
+		{"ModeTransition", DataTypeModeTransition, &awstypes.AttributeValueMemberS{Value: string(DataTypeModeTransition)}, false},
+		{"UnblockRequest", DataTypeUnblockRequest, &awstypes.AttributeValueMemberS{Value: string(DataTypeUnblockRequest)}, false},
+		{"CatalogMember", DataTypeCatalogMember, &awstypes.AttributeValueMemberS{Value: string(DataTypeCatalogMember)}, false},
 		{"MISSPELLED", DataType(""), nil, true},
 	}
 	for _, tt := range tests {
@@ -99,6 +117,12 @@ func TestDataTypes_UnmarshalDynamoDBAttributeValue(t *testing.T) {
 		{"RulesFeed", &awstypes.AttributeValueMemberS{Value: string(DataTypeRulesFeed)}, DataTypeRulesFeed, false},
 		{"MachineConfig", &awstypes.AttributeValueMemberS{Value: string(DataTypeMachineConfig)}, DataTypeMachineConfig, false},
 		{"GlobalConfig", &awstypes.AttributeValueMemberS{Value: string(DataTypeGlobalConfig)}, DataTypeGlobalConfig, false},
+		{"Event", &awstypes.AttributeValueMemberS{Value: string(DataTypeEvent)}, DataTypeEvent, false},
+		{"CatalogEntry", &awstypes.AttributeValueMemberS{Value: string(DataTypeCatalogEntry)}, DataTypeCatalogEntry, false},
+		{"MachineGroup", &awstypes.AttributeValueMemberS{Value: string(DataTypeMachineGroup)}, DataTypeMachineGroup, false},
+		{"ModeTransition", &awstypes.AttributeValueMemberS{Value: string(DataTypeModeTransition)}, DataTypeModeTransition, false},
+		{"UnblockRequest", &awstypes.AttributeValueMemberS{Value: string(DataTypeUnblockRequest)}, DataTypeUnblockRequest, false},
+		{"CatalogMember", &awstypes.AttributeValueMemberS{Value: string(DataTypeCatalogMember)}, DataTypeCatalogMember, false},
 		{"MISSPELLED", nil, DataType(""), true},
 	}
 	for _, tt := range tests {
@@ -18,6 +18,7 @@ func TestRuleType_MarshalText(t *testing.T) {
 		{"Certificate", RuleTypeCertificate, []byte("CERTIFICATE"), false},
 		{"SigningID", RuleTypeSigningID, []byte("SIGNINGID"), false},
 		{"TeamID", RuleTypeTeamID, []byte("TEAMID"), false},
+		{"CDHash", RuleTypeCDHash, []byte("CDHASH"), false},
 		{"Invalid", RuleType(0), nil, true},
 	}
 
@@ -44,6 +45,7 @@ func TestRuleType_UnmarshalText(t *testing.T) {
 		{"Certificate", []byte("CERTIFICATE"), RuleTypeCertificate, false},
 		{"SigningID", []byte("SIGNINGID"), RuleTypeSigningID, false},
 		{"TeamID", []byte("TEAMID"), RuleTypeTeamID, false},
+		{"CDHash", []byte("CDHASH"), RuleTypeCDHash, false},
 		{"Invalid", []byte("INVALID"), RuleType(0), true},
 	}
 
@@ -71,6 +73,7 @@ func TestRuleType_MarshalDynamoDBAttributeValue(t *testing.T) {
 		{"CERTIFICATE", RuleTypeCertificate, &awstypes.AttributeValueMemberN{Value: "2"}, false},
 		{"SIGNINGID", RuleTypeSigningID, &awstypes.AttributeValueMemberN{Value: "3"}, false},
 		{"TEAMID", RuleTypeTeamID, &awstypes.AttributeValueMemberN{Value: "4"}, false},
+		{"CDHASH", RuleTypeCDHash, &awstypes.AttributeValueMemberN{Value: "5"}, false},
 		{"INVALID", RuleType(0), nil, true},
 	}
 
@@ -97,6 +100,7 @@ func TestRuleType_UnmarshalDynamoDBAttributeValue(t *testing.T) {
 		{"CERTIFICATE", &awstypes.AttributeValueMemberN{Value: "2"}, RuleTypeCertificate, false},
 		{"SIGNINGID", &awstypes.AttributeValueMemberN{Value: "3"}, RuleTypeSigningID, false},
 		{"TEAMID", &awstypes.AttributeValueMemberN{Value: "4"}, RuleTypeTeamID, false},
+		{"CDHASH", &awstypes.AttributeValueMemberN{Value: "5"}, RuleTypeCDHash, false},
 		{"INVALID", nil, RuleType(0), true},
 	}
 	for _, tt := range tests {
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares dotted versions, such as macOS or Santa versions, component by component. Components are
+// compared numerically where both are numbers, so that "13.6" sorts before "14.0" and "2023.9" before "2023.10".
+// It returns a negative number when a is older than b, zero when they are equal and a positive number otherwise.
+func CompareVersions(a string, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNumber, aErr := strconv.Atoi(aParts[i])
+		bNumber, bErr := strconv.Atoi(bParts[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNumber != bNumber {
+				if aNumber < bNumber {
+					return -1
+				}
+				return 1
+			}
+		default:
+			if c := strings.Compare(aParts[i], bParts[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return len(aParts) - len(bParts)
+}
@@ -0,0 +1,15 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CompareVersions(t *testing.T) {
+	assert.Equal(t, -1, CompareVersions("13.6", "14.0"))
+	assert.Equal(t, -1, CompareVersions("2023.9", "2023.10"))
+	assert.Equal(t, 1, CompareVersions("2024.1", "2023.10"))
+	assert.Equal(t, 0, CompareVersions("14.2", "14.2"))
+	assert.Greater(t, CompareVersions("14.2.1", "14.2"), 0)
+}
@@ -11,6 +11,8 @@ BUILD_DIR=$DIR/build
 LINUX_BUILD_DIR=$BUILD_DIR/linux
 LINUX_BUILD_DIR_API=$LINUX_BUILD_DIR/api
 LINUX_BUILD_DIR_AUTHORIZER=$LINUX_BUILD_DIR/authorizer
+LINUX_BUILD_DIR_JOBS=$LINUX_BUILD_DIR/jobs
+LINUX_BUILD_DIR_CONSOLE=$LINUX_BUILD_DIR/console
 MACOS_BUILD_DIR=$BUILD_DIR/macos
 APPS_DIR=$DIR/cmd
 CLI_NAME=rudolph
@@ -18,6 +20,7 @@ CLI_BUILD_DIR=$BUILD_DIR/cli
 PKG_DIR=$BUILD_DIR/package
 API_DEPLOYMENT_ZIP_PATH=$PKG_DIR/api_deployment.zip
 API_AUTHORIZER_DEPLOYMENT_ZIP_PATH=$PKG_DIR/api_authorizer_deployment.zip
+JOBS_DEPLOYMENT_ZIP_PATH=$PKG_DIR/jobs_deployment.zip
 
 cd "$DIR"
 
@@ -33,6 +36,12 @@ GOOS=linux GOARCH=arm64 go build -o $LINUX_BUILD_DIR_API/bootstrap $APPS_DIR/api
 echo "  compiling authorizer in linux:arm64..."
 GOOS=linux GOARCH=arm64 go build -o $LINUX_BUILD_DIR_AUTHORIZER/bootstrap $APPS_DIR/authorizer
 
+echo "  compiling scheduled jobs in linux:arm64..."
+GOOS=linux GOARCH=arm64 go build -o $LINUX_BUILD_DIR_JOBS/bootstrap $APPS_DIR/jobs
+
+echo "  compiling web console in linux:arm64..."
+GOOS=linux GOARCH=arm64 go build -o $LINUX_BUILD_DIR_CONSOLE/console $APPS_DIR/console
+
 if [ "$(uname)" == "Darwin" ]; then
     echo "  compiling cross-compatible macOS cli..."
     GOOS=darwin GOARCH=amd64 go build -o $MACOS_BUILD_DIR/cli_amd64 $APPS_DIR/cli
@@ -51,12 +60,15 @@ mkdir $PKG_DIR
 # but you could use the -j option as well.
 cd $LINUX_BUILD_DIR_API; zip -r $API_DEPLOYMENT_ZIP_PATH *
 cd $LINUX_BUILD_DIR_AUTHORIZER; zip -r $API_AUTHORIZER_DEPLOYMENT_ZIP_PATH *
+cd $LINUX_BUILD_DIR_JOBS; zip -r $JOBS_DEPLOYMENT_ZIP_PATH *
 
 echo "*** complete ***"
 
 echo "  created:"
 echo "    API: $API_DEPLOYMENT_ZIP_PATH"
 echo "    API Authorizer: $API_AUTHORIZER_DEPLOYMENT_ZIP_PATH"
+echo "    Scheduled Jobs: $JOBS_DEPLOYMENT_ZIP_PATH"
+echo "    Web Console: $LINUX_BUILD_DIR_CONSOLE/console"
 if [ "$(uname)" == "Darwin" ]; then
     echo "    generated cross-compiled macOS cli"
     echo "    CLI: $MACOS_BUILD_DIR/cli"